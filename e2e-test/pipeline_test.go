@@ -303,7 +303,7 @@ var _ = Describe("Test the rest api about the pipeline", func() {
 	})
 
 	It("stop pipeline", func() {
-		By("update pipeline so that it will run for a while")
+		By("the dependencies of the steps must exist")
 		var req = apisv1.UpdatePipelineRequest{
 			Spec: model.WorkflowSpec{
 				Steps: []model.WorkflowStep{
@@ -319,6 +319,22 @@ var _ = Describe("Test the rest api about the pipeline", func() {
 			},
 		}
 		res := put("/projects/"+projectName1+"/pipelines/"+pipelineName, req)
+		Expect(res.StatusCode).Should(Equal(http.StatusBadRequest))
+
+		By("update pipeline so that it will run for a while")
+		req = apisv1.UpdatePipelineRequest{
+			Spec: model.WorkflowSpec{
+				Steps: []model.WorkflowStep{
+					{
+						WorkflowStepBase: model.WorkflowStepBase{
+							Name: "wait",
+							Type: "suspend",
+						},
+					},
+				},
+			},
+		}
+		res = put("/projects/"+projectName1+"/pipelines/"+pipelineName, req)
 		Expect(res.StatusCode).Should(Equal(http.StatusOK))
 
 		By("run the pipeline")
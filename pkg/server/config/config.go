@@ -18,6 +18,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/google/uuid"
@@ -61,6 +62,18 @@ type Config struct {
 
 	// ExitOnLostLeader will exit the process if this server lost the leader election, set this to true for debugging
 	ExitOnLostLeader bool
+
+	// RecycleBinRetention is how long the deleted applications, projects and pipelines could be restored
+	RecycleBinRetention time.Duration
+
+	// EnableEventOutbox saves the domain events to the datastore before dispatching, the events are redelivered if the process exits
+	EnableEventOutbox bool
+
+	// NotificationDeliveryRetention is how long the records of the notification deliveries are kept
+	NotificationDeliveryRetention time.Duration
+
+	// WebhookTrustedProxies the addresses of the proxies whose forwarded headers are trusted when checking the source of the webhook requests
+	WebhookTrustedProxies []string
 }
 
 // PluginConfig the plugin directory config
@@ -98,8 +111,10 @@ func NewConfig() *Config {
 			CorePluginPath:   "core-plugins",
 			CustomPluginPath: []string{"plugins"},
 		},
-		DexServerURL:     "http://dex.vela-system:5556",
-		ExitOnLostLeader: true,
+		DexServerURL:                  "http://dex.vela-system:5556",
+		ExitOnLostLeader:              true,
+		RecycleBinRetention:           time.Hour * 24 * 7,
+		NotificationDeliveryRetention: time.Hour * 24 * 7,
 	}
 }
 
@@ -111,6 +126,15 @@ func (s *Config) Validate() []error {
 		errs = append(errs, fmt.Errorf("not support datastore type %s", s.Datastore.Type))
 	}
 
+	for _, proxy := range s.WebhookTrustedProxies {
+		if net.ParseIP(proxy) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			errs = append(errs, fmt.Errorf("the trusted proxy %s is not an IP address or a CIDR", proxy))
+		}
+	}
+
 	return errs
 }
 
@@ -132,5 +156,9 @@ func (s *Config) AddFlags(fs *pflag.FlagSet, c *Config) {
 	fs.StringVar(&s.DexServerURL, "dex-server", c.DexServerURL, "the URL of the dex server.")
 	fs.StringArrayVar(&s.PluginConfig.CustomPluginPath, "plugin-path", c.PluginConfig.CustomPluginPath, "the path of the plugin directory")
 	fs.BoolVar(&s.ExitOnLostLeader, "exit-on-lost-leader", c.ExitOnLostLeader, "exit the process if this server lost the leader election")
+	fs.DurationVar(&s.RecycleBinRetention, "recycle-bin-retention", c.RecycleBinRetention, "how long the deleted applications, projects and pipelines are kept in the recycle bin before purging")
+	fs.DurationVar(&s.NotificationDeliveryRetention, "notification-delivery-retention", c.NotificationDeliveryRetention, "how long the records of the notification deliveries are kept before purging")
+	fs.BoolVar(&s.EnableEventOutbox, "enable-event-outbox", c.EnableEventOutbox, "save the domain events to the datastore outbox, the events that are not dispatched are redelivered by the leader")
+	fs.StringSliceVar(&s.WebhookTrustedProxies, "webhook-trusted-proxies", c.WebhookTrustedProxies, "the IP addresses or CIDRs of the proxies in front of the server, the X-Forwarded-For header of the webhook requests is trusted only if the request comes from them")
 	profiling.AddFlags(fs)
 }
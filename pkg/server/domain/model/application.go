@@ -27,12 +27,13 @@ import (
 )
 
 func init() {
-	RegisterModel(&ApplicationComponent{}, &ApplicationPolicy{}, &Application{}, &ApplicationRevision{}, &ApplicationTrigger{})
+	RegisterModel(&ApplicationComponent{}, &ApplicationPolicy{}, &Application{}, &ApplicationRevision{}, &ApplicationTrigger{}, &ScheduledDeploy{})
 }
 
 // Application application delivery model
 type Application struct {
 	BaseModel
+	SoftDeleteModel
 	Name        string            `json:"name" gorm:"primaryKey"`
 	Alias       string            `json:"alias"`
 	Project     string            `json:"project"`
@@ -40,6 +41,34 @@ type Application struct {
 	Icon        string            `json:"icon"`
 	Labels      map[string]string `json:"labels,omitempty" gorm:"serializer:json"`
 	Annotations map[string]string `json:"annotations,omitempty" gorm:"serializer:json"`
+	// Drift the difference between the rendered application and the application CR in the envs
+	Drift *ApplicationDrift `json:"drift,omitempty" gorm:"serializer:json"`
+}
+
+const (
+	// DriftStatusSynced means the application CRs in all envs are same as the rendered application
+	DriftStatusSynced = "synced"
+	// DriftStatusDrifted means the application CR in some env is changed out of VelaUX
+	DriftStatusDrifted = "drifted"
+)
+
+// ApplicationDrift the drift status of the application
+type ApplicationDrift struct {
+	Status string `json:"status,omitempty"`
+	// DetectTime the time of detecting the current status
+	DetectTime time.Time  `json:"detectTime,omitempty"`
+	Envs       []EnvDrift `json:"envs,omitempty"`
+	// AutoRemediate reset the application to the latest revision and redeploy it once the drift is detected
+	AutoRemediate     bool       `json:"autoRemediate,omitempty"`
+	LastRemediateTime *time.Time `json:"lastRemediateTime,omitempty"`
+}
+
+// EnvDrift the drift of the application CR in an env
+type EnvDrift struct {
+	EnvName string `json:"envName"`
+	Drifted bool   `json:"drifted"`
+	Diff    string `json:"diff,omitempty"`
+	Message string `json:"message,omitempty"`
 }
 
 // TableName return custom table name
@@ -292,8 +321,12 @@ type CodeInfo struct {
 	Commit string `json:"commit,omitempty"`
 	// Branch is the branch name
 	Branch string `json:"branch,omitempty"`
+	// Tag is the tag name if the revision is triggered by a tag
+	Tag string `json:"tag,omitempty"`
 	// User is the user name
 	User string `json:"user,omitempty"`
+	// CommitTime is the time of the commit, it is used to calculate the lead time for changes
+	CommitTime *time.Time `json:"commitTime,omitempty"`
 }
 
 // ImageInfo is the image info for webhook request
@@ -376,6 +409,75 @@ func (a *ApplicationRevision) Index() map[string]interface{} {
 	return index
 }
 
+const (
+	// ScheduledDeployStatusScheduled means the deploy is waiting for the schedule time
+	ScheduledDeployStatusScheduled = "scheduled"
+	// ScheduledDeployStatusExecuted means the deploy is executed
+	ScheduledDeployStatusExecuted = "executed"
+	// ScheduledDeployStatusPendingApproval means the deploy is held by the change request of the protected env
+	ScheduledDeployStatusPendingApproval = "pendingApproval"
+	// ScheduledDeployStatusFailed means the deploy is failed to execute
+	ScheduledDeployStatusFailed = "failed"
+	// ScheduledDeployStatusCanceled means the deploy is canceled before the schedule time
+	ScheduledDeployStatusCanceled = "canceled"
+)
+
+// ScheduledDeploy is the deploy request executed at the schedule time
+type ScheduledDeploy struct {
+	BaseModel
+	AppPrimaryKey  string     `json:"appPrimaryKey" gorm:"primaryKey"`
+	Name           string     `json:"name" gorm:"primaryKey"`
+	EnvName        string     `json:"envName"`
+	WorkflowName   string     `json:"workflowName"`
+	ScheduleTime   time.Time  `json:"scheduleTime"`
+	Creator        string     `json:"creator"`
+	Note           string     `json:"note,omitempty"`
+	TriggerType    string     `json:"triggerType"`
+	Force          bool       `json:"force"`
+	OverrideFreeze bool       `json:"overrideFreeze"`
+	CodeInfo       *CodeInfo  `json:"codeInfo,omitempty" gorm:"serializer:json"`
+	ImageInfo      *ImageInfo `json:"imageInfo,omitempty" gorm:"serializer:json"`
+	Status         string     `json:"status"`
+	Reason         string     `json:"reason,omitempty"`
+	// Revision the application revision created by the deploy
+	Revision string `json:"revision,omitempty"`
+	// ChangeRequest the change request that holds the deploy in the protected env
+	ChangeRequest string `json:"changeRequest,omitempty"`
+}
+
+// TableName return custom table name
+func (s *ScheduledDeploy) TableName() string {
+	return tableNamePrefix + "scheduled_deploy"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (s *ScheduledDeploy) ShortTableName() string {
+	return "sd"
+}
+
+// PrimaryKey return custom primary key
+func (s *ScheduledDeploy) PrimaryKey() string {
+	return fmt.Sprintf("%s-%s", s.AppPrimaryKey, s.Name)
+}
+
+// Index return custom index
+func (s *ScheduledDeploy) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if s.AppPrimaryKey != "" {
+		index["appPrimaryKey"] = s.AppPrimaryKey
+	}
+	if s.Name != "" {
+		index["name"] = s.Name
+	}
+	if s.EnvName != "" {
+		index["envName"] = s.EnvName
+	}
+	if s.Status != "" {
+		index["status"] = s.Status
+	}
+	return index
+}
+
 // ApplicationTrigger is the model for trigger
 type ApplicationTrigger struct {
 	BaseModel
@@ -389,6 +491,105 @@ type ApplicationTrigger struct {
 	PayloadType   string `json:"payloadType"`
 	ComponentName string `json:"componentName"`
 	Registry      string `json:"registry,omitempty"`
+	// Secret the shared secret to verify the requests, the requests are authenticated by the token only if it is empty
+	Secret string `json:"secret,omitempty"`
+	// SignatureType how the secret is carried by the requests, it is decided by the payload type if it is empty
+	SignatureType string `json:"signatureType,omitempty"`
+	// PreviousSecret the secret before rotating, it is still accepted until the expire time
+	PreviousSecret           string     `json:"previousSecret,omitempty"`
+	PreviousSecretExpireTime *time.Time `json:"previousSecretExpireTime,omitempty"`
+	// AllowedCIDRs the source addresses allowed to call the webhook, all addresses are allowed if it is empty
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty" gorm:"serializer:json"`
+	// GitEvents the git events to handle, only the push and tag events are handled if it is empty
+	GitEvents []string `json:"gitEvents,omitempty" gorm:"serializer:json"`
+	// BranchFilters the glob patterns of the branches to handle, all branches are handled if it is empty
+	BranchFilters []string `json:"branchFilters,omitempty" gorm:"serializer:json"`
+	// TagFilters the glob patterns of the tags to handle, all tags are handled if it is empty
+	TagFilters []string `json:"tagFilters,omitempty" gorm:"serializer:json"`
+	// PropertiesTemplate the template of the component properties rendered by the git event, such as `image: repo:{{.Tag}}`
+	PropertiesTemplate string `json:"propertiesTemplate,omitempty"`
+	// ImageProperties the properties patched by the pushed image, the `image` property is set to the image if it is empty
+	ImageProperties []ImagePropertyMapping `json:"imageProperties,omitempty" gorm:"serializer:json"`
+	// ImageTagFilter the pushed tags to handle, all tags are handled if it is empty
+	ImageTagFilter *ImageTagFilter `json:"imageTagFilter,omitempty" gorm:"serializer:json"`
+	// PayloadMapping extract the deploy request from the payload, it is required by the mapped payload type
+	PayloadMapping *PayloadMapping `json:"payloadMapping,omitempty" gorm:"serializer:json"`
+}
+
+// PayloadMapping extract the deploy request from an arbitrary JSON payload.
+// The values starting with `$` are the JSONPath expressions evaluated on the payload, the others are the literals.
+type PayloadMapping struct {
+	// Conditions the payload is handled only if all the conditions are matched
+	Conditions []PayloadCondition `json:"conditions,omitempty"`
+	// Action the action of the workflow, the same as the custom payload, it is execute if it is empty
+	Action string `json:"action,omitempty"`
+	Step   string `json:"step,omitempty"`
+	// Component the component to patch, the component of the trigger is used if it is empty
+	Component string `json:"component,omitempty"`
+	// Properties the property paths of the component and the value expressions
+	Properties map[string]string `json:"properties,omitempty"`
+	CodeInfo   *CodeInfoMapping  `json:"codeInfo,omitempty"`
+	ImageInfo  *ImageInfoMapping `json:"imageInfo,omitempty"`
+}
+
+// PayloadCondition match the value of the path in the payload
+type PayloadCondition struct {
+	Path string `json:"path"`
+	// Operator one of equals, notEquals, matches, exists and notExists, the value equals any of the values if the operator is equals
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// CodeInfoMapping the value expressions of the code info
+type CodeInfoMapping struct {
+	Commit string `json:"commit,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	Tag    string `json:"tag,omitempty"`
+	User   string `json:"user,omitempty"`
+	// CommitTime the RFC3339 time or the unix seconds
+	CommitTime string `json:"commitTime,omitempty"`
+}
+
+// ImageInfoMapping the value expressions of the image info
+type ImageInfoMapping struct {
+	URL        string `json:"url,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+const (
+	// PayloadConditionEquals the value equals any of the values
+	PayloadConditionEquals = "equals"
+	// PayloadConditionNotEquals the value equals none of the values
+	PayloadConditionNotEquals = "notEquals"
+	// PayloadConditionMatches the value matches the regular expression
+	PayloadConditionMatches = "matches"
+	// PayloadConditionExists the path exists in the payload
+	PayloadConditionExists = "exists"
+	// PayloadConditionNotExists the path does not exist in the payload
+	PayloadConditionNotExists = "notExists"
+)
+
+// ImagePropertyMapping set the property of the component to the value rendered by the pushed image
+type ImagePropertyMapping struct {
+	// Component the component to patch, the component of the trigger is used if it is empty
+	Component string `json:"component,omitempty"`
+	// Path the path of the property, such as `values.image.tag` or `containers[1].image`
+	Path string `json:"path"`
+	// Template the template of the value, the fields are Image, Repository, Tag and Digest, such as `{{.Repository}}@{{.Digest}}`
+	Template string `json:"template"`
+}
+
+// ImageTagFilter the rules of the pushed tags, the tag must match all the rules
+type ImageTagFilter struct {
+	// Regex the regular expression that the tag must match
+	Regex string `json:"regex,omitempty"`
+	// Semver the semantic version constraint that the tag must satisfy, such as `>= 1.2, < 2`
+	Semver string `json:"semver,omitempty"`
+	// Ignore the glob patterns of the ignored tags, such as `latest` and `*-test`
+	Ignore []string `json:"ignore,omitempty"`
 }
 
 const (
@@ -402,6 +603,42 @@ const (
 	PayloadTypeHarbor = "harbor"
 	// PayloadTypeJFrog is the payload type jfrog
 	PayloadTypeJFrog = "jfrog"
+	// PayloadTypeGitHub is the payload type of the GitHub push and pull request events
+	PayloadTypeGitHub = "github"
+	// PayloadTypeGitLab is the payload type of the GitLab push, tag push and merge request events
+	PayloadTypeGitLab = "gitlab"
+	// PayloadTypeGitea is the payload type of the Gitea push and pull request events
+	PayloadTypeGitea = "gitea"
+	// PayloadTypeBitbucket is the payload type of the Bitbucket Cloud push and pull request events
+	PayloadTypeBitbucket = "bitbucket"
+	// PayloadTypeMapped is the payload type of the arbitrary JSON extracted by the payload mapping of the trigger
+	PayloadTypeMapped = "mapped"
+	// PayloadTypeGHCR is the payload type of the GitHub package events of the container packages
+	PayloadTypeGHCR = "ghcr"
+	// PayloadTypeQuay is the payload type of the Quay repository push notification
+	PayloadTypeQuay = "quay"
+	// PayloadTypeECR is the payload type of the ECR Image Action event forwarded by the EventBridge
+	PayloadTypeECR = "ecr"
+	// PayloadTypeArtifactRegistry is the payload type of the Google Artifact Registry notification pushed by Pub/Sub
+	PayloadTypeArtifactRegistry = "artifact-registry"
+	// PayloadTypeGitLabRegistry is the payload type of the GitLab Container Registry notification
+	PayloadTypeGitLabRegistry = "gitlab-registry"
+
+	// GitEventPush the commits are pushed to a branch
+	GitEventPush = "push"
+	// GitEventTag the tag is pushed
+	GitEventTag = "tag"
+	// GitEventMergeRequest the merge request or the pull request is opened or updated
+	GitEventMergeRequest = "mergeRequest"
+
+	// SignatureTypeGitHub the HMAC-SHA256 of the body in the X-Hub-Signature-256 header
+	SignatureTypeGitHub = "github"
+	// SignatureTypeGitLab the secret in the X-Gitlab-Token header
+	SignatureTypeGitLab = "gitlab"
+	// SignatureTypeHarbor the secret in the Authorization header
+	SignatureTypeHarbor = "harbor"
+	// SignatureTypeHMAC the HMAC-SHA256 of the timestamp and the body in the X-VelaUX-Signature header
+	SignatureTypeHMAC = "hmac"
 
 	// ComponentTypeWebservice is the component type webservice
 	ComponentTypeWebservice = "webservice"
@@ -418,6 +655,16 @@ const (
 	JFrogEventTypePush = "pushed"
 	// JFrogDomainDocker is webhook domain of jfrog docker
 	JFrogDomainDocker = "docker"
+	// GHCRPackageTypeContainer is the package type of the container images
+	GHCRPackageTypeContainer = "CONTAINER"
+	// ECRActionTypePush is the action type of the pushed images
+	ECRActionTypePush = "PUSH"
+	// ECRResultSuccess is the result of the succeeded actions
+	ECRResultSuccess = "SUCCESS"
+	// ArtifactRegistryActionInsert is the action of the pushed images
+	ArtifactRegistryActionInsert = "INSERT"
+	// RegistryNotificationActionPush is the action of the pushed manifests
+	RegistryNotificationActionPush = "push"
 )
 
 // TableName return custom table name
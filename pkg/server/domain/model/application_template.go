@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import "fmt"
+
+func init() {
+	RegisterModel(&ApplicationTemplate{})
+}
+
+// ApplicationTemplate is one version of the template published from an application
+type ApplicationTemplate struct {
+	BaseModel
+	Name    string `json:"name" gorm:"primaryKey"`
+	Version string `json:"version" gorm:"primaryKey"`
+	// Project the template belongs to, the template is shared in the platform if it is empty
+	Project     string                         `json:"project"`
+	Description string                         `json:"description"`
+	CreateUser  string                         `json:"createUser"`
+	SourceApp   string                         `json:"sourceApp"`
+	Parameters  []ApplicationTemplateParameter `json:"parameters,omitempty" gorm:"serializer:json"`
+	Components  []ApplicationComponent         `json:"components,omitempty" gorm:"serializer:json"`
+	Policies    []ApplicationPolicy            `json:"policies,omitempty" gorm:"serializer:json"`
+	Workflows   []Workflow                     `json:"workflows,omitempty" gorm:"serializer:json"`
+	EnvBindings []EnvBinding                   `json:"envBindings,omitempty" gorm:"serializer:json"`
+}
+
+// ApplicationTemplateParameter the parameter sets a property of the component or the trait when instantiating the template
+type ApplicationTemplateParameter struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Component   string `json:"component"`
+	// Trait is the trait type, the parameter sets the component properties if it is empty
+	Trait string `json:"trait,omitempty"`
+	// Path is the dot-separated path of the property, such as `image` or `resources.cpu`
+	Path string `json:"path"`
+	// Default is taken from the source application if it is not specified when publishing
+	Default interface{} `json:"default,omitempty"`
+}
+
+// TableName return custom table name
+func (a *ApplicationTemplate) TableName() string {
+	return tableNamePrefix + "application_template"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (a *ApplicationTemplate) ShortTableName() string {
+	return "app_tpl"
+}
+
+// PrimaryKey return custom primary key, the template name can not contain the dot so the key is unambiguous
+func (a *ApplicationTemplate) PrimaryKey() string {
+	return fmt.Sprintf("%s.%s", a.Name, a.Version)
+}
+
+// Index return custom index
+func (a *ApplicationTemplate) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if a.Name != "" {
+		index["name"] = a.Name
+	}
+	if a.Version != "" {
+		index["version"] = a.Version
+	}
+	if a.Project != "" {
+		index["project"] = a.Project
+	}
+	return index
+}
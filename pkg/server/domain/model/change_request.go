@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+func init() {
+	RegisterModel(&ChangeRequest{})
+}
+
+const (
+	// ChangeRequestStatusPending means the change request is waiting for the approvals
+	ChangeRequestStatusPending = "pending"
+	// ChangeRequestStatusApproved means the change request is approved and could be deployed
+	ChangeRequestStatusApproved = "approved"
+	// ChangeRequestStatusRejected means the change request is rejected by an approver
+	ChangeRequestStatusRejected = "rejected"
+	// ChangeRequestStatusDeployed means the change request is deployed
+	ChangeRequestStatusDeployed = "deployed"
+	// ChangeRequestStatusOutdated means the application is changed after the change request is created
+	ChangeRequestStatusOutdated = "outdated"
+	// ChangeRequestStatusCanceled means the change request is canceled by the requester
+	ChangeRequestStatusCanceled = "canceled"
+)
+
+// ChangeRequest is the request of deploying the application to a protected env
+type ChangeRequest struct {
+	BaseModel
+	AppPrimaryKey string `json:"appPrimaryKey" gorm:"primaryKey"`
+	Name          string `json:"name" gorm:"primaryKey"`
+	Project       string `json:"project"`
+	EnvName       string `json:"envName"`
+	WorkflowName  string `json:"workflowName"`
+	// Requester the user that triggers the deploy
+	Requester   string `json:"requester"`
+	Note        string `json:"note,omitempty"`
+	TriggerType string `json:"triggerType"`
+	// Force the unfinished revision of the env is ignored when deploying the change request
+	Force bool `json:"force,omitempty"`
+	// ApplyAppConfig the application configuration rendered when the change request is created
+	ApplyAppConfig string `json:"applyAppConfig,omitempty"`
+	// Diff the unified diff from the configuration of the latest revision of the env
+	Diff      string     `json:"diff,omitempty"`
+	CodeInfo  *CodeInfo  `json:"codeInfo,omitempty" gorm:"serializer:json"`
+	ImageInfo *ImageInfo `json:"imageInfo,omitempty" gorm:"serializer:json"`
+
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	// MinApprovals the number of approvals required when the change request is created
+	MinApprovals int                   `json:"minApprovals"`
+	Reviews      []ChangeRequestReview `json:"reviews,omitempty" gorm:"serializer:json"`
+	// Revision the application revision created by deploying the change request
+	Revision string `json:"revision,omitempty"`
+}
+
+// ChangeRequestReview the review of an approver
+type ChangeRequestReview struct {
+	User       string    `json:"user"`
+	Approved   bool      `json:"approved"`
+	Comment    string    `json:"comment,omitempty"`
+	ReviewTime time.Time `json:"reviewTime"`
+}
+
+// Approvals return the number of the approvals
+func (c *ChangeRequest) Approvals() int {
+	var count int
+	for _, review := range c.Reviews {
+		if review.Approved {
+			count++
+		}
+	}
+	return count
+}
+
+// TableName return custom table name
+func (c *ChangeRequest) TableName() string {
+	return tableNamePrefix + "change_request"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (c *ChangeRequest) ShortTableName() string {
+	return "cr"
+}
+
+// PrimaryKey return custom primary key
+func (c *ChangeRequest) PrimaryKey() string {
+	return fmt.Sprintf("%s-%s", c.AppPrimaryKey, c.Name)
+}
+
+// Index return custom index
+func (c *ChangeRequest) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if c.AppPrimaryKey != "" {
+		index["appPrimaryKey"] = c.AppPrimaryKey
+	}
+	if c.Name != "" {
+		index["name"] = c.Name
+	}
+	if c.Project != "" {
+		index["project"] = c.Project
+	}
+	if c.EnvName != "" {
+		index["envName"] = c.EnvName
+	}
+	if c.Status != "" {
+		index["status"] = c.Status
+	}
+	if c.Requester != "" {
+		index["requester"] = c.Requester
+	}
+	return index
+}
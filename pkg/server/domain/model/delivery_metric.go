@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import "fmt"
+
+func init() {
+	RegisterModel(&DeliveryMetricRollup{})
+}
+
+// DeliveryMetricDateFormat the date format of the daily rollups
+const DeliveryMetricDateFormat = "2006-01-02"
+
+// DeliveryMetricRollup the daily delivery metrics of the application in an env, it is calculated from the revisions
+type DeliveryMetricRollup struct {
+	BaseModel
+	AppPrimaryKey string `json:"appPrimaryKey" gorm:"primaryKey"`
+	EnvName       string `json:"envName" gorm:"primaryKey"`
+	// Date the day in UTC, formatted as 2006-01-02
+	Date    string `json:"date" gorm:"primaryKey"`
+	Project string `json:"project"`
+	// Deployments the number of the finished deploys
+	Deployments int64 `json:"deployments"`
+	// Failures the number of the deploys that failed or were rolled back
+	Failures int64 `json:"failures"`
+	// LeadTimes the seconds from the commit to the completion of the deploys
+	LeadTimes []int64 `json:"leadTimes,omitempty" gorm:"serializer:json"`
+	// RestoreTimes the seconds from the failed deploys to the next successful deploys or rollbacks
+	RestoreTimes []int64 `json:"restoreTimes,omitempty" gorm:"serializer:json"`
+}
+
+// TableName return custom table name
+func (d *DeliveryMetricRollup) TableName() string {
+	return tableNamePrefix + "delivery_metric_rollup"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (d *DeliveryMetricRollup) ShortTableName() string {
+	return "dmr"
+}
+
+// PrimaryKey return custom primary key
+func (d *DeliveryMetricRollup) PrimaryKey() string {
+	return fmt.Sprintf("%s-%s-%s", d.AppPrimaryKey, d.EnvName, d.Date)
+}
+
+// Index return custom index
+func (d *DeliveryMetricRollup) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if d.AppPrimaryKey != "" {
+		index["appPrimaryKey"] = d.AppPrimaryKey
+	}
+	if d.EnvName != "" {
+		index["envName"] = d.EnvName
+	}
+	if d.Date != "" {
+		index["date"] = d.Date
+	}
+	if d.Project != "" {
+		index["project"] = d.Project
+	}
+	return index
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+func init() {
+	RegisterModel(&DeployLock{})
+}
+
+const (
+	// DeployLockStatusLocked means the lock is held until it is released or expired
+	DeployLockStatusLocked = "locked"
+	// DeployLockStatusReleased means the lock is released by the owner
+	DeployLockStatusReleased = "released"
+	// DeployLockStatusBroken means the lock is broken by another user with the permission
+	DeployLockStatusBroken = "broken"
+	// DeployLockStatusExpired means the lock is expired, it is only used for display
+	DeployLockStatusExpired = "expired"
+)
+
+// DeployLock blocks the deploys of the application in the env, the records are kept for auditing
+type DeployLock struct {
+	BaseModel
+	AppPrimaryKey string     `json:"appPrimaryKey" gorm:"primaryKey"`
+	Name          string     `json:"name" gorm:"primaryKey"`
+	Project       string     `json:"project"`
+	EnvName       string     `json:"envName"`
+	Owner         string     `json:"owner"`
+	Reason        string     `json:"reason"`
+	ExpireTime    *time.Time `json:"expireTime,omitempty"`
+	Status        string     `json:"status"`
+	// ReleaseUser the user that releases or breaks the lock
+	ReleaseUser   string     `json:"releaseUser,omitempty"`
+	ReleaseReason string     `json:"releaseReason,omitempty"`
+	ReleaseTime   *time.Time `json:"releaseTime,omitempty"`
+}
+
+// IsActive check whether the lock is held at the time
+func (d *DeployLock) IsActive(now time.Time) bool {
+	return d.Status == DeployLockStatusLocked && (d.ExpireTime == nil || now.Before(*d.ExpireTime))
+}
+
+// TableName return custom table name
+func (d *DeployLock) TableName() string {
+	return tableNamePrefix + "deploy_lock"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (d *DeployLock) ShortTableName() string {
+	return "dl"
+}
+
+// PrimaryKey return custom primary key
+func (d *DeployLock) PrimaryKey() string {
+	return fmt.Sprintf("%s-%s", d.AppPrimaryKey, d.Name)
+}
+
+// Index return custom index
+func (d *DeployLock) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if d.AppPrimaryKey != "" {
+		index["appPrimaryKey"] = d.AppPrimaryKey
+	}
+	if d.Name != "" {
+		index["name"] = d.Name
+	}
+	if d.Project != "" {
+		index["project"] = d.Project
+	}
+	if d.EnvName != "" {
+		index["envName"] = d.EnvName
+	}
+	if d.Status != "" {
+		index["status"] = d.Status
+	}
+	if d.Owner != "" {
+		index["owner"] = d.Owner
+	}
+	return index
+}
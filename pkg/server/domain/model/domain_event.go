@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+func init() {
+	RegisterModel(&DomainEvent{})
+}
+
+const (
+	// EventAppCreated the application is created
+	EventAppCreated = "AppCreated"
+	// EventAppDeleted the application is deleted
+	EventAppDeleted = "AppDeleted"
+	// EventAppDeployed the application revision is applied to the env
+	EventAppDeployed = "AppDeployed"
+	// EventAppDeployFailed the application revision failed to apply
+	EventAppDeployFailed = "AppDeployFailed"
+	// EventRevisionStatusChanged the status of the application revision is changed
+	EventRevisionStatusChanged = "RevisionStatusChanged"
+	// EventWorkflowRecordStatusChanged the status of the workflow record is changed
+	EventWorkflowRecordStatusChanged = "WorkflowRecordStatusChanged"
+	// EventPipelineRunStatusChanged the phase of the pipeline run is changed
+	EventPipelineRunStatusChanged = "PipelineRunStatusChanged"
+	// EventProjectCreated the project is created
+	EventProjectCreated = "ProjectCreated"
+	// EventProjectDeleted the project is deleted
+	EventProjectDeleted = "ProjectDeleted"
+	// EventUserAdded the user is created
+	EventUserAdded = "UserAdded"
+	// EventUserDeleted the user is deleted
+	EventUserDeleted = "UserDeleted"
+	// EventAddonEnabled the addon is enabled
+	EventAddonEnabled = "AddonEnabled"
+	// EventAddonDisabled the addon is disabled
+	EventAddonDisabled = "AddonDisabled"
+)
+
+// DomainEvent the event published by the services.
+// It is saved to the outbox before dispatching if the outbox is enabled, and removed after all subscribers handle it.
+type DomainEvent struct {
+	BaseModel
+	ID      string `json:"id" gorm:"primaryKey"`
+	Type    string `json:"type"`
+	Project string `json:"project,omitempty"`
+	// Resource the RBAC resource that the event belongs to, such as project/application, user and addon
+	Resource string `json:"resource"`
+	User     string `json:"user,omitempty"`
+	// Data the JSON of the typed payload, such as AppEventData
+	Data string    `json:"data,omitempty"`
+	Time time.Time `json:"time"`
+	// FailedSubscribers the subscribers that failed to handle the event, only they are retried by the redelivery
+	FailedSubscribers []string `json:"failedSubscribers,omitempty" gorm:"serializer:json"`
+	Attempts          int      `json:"attempts,omitempty"`
+}
+
+// TableName return custom table name
+func (d *DomainEvent) TableName() string {
+	return tableNamePrefix + "domain_event"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (d *DomainEvent) ShortTableName() string {
+	return "de"
+}
+
+// PrimaryKey return custom primary key
+func (d *DomainEvent) PrimaryKey() string {
+	return d.ID
+}
+
+// Index return custom index
+func (d *DomainEvent) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if d.ID != "" {
+		index["id"] = d.ID
+	}
+	if d.Type != "" {
+		index["type"] = d.Type
+	}
+	if d.Project != "" {
+		index["project"] = d.Project
+	}
+	return index
+}
+
+// DecodeData decode the typed payload of the event
+func (d *DomainEvent) DecodeData(data interface{}) error {
+	return json.Unmarshal([]byte(d.Data), data)
+}
+
+// AppEventData the payload of the application events
+type AppEventData struct {
+	AppName   string            `json:"appName"`
+	AppAlias  string            `json:"appAlias,omitempty"`
+	AppLabels map[string]string `json:"appLabels,omitempty"`
+	EnvName   string            `json:"envName,omitempty"`
+	Revision  string            `json:"revision,omitempty"`
+	Message   string            `json:"message,omitempty"`
+}
+
+// StatusChangedData the payload of the status changed events
+type StatusChangedData struct {
+	AppEventData    `json:",inline"`
+	WorkflowName    string `json:"workflowName,omitempty"`
+	RecordName      string `json:"recordName,omitempty"`
+	PipelineName    string `json:"pipelineName,omitempty"`
+	PipelineRunName string `json:"pipelineRunName,omitempty"`
+	From            string `json:"from,omitempty"`
+	To              string `json:"to"`
+}
+
+// ProjectEventData the payload of the project events
+type ProjectEventData struct {
+	ProjectName string `json:"projectName"`
+	Owner       string `json:"owner,omitempty"`
+}
+
+// UserEventData the payload of the user events
+type UserEventData struct {
+	UserName string `json:"userName"`
+	Email    string `json:"email,omitempty"`
+}
+
+// AddonEventData the payload of the addon events
+type AddonEventData struct {
+	AddonName string `json:"addonName"`
+	Version   string `json:"version,omitempty"`
+}
@@ -35,6 +35,19 @@ type Env struct {
 	// Targets defines the name of delivery target that belongs to this env
 	// In one project, a delivery target can only belong to one env.
 	Targets []string `json:"targets,omitempty" gorm:"serializer:json"`
+
+	// Protection the deploys of the env must be approved if it is set
+	Protection *EnvProtection `json:"protection,omitempty" gorm:"serializer:json"`
+}
+
+// EnvProtection the approval rules of deploying the applications to the env
+type EnvProtection struct {
+	// Approvers the users that could approve the deploys
+	Approvers []string `json:"approvers,omitempty"`
+	// ApproverRoles the users with the platform or project roles could approve the deploys
+	ApproverRoles []string `json:"approverRoles,omitempty"`
+	// MinApprovals the number of approvals required, the minimum is 1
+	MinApprovals int `json:"minApprovals"`
 }
 
 // TableName return custom table name
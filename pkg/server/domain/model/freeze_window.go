@@ -0,0 +1,83 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+func init() {
+	RegisterModel(&FreezeWindow{})
+}
+
+// FreezeWindow blocks the deploys of the applications and the runs of the pipelines in a period.
+// The window belongs to the platform if the project is empty, and belongs to an env if the env name is set.
+type FreezeWindow struct {
+	BaseModel
+	Name        string `json:"name" gorm:"primaryKey"`
+	Project     string `json:"project" gorm:"primaryKey"`
+	EnvName     string `json:"envName,omitempty"`
+	Alias       string `json:"alias,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Recurrence the start time of the recurring window, support the cron spec or the RRULE like "RRULE:FREQ=WEEKLY;BYDAY=SA;BYHOUR=0"
+	Recurrence string `json:"recurrence,omitempty"`
+	// Duration the length of each recurring window, such as 48h
+	Duration string `json:"duration,omitempty"`
+	// Timezone the location of the recurrence, default is UTC
+	Timezone string `json:"timezone,omitempty"`
+	// StartTime and EndTime define a one-off window
+	StartTime *time.Time `json:"startTime,omitempty"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
+	Disabled  bool       `json:"disabled"`
+	Creator   string     `json:"creator"`
+}
+
+// TableName return custom table name
+func (f *FreezeWindow) TableName() string {
+	return tableNamePrefix + "freeze_window"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (f *FreezeWindow) ShortTableName() string {
+	return "fw"
+}
+
+// PrimaryKey return custom primary key
+// The project and the name are joined with a dot, the names can not contain it, so the windows of the projects
+// never collide with the windows of the platform.
+func (f *FreezeWindow) PrimaryKey() string {
+	if f.Project == "" {
+		return f.Name
+	}
+	return fmt.Sprintf("%s.%s", f.Project, f.Name)
+}
+
+// Index return custom index
+func (f *FreezeWindow) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if f.Name != "" {
+		index["name"] = f.Name
+	}
+	if f.Project != "" {
+		index["project"] = f.Project
+	}
+	if f.EnvName != "" {
+		index["envName"] = f.EnvName
+	}
+	return index
+}
@@ -145,6 +145,29 @@ func (m *BaseModel) SetUpdateTime(time time.Time) {
 	m.UpdateTime = time
 }
 
+// SoftDeleteModel records who moved the record into the recycle bin and when
+type SoftDeleteModel struct {
+	DeleteTime *time.Time `json:"deleteTime,omitempty"`
+	Deleter    string     `json:"deleter,omitempty"`
+}
+
+// IsDeleted whether the record is in the recycle bin
+func (m *SoftDeleteModel) IsDeleted() bool {
+	return m.DeleteTime != nil
+}
+
+// MarkDeleted move the record into the recycle bin
+func (m *SoftDeleteModel) MarkDeleted(deleter string, time time.Time) {
+	m.Deleter = deleter
+	m.DeleteTime = &time
+}
+
+// ClearDeleted restore the record from the recycle bin
+func (m *SoftDeleteModel) ClearDeleted() {
+	m.Deleter = ""
+	m.DeleteTime = nil
+}
+
 func deepCopy(src interface{}) interface{} {
 	dst := reflect.New(reflect.TypeOf(src).Elem())
 
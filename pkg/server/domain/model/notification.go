@@ -0,0 +1,246 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+func init() {
+	RegisterModel(&NotificationChannel{}, &NotificationSubscription{}, &NotificationDelivery{})
+}
+
+const (
+	// NotificationChannelWebhook posts the event as JSON, the body is signed with HMAC-SHA256 if the secret is set
+	NotificationChannelWebhook = "webhook"
+	// NotificationChannelSlack posts the message to the Slack-compatible incoming webhook
+	NotificationChannelSlack = "slack"
+	// NotificationChannelDingTalk posts the message to the DingTalk robot
+	NotificationChannelDingTalk = "dingtalk"
+	// NotificationChannelLark posts the message to the Lark robot
+	NotificationChannelLark = "lark"
+	// NotificationChannelWeCom posts the message to the WeCom robot
+	NotificationChannelWeCom = "wecom"
+	// NotificationChannelEmail sends the message by SMTP
+	NotificationChannelEmail = "email"
+)
+
+const (
+	// NotificationEventDeploy the application is deployed to the env
+	NotificationEventDeploy = "application.deploy"
+	// NotificationEventDeployFailed the application failed to deploy to the env
+	NotificationEventDeployFailed = "application.deployFailed"
+	// NotificationEventWorkflowSucceeded the workflow of the application is succeeded
+	NotificationEventWorkflowSucceeded = "workflow.succeeded"
+	// NotificationEventWorkflowFailed the workflow of the application is failed
+	NotificationEventWorkflowFailed = "workflow.failed"
+	// NotificationEventWorkflowSuspending the workflow of the application is suspending
+	NotificationEventWorkflowSuspending = "workflow.suspending"
+	// NotificationEventWorkflowTerminated the workflow of the application is terminated
+	NotificationEventWorkflowTerminated = "workflow.terminated"
+	// NotificationEventPipelineExecuting the pipeline run is executing
+	NotificationEventPipelineExecuting = "pipeline.executing"
+	// NotificationEventPipelineSucceeded the pipeline run is succeeded
+	NotificationEventPipelineSucceeded = "pipeline.succeeded"
+	// NotificationEventPipelineFailed the pipeline run is failed
+	NotificationEventPipelineFailed = "pipeline.failed"
+	// NotificationEventPipelineSuspending the pipeline run is suspending
+	NotificationEventPipelineSuspending = "pipeline.suspending"
+	// NotificationEventPipelineTerminated the pipeline run is terminated
+	NotificationEventPipelineTerminated = "pipeline.terminated"
+)
+
+// NotificationEventTypes all the types of the events could be subscribed
+var NotificationEventTypes = []string{
+	NotificationEventDeploy, NotificationEventDeployFailed,
+	NotificationEventWorkflowSucceeded, NotificationEventWorkflowFailed, NotificationEventWorkflowSuspending, NotificationEventWorkflowTerminated,
+	NotificationEventPipelineExecuting, NotificationEventPipelineSucceeded, NotificationEventPipelineFailed, NotificationEventPipelineSuspending, NotificationEventPipelineTerminated,
+}
+
+const (
+	// NotificationDeliveryPending means the delivery is waiting for sending or retrying
+	NotificationDeliveryPending = "pending"
+	// NotificationDeliverySucceeded means the message is delivered
+	NotificationDeliverySucceeded = "succeeded"
+	// NotificationDeliveryFailed means the delivery is failed after all retries
+	NotificationDeliveryFailed = "failed"
+)
+
+// NotificationChannel the destination of the notifications in the project
+type NotificationChannel struct {
+	BaseModel
+	Name        string `json:"name" gorm:"primaryKey"`
+	Project     string `json:"project" gorm:"primaryKey"`
+	Alias       string `json:"alias,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	// URL the address of the webhook or the robot
+	URL string `json:"url,omitempty"`
+	// Secret signs the webhook body, or the DingTalk and Lark robot requests
+	Secret  string            `json:"secret,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" gorm:"serializer:json"`
+	Email   *EmailChannel     `json:"email,omitempty" gorm:"serializer:json"`
+	Creator string            `json:"creator"`
+}
+
+// EmailChannel the SMTP configuration of the email channel
+type EmailChannel struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// TableName return custom table name
+func (n *NotificationChannel) TableName() string {
+	return tableNamePrefix + "notification_channel"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (n *NotificationChannel) ShortTableName() string {
+	return "nc"
+}
+
+// PrimaryKey return custom primary key
+func (n *NotificationChannel) PrimaryKey() string {
+	return fmt.Sprintf("%s-%s", n.Project, n.Name)
+}
+
+// Index return custom index
+func (n *NotificationChannel) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if n.Name != "" {
+		index["name"] = n.Name
+	}
+	if n.Project != "" {
+		index["project"] = n.Project
+	}
+	if n.Type != "" {
+		index["type"] = n.Type
+	}
+	return index
+}
+
+// NotificationSubscription send the matched events to the channel
+type NotificationSubscription struct {
+	BaseModel
+	Name        string `json:"name" gorm:"primaryKey"`
+	Project     string `json:"project" gorm:"primaryKey"`
+	Alias       string `json:"alias,omitempty"`
+	Description string `json:"description,omitempty"`
+	Channel     string `json:"channel"`
+	// EventTypes the types of the events, all events are matched if it is empty
+	EventTypes []string `json:"eventTypes,omitempty" gorm:"serializer:json"`
+	// EnvNames the envs of the application events, all envs are matched if it is empty
+	EnvNames []string `json:"envNames,omitempty" gorm:"serializer:json"`
+	// AppSelector the labels that the application must have, the pipeline events are not matched if it is set
+	AppSelector map[string]string `json:"appSelector,omitempty" gorm:"serializer:json"`
+	// Template the go template of the message, the default template is used if it is empty
+	Template string `json:"template,omitempty"`
+	Disabled bool   `json:"disabled"`
+	Creator  string `json:"creator"`
+}
+
+// TableName return custom table name
+func (n *NotificationSubscription) TableName() string {
+	return tableNamePrefix + "notification_subscription"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (n *NotificationSubscription) ShortTableName() string {
+	return "ns"
+}
+
+// PrimaryKey return custom primary key
+func (n *NotificationSubscription) PrimaryKey() string {
+	return fmt.Sprintf("%s-%s", n.Project, n.Name)
+}
+
+// Index return custom index
+func (n *NotificationSubscription) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if n.Name != "" {
+		index["name"] = n.Name
+	}
+	if n.Project != "" {
+		index["project"] = n.Project
+	}
+	if n.Channel != "" {
+		index["channel"] = n.Channel
+	}
+	return index
+}
+
+// NotificationDelivery the log of sending a notification
+type NotificationDelivery struct {
+	BaseModel
+	Name         string    `json:"name" gorm:"primaryKey"`
+	Project      string    `json:"project"`
+	Subscription string    `json:"subscription"`
+	Channel      string    `json:"channel"`
+	EventType    string    `json:"eventType"`
+	Event        string    `json:"event"`
+	Message      string    `json:"message"`
+	Status       string    `json:"status"`
+	Attempts     int       `json:"attempts"`
+	NextTime     time.Time `json:"nextTime"`
+	Error        string    `json:"error,omitempty"`
+	// DeliverTime the time of the successful delivery
+	DeliverTime *time.Time `json:"deliverTime,omitempty"`
+}
+
+// TableName return custom table name
+func (n *NotificationDelivery) TableName() string {
+	return tableNamePrefix + "notification_delivery"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (n *NotificationDelivery) ShortTableName() string {
+	return "nd"
+}
+
+// PrimaryKey return custom primary key
+func (n *NotificationDelivery) PrimaryKey() string {
+	return n.Name
+}
+
+// Index return custom index
+func (n *NotificationDelivery) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if n.Name != "" {
+		index["name"] = n.Name
+	}
+	if n.Project != "" {
+		index["project"] = n.Project
+	}
+	if n.Subscription != "" {
+		index["subscription"] = n.Subscription
+	}
+	if n.Channel != "" {
+		index["channel"] = n.Channel
+	}
+	if n.EventType != "" {
+		index["eventType"] = n.EventType
+	}
+	if n.Status != "" {
+		index["status"] = n.Status
+	}
+	return index
+}
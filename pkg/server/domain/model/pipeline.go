@@ -18,6 +18,7 @@ package model
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kubevela/workflow/api/v1alpha1"
 )
@@ -25,6 +26,8 @@ import (
 func init() {
 	RegisterModel(&PipelineContext{})
 	RegisterModel(&Pipeline{})
+	RegisterModel(&PipelineVersion{})
+	RegisterModel(&PipelineTrigger{})
 }
 
 // Structs copied from workflow/api/v1alpha1/types.go
@@ -38,11 +41,73 @@ type WorkflowSpec struct {
 // Pipeline is the model of pipeline
 type Pipeline struct {
 	BaseModel
+	SoftDeleteModel
 	Spec        WorkflowSpec `gorm:"serializer:json"`
 	Name        string       `json:"name" gorm:"primaryKey"`
 	Project     string       `json:"project" gorm:"primaryKey"`
 	Alias       string       `json:"alias"`
 	Description string       `json:"description"`
+	// Schedules the cron schedules to run the pipeline
+	Schedules []PipelineSchedule `json:"schedules,omitempty" gorm:"serializer:json"`
+	// Version the current version of the spec, it is zero if the pipeline is created before the versions are recorded
+	Version int64 `json:"version"`
+	// Parameters the typed parameters of the runs, they are merged over the context values
+	Parameters []PipelineParameter `json:"parameters,omitempty" gorm:"serializer:json"`
+}
+
+const (
+	// PipelineParameterTypeString the value of the parameter is a string
+	PipelineParameterTypeString = "string"
+	// PipelineParameterTypeNumber the value of the parameter is a number
+	PipelineParameterTypeNumber = "number"
+	// PipelineParameterTypeBool the value of the parameter is true or false
+	PipelineParameterTypeBool = "bool"
+	// PipelineParameterTypeEnum the value of the parameter is one of the options
+	PipelineParameterTypeEnum = "enum"
+	// PipelineParameterTypeSecretRef the value of the parameter is `<secret name>/<key>` of the secret in the project namespace,
+	// it is injected into the run context as the reference of the secret key
+	PipelineParameterTypeSecretRef = "secret-ref"
+)
+
+// PipelineParameter is the typed parameter of the pipeline runs
+type PipelineParameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	// Required the run fails if the value is not set by the request, the context or the default
+	Required bool        `json:"required,omitempty"`
+	Default  interface{} `json:"default,omitempty"`
+	// Options the available values of the enum parameter
+	Options []string `json:"options,omitempty"`
+}
+
+const (
+	// ConcurrencyPolicyAllow the scheduled run starts even if the previous runs are not finished
+	ConcurrencyPolicyAllow = "allow"
+	// ConcurrencyPolicyForbid the scheduled run is skipped if the previous run is not finished
+	ConcurrencyPolicyForbid = "forbid"
+	// ConcurrencyPolicyReplace the unfinished runs are terminated before the scheduled run starts
+	ConcurrencyPolicyReplace = "replace"
+)
+
+// PipelineSchedule run the pipeline periodically
+type PipelineSchedule struct {
+	Name string `json:"name"`
+	// Cron the standard cron expression, such as `0 2 * * *`
+	Cron string `json:"cron"`
+	// TimeZone the IANA time zone of the cron expression, UTC by default
+	TimeZone          string `json:"timeZone,omitempty"`
+	ContextName       string `json:"contextName,omitempty"`
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+	Suspend           bool   `json:"suspend,omitempty"`
+	// Creator the pipeline is run as the user who sets the schedule
+	Creator string `json:"creator,omitempty"`
+	// StartTime the schedule fires after the time, it is reset when the cron, the time zone or the suspend flag is changed
+	StartTime    time.Time  `json:"startTime"`
+	LastFireTime *time.Time `json:"lastFireTime,omitempty"`
+	LastRunName  string     `json:"lastRunName,omitempty"`
+	// Message why the last fire is skipped or failed
+	Message string `json:"message,omitempty"`
 }
 
 // PrimaryKey return custom primary key
@@ -72,10 +137,64 @@ func (p Pipeline) Index() map[string]interface{} {
 	return index
 }
 
+// PipelineVersion is the immutable snapshot of the pipeline spec, it is recorded when the spec is changed
+type PipelineVersion struct {
+	BaseModel
+	Project      string       `json:"project" gorm:"primaryKey"`
+	PipelineName string       `json:"pipelineName" gorm:"primaryKey"`
+	Version      int64        `json:"version" gorm:"primaryKey"`
+	Spec         WorkflowSpec `json:"spec" gorm:"serializer:json"`
+	// Parameters the parameters of the pipeline are versioned with the spec
+	Parameters []PipelineParameter `json:"parameters,omitempty" gorm:"serializer:json"`
+	Author     string              `json:"author"`
+	Message    string              `json:"message"`
+	// RestoredFrom the version restored by this version
+	RestoredFrom int64 `json:"restoredFrom,omitempty"`
+}
+
+// PrimaryKey return custom primary key
+func (p *PipelineVersion) PrimaryKey() string {
+	return fmt.Sprintf("%s-%s-%d", p.Project, p.PipelineName, p.Version)
+}
+
+// TableName return custom table name
+func (p *PipelineVersion) TableName() string {
+	return tableNamePrefix + "pipeline_version"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (p *PipelineVersion) ShortTableName() string {
+	return "pp-ver"
+}
+
+// Index return custom index
+func (p *PipelineVersion) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if p.Project != "" {
+		index["project"] = p.Project
+	}
+	if p.PipelineName != "" {
+		index["pipelineName"] = p.PipelineName
+	}
+	if p.Version != 0 {
+		index["version"] = p.Version
+	}
+	return index
+}
+
+const (
+	// ContextValueTypePlain the value is stored in the context
+	ContextValueTypePlain = "plain"
+	// ContextValueTypeSecret the value is stored in the kubernetes secret of the pipeline, it is write-only
+	ContextValueTypeSecret = "secret"
+)
+
 // Value is a k-v pair
 type Value struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
+	// Type is plain or secret, plain by default
+	Type string `json:"type,omitempty"`
 }
 
 // PipelineContext is pipeline's context groups
@@ -109,3 +228,91 @@ func (c *PipelineContext) Index() map[string]interface{} {
 	}
 	return index
 }
+
+const (
+	// PipelineTriggerTypeWebhook the pipeline is run by the webhook requests
+	PipelineTriggerTypeWebhook = "webhook"
+	// PipelineTriggerTypePipeline the pipeline is run when a run of the source pipeline is completed
+	PipelineTriggerTypePipeline = "pipeline"
+)
+
+// PipelineTrigger run the pipeline by the webhook requests or the completion of another pipeline in the project.
+// The values of the parameters and the context are the JSONPath expressions evaluated on the payload, or the literals.
+type PipelineTrigger struct {
+	BaseModel
+	Project      string `json:"project"`
+	PipelineName string `json:"pipelineName"`
+	Name         string `json:"name"`
+	Alias        string `json:"alias,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Token        string `json:"token" gorm:"primaryKey"`
+	Type         string `json:"type"`
+	// PayloadType the provider of the webhook requests, the same as the application triggers
+	PayloadType string `json:"payloadType,omitempty"`
+	Registry    string `json:"registry,omitempty"`
+	// Secret the shared secret to verify the webhook requests, the requests are authenticated by the token only if it is empty
+	Secret        string   `json:"secret,omitempty"`
+	SignatureType string   `json:"signatureType,omitempty"`
+	AllowedCIDRs  []string `json:"allowedCIDRs,omitempty" gorm:"serializer:json"`
+	GitEvents     []string `json:"gitEvents,omitempty" gorm:"serializer:json"`
+	BranchFilters []string `json:"branchFilters,omitempty" gorm:"serializer:json"`
+	TagFilters    []string `json:"tagFilters,omitempty" gorm:"serializer:json"`
+	// ImageTagFilter the pushed image tags to handle, the same as the application triggers
+	ImageTagFilter *ImageTagFilter `json:"imageTagFilter,omitempty" gorm:"serializer:json"`
+	// SourcePipeline the pipeline whose completed runs run this pipeline
+	SourcePipeline string `json:"sourcePipeline,omitempty"`
+	// SourcePhases the phases of the completed source runs to handle, only the succeeded runs are handled if it is empty
+	SourcePhases []string `json:"sourcePhases,omitempty" gorm:"serializer:json"`
+	ContextName  string   `json:"contextName,omitempty"`
+	// Conditions the run is skipped if any condition is not matched by the payload
+	Conditions []PayloadCondition `json:"conditions,omitempty" gorm:"serializer:json"`
+	// Parameters the names of the pipeline parameters and the value expressions
+	Parameters map[string]string `json:"parameters,omitempty" gorm:"serializer:json"`
+	// ContextValues the keys of the run context and the value expressions, the parameters are merged over them
+	ContextValues map[string]string `json:"contextValues,omitempty" gorm:"serializer:json"`
+	// Creator the pipeline is run as the user who creates the trigger
+	Creator string `json:"creator,omitempty"`
+	// HandledTime the end time of the latest source run that has been handled
+	HandledTime *time.Time `json:"handledTime,omitempty"`
+	// HandledRuns the source runs that ended at the handled time, they are not handled again
+	HandledRuns []string `json:"handledRuns,omitempty" gorm:"serializer:json"`
+}
+
+// PrimaryKey return custom primary key
+func (p *PipelineTrigger) PrimaryKey() string {
+	return p.Token
+}
+
+// TableName return custom table name
+func (p *PipelineTrigger) TableName() string {
+	return tableNamePrefix + "pipeline_trigger"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (p *PipelineTrigger) ShortTableName() string {
+	return "pp-tg"
+}
+
+// Index return custom index
+func (p *PipelineTrigger) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if p.Project != "" {
+		index["project"] = p.Project
+	}
+	if p.PipelineName != "" {
+		index["pipelineName"] = p.PipelineName
+	}
+	if p.Token != "" {
+		index["token"] = p.Token
+	}
+	if p.Name != "" {
+		index["name"] = p.Name
+	}
+	if p.Type != "" {
+		index["type"] = p.Type
+	}
+	if p.SourcePipeline != "" {
+		index["sourcePipeline"] = p.SourcePipeline
+	}
+	return index
+}
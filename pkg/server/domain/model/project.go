@@ -23,11 +23,21 @@ func init() {
 // Project basic model
 type Project struct {
 	BaseModel
+	SoftDeleteModel
 	Name        string `json:"name" gorm:"primaryKey"`
 	Alias       string `json:"alias"`
 	Owner       string `json:"owner"`
 	Description string `json:"description,omitempty"`
 	Namespace   string `json:"namespace"`
+	// PromotionPath the ordered envs that the applications are promoted through
+	PromotionPath *PromotionPath `json:"promotionPath,omitempty" gorm:"serializer:json"`
+}
+
+// PromotionPath the ordered envs of the project
+type PromotionPath struct {
+	Envs []string `json:"envs"`
+	// ForbidSkip the application could only be promoted to the next env of the path
+	ForbidSkip bool `json:"forbidSkip"`
 }
 
 // GetNamespace get the namespace name of this project.
@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import "time"
+
+func init() {
+	RegisterModel(&WebhookNonce{}, &WebhookDelivery{})
+}
+
+// WebhookNonce the signed webhook request that has been handled, it is used to reject the replayed requests.
+// The idempotency keys reserved by the deliveries are recorded as the nonces too.
+type WebhookNonce struct {
+	BaseModel
+	// ID the hash of the trigger token and the nonce of the request
+	ID    string `json:"id" gorm:"primaryKey"`
+	Token string `json:"token"`
+	// DeliveryID the delivery that reserves the idempotency key
+	DeliveryID string    `json:"deliveryID,omitempty"`
+	ExpireTime time.Time `json:"expireTime"`
+}
+
+// TableName return custom table name
+func (w *WebhookNonce) TableName() string {
+	return tableNamePrefix + "webhook_nonce"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (w *WebhookNonce) ShortTableName() string {
+	return "wh_nc"
+}
+
+// PrimaryKey return custom primary key
+func (w *WebhookNonce) PrimaryKey() string {
+	return w.ID
+}
+
+// Index return custom index
+func (w *WebhookNonce) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if w.ID != "" {
+		index["id"] = w.ID
+	}
+	if w.Token != "" {
+		index["token"] = w.Token
+	}
+	if w.DeliveryID != "" {
+		index["deliveryID"] = w.DeliveryID
+	}
+	return index
+}
+
+const (
+	// WebhookDeliverySucceeded the delivery is handled, such as the application is deployed
+	WebhookDeliverySucceeded = "succeeded"
+	// WebhookDeliveryFailed the delivery is rejected or failed to handle
+	WebhookDeliveryFailed = "failed"
+	// WebhookDeliverySkipped the delivery is filtered by the trigger
+	WebhookDeliverySkipped = "skipped"
+	// WebhookDeliveryDuplicated the delivery has been handled before, it is not deployed again
+	WebhookDeliveryDuplicated = "duplicated"
+)
+
+// WebhookDelivery the inbound request of the application trigger
+type WebhookDelivery struct {
+	BaseModel
+	ID            string `json:"id" gorm:"primaryKey"`
+	AppPrimaryKey string `json:"appPrimaryKey"`
+	// TriggerName the name is kept after the token of the trigger is rotated
+	TriggerName string `json:"triggerName"`
+	// PayloadType the handler chosen to parse the body
+	PayloadType string `json:"payloadType"`
+	// Headers the request headers, the credentials are redacted
+	Headers map[string]string `json:"headers,omitempty" gorm:"serializer:json"`
+	// Body the request body, it is truncated if it is larger than the limit
+	Body          string `json:"body,omitempty"`
+	BodyTruncated bool   `json:"bodyTruncated,omitempty"`
+	SourceIP      string `json:"sourceIP,omitempty"`
+	// IdempotencyKey the hash of the delivery ID of the provider or the pushed image, the duplicated deliveries are not deployed
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	Status         string `json:"status"`
+	// Revision the application revision created by the delivery
+	Revision   string `json:"revision,omitempty"`
+	RecordName string `json:"recordName,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// ErrorCode the business code of the error
+	ErrorCode int32 `json:"errorCode,omitempty"`
+	// Latency the milliseconds to handle the delivery
+	Latency int64 `json:"latency"`
+	// ReplayOf the ID of the replayed delivery
+	ReplayOf string    `json:"replayOf,omitempty"`
+	User     string    `json:"user,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// TableName return custom table name
+func (w *WebhookDelivery) TableName() string {
+	return tableNamePrefix + "webhook_delivery"
+}
+
+// ShortTableName is the compressed version of table name for kubeapi storage and others
+func (w *WebhookDelivery) ShortTableName() string {
+	return "wh_dl"
+}
+
+// PrimaryKey return custom primary key
+func (w *WebhookDelivery) PrimaryKey() string {
+	return w.ID
+}
+
+// Index return custom index
+func (w *WebhookDelivery) Index() map[string]interface{} {
+	index := make(map[string]interface{})
+	if w.ID != "" {
+		index["id"] = w.ID
+	}
+	if w.AppPrimaryKey != "" {
+		index["appPrimaryKey"] = w.AppPrimaryKey
+	}
+	if w.TriggerName != "" {
+		index["triggerName"] = w.TriggerName
+	}
+	if w.Status != "" {
+		index["status"] = w.Status
+	}
+	if w.IdempotencyKey != "" {
+		index["idempotencyKey"] = w.IdempotencyKey
+	}
+	return index
+}
@@ -49,6 +49,7 @@ import (
 	velaerr "github.com/oam-dev/kubevela/pkg/utils/errors"
 	"github.com/oam-dev/kubevela/pkg/utils/schema"
 
+	"github.com/kubevela/velaux/pkg/server/domain/model"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/clients"
 	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 	"github.com/kubevela/velaux/pkg/server/utils/bcode"
@@ -137,6 +138,7 @@ type addonServiceImpl struct {
 	KubeClient         client.Client              `inject:"kubeClient"`
 	KubeConfig         *rest.Config               `inject:"kubeConfig"`
 	Apply              apply.Applicator           `inject:"apply"`
+	EventBus           EventBus                   `inject:""`
 	discoveryClient    *discovery.DiscoveryClient
 	mutex              *sync.RWMutex
 }
@@ -411,6 +413,7 @@ func (u *addonServiceImpl) EnableAddon(ctx context.Context, name string, args ap
 		// TODO: response the additional info to velaux users
 		_, err = pkgaddon.EnableAddon(ctx, name, args.Version, u.KubeClient, u.discoveryClient, u.Apply, u.KubeConfig, r, args.Args, u.addonRegistryCache, pkgaddon.FilterDependencyRegistries(i, registries))
 		if err == nil {
+			publishEvent(ctx, u.EventBus, newDomainEvent(model.EventAddonEnabled, "", "addon", model.AddonEventData{AddonName: name, Version: args.Version}))
 			return nil
 		}
 
@@ -439,6 +442,7 @@ func (u *addonServiceImpl) DisableAddon(ctx context.Context, name string, force
 		klog.Errorf("delete application fail: %s", err.Error())
 		return err
 	}
+	publishEvent(ctx, u.EventBus, newDomainEvent(model.EventAddonDisabled, "", "addon", model.AddonEventData{AddonName: name}))
 	return nil
 }
 
@@ -72,10 +72,18 @@ type ApplicationService interface {
 	GetApplicationStatus(ctx context.Context, app *model.Application, envName string) (*common.AppStatus, error)
 	GetApplicationStatusFromAllEnvs(ctx context.Context, app *model.Application) ([]*apisv1.ApplicationStatusResponse, error)
 	DetailApplication(ctx context.Context, app *model.Application) (*apisv1.DetailApplicationResponse, error)
-	PublishApplicationTemplate(ctx context.Context, app *model.Application) (*apisv1.ApplicationTemplateBase, error)
+	PublishApplicationTemplate(ctx context.Context, app *model.Application, req apisv1.CreateApplicationTemplateRequest, platform bool) (*apisv1.ApplicationTemplateBase, error)
+	ListApplicationTemplates(ctx context.Context, projectName string) (*apisv1.ListApplicationTemplateResponse, error)
+	PreviewApplicationTemplate(ctx context.Context, projectName, templateName string, req apisv1.PreviewApplicationTemplateRequest) (*apisv1.PreviewApplicationTemplateResponse, error)
+	CloneApplication(ctx context.Context, app *model.Application, req apisv1.CloneApplicationRequest) (*apisv1.ApplicationBase, error)
+	// ExportApplications export the applications of the project as a bundle, all applications are exported if the names are empty
+	ExportApplications(ctx context.Context, projectName string, appNames []string, format string) (*apisv1.ExportApplicationBundleResponse, error)
+	ImportApplications(ctx context.Context, projectName string, req apisv1.ImportApplicationBundleRequest) (*apisv1.ImportApplicationBundleResponse, error)
 	CreateApplication(context.Context, apisv1.CreateApplicationRequest) (*apisv1.ApplicationBase, error)
 	UpdateApplication(context.Context, *model.Application, apisv1.UpdateApplicationRequest) (*apisv1.ApplicationBase, error)
 	DeleteApplication(ctx context.Context, app *model.Application) error
+	RestoreApplication(ctx context.Context, app *model.Application) error
+	PurgeApplication(ctx context.Context, app *model.Application) error
 	Deploy(ctx context.Context, app *model.Application, req apisv1.ApplicationDeployRequest) (*apisv1.ApplicationDeployResponse, error)
 	GetApplicationComponent(ctx context.Context, app *model.Application, componentName string) (*model.ApplicationComponent, error)
 	ListComponents(ctx context.Context, app *model.Application, op apisv1.ListApplicationComponentOptions) ([]*apisv1.ComponentBase, error)
@@ -92,17 +100,34 @@ type ApplicationService interface {
 	DeleteApplicationTrait(ctx context.Context, app *model.Application, component *model.ApplicationComponent, traitType string) error
 	UpdateApplicationTrait(ctx context.Context, app *model.Application, component *model.ApplicationComponent, traitType string, req apisv1.UpdateApplicationTraitRequest) (*apisv1.ApplicationTrait, error)
 	ListRevisions(ctx context.Context, appName, envName, status string, page, pageSize int) (*apisv1.ListRevisionsResponse, error)
+	ListChangeRequests(ctx context.Context, app *model.Application, envName, status string, page, pageSize int) (*apisv1.ListChangeRequestsResponse, error)
+	DetailChangeRequest(ctx context.Context, app *model.Application, name string) (*apisv1.DetailChangeRequestResponse, error)
+	ReviewChangeRequest(ctx context.Context, app *model.Application, name string, req apisv1.ReviewChangeRequestRequest) (*apisv1.ChangeRequestBase, error)
+	DeployChangeRequest(ctx context.Context, app *model.Application, name string) (*apisv1.ApplicationDeployResponse, error)
+	CancelChangeRequest(ctx context.Context, app *model.Application, name string) (*apisv1.ChangeRequestBase, error)
+	ListDeployLocks(ctx context.Context, app *model.Application, envName string) (*apisv1.ListDeployLocksResponse, error)
+	CreateDeployLock(ctx context.Context, app *model.Application, req apisv1.CreateDeployLockRequest) (*apisv1.DeployLockBase, error)
+	ReleaseDeployLock(ctx context.Context, app *model.Application, name string, req apisv1.ReleaseDeployLockRequest) (*apisv1.DeployLockBase, error)
+	CancelScheduledDeploy(ctx context.Context, app *model.Application, name string) error
+	// ExecuteScheduledDeploys deploy the applications that reach the schedule time
+	ExecuteScheduledDeploys(ctx context.Context) error
 	DetailRevision(ctx context.Context, appName, revisionName string) (*apisv1.DetailRevisionResponse, error)
+	CompareRevisions(ctx context.Context, app *model.Application, req apisv1.CompareRevisionsRequest) (*apisv1.CompareRevisionsResponse, error)
+	PromoteApplication(ctx context.Context, app *model.Application, req apisv1.PromoteApplicationRequest) (*apisv1.PromoteApplicationResponse, error)
 	RollbackWithRevision(ctx context.Context, app *model.Application, revisionName string) (*apisv1.ApplicationRollbackResponse, error)
 	Statistics(ctx context.Context, app *model.Application) (*apisv1.ApplicationStatisticsResponse, error)
 	ListRecords(ctx context.Context, appName string) (*apisv1.ListWorkflowRecordsResponse, error)
 	CompareApp(ctx context.Context, app *model.Application, compareReq apisv1.AppCompareReq) (*apisv1.AppCompareResponse, error)
 	ResetAppToLatestRevision(ctx context.Context, appName string) (*apisv1.AppResetResponse, error)
+	GetApplicationDrift(ctx context.Context, app *model.Application, refresh bool) (*apisv1.ApplicationDriftResponse, error)
+	UpdateApplicationDrift(ctx context.Context, app *model.Application, req apisv1.UpdateApplicationDriftRequest) (*apisv1.ApplicationDriftResponse, error)
+	DetectDrifts(ctx context.Context) error
 	DryRunAppOrRevision(ctx context.Context, app *model.Application, dryRunReq apisv1.AppDryRunReq) (*apisv1.AppDryRunResponse, error)
 	CreateApplicationTrigger(ctx context.Context, app *model.Application, req apisv1.CreateApplicationTriggerRequest) (*apisv1.ApplicationTriggerBase, error)
 	ListApplicationTriggers(ctx context.Context, app *model.Application) ([]*apisv1.ApplicationTriggerBase, error)
 	DeleteApplicationTrigger(ctx context.Context, app *model.Application, triggerName string) error
 	UpdateApplicationTrigger(ctx context.Context, app *model.Application, token string, req apisv1.UpdateApplicationTriggerRequest) (*apisv1.ApplicationTriggerBase, error)
+	RotateApplicationTrigger(ctx context.Context, app *model.Application, token string, req apisv1.RotateApplicationTriggerRequest) (*apisv1.RotateApplicationTriggerResponse, error)
 }
 
 type applicationServiceImpl struct {
@@ -117,6 +142,8 @@ type applicationServiceImpl struct {
 	DefinitionService DefinitionService   `inject:""`
 	ProjectService    ProjectService      `inject:""`
 	UserService       UserService         `inject:""`
+	RbacService       RBACService         `inject:""`
+	EventBus          EventBus            `inject:""`
 }
 
 // NewApplicationService new application service
@@ -149,7 +176,7 @@ func listApp(ctx context.Context, ds datastore.DataStore, listOptions apisv1.Lis
 	var list []*model.Application
 	for _, entity := range entities {
 		appModel, ok := entity.(*model.Application)
-		if !ok {
+		if !ok || appModel.IsDeleted() {
 			continue
 		}
 		if listOptions.Query != "" &&
@@ -177,6 +204,9 @@ func listApp(ctx context.Context, ds datastore.DataStore, listOptions apisv1.Lis
 				continue
 			}
 		}
+		if listOptions.DriftStatus != "" && (appModel.Drift == nil || appModel.Drift.Status != listOptions.DriftStatus) {
+			continue
+		}
 		if len(listOptions.Labels) > 0 {
 			matchLabels := false
 			for k, v := range listOptions.Labels {
@@ -244,6 +274,9 @@ func (c *applicationServiceImpl) GetApplication(ctx context.Context, appName str
 		}
 		return nil, err
 	}
+	if app.IsDeleted() {
+		return nil, bcode.ErrApplicationNotExist
+	}
 	return &app, nil
 }
 
@@ -389,12 +422,6 @@ func (c *applicationServiceImpl) GetApplicationCR(ctx context.Context, appModel
 	return apps, nil
 }
 
-// PublishApplicationTemplate publish appUtil template
-func (c *applicationServiceImpl) PublishApplicationTemplate(_ context.Context, _ *model.Application) (*apisv1.ApplicationTemplateBase, error) {
-	// TODO:
-	return nil, nil
-}
-
 // CreateApplication create application
 func (c *applicationServiceImpl) CreateApplication(ctx context.Context, req apisv1.CreateApplicationRequest) (*apisv1.ApplicationBase, error) {
 	application := model.Application{
@@ -420,6 +447,33 @@ func (c *applicationServiceImpl) CreateApplication(ctx context.Context, req apis
 	}
 	application.Project = project.Name
 
+	var template *model.ApplicationTemplate
+	// the records added from the template are deleted if the application is not created
+	var templateRecords []datastore.Entity
+	created := false
+	defer func() {
+		if !created {
+			c.deleteTemplateRecords(ctx, templateRecords)
+		}
+	}()
+	if req.Template != nil {
+		if req.Component != nil {
+			return nil, bcode.ErrApplicationTemplateComponentConflict
+		}
+		template, err = c.renderApplicationTemplate(ctx, project.Name, req.Template.Name, req.Template.Version, req.Template.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		if len(req.EnvBinding) == 0 {
+			req.EnvBinding = c.templateEnvBindings(ctx, project.Name, template)
+		}
+		records, err := c.createTemplateComponents(ctx, &application, template)
+		templateRecords = append(templateRecords, records...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if req.Component != nil {
 		_, err = c.createComponent(ctx, &application, *req.Component, true)
 		if err != nil {
@@ -433,6 +487,13 @@ func (c *applicationServiceImpl) CreateApplication(ctx context.Context, req apis
 		if err != nil {
 			return nil, err
 		}
+		if template != nil {
+			records, err := c.saveTemplateEnvResources(ctx, &application, template, req.EnvBinding)
+			templateRecords = append(templateRecords, records...)
+			if err != nil {
+				return nil, err
+			}
+		}
 		// For the custom payload, no need assign the component name
 		if _, err := c.CreateApplicationTrigger(ctx, &application, apisv1.CreateApplicationTriggerRequest{
 			Name:         fmt.Sprintf("%s-%s", application.Name, "default"),
@@ -450,6 +511,8 @@ func (c *applicationServiceImpl) CreateApplication(ctx context.Context, req apis
 		}
 		return nil, err
 	}
+	created = true
+	publishEvent(ctx, c.EventBus, newDomainEvent(model.EventAppCreated, application.Project, "project/application", newAppEventData(&application, "", "")))
 	// render appUtil base info.
 	base := assembler.ConvertAppModelToBase(&application, []*apisv1.ProjectBase{project})
 	return base, nil
@@ -468,18 +531,40 @@ func (c *applicationServiceImpl) CreateApplicationTrigger(ctx context.Context, a
 			return nil, err
 		}
 	}
+	if err := checkAllowedCIDRs(req.AllowedCIDRs); err != nil {
+		return nil, err
+	}
+	if err := checkGitTriggerRules(req.BranchFilters, req.TagFilters, req.PropertiesTemplate); err != nil {
+		return nil, err
+	}
+	if err := checkImageTriggerRules(req.ImageProperties, req.ImageTagFilter); err != nil {
+		return nil, err
+	}
+	if err := checkPayloadMapping(req.PayloadType, req.PayloadMapping); err != nil {
+		return nil, err
+	}
 
 	trigger := &model.ApplicationTrigger{
-		AppPrimaryKey: app.Name,
-		WorkflowName:  req.WorkflowName,
-		Name:          req.Name,
-		Alias:         req.Alias,
-		Description:   req.Description,
-		Type:          req.Type,
-		PayloadType:   req.PayloadType,
-		ComponentName: req.ComponentName,
-		Registry:      req.Registry,
-		Token:         genWebhookToken(),
+		AppPrimaryKey:      app.Name,
+		WorkflowName:       req.WorkflowName,
+		Name:               req.Name,
+		Alias:              req.Alias,
+		Description:        req.Description,
+		Type:               req.Type,
+		PayloadType:        req.PayloadType,
+		ComponentName:      req.ComponentName,
+		Registry:           req.Registry,
+		Secret:             req.Secret,
+		SignatureType:      req.SignatureType,
+		AllowedCIDRs:       req.AllowedCIDRs,
+		GitEvents:          req.GitEvents,
+		BranchFilters:      req.BranchFilters,
+		TagFilters:         req.TagFilters,
+		PropertiesTemplate: req.PropertiesTemplate,
+		ImageProperties:    req.ImageProperties,
+		ImageTagFilter:     req.ImageTagFilter,
+		PayloadMapping:     req.PayloadMapping,
+		Token:              genWebhookToken(),
 	}
 	if err := c.Store.Add(ctx, trigger); err != nil {
 		klog.Errorf("failed to create application trigger, %s", err.Error())
@@ -529,18 +614,89 @@ func (c *applicationServiceImpl) UpdateApplicationTrigger(ctx context.Context, a
 			return nil, err
 		}
 	}
+	if err := checkAllowedCIDRs(req.AllowedCIDRs); err != nil {
+		return nil, err
+	}
+	if err := checkGitTriggerRules(req.BranchFilters, req.TagFilters, req.PropertiesTemplate); err != nil {
+		return nil, err
+	}
+	if err := checkImageTriggerRules(req.ImageProperties, req.ImageTagFilter); err != nil {
+		return nil, err
+	}
+	if err := checkPayloadMapping(req.PayloadType, req.PayloadMapping); err != nil {
+		return nil, err
+	}
 	trigger.Alias = req.Alias
 	trigger.ComponentName = req.ComponentName
 	trigger.Description = req.Description
 	trigger.WorkflowName = req.WorkflowName
 	trigger.Registry = req.Registry
 	trigger.PayloadType = req.PayloadType
+	trigger.SignatureType = req.SignatureType
+	trigger.AllowedCIDRs = req.AllowedCIDRs
+	trigger.GitEvents = req.GitEvents
+	trigger.BranchFilters = req.BranchFilters
+	trigger.TagFilters = req.TagFilters
+	trigger.PropertiesTemplate = req.PropertiesTemplate
+	trigger.ImageProperties = req.ImageProperties
+	trigger.ImageTagFilter = req.ImageTagFilter
+	trigger.PayloadMapping = req.PayloadMapping
+	if req.Secret != "" {
+		trigger.Secret = req.Secret
+	}
 	if err := c.Store.Put(ctx, &trigger); err != nil {
 		return nil, err
 	}
 	return assembler.ConvertTrigger2DTO(trigger), nil
 }
 
+// RotateApplicationTrigger regenerate the token or the secret of the trigger, the other settings are kept
+func (c *applicationServiceImpl) RotateApplicationTrigger(ctx context.Context, app *model.Application, token string, req apisv1.RotateApplicationTriggerRequest) (*apisv1.RotateApplicationTriggerResponse, error) {
+	trigger := model.ApplicationTrigger{
+		AppPrimaryKey: app.PrimaryKey(),
+		Token:         token,
+	}
+	if err := c.Store.Get(ctx, &trigger); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrApplicationTriggerNotExist
+		}
+		return nil, err
+	}
+	if trigger.AppPrimaryKey != app.PrimaryKey() {
+		return nil, bcode.ErrApplicationTriggerNotExist
+	}
+	var secret string
+	if req.Secret {
+		secret = genWebhookSecret()
+		trigger.PreviousSecret = ""
+		trigger.PreviousSecretExpireTime = nil
+		if trigger.Secret != "" && req.SecretGracePeriod > 0 {
+			expireTime := time.Now().Add(time.Duration(req.SecretGracePeriod) * time.Second)
+			trigger.PreviousSecret = trigger.Secret
+			trigger.PreviousSecretExpireTime = &expireTime
+		}
+		trigger.Secret = secret
+	}
+	if req.Token {
+		// the token is the primary key, so the trigger is saved as a new record
+		trigger.Token = genWebhookToken()
+		if err := c.Store.Add(ctx, &trigger); err != nil {
+			return nil, err
+		}
+		// the old token must not work after the rotation, remove the new record if the old one can not be deleted
+		if err := c.Store.Delete(ctx, &model.ApplicationTrigger{AppPrimaryKey: app.PrimaryKey(), Token: token}); err != nil {
+			klog.Errorf("failed to delete the trigger with the rotated token: %s", err.Error())
+			if err := c.Store.Delete(ctx, &trigger); err != nil {
+				klog.Errorf("failed to delete the trigger with the new token: %s", err.Error())
+			}
+			return nil, err
+		}
+	} else if err := c.Store.Put(ctx, &trigger); err != nil {
+		return nil, err
+	}
+	return &apisv1.RotateApplicationTriggerResponse{ApplicationTriggerBase: *assembler.ConvertTrigger2DTO(trigger), Secret: secret}, nil
+}
+
 // ListApplicationTrigger list application triggers
 func (c *applicationServiceImpl) ListApplicationTriggers(ctx context.Context, app *model.Application) ([]*apisv1.ApplicationTriggerBase, error) {
 	trigger := &model.ApplicationTrigger{
@@ -558,18 +714,7 @@ func (c *applicationServiceImpl) ListApplicationTriggers(ctx context.Context, ap
 	for _, raw := range triggers {
 		trigger, ok := raw.(*model.ApplicationTrigger)
 		if ok {
-			resp = append(resp, &apisv1.ApplicationTriggerBase{
-				WorkflowName:  trigger.WorkflowName,
-				Name:          trigger.Name,
-				Alias:         trigger.Alias,
-				Description:   trigger.Description,
-				Type:          trigger.Type,
-				PayloadType:   trigger.PayloadType,
-				Token:         trigger.Token,
-				UpdateTime:    trigger.UpdateTime,
-				CreateTime:    trigger.CreateTime,
-				ComponentName: trigger.ComponentName,
-			})
+			resp = append(resp, assembler.ConvertTrigger2DTO(*trigger))
 		}
 	}
 	return resp, nil
@@ -739,7 +884,13 @@ func (c *applicationServiceImpl) DetailPolicy(ctx context.Context, app *model.Ap
 // Deploy deploys appUtil to cluster
 // means to render oam application config and apply to cluster.
 // An event record is generated for each deploy.
+// The deploy to a protected env is held by a change request until it is approved.
 func (c *applicationServiceImpl) Deploy(ctx context.Context, app *model.Application, req apisv1.ApplicationDeployRequest) (*apisv1.ApplicationDeployResponse, error) {
+	return c.deploy(ctx, app, req, nil)
+}
+
+// deploy the application, the changeRequest is set when deploying the approved change request
+func (c *applicationServiceImpl) deploy(ctx context.Context, app *model.Application, req apisv1.ApplicationDeployRequest, changeRequest *model.ChangeRequest) (*apisv1.ApplicationDeployResponse, error) {
 	var userName string
 	if user := ctx.Value(&apisv1.CtxKeyUser); user != nil {
 		if u, ok := user.(string); ok {
@@ -761,6 +912,36 @@ func (c *applicationServiceImpl) Deploy(ctx context.Context, app *model.Applicat
 		return nil, err
 	}
 
+	if changeRequest == nil && req.ScheduleTime != nil && req.ScheduleTime.After(time.Now()) {
+		return c.scheduleDeploy(ctx, app, workflow, req, userName)
+	}
+	if err := checkFreezeWindows(ctx, c.Store, c.RbacService, app.Project, workflow.EnvName, req.OverrideFreeze); err != nil {
+		return nil, err
+	}
+	// the owner of the lock could deploy manually, the webhook is always blocked
+	lockUser := userName
+	if req.TriggerType == apisv1.TriggerTypeWebhook {
+		lockUser = ""
+	}
+	if err := checkDeployLock(ctx, c.Store, app.PrimaryKey(), workflow.EnvName, lockUser); err != nil {
+		return nil, err
+	}
+
+	if changeRequest != nil {
+		if err := checkChangeRequestConfig(changeRequest, oamApp); err != nil {
+			return nil, err
+		}
+		userName = changeRequest.Requester
+	} else if workflow.EnvName != "" {
+		env, err := repository.GetEnv(ctx, c.Store, workflow.EnvName)
+		if err != nil {
+			return nil, err
+		}
+		if env.Protection != nil {
+			return c.createChangeRequest(ctx, app, env, oamApp, workflow, req, userName)
+		}
+	}
+
 	// step2: check and create application revision
 	if !req.Force {
 		var lastVersion = model.ApplicationRevision{
@@ -831,6 +1012,9 @@ func (c *applicationServiceImpl) Deploy(ctx context.Context, app *model.Applicat
 		}
 
 		klog.Errorf("deploy appUtil %s failure %s", app.PrimaryKey(), err.Error())
+		data := newAppEventData(app, appRevision.EnvName, appRevision.Version)
+		data.Message = err.Error()
+		publishEvent(ctx, c.EventBus, newDomainEvent(model.EventAppDeployFailed, app.Project, "project/application", data))
 		return nil, bcode.ErrDeployApplyFail
 	}
 
@@ -854,6 +1038,7 @@ func (c *applicationServiceImpl) Deploy(ctx context.Context, app *model.Applicat
 	if err := c.Store.Put(ctx, app); err != nil {
 		klog.Warningf("failed to update appUtil %s", err.Error())
 	}
+	publishEvent(ctx, c.EventBus, newDomainEvent(model.EventAppDeployed, app.Project, "project/application", newAppEventData(app, appRevision.EnvName, appRevision.Version)))
 
 	res := &apisv1.ApplicationDeployResponse{
 		ApplicationRevisionBase: c.convertRevisionModelToBase(ctx, appRevision),
@@ -962,32 +1147,11 @@ func (c *applicationServiceImpl) renderOAMApplication(ctx context.Context, appMo
 	policies = append(policies, envPolicies...)
 
 	for _, entity := range components {
-		component := entity.(*model.ApplicationComponent)
-		var traits []common.ApplicationTrait
-		for _, trait := range component.Traits {
-			aTrait := common.ApplicationTrait{
-				Type: trait.Type,
-			}
-			if trait.Properties != nil {
-				aTrait.Properties = trait.Properties.RawExtension()
-			}
-			traits = append(traits, aTrait)
-		}
-		bc := common.ApplicationComponent{
-			Name:             component.Name,
-			Type:             component.Type,
-			ExternalRevision: component.ExternalRevision,
-			DependsOn:        component.DependsOn,
-			Inputs:           component.Inputs,
-			Outputs:          component.Outputs,
-			Traits:           traits,
-			Scopes:           component.Scopes,
-			Properties:       component.Properties.RawExtension(),
-		}
-		if component.Properties != nil {
-			bc.Properties = component.Properties.RawExtension()
+		component, enabled := applyComponentPatch(*entity.(*model.ApplicationComponent), envbinding.ComponentsPatch)
+		if !enabled {
+			continue
 		}
-		application.Spec.Components = append(application.Spec.Components, bc)
+		application.Spec.Components = append(application.Spec.Components, convertComponentModel2Spec(&component))
 	}
 
 	for _, policy := range policies {
@@ -1048,8 +1212,32 @@ func (c *applicationServiceImpl) convertRevisionModelToBase(ctx context.Context,
 	return assembler.ConvertRevisionModelToBase(revision, deployUser)
 }
 
-// DeleteApplication delete application
+// DeleteApplication move the application into the recycle bin, the records will be purged after the retention period
 func (c *applicationServiceImpl) DeleteApplication(ctx context.Context, app *model.Application) error {
+	crs, err := c.GetApplicationCR(ctx, app)
+	if err != nil {
+		return err
+	}
+	if len(crs) > 0 {
+		return bcode.ErrApplicationRefusedDelete
+	}
+	userName, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	app.MarkDeleted(userName, time.Now())
+	if err := c.Store.Put(ctx, app); err != nil {
+		return err
+	}
+	publishEvent(ctx, c.EventBus, newDomainEvent(model.EventAppDeleted, app.Project, "project/application", newAppEventData(app, "", "")))
+	return nil
+}
+
+// RestoreApplication restore the application from the recycle bin
+func (c *applicationServiceImpl) RestoreApplication(ctx context.Context, app *model.Application) error {
+	app.ClearDeleted()
+	return c.Store.Put(ctx, app)
+}
+
+// PurgeApplication delete the application and all its records permanently
+func (c *applicationServiceImpl) PurgeApplication(ctx context.Context, app *model.Application) error {
 	crs, err := c.GetApplicationCR(ctx, app)
 	if err != nil {
 		return err
@@ -1117,6 +1305,30 @@ func (c *applicationServiceImpl) DeleteApplication(ctx context.Context, app *mod
 		klog.Errorf("delete envbindings in appUtil %s failure %s", app.Name, err.Error())
 	}
 
+	changeRequests, err := c.Store.List(ctx, &model.ChangeRequest{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		klog.Errorf("list change requests in appUtil %s failure %s", app.Name, err.Error())
+	}
+	scheduledDeploys, err := c.Store.List(ctx, &model.ScheduledDeploy{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		klog.Errorf("list scheduled deploys in appUtil %s failure %s", app.Name, err.Error())
+	}
+	deployLocks, err := c.Store.List(ctx, &model.DeployLock{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		klog.Errorf("list deploy locks in appUtil %s failure %s", app.Name, err.Error())
+	}
+	rollups, err := c.Store.List(ctx, &model.DeliveryMetricRollup{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		klog.Errorf("list delivery metric rollups in appUtil %s failure %s", app.Name, err.Error())
+	}
+	entities := append(changeRequests, scheduledDeploys...)
+	entities = append(entities, deployLocks...)
+	for _, entity := range append(entities, rollups...) {
+		if err := c.Store.Delete(ctx, entity); err != nil {
+			klog.Errorf("delete %s %s in appUtil %s failure %s", entity.TableName(), entity.PrimaryKey(), app.Name, err.Error())
+		}
+	}
+
 	return c.Store.Delete(ctx, app)
 }
 
@@ -1441,30 +1653,60 @@ func (c *applicationServiceImpl) ListRevisions(ctx context.Context, appName, env
 		revision.Status = status
 	}
 
-	revisions, err := c.Store.List(ctx, &revision, &datastore.ListOptions{
+	// the pending scheduled deploys are shown before the revisions
+	var scheduled []apisv1.ApplicationRevisionBase
+	if status == "" || status == model.ScheduledDeployStatusScheduled {
+		var err error
+		if scheduled, err = c.listPendingScheduledDeploys(ctx, appName, envName); err != nil {
+			return nil, err
+		}
+	}
+	resp := &apisv1.ListRevisionsResponse{
+		Revisions: []apisv1.ApplicationRevisionBase{},
+		Total:     int64(len(scheduled)),
+	}
+
+	listOptions := &datastore.ListOptions{
 		Page:     page,
 		PageSize: pageSize,
 		SortBy:   []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
-	})
-	if err != nil {
-		return nil, err
 	}
-
-	resp := &apisv1.ListRevisionsResponse{
-		Revisions: []apisv1.ApplicationRevisionBase{},
+	// the scheduled deploys take the first items of the pages, so the revisions are listed from the first one and the shown ones are skipped
+	var skip int
+	if page > 0 && pageSize > 0 && len(scheduled) > 0 {
+		offset := (page - 1) * pageSize
+		if offset >= len(scheduled) {
+			skip = offset - len(scheduled)
+			scheduled = nil
+		} else {
+			scheduled = scheduled[offset:]
+			if len(scheduled) > pageSize {
+				scheduled = scheduled[:pageSize]
+			}
+		}
+		listOptions.Page = 1
+		listOptions.PageSize = skip + pageSize - len(scheduled)
 	}
-	for _, raw := range revisions {
-		r, ok := raw.(*model.ApplicationRevision)
-		if ok {
-			resp.Revisions = append(resp.Revisions, c.convertRevisionModelToBase(ctx, r))
+	resp.Revisions = append(resp.Revisions, scheduled...)
+
+	// the page may be full of the scheduled deploys
+	if listOptions.PageSize > 0 || pageSize <= 0 || page <= 0 {
+		revisions, err := c.Store.List(ctx, &revision, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for i, raw := range revisions {
+			r, ok := raw.(*model.ApplicationRevision)
+			if ok && i >= skip {
+				resp.Revisions = append(resp.Revisions, c.convertRevisionModelToBase(ctx, r))
+			}
 		}
 	}
 	count, err := c.Store.Count(ctx, &revision, nil)
 	if err != nil {
 		return nil, err
 	}
-	resp.Total = count
-
+	resp.Total += count
 	return resp, nil
 }
 
@@ -1509,11 +1751,16 @@ func (c *applicationServiceImpl) Statistics(ctx context.Context, app *model.Appl
 	if err != nil {
 		return nil, err
 	}
+	delivery, err := queryDeliveryMetrics(ctx, c.Store, apisv1.DeliveryMetricsOptions{AppPrimaryKey: app.PrimaryKey()})
+	if err != nil {
+		klog.Errorf("query the delivery metrics of the appUtil failure %s", err.Error())
+	}
 	return &apisv1.ApplicationStatisticsResponse{
 		EnvCount:      int64(len(envbinding)),
 		TargetCount:   int64(len(targetMap)),
 		RevisionCount: count,
 		WorkflowCount: c.WorkflowService.CountWorkflow(ctx, app),
+		Delivery:      delivery,
 	}, nil
 }
 
@@ -1762,6 +2009,13 @@ func (c *applicationServiceImpl) RollbackWithRevision(ctx context.Context, appli
 		return nil, err
 	}
 	revision := revisionDetail.ApplicationRevision
+	userName, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if err := checkDeployLock(ctx, c.Store, application.PrimaryKey(), revision.EnvName, userName); err != nil {
+		return nil, err
+	}
+	if err := checkProtectedEnvRollback(ctx, c.Store, revision.EnvName, application.Project, userName); err != nil {
+		return nil, err
+	}
 	appCR, err := c.GetApplicationCRInEnv(ctx, application, revision.EnvName)
 	if err != nil {
 		return nil, err
@@ -1970,6 +2224,7 @@ func NewTestApplicationService(ds datastore.DataStore, c client.Client, cfg *res
 		DefinitionService: def,
 		ProjectService:    projectService,
 		UserService:       userService,
+		RbacService:       rbacService,
 	}
 }
 
@@ -0,0 +1,780 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	applicationBundleAPIVersion = "velaux.oam.dev/v1"
+	applicationBundleKind       = "ApplicationBundle"
+	// applicationBundleFile the bundle info file in the tar archive
+	applicationBundleFile = "bundle.yaml"
+	// applicationBundleDir the directory of the application files in the tar archive
+	applicationBundleDir = "applications"
+	// maxApplicationBundleSize the max size of the decompressed bundle
+	maxApplicationBundleSize = 50 * 1024 * 1024
+)
+
+// ExportApplications export the applications of the project as a bundle, all applications are exported if the names are empty
+func (c *applicationServiceImpl) ExportApplications(ctx context.Context, projectName string, appNames []string, format string) (*apisv1.ExportApplicationBundleResponse, error) {
+	if format == "" {
+		format = apisv1.ApplicationBundleFormatYAML
+	}
+	if format != apisv1.ApplicationBundleFormatYAML && format != apisv1.ApplicationBundleFormatTar {
+		return nil, bcode.ErrApplicationBundleInvalid
+	}
+	var apps []*model.Application
+	if len(appNames) == 0 {
+		var err error
+		apps, err = listApp(ctx, c.Store, apisv1.ListApplicationOptions{Projects: []string{projectName}})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range appNames {
+		app, err := c.GetApplication(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if app.Project != projectName {
+			return nil, bcode.ErrApplicationNotExist
+		}
+		apps = append(apps, app)
+	}
+	bundle := &apisv1.ApplicationBundle{
+		APIVersion: applicationBundleAPIVersion,
+		Kind:       applicationBundleKind,
+		Project:    projectName,
+		ExportTime: time.Now(),
+	}
+	for _, app := range apps {
+		item, err := c.exportApplication(ctx, app, true)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Applications = append(bundle.Applications, *item)
+	}
+	content, err := encodeApplicationBundle(bundle, format)
+	if err != nil {
+		return nil, err
+	}
+	fileName := projectName
+	if len(appNames) == 1 {
+		fileName = appNames[0]
+	}
+	if format == apisv1.ApplicationBundleFormatTar {
+		fileName += ".tar.gz"
+	} else {
+		fileName += ".yaml"
+	}
+	return &apisv1.ExportApplicationBundleResponse{FileName: fileName, Format: format, Content: content}, nil
+}
+
+// exportApplication convert the records of the application to the bundle item, the trigger tokens and secrets are removed
+func (c *applicationServiceImpl) exportApplication(ctx context.Context, app *model.Application, withManifests bool) (*apisv1.ApplicationBundleItem, error) {
+	item := &apisv1.ApplicationBundleItem{
+		Application: apisv1.ApplicationBundleMeta{
+			Name:        app.Name,
+			Alias:       app.Alias,
+			Description: app.Description,
+			Icon:        app.Icon,
+			Labels:      app.Labels,
+			Annotations: app.Annotations,
+		},
+	}
+	components, err := c.Store.List(ctx, &model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range components {
+		component := entity.(*model.ApplicationComponent)
+		component.BaseModel = model.BaseModel{}
+		component.AppPrimaryKey = ""
+		item.Components = append(item.Components, *component)
+	}
+	policies, err := repository.ListApplicationPolicies(ctx, c.Store, app)
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range policies {
+		policy.BaseModel = model.BaseModel{}
+		policy.AppPrimaryKey = ""
+		item.Policies = append(item.Policies, *policy)
+	}
+	workflows, err := c.Store.List(ctx, &model.Workflow{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range workflows {
+		workflow := entity.(*model.Workflow)
+		workflow.BaseModel = model.BaseModel{}
+		workflow.AppPrimaryKey = ""
+		item.Workflows = append(item.Workflows, *workflow)
+	}
+	envBindings, err := c.Store.List(ctx, &model.EnvBinding{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range envBindings {
+		envBinding := entity.(*model.EnvBinding)
+		envBinding.BaseModel = model.BaseModel{}
+		envBinding.AppPrimaryKey = ""
+		envBinding.AppDeployName = ""
+		item.EnvBindings = append(item.EnvBindings, *envBinding)
+	}
+	triggers, err := c.Store.List(ctx, &model.ApplicationTrigger{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range triggers {
+		item.Triggers = append(item.Triggers, trigger2BundleTrigger(entity.(*model.ApplicationTrigger)))
+	}
+	if !withManifests {
+		return item, nil
+	}
+	for _, envBinding := range item.EnvBindings {
+		manifest, err := c.renderOAMApplication(ctx, app, repository.ConvertWorkflowName(envBinding.Name), envBinding.Name, "")
+		if err != nil {
+			klog.Warningf("render the application %s in the env %s failure %s", app.Name, envBinding.Name, err.Error())
+			continue
+		}
+		manifest.ResourceVersion = ""
+		if item.Manifests == nil {
+			item.Manifests = map[string]*v1beta1.Application{}
+		}
+		item.Manifests[envBinding.Name] = manifest
+	}
+	return item, nil
+}
+
+// ImportApplications import the applications from the bundle to the project
+func (c *applicationServiceImpl) ImportApplications(ctx context.Context, projectName string, req apisv1.ImportApplicationBundleRequest) (*apisv1.ImportApplicationBundleResponse, error) {
+	project, err := c.ProjectService.DetailProject(ctx, projectName)
+	if err != nil {
+		return nil, bcode.ErrProjectIsNotExist
+	}
+	bundle, err := decodeApplicationBundle(req.Format, req.Content)
+	if err != nil {
+		return nil, err
+	}
+	strategy := req.ConflictStrategy
+	if strategy == "" {
+		strategy = apisv1.ImportConflictSkip
+	}
+	for _, targetName := range req.TargetMapping {
+		target := &model.Target{Name: targetName}
+		if err := c.Store.Get(ctx, target); err != nil || target.Project != project.Name {
+			return nil, bcode.ErrApplicationBundleTargetInvalid
+		}
+	}
+
+	// the names of the applications are reserved, so the renamed applications do not take the names of others in the bundle
+	reserved := map[string]bool{}
+	for _, item := range bundle.Applications {
+		if reserved[item.Application.Name] {
+			return nil, bcode.ErrApplicationBundleInvalid.SetMessage(fmt.Sprintf("the application %s is duplicated in the bundle", item.Application.Name))
+		}
+		reserved[item.Application.Name] = true
+	}
+
+	// check all applications before importing, so the bundle is not imported partially by the invalid envs
+	var envMappings []map[string]string
+	res := &apisv1.ImportApplicationBundleResponse{DryRun: req.DryRun, Applications: []apisv1.ImportApplicationResult{}}
+	for i := range bundle.Applications {
+		item := &bundle.Applications[i]
+		envMapping, err := c.mappingBundleEnvs(ctx, project.Name, item, req.EnvMapping)
+		if err != nil {
+			return nil, err
+		}
+		envMappings = append(envMappings, envMapping)
+		for _, trigger := range item.Triggers {
+			if err := checkBundleTrigger(trigger); err != nil {
+				return nil, err
+			}
+		}
+		result := apisv1.ImportApplicationResult{
+			Name:       item.Application.Name,
+			ImportName: item.Application.Name,
+			Action:     "create",
+		}
+		existing := &model.Application{Name: item.Application.Name}
+		if err := c.Store.Get(ctx, existing); err != nil {
+			if !errors.Is(err, datastore.ErrRecordNotExist) {
+				return nil, err
+			}
+			res.Applications = append(res.Applications, result)
+			continue
+		}
+		switch strategy {
+		case apisv1.ImportConflictSkip:
+			result.Action = "skip"
+		case apisv1.ImportConflictOverwrite:
+			if existing.Project != project.Name || existing.IsDeleted() {
+				return nil, bcode.ErrApplicationBundleConflict
+			}
+			result.Action = "overwrite"
+			current, err := c.exportApplication(ctx, existing, false)
+			if err != nil {
+				return nil, err
+			}
+			if result.Diff, err = diffBundleItems(current, item); err != nil {
+				return nil, err
+			}
+		case apisv1.ImportConflictRename:
+			if result.ImportName, err = c.availableApplicationName(ctx, item.Application.Name, reserved); err != nil {
+				return nil, err
+			}
+			reserved[result.ImportName] = true
+		}
+		res.Applications = append(res.Applications, result)
+	}
+	if req.DryRun {
+		return res, nil
+	}
+	// the imported applications are rolled back if one of them fails, the overwritten applications are restored by the snapshots
+	var imported []importedApplication
+	for i, result := range res.Applications {
+		if result.Action == "skip" {
+			continue
+		}
+		current := importedApplication{name: result.ImportName}
+		if result.Action == "overwrite" {
+			snapshot, records, err := c.snapshotApplication(ctx, result.ImportName)
+			if err != nil {
+				c.rollbackImportedApplications(ctx, imported)
+				return nil, err
+			}
+			current.snapshot, current.records = snapshot, records
+		}
+		imported = append(imported, current)
+		if err := c.importBundleItem(ctx, project.Name, result, &bundle.Applications[i], envMappings[i], req.TargetMapping); err != nil {
+			klog.Errorf("import the application %s failure %s", result.ImportName, err.Error())
+			c.rollbackImportedApplications(ctx, imported)
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// importedApplication the application written by the import, the snapshot is set if the application is overwritten
+type importedApplication struct {
+	name     string
+	snapshot *model.Application
+	records  []datastore.Entity
+}
+
+// snapshotApplication return the application and its records before they are overwritten
+func (c *applicationServiceImpl) snapshotApplication(ctx context.Context, name string) (*model.Application, []datastore.Entity, error) {
+	app := &model.Application{Name: name}
+	if err := c.Store.Get(ctx, app); err != nil {
+		return nil, nil, err
+	}
+	records, err := c.listApplicationRecords(ctx, app)
+	if err != nil {
+		return nil, nil, err
+	}
+	return app, records, nil
+}
+
+// rollbackImportedApplications remove the records written by the import in the reverse order,
+// the created applications are deleted and the overwritten applications are restored.
+func (c *applicationServiceImpl) rollbackImportedApplications(ctx context.Context, imported []importedApplication) {
+	for i := len(imported) - 1; i >= 0; i-- {
+		item := imported[i]
+		app := &model.Application{Name: item.name}
+		if err := c.deleteApplicationRecords(ctx, app); err != nil {
+			klog.Errorf("clean up the records of the application %s failure %s", item.name, err.Error())
+			continue
+		}
+		if item.snapshot == nil {
+			if err := c.Store.Delete(ctx, app); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+				klog.Errorf("clean up the application %s failure %s", item.name, err.Error())
+			}
+			continue
+		}
+		for _, record := range item.records {
+			if err := c.Store.Add(ctx, record); err != nil && !errors.Is(err, datastore.ErrRecordExist) {
+				klog.Errorf("restore the record %s of the application %s failure %s", record.PrimaryKey(), item.name, err.Error())
+			}
+		}
+		if err := c.Store.Put(ctx, item.snapshot); err != nil {
+			klog.Errorf("restore the application %s failure %s", item.name, err.Error())
+		}
+	}
+}
+
+// mappingBundleEnvs return the mapping from the envs in the bundle to the envs of the project
+func (c *applicationServiceImpl) mappingBundleEnvs(ctx context.Context, projectName string, item *apisv1.ApplicationBundleItem, mapping map[string]string) (map[string]string, error) {
+	envMapping := map[string]string{}
+	targetEnvs := map[string]bool{}
+	for _, envBinding := range item.EnvBindings {
+		envName := envBinding.Name
+		if mapped, exist := mapping[envName]; exist {
+			envName = mapped
+		}
+		env, err := repository.GetEnv(ctx, c.Store, envName)
+		if err != nil || env.Project != projectName || targetEnvs[envName] {
+			return nil, bcode.ErrApplicationBundleEnvInvalid
+		}
+		targetEnvs[envName] = true
+		envMapping[envBinding.Name] = envName
+	}
+	return envMapping, nil
+}
+
+func (c *applicationServiceImpl) availableApplicationName(ctx context.Context, name string, reserved map[string]bool) (string, error) {
+	for i := 1; i <= 100; i++ {
+		newName := fmt.Sprintf("%s-%d", name, i)
+		if reserved[newName] {
+			continue
+		}
+		exist, err := c.Store.IsExist(ctx, &model.Application{Name: newName})
+		if err != nil {
+			return "", err
+		}
+		if !exist {
+			return newName, nil
+		}
+	}
+	return "", bcode.ErrApplicationExist
+}
+
+// importBundleItem create the records of the application, the workflows and topology policies of the envs are generated by the targets of the envs.
+func (c *applicationServiceImpl) importBundleItem(ctx context.Context, projectName string, result apisv1.ImportApplicationResult, item *apisv1.ApplicationBundleItem, envMapping, targetMapping map[string]string) error {
+	app := &model.Application{Name: result.ImportName}
+	overwrite := result.Action == "overwrite"
+	if overwrite {
+		if err := c.Store.Get(ctx, app); err != nil {
+			return err
+		}
+		if err := c.deleteApplicationRecords(ctx, app); err != nil {
+			return err
+		}
+	}
+	app.Project = projectName
+	app.Alias = item.Application.Alias
+	app.Description = item.Application.Description
+	app.Icon = item.Application.Icon
+	app.Labels = item.Application.Labels
+	app.Annotations = item.Application.Annotations
+
+	userName, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	for _, component := range item.Components {
+		component.AppPrimaryKey = app.PrimaryKey()
+		component.Creator = userName
+		if err := c.Store.Add(ctx, &component); err != nil {
+			return err
+		}
+	}
+	for _, policy := range item.Policies {
+		if policy.EnvName != "" {
+			continue
+		}
+		policy.AppPrimaryKey = app.PrimaryKey()
+		policy.Creator = userName
+		if err := c.Store.Add(ctx, &policy); err != nil {
+			return err
+		}
+	}
+
+	var defaultEnv string
+	for _, workflow := range item.Workflows {
+		if workflow.Default != nil && *workflow.Default {
+			defaultEnv = workflow.EnvName
+		}
+	}
+	var envBindings []*apisv1.EnvBinding
+	for _, envBinding := range item.EnvBindings {
+		if envBinding.Name == defaultEnv {
+			envBindings = append([]*apisv1.EnvBinding{{Name: envMapping[envBinding.Name]}}, envBindings...)
+		} else {
+			envBindings = append(envBindings, &apisv1.EnvBinding{Name: envMapping[envBinding.Name]})
+		}
+	}
+	if len(envBindings) > 0 {
+		if err := c.saveApplicationEnvBinding(ctx, *app, envBindings); err != nil {
+			return err
+		}
+	}
+	for _, envBinding := range item.EnvBindings {
+		if len(envBinding.ComponentsPatch) == 0 {
+			continue
+		}
+		binding := &model.EnvBinding{AppPrimaryKey: app.PrimaryKey(), Name: envMapping[envBinding.Name]}
+		if err := c.Store.Get(ctx, binding); err != nil {
+			return err
+		}
+		binding.ComponentsPatch = envBinding.ComponentsPatch
+		if err := c.Store.Put(ctx, binding); err != nil {
+			return err
+		}
+	}
+	for _, policy := range item.Policies {
+		// the topology policies are generated by the targets of the env
+		if policy.EnvName == "" || policy.Type == v1alpha1.TopologyPolicyType || policy.Type == v1alpha1.EnvBindingPolicyType {
+			continue
+		}
+		policy.AppPrimaryKey = app.PrimaryKey()
+		policy.EnvName = envMapping[policy.EnvName]
+		if err := c.Store.Add(ctx, &policy); err != nil && !errors.Is(err, datastore.ErrRecordExist) {
+			return err
+		}
+	}
+
+	workflowNames := map[string]string{}
+	for _, workflow := range item.Workflows {
+		if workflow.EnvName == "" {
+			workflow.AppPrimaryKey = app.PrimaryKey()
+			workflow.Steps = mappingWorkflowStepTargets(workflow.Steps, targetMapping)
+			if err := c.Store.Add(ctx, &workflow); err != nil && !errors.Is(err, datastore.ErrRecordExist) {
+				return err
+			}
+			workflowNames[workflow.Name] = workflow.Name
+			continue
+		}
+		envName, exist := envMapping[workflow.EnvName]
+		if !exist {
+			continue
+		}
+		workflowNames[workflow.Name] = repository.ConvertWorkflowName(envName)
+		env, err := repository.GetEnv(ctx, c.Store, envName)
+		if err != nil {
+			return err
+		}
+		if !workflowTargetsMapped(item.Policies, workflow.EnvName, env, targetMapping) {
+			klog.Infof("the targets of the env %s are changed, keep the generated workflow of the application %s", envName, app.Name)
+			continue
+		}
+		// keep the custom steps if all targets are mapped to the targets of the env
+		generated := &model.Workflow{AppPrimaryKey: app.PrimaryKey(), Name: repository.ConvertWorkflowName(envName)}
+		if err := c.Store.Get(ctx, generated); err != nil {
+			return err
+		}
+		generated.Steps = mappingWorkflowStepTargets(workflow.Steps, targetMapping)
+		generated.Mode = workflow.Mode
+		generated.Alias = workflow.Alias
+		generated.Description = workflow.Description
+		if err := c.Store.Put(ctx, generated); err != nil {
+			return err
+		}
+	}
+
+	for _, trigger := range item.Triggers {
+		workflowName, exist := workflowNames[trigger.WorkflowName]
+		if !exist {
+			klog.Infof("skip the trigger %s when importing the application %s, the workflow is not imported", trigger.Name, app.Name)
+			continue
+		}
+		if _, err := c.CreateApplicationTrigger(ctx, app, bundleTrigger2CreateRequest(trigger, workflowName)); err != nil {
+			return err
+		}
+	}
+	if overwrite {
+		return c.Store.Put(ctx, app)
+	}
+	return c.Store.Add(ctx, app)
+}
+
+// listApplicationRecords list the components, policies, workflows, env bindings and triggers of the application
+func (c *applicationServiceImpl) listApplicationRecords(ctx context.Context, app *model.Application) ([]datastore.Entity, error) {
+	var res []datastore.Entity
+	for _, entity := range []datastore.Entity{
+		&model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()},
+		&model.ApplicationPolicy{AppPrimaryKey: app.PrimaryKey()},
+		&model.Workflow{AppPrimaryKey: app.PrimaryKey()},
+		&model.EnvBinding{AppPrimaryKey: app.PrimaryKey()},
+		&model.ApplicationTrigger{AppPrimaryKey: app.PrimaryKey()},
+	} {
+		records, err := c.Store.List(ctx, entity, &datastore.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, records...)
+	}
+	return res, nil
+}
+
+// deleteApplicationRecords delete the components, policies, workflows, env bindings and triggers of the application
+func (c *applicationServiceImpl) deleteApplicationRecords(ctx context.Context, app *model.Application) error {
+	records, err := c.listApplicationRecords(ctx, app)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := c.Store.Delete(ctx, record); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// workflowTargetsMapped check whether all targets of the env in the bundle are mapped to the targets of the env
+func workflowTargetsMapped(policies []model.ApplicationPolicy, envName string, env *model.Env, targetMapping map[string]string) bool {
+	targets := map[string]bool{}
+	for _, target := range env.Targets {
+		targets[target] = true
+	}
+	for _, policy := range policies {
+		if policy.EnvName != envName {
+			continue
+		}
+		if policy.Type == v1alpha1.EnvBindingPolicyType {
+			return false
+		}
+		if policy.Type != v1alpha1.TopologyPolicyType {
+			continue
+		}
+		target := policy.Name
+		if mapped, exist := targetMapping[target]; exist {
+			target = mapped
+		}
+		if !targets[target] {
+			return false
+		}
+	}
+	return true
+}
+
+// mappingWorkflowStepTargets replace the target names in the step names and the policies of the deploy steps
+func mappingWorkflowStepTargets(steps []model.WorkflowStep, targetMapping map[string]string) []model.WorkflowStep {
+	if len(targetMapping) == 0 {
+		return steps
+	}
+	mappingStep := func(step *model.WorkflowStepBase) {
+		if mapped, exist := targetMapping[step.Name]; exist {
+			step.Name = mapped
+		}
+		if step.Properties == nil {
+			return
+		}
+		properties := *step.Properties
+		if policies, ok := properties["policies"].([]interface{}); ok {
+			for i, policy := range policies {
+				if mapped, exist := targetMapping[fmt.Sprint(policy)]; exist {
+					policies[i] = mapped
+				}
+			}
+		}
+	}
+	for i := range steps {
+		mappingStep(&steps[i].WorkflowStepBase)
+		for j := range steps[i].SubSteps {
+			mappingStep(&steps[i].SubSteps[j])
+		}
+	}
+	return steps
+}
+
+// trigger2BundleTrigger copy the settings of the trigger, the token and the secrets must not be exported
+func trigger2BundleTrigger(trigger *model.ApplicationTrigger) apisv1.ApplicationBundleTrigger {
+	return apisv1.ApplicationBundleTrigger{
+		Name:               trigger.Name,
+		Alias:              trigger.Alias,
+		Description:        trigger.Description,
+		WorkflowName:       trigger.WorkflowName,
+		Type:               trigger.Type,
+		PayloadType:        trigger.PayloadType,
+		ComponentName:      trigger.ComponentName,
+		Registry:           trigger.Registry,
+		SignatureType:      trigger.SignatureType,
+		AllowedCIDRs:       trigger.AllowedCIDRs,
+		GitEvents:          trigger.GitEvents,
+		BranchFilters:      trigger.BranchFilters,
+		TagFilters:         trigger.TagFilters,
+		PropertiesTemplate: trigger.PropertiesTemplate,
+		ImageProperties:    trigger.ImageProperties,
+		ImageTagFilter:     trigger.ImageTagFilter,
+		PayloadMapping:     trigger.PayloadMapping,
+	}
+}
+
+// bundleTrigger2CreateRequest create the trigger with a new token by the settings in the bundle
+func bundleTrigger2CreateRequest(trigger apisv1.ApplicationBundleTrigger, workflowName string) apisv1.CreateApplicationTriggerRequest {
+	return apisv1.CreateApplicationTriggerRequest{
+		Name:               trigger.Name,
+		Alias:              trigger.Alias,
+		Description:        trigger.Description,
+		WorkflowName:       workflowName,
+		Type:               trigger.Type,
+		PayloadType:        trigger.PayloadType,
+		ComponentName:      trigger.ComponentName,
+		Registry:           trigger.Registry,
+		SignatureType:      trigger.SignatureType,
+		AllowedCIDRs:       trigger.AllowedCIDRs,
+		GitEvents:          trigger.GitEvents,
+		BranchFilters:      trigger.BranchFilters,
+		TagFilters:         trigger.TagFilters,
+		PropertiesTemplate: trigger.PropertiesTemplate,
+		ImageProperties:    trigger.ImageProperties,
+		ImageTagFilter:     trigger.ImageTagFilter,
+		PayloadMapping:     trigger.PayloadMapping,
+	}
+}
+
+// checkBundleTrigger check the rules of the trigger before writing the records of the application
+func checkBundleTrigger(trigger apisv1.ApplicationBundleTrigger) error {
+	if err := checkAllowedCIDRs(trigger.AllowedCIDRs); err != nil {
+		return err
+	}
+	if err := checkGitTriggerRules(trigger.BranchFilters, trigger.TagFilters, trigger.PropertiesTemplate); err != nil {
+		return err
+	}
+	if err := checkImageTriggerRules(trigger.ImageProperties, trigger.ImageTagFilter); err != nil {
+		return err
+	}
+	return checkPayloadMapping(trigger.PayloadType, trigger.PayloadMapping)
+}
+
+func diffBundleItems(current, target *apisv1.ApplicationBundleItem) (string, error) {
+	return unifiedDiff("current", "bundle", current, &apisv1.ApplicationBundleItem{
+		Application: target.Application,
+		Components:  target.Components,
+		Policies:    target.Policies,
+		Workflows:   target.Workflows,
+		EnvBindings: target.EnvBindings,
+		Triggers:    target.Triggers,
+	})
+}
+
+func encodeApplicationBundle(bundle *apisv1.ApplicationBundle, format string) (string, error) {
+	if format == apisv1.ApplicationBundleFormatYAML {
+		content, err := yaml.Marshal(bundle)
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	}
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	writeFile := func(name string, object interface{}) error {
+		content, err := yaml.Marshal(object)
+		if err != nil {
+			return err
+		}
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), ModTime: bundle.ExportTime}); err != nil {
+			return err
+		}
+		_, err = tarWriter.Write(content)
+		return err
+	}
+	info := *bundle
+	info.Applications = nil
+	if err := writeFile(applicationBundleFile, info); err != nil {
+		return "", err
+	}
+	for _, item := range bundle.Applications {
+		if err := writeFile(path.Join(applicationBundleDir, item.Application.Name+".yaml"), item); err != nil {
+			return "", err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return "", err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
+
+func decodeApplicationBundle(format, content string) (*apisv1.ApplicationBundle, error) {
+	var bundle apisv1.ApplicationBundle
+	switch format {
+	case "", apisv1.ApplicationBundleFormatYAML:
+		if err := yaml.Unmarshal([]byte(content), &bundle); err != nil {
+			klog.Warningf("decode the application bundle failure %s", err.Error())
+			return nil, bcode.ErrApplicationBundleInvalid
+		}
+	case apisv1.ApplicationBundleFormatTar:
+		archive, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return nil, bcode.ErrApplicationBundleInvalid
+		}
+		gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+		if err != nil {
+			return nil, bcode.ErrApplicationBundleInvalid
+		}
+		tarReader := tar.NewReader(io.LimitReader(gzipReader, maxApplicationBundleSize))
+		var items []apisv1.ApplicationBundleItem
+		for {
+			header, err := tarReader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				klog.Warningf("read the application bundle failure %s", err.Error())
+				return nil, bcode.ErrApplicationBundleInvalid
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+			data, err := io.ReadAll(tarReader)
+			if err != nil {
+				return nil, bcode.ErrApplicationBundleInvalid
+			}
+			name := path.Clean(header.Name)
+			switch {
+			case name == applicationBundleFile:
+				if err := yaml.Unmarshal(data, &bundle); err != nil {
+					return nil, bcode.ErrApplicationBundleInvalid
+				}
+			case strings.HasPrefix(name, applicationBundleDir+"/") && strings.HasSuffix(name, ".yaml"):
+				var item apisv1.ApplicationBundleItem
+				if err := yaml.Unmarshal(data, &item); err != nil {
+					return nil, bcode.ErrApplicationBundleInvalid
+				}
+				items = append(items, item)
+			}
+		}
+		bundle.Applications = items
+	default:
+		return nil, bcode.ErrApplicationBundleInvalid
+	}
+	if bundle.APIVersion != applicationBundleAPIVersion || bundle.Kind != applicationBundleKind {
+		return nil, bcode.ErrApplicationBundleInvalid
+	}
+	for _, item := range bundle.Applications {
+		if item.Application.Name == "" {
+			return nil, bcode.ErrApplicationBundleInvalid
+		}
+	}
+	return &bundle, nil
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test exporting and importing the applications", func() {
+	var (
+		bundleProject  = "test-bundle-project"
+		bundleImported = "test-bundle-imported"
+		bundleApp      = "test-bundle-app"
+		bundleCtx      context.Context
+		bundleContent  string
+	)
+
+	It("Init services and data", func() {
+		bundleCtx = InitTestEnvWithAdmin("app-bundle-test-kubevela")
+		for _, project := range []string{bundleProject, bundleImported} {
+			InitTestProject(bundleCtx, project, project+"-target", project+"-dev")
+		}
+		InitTestApplication(bundleCtx, bundleProject, bundleApp, `{"image":"nginx"}`, bundleProject+"-dev")
+	})
+
+	It("Test exporting the application", func() {
+		app, err := appService.GetApplication(bundleCtx, bundleApp)
+		Expect(err).Should(BeNil())
+		triggers, err := appService.ListApplicationTriggers(bundleCtx, app)
+		Expect(err).Should(BeNil())
+		Expect(len(triggers)).Should(BeEquivalentTo(1))
+		_, err = appService.UpdateApplicationTrigger(bundleCtx, app, triggers[0].Token, apisv1.UpdateApplicationTriggerRequest{
+			WorkflowName: triggers[0].WorkflowName,
+			PayloadType:  triggers[0].PayloadType,
+			Secret:       "bundle-trigger-secret",
+		})
+		Expect(err).Should(BeNil())
+
+		res, err := appService.ExportApplications(bundleCtx, bundleProject, []string{bundleApp}, "")
+		Expect(err).Should(BeNil())
+		Expect(res.FileName).Should(BeEquivalentTo(bundleApp + ".yaml"))
+		bundle, err := decodeApplicationBundle(res.Format, res.Content)
+		Expect(err).Should(BeNil())
+		Expect(len(bundle.Applications)).Should(BeEquivalentTo(1))
+		Expect(len(bundle.Applications[0].Components)).Should(BeEquivalentTo(1))
+		Expect(len(bundle.Applications[0].Triggers)).Should(BeEquivalentTo(1))
+		Expect(res.Content).ShouldNot(ContainSubstring(triggers[0].Token))
+		Expect(res.Content).ShouldNot(ContainSubstring("bundle-trigger-secret"))
+		bundleContent = res.Content
+
+		res, err = appService.ExportApplications(bundleCtx, bundleProject, nil, apisv1.ApplicationBundleFormatTar)
+		Expect(err).Should(BeNil())
+		bundle, err = decodeApplicationBundle(res.Format, res.Content)
+		Expect(err).Should(BeNil())
+		Expect(len(bundle.Applications)).Should(BeEquivalentTo(1))
+	})
+
+	It("Test importing the applications", func() {
+		_, err := appService.ImportApplications(bundleCtx, bundleImported, apisv1.ImportApplicationBundleRequest{Content: bundleContent})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationBundleEnvInvalid))
+
+		req := apisv1.ImportApplicationBundleRequest{
+			Content:          bundleContent,
+			DryRun:           true,
+			ConflictStrategy: apisv1.ImportConflictRename,
+			EnvMapping:       map[string]string{bundleProject + "-dev": bundleImported + "-dev"},
+		}
+		res, err := appService.ImportApplications(bundleCtx, bundleImported, req)
+		Expect(err).Should(BeNil())
+		Expect(res.Applications[0].ImportName).Should(BeEquivalentTo(bundleApp + "-1"))
+		_, err = appService.GetApplication(bundleCtx, bundleApp+"-1")
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationNotExist))
+
+		invalid, err := decodeApplicationBundle(apisv1.ApplicationBundleFormatYAML, bundleContent)
+		Expect(err).Should(BeNil())
+		invalid.Applications[0].Triggers[0].PayloadType = model.PayloadTypeMapped
+		invalidContent, err := encodeApplicationBundle(invalid, apisv1.ApplicationBundleFormatYAML)
+		Expect(err).Should(BeNil())
+		_, err = appService.ImportApplications(bundleCtx, bundleImported, apisv1.ImportApplicationBundleRequest{
+			Content:          invalidContent,
+			ConflictStrategy: apisv1.ImportConflictRename,
+			EnvMapping:       req.EnvMapping,
+		})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationTriggerMappingInvalid))
+		components, err := ds.List(bundleCtx, &model.ApplicationComponent{AppPrimaryKey: bundleApp + "-1"}, nil)
+		Expect(err).Should(BeNil())
+		Expect(len(components)).Should(BeEquivalentTo(0))
+
+		req.DryRun = false
+		_, err = appService.ImportApplications(bundleCtx, bundleImported, req)
+		Expect(err).Should(BeNil())
+		app, err := appService.GetApplication(bundleCtx, bundleApp+"-1")
+		Expect(err).Should(BeNil())
+		Expect(app.Project).Should(BeEquivalentTo(bundleImported))
+		workflow, err := repository.GetWorkflowByEnv(bundleCtx, ds, app, bundleImported+"-dev")
+		Expect(err).Should(BeNil())
+		Expect(workflow.Steps[0].Name).Should(BeEquivalentTo(bundleImported + "-target"))
+		triggers, err := appService.ListApplicationTriggers(bundleCtx, app)
+		Expect(err).Should(BeNil())
+		Expect(len(triggers)).Should(BeEquivalentTo(1))
+		Expect(triggers[0].Token).ShouldNot(BeEmpty())
+
+		req.ConflictStrategy = apisv1.ImportConflictOverwrite
+		_, err = appService.ImportApplications(bundleCtx, bundleImported, req)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationBundleConflict))
+
+		req.EnvMapping = nil
+		req.DryRun = true
+		res, err = appService.ImportApplications(bundleCtx, bundleProject, req)
+		Expect(err).Should(BeNil())
+		Expect(res.Applications[0].Action).Should(BeEquivalentTo("overwrite"))
+		Expect(res.Applications[0].Diff).Should(BeEmpty())
+
+		duplicated, err := decodeApplicationBundle(apisv1.ApplicationBundleFormatYAML, bundleContent)
+		Expect(err).Should(BeNil())
+		duplicated.Applications = append(duplicated.Applications, duplicated.Applications[0])
+		duplicatedContent, err := encodeApplicationBundle(duplicated, apisv1.ApplicationBundleFormatYAML)
+		Expect(err).Should(BeNil())
+		_, err = appService.ImportApplications(bundleCtx, bundleProject, apisv1.ImportApplicationBundleRequest{
+			Content: duplicatedContent, ConflictStrategy: apisv1.ImportConflictOverwrite})
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrApplicationBundleInvalid.BusinessCode))
+	})
+
+	It("Test rolling back the imported applications", func() {
+		snapshot, records, err := appService.snapshotApplication(bundleCtx, bundleApp)
+		Expect(err).Should(BeNil())
+		Expect(len(records)).ShouldNot(BeZero())
+		res, err := appService.ImportApplications(bundleCtx, bundleProject, apisv1.ImportApplicationBundleRequest{
+			Content: bundleContent, ConflictStrategy: apisv1.ImportConflictRename})
+		Expect(err).Should(BeNil())
+		renamed := res.Applications[0].ImportName
+		// the overwritten application lost its records and the renamed one is created before the failure
+		Expect(appService.deleteApplicationRecords(bundleCtx, snapshot)).Should(BeNil())
+		appService.rollbackImportedApplications(bundleCtx, []importedApplication{
+			{name: bundleApp, snapshot: snapshot, records: records},
+			{name: renamed},
+		})
+		restored, err := appService.listApplicationRecords(bundleCtx, snapshot)
+		Expect(err).Should(BeNil())
+		Expect(len(restored)).Should(Equal(len(records)))
+		_, err = appService.GetApplication(bundleCtx, renamed)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationNotExist))
+	})
+})
+
+var _ = Describe("Test the application bundle functions", func() {
+	It("Test encoding and decoding the tar bundle", func() {
+		bundle := &apisv1.ApplicationBundle{
+			APIVersion:   applicationBundleAPIVersion,
+			Kind:         applicationBundleKind,
+			Project:      "default",
+			ExportTime:   time.Now(),
+			Applications: []apisv1.ApplicationBundleItem{{Application: apisv1.ApplicationBundleMeta{Name: "app1"}}, {Application: apisv1.ApplicationBundleMeta{Name: "app2"}}},
+		}
+		content, err := encodeApplicationBundle(bundle, apisv1.ApplicationBundleFormatTar)
+		Expect(err).Should(BeNil())
+		decoded, err := decodeApplicationBundle(apisv1.ApplicationBundleFormatTar, content)
+		Expect(err).Should(BeNil())
+		Expect(decoded.Project).Should(BeEquivalentTo("default"))
+		Expect(len(decoded.Applications)).Should(BeEquivalentTo(2))
+
+		_, err = decodeApplicationBundle(apisv1.ApplicationBundleFormatYAML, "kind: Application")
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationBundleInvalid))
+	})
+
+	It("Test mapping the targets of the workflow steps", func() {
+		steps := mappingWorkflowStepTargets([]model.WorkflowStep{{
+			WorkflowStepBase: model.WorkflowStepBase{
+				Name:       "staging",
+				Type:       "deploy",
+				Properties: &model.JSONStruct{"policies": []interface{}{"staging"}},
+			},
+		}}, map[string]string{"staging": "prod"})
+		Expect(steps[0].Name).Should(BeEquivalentTo("prod"))
+		Expect((*steps[0].Properties)["policies"]).Should(BeEquivalentTo([]interface{}{"prod"}))
+	})
+})
@@ -0,0 +1,370 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// createChangeRequest hold the deploy of the protected env until it is approved
+func (c *applicationServiceImpl) createChangeRequest(ctx context.Context, app *model.Application, env *model.Env, oamApp *v1beta1.Application, workflow *model.Workflow, req apisv1.ApplicationDeployRequest, userName string) (*apisv1.ApplicationDeployResponse, error) {
+	configByte, err := yaml.Marshal(oamApp)
+	if err != nil {
+		return nil, err
+	}
+	var baseSpec interface{}
+	list, err := c.Store.List(ctx, &model.ApplicationRevision{AppPrimaryKey: app.PrimaryKey(), EnvName: env.Name}, &datastore.ListOptions{
+		PageSize: 1, Page: 1, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
+	if len(list) > 0 {
+		baseApp := &v1beta1.Application{}
+		if err := yaml.Unmarshal([]byte(list[0].(*model.ApplicationRevision).ApplyAppConfig), baseApp); err == nil {
+			baseSpec = baseApp.Spec
+		}
+	}
+	diff, err := unifiedDiff("current", "request", baseSpec, oamApp.Spec)
+	if err != nil {
+		return nil, err
+	}
+	changeRequest := &model.ChangeRequest{
+		AppPrimaryKey:  app.PrimaryKey(),
+		Name:           utils.GenerateVersion("cr"),
+		Project:        app.Project,
+		EnvName:        env.Name,
+		WorkflowName:   workflow.Name,
+		Requester:      userName,
+		Note:           req.Note,
+		TriggerType:    req.TriggerType,
+		Force:          req.Force,
+		ApplyAppConfig: string(configByte),
+		Diff:           diff,
+		CodeInfo:       req.CodeInfo,
+		ImageInfo:      req.ImageInfo,
+		Status:         model.ChangeRequestStatusPending,
+		MinApprovals:   env.Protection.MinApprovals,
+	}
+	if changeRequest.MinApprovals < 1 {
+		changeRequest.MinApprovals = 1
+	}
+	if err := c.Store.Add(ctx, changeRequest); err != nil {
+		return nil, err
+	}
+	klog.Infof("the env %s is protected, the deploy of the application %s is held by the change request %s", env.Name, app.Name, changeRequest.Name)
+	return &apisv1.ApplicationDeployResponse{ChangeRequest: convertChangeRequestModelToBase(changeRequest)}, nil
+}
+
+// ListChangeRequests list the change requests of the application
+func (c *applicationServiceImpl) ListChangeRequests(ctx context.Context, app *model.Application, envName, status string, page, pageSize int) (*apisv1.ListChangeRequestsResponse, error) {
+	changeRequest := &model.ChangeRequest{AppPrimaryKey: app.PrimaryKey(), EnvName: envName, Status: status}
+	entities, err := c.Store.List(ctx, changeRequest, &datastore.ListOptions{
+		Page:     page,
+		PageSize: pageSize,
+		SortBy:   []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListChangeRequestsResponse{ChangeRequests: []*apisv1.ChangeRequestBase{}}
+	for _, entity := range entities {
+		res.ChangeRequests = append(res.ChangeRequests, convertChangeRequestModelToBase(entity.(*model.ChangeRequest)))
+	}
+	count, err := c.Store.Count(ctx, changeRequest, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Total = count
+	return res, nil
+}
+
+// DetailChangeRequest get the change request with the rendered configuration and the diff
+func (c *applicationServiceImpl) DetailChangeRequest(ctx context.Context, app *model.Application, name string) (*apisv1.DetailChangeRequestResponse, error) {
+	changeRequest, err := c.getChangeRequest(ctx, app, name)
+	if err != nil {
+		return nil, err
+	}
+	return &apisv1.DetailChangeRequestResponse{
+		ChangeRequestBase: *convertChangeRequestModelToBase(changeRequest),
+		ApplyAppConfig:    changeRequest.ApplyAppConfig,
+		Diff:              changeRequest.Diff,
+	}, nil
+}
+
+// ReviewChangeRequest approve or reject the change request, the application is deployed once the approvals are enough
+func (c *applicationServiceImpl) ReviewChangeRequest(ctx context.Context, app *model.Application, name string, req apisv1.ReviewChangeRequestRequest) (*apisv1.ChangeRequestBase, error) {
+	changeRequest, err := c.getChangeRequest(ctx, app, name)
+	if err != nil {
+		return nil, err
+	}
+	if changeRequest.Status != model.ChangeRequestStatusPending {
+		return nil, bcode.ErrChangeRequestNotPending
+	}
+	userName, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if userName == changeRequest.Requester {
+		return nil, bcode.ErrChangeRequestSelfReview
+	}
+	for _, review := range changeRequest.Reviews {
+		if review.User == userName {
+			return nil, bcode.ErrChangeRequestReviewed
+		}
+	}
+	env := &model.Env{Name: changeRequest.EnvName}
+	if err := c.Store.Get(ctx, env); err != nil {
+		return nil, err
+	}
+	// the protection is removed after the change request is created, nobody could approve it
+	if env.Protection == nil {
+		changeRequest.Status = model.ChangeRequestStatusOutdated
+		changeRequest.Reason = bcode.ErrChangeRequestProtectionRemoved.Message
+		if err := c.Store.Put(ctx, changeRequest); err != nil {
+			return nil, err
+		}
+		return nil, bcode.ErrChangeRequestProtectionRemoved
+	}
+	if !isEnvApprover(ctx, c.Store, env, changeRequest.Project, userName) {
+		return nil, bcode.ErrChangeRequestNotApprover
+	}
+	changeRequest.Reviews = append(changeRequest.Reviews, model.ChangeRequestReview{
+		User:       userName,
+		Approved:   req.Approved,
+		Comment:    req.Comment,
+		ReviewTime: time.Now(),
+	})
+	switch {
+	case !req.Approved:
+		changeRequest.Status = model.ChangeRequestStatusRejected
+	case changeRequest.Approvals() >= changeRequest.MinApprovals:
+		changeRequest.Status = model.ChangeRequestStatusApproved
+	}
+	if err := c.Store.Put(ctx, changeRequest); err != nil {
+		return nil, err
+	}
+	if changeRequest.Status == model.ChangeRequestStatusApproved {
+		// the change request keeps approved if the deploy fails, it could be deployed again
+		if _, err := c.deployChangeRequest(ctx, app, changeRequest); err != nil {
+			klog.Warningf("failed to deploy the approved change request %s: %s", changeRequest.Name, err.Error())
+		}
+	}
+	return convertChangeRequestModelToBase(changeRequest), nil
+}
+
+// DeployChangeRequest deploy the approved change request
+func (c *applicationServiceImpl) DeployChangeRequest(ctx context.Context, app *model.Application, name string) (*apisv1.ApplicationDeployResponse, error) {
+	changeRequest, err := c.getChangeRequest(ctx, app, name)
+	if err != nil {
+		return nil, err
+	}
+	if changeRequest.Status != model.ChangeRequestStatusApproved {
+		return nil, bcode.ErrChangeRequestNotApproved
+	}
+	return c.deployChangeRequest(ctx, app, changeRequest)
+}
+
+// CancelChangeRequest close the change request that is not deployed
+func (c *applicationServiceImpl) CancelChangeRequest(ctx context.Context, app *model.Application, name string) (*apisv1.ChangeRequestBase, error) {
+	changeRequest, err := c.getChangeRequest(ctx, app, name)
+	if err != nil {
+		return nil, err
+	}
+	if changeRequest.Status != model.ChangeRequestStatusPending && changeRequest.Status != model.ChangeRequestStatusApproved {
+		return nil, bcode.ErrChangeRequestNotPending
+	}
+	changeRequest.Status = model.ChangeRequestStatusCanceled
+	if err := c.Store.Put(ctx, changeRequest); err != nil {
+		return nil, err
+	}
+	return convertChangeRequestModelToBase(changeRequest), nil
+}
+
+func (c *applicationServiceImpl) deployChangeRequest(ctx context.Context, app *model.Application, changeRequest *model.ChangeRequest) (*apisv1.ApplicationDeployResponse, error) {
+	res, err := c.deploy(ctx, app, apisv1.ApplicationDeployRequest{
+		WorkflowName: changeRequest.WorkflowName,
+		Note:         changeRequest.Note,
+		TriggerType:  changeRequest.TriggerType,
+		Force:        changeRequest.Force,
+		CodeInfo:     changeRequest.CodeInfo,
+		ImageInfo:    changeRequest.ImageInfo,
+	}, changeRequest)
+	if err != nil {
+		changeRequest.Reason = err.Error()
+		if errors.Is(err, bcode.ErrChangeRequestOutdated) {
+			changeRequest.Status = model.ChangeRequestStatusOutdated
+		}
+	} else {
+		changeRequest.Reason = ""
+		changeRequest.Status = model.ChangeRequestStatusDeployed
+		changeRequest.Revision = res.Version
+	}
+	if err := c.Store.Put(ctx, changeRequest); err != nil {
+		klog.Warningf("failed to update the change request %s: %s", changeRequest.Name, err.Error())
+	}
+	return res, err
+}
+
+// checkChangeRequestConfig check whether the application is changed after the change request is created
+func checkChangeRequestConfig(changeRequest *model.ChangeRequest, oamApp *v1beta1.Application) error {
+	requestApp := &v1beta1.Application{}
+	if err := yaml.Unmarshal([]byte(changeRequest.ApplyAppConfig), requestApp); err != nil {
+		return err
+	}
+	diff, err := unifiedDiff("request", "current", requestApp.Spec, oamApp.Spec)
+	if err != nil {
+		return err
+	}
+	if diff != "" {
+		return bcode.ErrChangeRequestOutdated
+	}
+	return nil
+}
+
+// checkProtectedEnvRollback the rollback applies the historical revision without the change request,
+// so only the approvers could roll back the application in the protected env.
+func checkProtectedEnvRollback(ctx context.Context, store datastore.DataStore, envName, project, userName string) error {
+	if envName == "" {
+		return nil
+	}
+	env := &model.Env{Name: envName}
+	if err := store.Get(ctx, env); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil
+		}
+		return err
+	}
+	if env.Protection != nil && !isEnvApprover(ctx, store, env, project, userName) {
+		return bcode.ErrChangeRequestRollbackForbidden
+	}
+	return nil
+}
+
+// pipelineApplyStepObjects the step types that apply the object in the properties
+var pipelineApplyStepObjects = map[string]string{"apply-app": "data", "apply-object": "value"}
+
+// checkPipelineProtectedEnvs the pipeline could not apply the application to the namespace of the protected env,
+// the deploys of the protected env must be approved by the change requests.
+func checkPipelineProtectedEnvs(ctx context.Context, store datastore.DataStore, namespace string, spec model.WorkflowSpec) error {
+	var steps []model.WorkflowStepBase
+	for _, step := range spec.Steps {
+		steps = append(steps, step.WorkflowStepBase)
+		steps = append(steps, step.SubSteps...)
+	}
+	for _, step := range steps {
+		key, exist := pipelineApplyStepObjects[step.Type]
+		if !exist || step.Properties == nil {
+			continue
+		}
+		object, ok := (*step.Properties)[key].(map[string]interface{})
+		if !ok || object["kind"] != "Application" {
+			continue
+		}
+		targetNamespace := namespace
+		if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+			if ns, ok := metadata["namespace"].(string); ok && ns != "" {
+				targetNamespace = ns
+			}
+		}
+		envs, err := store.List(ctx, &model.Env{Namespace: targetNamespace}, &datastore.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, entity := range envs {
+			if entity.(*model.Env).Protection != nil {
+				return bcode.ErrChangeRequestPipelineForbidden
+			}
+		}
+	}
+	return nil
+}
+
+// isEnvApprover check whether the user is an approver of the protected env, or has one of the approver roles in the platform or the project
+func isEnvApprover(ctx context.Context, store datastore.DataStore, env *model.Env, project, userName string) bool {
+	if env.Protection == nil || userName == "" {
+		return false
+	}
+	for _, approver := range env.Protection.Approvers {
+		if approver == userName {
+			return true
+		}
+	}
+	if len(env.Protection.ApproverRoles) == 0 {
+		return false
+	}
+	var roles []string
+	user := &model.User{Name: userName}
+	if err := store.Get(ctx, user); err == nil {
+		roles = append(roles, user.UserRoles...)
+	}
+	projectUser := &model.ProjectUser{Username: userName, ProjectName: project}
+	if err := store.Get(ctx, projectUser); err == nil {
+		roles = append(roles, projectUser.UserRoles...)
+	}
+	for _, role := range roles {
+		for _, approverRole := range env.Protection.ApproverRoles {
+			if role == approverRole {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *applicationServiceImpl) getChangeRequest(ctx context.Context, app *model.Application, name string) (*model.ChangeRequest, error) {
+	changeRequest := &model.ChangeRequest{AppPrimaryKey: app.PrimaryKey(), Name: name}
+	if err := c.Store.Get(ctx, changeRequest); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrChangeRequestNotExist
+		}
+		return nil, err
+	}
+	return changeRequest, nil
+}
+
+func convertChangeRequestModelToBase(changeRequest *model.ChangeRequest) *apisv1.ChangeRequestBase {
+	return &apisv1.ChangeRequestBase{
+		Name:         changeRequest.Name,
+		Project:      changeRequest.Project,
+		EnvName:      changeRequest.EnvName,
+		WorkflowName: changeRequest.WorkflowName,
+		Requester:    changeRequest.Requester,
+		Note:         changeRequest.Note,
+		TriggerType:  changeRequest.TriggerType,
+		Force:        changeRequest.Force,
+		Status:       changeRequest.Status,
+		Reason:       changeRequest.Reason,
+		MinApprovals: changeRequest.MinApprovals,
+		Approvals:    changeRequest.Approvals(),
+		Reviews:      changeRequest.Reviews,
+		Revision:     changeRequest.Revision,
+		CodeInfo:     changeRequest.CodeInfo,
+		ImageInfo:    changeRequest.ImageInfo,
+		CreateTime:   changeRequest.CreateTime,
+		UpdateTime:   changeRequest.UpdateTime,
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the change requests of the protected env", func() {
+	var (
+		crProject = "test-cr-project"
+		crApp     = "test-cr-app"
+		crEnv     = "test-cr-prod"
+		adminCtx  context.Context
+		userCtx   = func(name string) context.Context {
+			return context.WithValue(context.TODO(), &apisv1.CtxKeyUser, name)
+		}
+	)
+
+	It("Init services and data", func() {
+		adminCtx = InitTestEnvWithAdmin("app-change-request-test-kubevela")
+		for _, name := range []string{"test-cr-approver1", "test-cr-approver2"} {
+			_, err := userService.CreateUser(adminCtx, apisv1.CreateUserRequest{Name: name, Email: name + "@example.com", Password: "password1"})
+			Expect(err).Should(BeNil())
+		}
+		InitTestProject(adminCtx, crProject, "test-cr-target", crEnv)
+		InitTestApplication(adminCtx, crProject, crApp, `{"image":"nginx"}`, crEnv)
+	})
+
+	It("Test updating the env protection", func() {
+		_, err := envService.UpdateEnvProtection(adminCtx, crEnv, apisv1.UpdateEnvProtectionRequest{Approvers: []string{"not-exist"}})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrEnvProtectionInvalid))
+		_, err = envService.UpdateEnvProtection(adminCtx, crEnv, apisv1.UpdateEnvProtectionRequest{Approvers: []string{"test-cr-approver1"}, MinApprovals: 2})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrEnvProtectionInvalid))
+
+		env, err := envService.UpdateEnvProtection(adminCtx, crEnv, apisv1.UpdateEnvProtectionRequest{
+			Approvers: []string{"test-cr-approver1", "test-cr-approver2"}, MinApprovals: 2})
+		Expect(err).Should(BeNil())
+		Expect(env.Protection.MinApprovals).Should(BeEquivalentTo(2))
+	})
+
+	It("Test approving the change request", func() {
+		app, err := appService.GetApplication(adminCtx, crApp)
+		Expect(err).Should(BeNil())
+		res, err := appService.Deploy(adminCtx, app, apisv1.ApplicationDeployRequest{WorkflowName: repository.ConvertWorkflowName(crEnv), TriggerType: apisv1.TriggerTypeWeb})
+		Expect(err).Should(BeNil())
+		Expect(res.ChangeRequest).ShouldNot(BeNil())
+		Expect(res.ChangeRequest.Status).Should(BeEquivalentTo(model.ChangeRequestStatusPending))
+		revisions, err := appService.ListRevisions(adminCtx, crApp, crEnv, "", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(revisions.Total).Should(BeEquivalentTo(0))
+
+		detail, err := appService.DetailChangeRequest(adminCtx, app, res.ChangeRequest.Name)
+		Expect(err).Should(BeNil())
+		Expect(detail.Diff).Should(ContainSubstring("nginx"))
+
+		_, err = appService.ReviewChangeRequest(adminCtx, app, res.ChangeRequest.Name, apisv1.ReviewChangeRequestRequest{Approved: true})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrChangeRequestSelfReview))
+		_, err = appService.DeployChangeRequest(adminCtx, app, res.ChangeRequest.Name)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrChangeRequestNotApproved))
+
+		changeRequest, err := appService.ReviewChangeRequest(userCtx("test-cr-approver1"), app, res.ChangeRequest.Name, apisv1.ReviewChangeRequestRequest{Approved: true})
+		Expect(err).Should(BeNil())
+		Expect(changeRequest.Status).Should(BeEquivalentTo(model.ChangeRequestStatusPending))
+		Expect(changeRequest.Approvals).Should(BeEquivalentTo(1))
+		_, err = appService.ReviewChangeRequest(userCtx("test-cr-approver1"), app, res.ChangeRequest.Name, apisv1.ReviewChangeRequestRequest{Approved: true})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrChangeRequestReviewed))
+
+		changeRequest, err = appService.ReviewChangeRequest(userCtx("test-cr-approver2"), app, res.ChangeRequest.Name, apisv1.ReviewChangeRequestRequest{Approved: true})
+		Expect(err).Should(BeNil())
+		Expect(changeRequest.Status).Should(BeEquivalentTo(model.ChangeRequestStatusDeployed))
+		Expect(changeRequest.Revision).ShouldNot(BeEmpty())
+		revision, err := appService.DetailRevision(adminCtx, crApp, changeRequest.Revision)
+		Expect(err).Should(BeNil())
+		Expect(revision.DeployUser.Name).Should(BeEquivalentTo(FakeAdminName))
+	})
+
+	It("Test rejecting the outdated change request", func() {
+		app, err := appService.GetApplication(adminCtx, crApp)
+		Expect(err).Should(BeNil())
+		res, err := appService.Deploy(adminCtx, app, apisv1.ApplicationDeployRequest{WorkflowName: repository.ConvertWorkflowName(crEnv), TriggerType: apisv1.TriggerTypeWeb, Force: true})
+		Expect(err).Should(BeNil())
+		Expect(res.ChangeRequest).ShouldNot(BeNil())
+		Expect(res.ChangeRequest.Force).Should(BeTrue())
+
+		component, err := appService.GetApplicationComponent(adminCtx, app, "web")
+		Expect(err).Should(BeNil())
+		properties := `{"image":"nginx:1.25"}`
+		_, err = appService.UpdateComponent(adminCtx, app, component, apisv1.UpdateApplicationComponentRequest{Properties: &properties})
+		Expect(err).Should(BeNil())
+
+		_, err = appService.ReviewChangeRequest(userCtx("test-cr-approver1"), app, res.ChangeRequest.Name, apisv1.ReviewChangeRequestRequest{Approved: true})
+		Expect(err).Should(BeNil())
+		changeRequest, err := appService.ReviewChangeRequest(userCtx("test-cr-approver2"), app, res.ChangeRequest.Name, apisv1.ReviewChangeRequestRequest{Approved: true})
+		Expect(err).Should(BeNil())
+		Expect(changeRequest.Status).Should(BeEquivalentTo(model.ChangeRequestStatusOutdated))
+
+		res, err = appService.Deploy(adminCtx, app, apisv1.ApplicationDeployRequest{WorkflowName: repository.ConvertWorkflowName(crEnv), TriggerType: apisv1.TriggerTypeWeb, Force: true})
+		Expect(err).Should(BeNil())
+		changeRequest, err = appService.ReviewChangeRequest(userCtx("test-cr-approver1"), app, res.ChangeRequest.Name, apisv1.ReviewChangeRequestRequest{Approved: false, Comment: "not now"})
+		Expect(err).Should(BeNil())
+		Expect(changeRequest.Status).Should(BeEquivalentTo(model.ChangeRequestStatusRejected))
+
+		list, err := appService.ListChangeRequests(adminCtx, app, crEnv, "", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(list.Total).Should(BeEquivalentTo(3))
+	})
+
+	It("Test rolling back the application and running the pipeline in the protected env", func() {
+		app, err := appService.GetApplication(adminCtx, crApp)
+		Expect(err).Should(BeNil())
+		deployed, err := appService.ListChangeRequests(adminCtx, app, crEnv, model.ChangeRequestStatusDeployed, 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(len(deployed.ChangeRequests)).Should(Equal(1))
+		_, err = appService.RollbackWithRevision(adminCtx, app, deployed.ChangeRequests[0].Revision)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrChangeRequestRollbackForbidden))
+
+		properties := model.JSONStruct{"data": map[string]interface{}{
+			"apiVersion": "core.oam.dev/v1beta1",
+			"kind":       "Application",
+			"metadata":   map[string]interface{}{"name": crApp, "namespace": crEnv},
+		}}
+		spec := model.WorkflowSpec{Steps: []model.WorkflowStep{{
+			WorkflowStepBase: model.WorkflowStepBase{Name: "apply", Type: "apply-app", Properties: &properties},
+		}}}
+		Expect(checkPipelineProtectedEnvs(context.TODO(), appService.Store, crProject, spec)).Should(BeEquivalentTo(bcode.ErrChangeRequestPipelineForbidden))
+		properties["data"].(map[string]interface{})["metadata"] = map[string]interface{}{"name": crApp}
+		Expect(checkPipelineProtectedEnvs(context.TODO(), appService.Store, crProject, spec)).Should(BeNil())
+	})
+
+	It("Test the scheduled deploy held by the change request", func() {
+		app, err := appService.GetApplication(adminCtx, crApp)
+		Expect(err).Should(BeNil())
+		scheduleTime := time.Now().Add(time.Hour)
+		res, err := appService.Deploy(adminCtx, app, apisv1.ApplicationDeployRequest{
+			WorkflowName: repository.ConvertWorkflowName(crEnv), TriggerType: apisv1.TriggerTypeWeb, ScheduleTime: &scheduleTime, Force: true})
+		Expect(err).Should(BeNil())
+		Expect(res.ChangeRequest).Should(BeNil())
+		scheduled := &model.ScheduledDeploy{AppPrimaryKey: app.PrimaryKey(), Name: res.Version}
+		Expect(appService.Store.Get(adminCtx, scheduled)).Should(BeNil())
+		scheduled.ScheduleTime = time.Now().Add(-time.Minute)
+		Expect(appService.Store.Put(adminCtx, scheduled)).Should(BeNil())
+
+		Expect(appService.ExecuteScheduledDeploys(context.TODO())).Should(BeNil())
+		Expect(appService.Store.Get(adminCtx, scheduled)).Should(BeNil())
+		Expect(scheduled.Status).Should(BeEquivalentTo(model.ScheduledDeployStatusPendingApproval))
+		Expect(scheduled.Revision).Should(BeEmpty())
+		Expect(scheduled.ChangeRequest).ShouldNot(BeEmpty())
+		detail, err := appService.DetailChangeRequest(adminCtx, app, scheduled.ChangeRequest)
+		Expect(err).Should(BeNil())
+		Expect(detail.Status).Should(BeEquivalentTo(model.ChangeRequestStatusPending))
+		_, err = appService.CancelChangeRequest(adminCtx, app, scheduled.ChangeRequest)
+		Expect(err).Should(BeNil())
+	})
+
+	It("Test the change request after the protection is removed", func() {
+		app, err := appService.GetApplication(adminCtx, crApp)
+		Expect(err).Should(BeNil())
+		res, err := appService.Deploy(adminCtx, app, apisv1.ApplicationDeployRequest{WorkflowName: repository.ConvertWorkflowName(crEnv), TriggerType: apisv1.TriggerTypeWeb, Force: true})
+		Expect(err).Should(BeNil())
+		Expect(res.ChangeRequest).ShouldNot(BeNil())
+
+		_, err = envService.UpdateEnvProtection(adminCtx, crEnv, apisv1.UpdateEnvProtectionRequest{})
+		Expect(err).Should(BeNil())
+		_, err = appService.ReviewChangeRequest(userCtx("test-cr-approver1"), app, res.ChangeRequest.Name, apisv1.ReviewChangeRequestRequest{Approved: true})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrChangeRequestProtectionRemoved))
+		detail, err := appService.DetailChangeRequest(adminCtx, app, res.ChangeRequest.Name)
+		Expect(err).Should(BeNil())
+		Expect(detail.Status).Should(BeEquivalentTo(model.ChangeRequestStatusOutdated))
+
+		_, err = envService.UpdateEnvProtection(adminCtx, crEnv, apisv1.UpdateEnvProtectionRequest{
+			Approvers: []string{"test-cr-approver1", "test-cr-approver2"}, MinApprovals: 2})
+		Expect(err).Should(BeNil())
+	})
+})
@@ -0,0 +1,290 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/klog/v2"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	velatypes "github.com/oam-dev/kubevela/apis/types"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	assembler "github.com/kubevela/velaux/pkg/server/interfaces/api/assembler/v1"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// CloneApplication deep copy the components, policies, workflows, env bindings and triggers of the application.
+// The revisions and the workflow records are not copied.
+func (c *applicationServiceImpl) CloneApplication(ctx context.Context, app *model.Application, req apisv1.CloneApplicationRequest) (*apisv1.ApplicationBase, error) {
+	projectName := req.Project
+	if projectName == "" {
+		projectName = app.Project
+	}
+	project, err := c.ProjectService.DetailProject(ctx, projectName)
+	if err != nil {
+		return nil, bcode.ErrProjectIsNotExist
+	}
+	clone := model.Application{
+		Name:        req.Name,
+		Alias:       req.Alias,
+		Description: req.Description,
+		Icon:        app.Icon,
+		Project:     project.Name,
+		Annotations: app.Annotations,
+	}
+	if clone.Alias == "" {
+		clone.Alias = app.Alias
+	}
+	if clone.Description == "" {
+		clone.Description = app.Description
+	}
+	// the cloned application is managed by the UX, the synced labels should not be copied
+	for k, v := range app.Labels {
+		if k == model.LabelSyncNamespace || k == model.LabelSyncGeneration || k == velatypes.LabelSourceOfTruth {
+			continue
+		}
+		if clone.Labels == nil {
+			clone.Labels = map[string]string{}
+		}
+		clone.Labels[k] = v
+	}
+	exist, err := c.Store.IsExist(ctx, &clone)
+	if err != nil {
+		klog.Errorf("check application name is exist failure %s", err.Error())
+		return nil, bcode.ErrApplicationExist
+	}
+	if exist {
+		return nil, bcode.ErrApplicationExist
+	}
+
+	workflows, err := c.Store.List(ctx, &model.Workflow{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	envMapping, envBindings, err := c.mappingCloneEnvs(ctx, app, project.Name, req.EnvMapping, workflows)
+	if err != nil {
+		return nil, err
+	}
+	// check the triggers before writing any record, so the application is not cloned partially by the invalid triggers
+	triggers, err := c.Store.List(ctx, &model.ApplicationTrigger{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range triggers {
+		if err := checkBundleTrigger(trigger2BundleTrigger(entity.(*model.ApplicationTrigger))); err != nil {
+			return nil, err
+		}
+	}
+	cloned := false
+	defer func() {
+		if cloned {
+			return
+		}
+		if err := c.deleteApplicationRecords(ctx, &clone); err != nil {
+			klog.Errorf("clean up the records of the application %s failure %s", clone.Name, err.Error())
+		}
+	}()
+
+	userName, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	components, err := c.Store.List(ctx, &model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range components {
+		component := entity.(*model.ApplicationComponent)
+		component.BaseModel = model.BaseModel{}
+		component.AppPrimaryKey = clone.PrimaryKey()
+		component.Creator = userName
+		if err := c.Store.Add(ctx, component); err != nil {
+			return nil, err
+		}
+	}
+	policies, err := repository.ListApplicationCommonPolicies(ctx, c.Store, app)
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range policies {
+		policy.BaseModel = model.BaseModel{}
+		policy.AppPrimaryKey = clone.PrimaryKey()
+		policy.Creator = userName
+		if err := c.Store.Add(ctx, policy); err != nil {
+			return nil, err
+		}
+	}
+
+	// create the env bindings by the target envs, the workflows and topology policies are generated by the targets of the envs
+	if len(envBindings) > 0 {
+		if err := c.saveApplicationEnvBinding(ctx, clone, envBindings); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.cloneEnvResources(ctx, app, &clone, envMapping, workflows); err != nil {
+		return nil, err
+	}
+	if err := c.cloneTriggers(ctx, app, &clone, envMapping, workflows, triggers); err != nil {
+		return nil, err
+	}
+
+	if err := c.Store.Add(ctx, &clone); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			// the records may belong to the application created at the same time, keep them
+			cloned = true
+			return nil, bcode.ErrApplicationExist
+		}
+		return nil, err
+	}
+	cloned = true
+	return assembler.ConvertAppModelToBase(&clone, []*apisv1.ProjectBase{project}), nil
+}
+
+// mappingCloneEnvs return the mapping from the source envs to the target envs, the env of the default workflow is the first one.
+func (c *applicationServiceImpl) mappingCloneEnvs(ctx context.Context, app *model.Application, projectName string, mapping map[string]string, workflows []datastore.Entity) (map[string]string, []*apisv1.EnvBinding, error) {
+	bindings, err := c.Store.List(ctx, &model.EnvBinding{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var defaultEnv string
+	for _, entity := range workflows {
+		if workflow := entity.(*model.Workflow); workflow.Default != nil && *workflow.Default {
+			defaultEnv = workflow.EnvName
+		}
+	}
+	envMapping := map[string]string{}
+	targetEnvs := map[string]bool{}
+	var envBindings []*apisv1.EnvBinding
+	for _, entity := range bindings {
+		binding := entity.(*model.EnvBinding)
+		envName, mapped := mapping[binding.Name]
+		if !mapped {
+			if projectName != app.Project {
+				klog.Infof("skip the env %s when cloning the application %s to the project %s", binding.Name, app.Name, projectName)
+				continue
+			}
+			envName = binding.Name
+		}
+		env, err := repository.GetEnv(ctx, c.Store, envName)
+		if err != nil || env.Project != projectName || targetEnvs[envName] {
+			return nil, nil, bcode.ErrApplicationCloneEnvInvalid
+		}
+		targetEnvs[envName] = true
+		envMapping[binding.Name] = envName
+		if binding.Name == defaultEnv {
+			envBindings = append([]*apisv1.EnvBinding{{Name: envName}}, envBindings...)
+		} else {
+			envBindings = append(envBindings, &apisv1.EnvBinding{Name: envName})
+		}
+	}
+	for source := range mapping {
+		if _, exist := envMapping[source]; !exist {
+			return nil, nil, bcode.ErrApplicationCloneEnvInvalid
+		}
+	}
+	return envMapping, envBindings, nil
+}
+
+// cloneEnvResources copy the component patches, the env policies and the workflows to the cloned application
+func (c *applicationServiceImpl) cloneEnvResources(ctx context.Context, app, clone *model.Application, envMapping map[string]string, workflows []datastore.Entity) error {
+	for source, target := range envMapping {
+		binding := &model.EnvBinding{AppPrimaryKey: app.PrimaryKey(), Name: source}
+		if err := c.Store.Get(ctx, binding); err != nil {
+			return err
+		}
+		if len(binding.ComponentsPatch) > 0 {
+			cloneBinding := &model.EnvBinding{AppPrimaryKey: clone.PrimaryKey(), Name: target}
+			if err := c.Store.Get(ctx, cloneBinding); err != nil {
+				return err
+			}
+			cloneBinding.ComponentsPatch = binding.ComponentsPatch
+			if err := c.Store.Put(ctx, cloneBinding); err != nil {
+				return err
+			}
+		}
+		policies, err := repository.ListApplicationEnvPolicies(ctx, c.Store, app, source)
+		if err != nil {
+			return err
+		}
+		for _, policy := range policies {
+			// the topology policies are generated by the targets of the target env
+			if policy.Type == v1alpha1.TopologyPolicyType || policy.Type == v1alpha1.EnvBindingPolicyType {
+				continue
+			}
+			policy.BaseModel = model.BaseModel{}
+			policy.AppPrimaryKey = clone.PrimaryKey()
+			policy.EnvName = target
+			if err := c.Store.Add(ctx, policy); err != nil && !errors.Is(err, datastore.ErrRecordExist) {
+				return err
+			}
+		}
+	}
+	for _, entity := range workflows {
+		workflow := entity.(*model.Workflow)
+		target, mapped := envMapping[workflow.EnvName]
+		switch {
+		case workflow.EnvName == "" && clone.Project == app.Project:
+			workflow.BaseModel = model.BaseModel{}
+			workflow.AppPrimaryKey = clone.PrimaryKey()
+			if err := c.Store.Add(ctx, workflow); err != nil && !errors.Is(err, datastore.ErrRecordExist) {
+				return err
+			}
+		case mapped && target == workflow.EnvName:
+			// keep the custom steps of the workflow if the env is not changed
+			cloneWorkflow := &model.Workflow{AppPrimaryKey: clone.PrimaryKey(), Name: workflow.Name}
+			if err := c.Store.Get(ctx, cloneWorkflow); err != nil {
+				return err
+			}
+			cloneWorkflow.Steps = workflow.Steps
+			cloneWorkflow.Mode = workflow.Mode
+			cloneWorkflow.Alias = workflow.Alias
+			cloneWorkflow.Description = workflow.Description
+			if err := c.Store.Put(ctx, cloneWorkflow); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cloneTriggers create the triggers with the new tokens, the secrets are not copied
+func (c *applicationServiceImpl) cloneTriggers(ctx context.Context, app, clone *model.Application, envMapping map[string]string, workflows, triggers []datastore.Entity) error {
+	workflowNames := map[string]string{}
+	for _, entity := range workflows {
+		workflow := entity.(*model.Workflow)
+		if target, mapped := envMapping[workflow.EnvName]; mapped {
+			workflowNames[workflow.Name] = repository.ConvertWorkflowName(target)
+		} else if workflow.EnvName == "" && clone.Project == app.Project {
+			workflowNames[workflow.Name] = workflow.Name
+		}
+	}
+	for _, entity := range triggers {
+		trigger := entity.(*model.ApplicationTrigger)
+		workflowName, exist := workflowNames[trigger.WorkflowName]
+		if !exist {
+			klog.Infof("skip the trigger %s when cloning the application %s, the workflow is not cloned", trigger.Name, app.Name)
+			continue
+		}
+		if _, err := c.CreateApplicationTrigger(ctx, clone, bundleTrigger2CreateRequest(trigger2BundleTrigger(trigger), workflowName)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
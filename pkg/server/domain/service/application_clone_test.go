@@ -0,0 +1,156 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test cloning the application", func() {
+	var (
+		cloneProject      = "test-clone-project"
+		cloneOtherProject = "test-clone-other"
+		cloneApp          = "test-clone-app"
+		cloneCtx          context.Context
+	)
+
+	It("Init services and data", func() {
+		cloneCtx = InitTestEnvWithAdmin("app-clone-test-kubevela")
+		for _, project := range []string{cloneProject, cloneOtherProject} {
+			InitTestProject(cloneCtx, project, project+"-target", project+"-dev")
+		}
+		InitTestApplication(cloneCtx, cloneProject, cloneApp, `{"image":"nginx"}`, cloneProject+"-dev")
+	})
+
+	It("Test cloning the application in the same project", func() {
+		app, err := appService.GetApplication(cloneCtx, cloneApp)
+		Expect(err).Should(BeNil())
+		_, err = appService.CloneApplication(cloneCtx, app, apisv1.CloneApplicationRequest{Name: cloneApp})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationExist))
+
+		triggers, err := appService.ListApplicationTriggers(cloneCtx, app)
+		Expect(err).Should(BeNil())
+		_, err = appService.CreateApplicationTrigger(cloneCtx, app, apisv1.CreateApplicationTriggerRequest{
+			Name:               "git",
+			WorkflowName:       triggers[0].WorkflowName,
+			Type:               "webhook",
+			PayloadType:        model.PayloadTypeGitHub,
+			ComponentName:      "web",
+			GitEvents:          []string{model.GitEventPush},
+			BranchFilters:      []string{"main"},
+			TagFilters:         []string{"v*"},
+			PropertiesTemplate: "image: nginx:{{.ShortCommit}}",
+		})
+		Expect(err).Should(BeNil())
+		_, err = appService.CreateApplicationTrigger(cloneCtx, app, apisv1.CreateApplicationTriggerRequest{
+			Name:            "registry",
+			WorkflowName:    triggers[0].WorkflowName,
+			Type:            "webhook",
+			PayloadType:     model.PayloadTypeHarbor,
+			ComponentName:   "web",
+			SignatureType:   "harbor",
+			AllowedCIDRs:    []string{"10.0.0.0/8"},
+			ImageProperties: []model.ImagePropertyMapping{{Path: "image", Template: "{{.Repository}}@{{.Digest}}"}},
+			ImageTagFilter:  &model.ImageTagFilter{Semver: ">= 1.0", Ignore: []string{"latest"}},
+		})
+		Expect(err).Should(BeNil())
+		_, err = appService.CreateApplicationTrigger(cloneCtx, app, apisv1.CreateApplicationTriggerRequest{
+			Name:          "mapped",
+			WorkflowName:  triggers[0].WorkflowName,
+			Type:          "webhook",
+			PayloadType:   model.PayloadTypeMapped,
+			ComponentName: "web",
+			PayloadMapping: &model.PayloadMapping{
+				Conditions: []model.PayloadCondition{{Path: "$.ref", Operator: model.PayloadConditionEquals, Values: []string{"main"}}},
+				Properties: map[string]string{"image": "$.image"},
+			},
+		})
+		Expect(err).Should(BeNil())
+
+		base, err := appService.CloneApplication(cloneCtx, app, apisv1.CloneApplicationRequest{Name: "test-clone-copy"})
+		Expect(err).Should(BeNil())
+		Expect(base.Project.Name).Should(BeEquivalentTo(cloneProject))
+
+		clone := &model.Application{Name: "test-clone-copy"}
+		component, err := appService.GetApplicationComponent(cloneCtx, clone, "web")
+		Expect(err).Should(BeNil())
+		Expect((*component.Properties)["image"]).Should(BeEquivalentTo("nginx"))
+		_, err = repository.GetWorkflowByEnv(cloneCtx, ds, clone, cloneProject+"-dev")
+		Expect(err).Should(BeNil())
+
+		triggers, err = appService.ListApplicationTriggers(cloneCtx, app)
+		Expect(err).Should(BeNil())
+		cloneTriggers, err := appService.ListApplicationTriggers(cloneCtx, clone)
+		Expect(err).Should(BeNil())
+		Expect(len(cloneTriggers)).Should(BeEquivalentTo(len(triggers)))
+		for _, trigger := range cloneTriggers {
+			for _, source := range triggers {
+				if source.Name != trigger.Name {
+					continue
+				}
+				Expect(trigger.Token).ShouldNot(BeEquivalentTo(source.Token))
+				Expect(trigger.GitEvents).Should(Equal(source.GitEvents))
+				Expect(trigger.BranchFilters).Should(Equal(source.BranchFilters))
+				Expect(trigger.TagFilters).Should(Equal(source.TagFilters))
+				Expect(trigger.PropertiesTemplate).Should(Equal(source.PropertiesTemplate))
+				Expect(trigger.ImageProperties).Should(Equal(source.ImageProperties))
+				Expect(trigger.ImageTagFilter).Should(Equal(source.ImageTagFilter))
+				Expect(trigger.SignatureType).Should(Equal(source.SignatureType))
+				Expect(trigger.AllowedCIDRs).Should(Equal(source.AllowedCIDRs))
+				Expect(trigger.PayloadMapping).Should(Equal(source.PayloadMapping))
+			}
+		}
+	})
+
+	It("Test cloning the application into another project", func() {
+		app, err := appService.GetApplication(cloneCtx, cloneApp)
+		Expect(err).Should(BeNil())
+		_, err = appService.CloneApplication(cloneCtx, app, apisv1.CloneApplicationRequest{
+			Name:       "test-clone-other-copy",
+			Project:    cloneOtherProject,
+			EnvMapping: map[string]string{cloneProject + "-dev": cloneProject + "-dev"},
+		})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationCloneEnvInvalid))
+
+		base, err := appService.CloneApplication(cloneCtx, app, apisv1.CloneApplicationRequest{
+			Name:       "test-clone-other-copy",
+			Project:    cloneOtherProject,
+			EnvMapping: map[string]string{cloneProject + "-dev": cloneOtherProject + "-dev"},
+		})
+		Expect(err).Should(BeNil())
+		Expect(base.Project.Name).Should(BeEquivalentTo(cloneOtherProject))
+
+		clone := &model.Application{Name: "test-clone-other-copy"}
+		workflow, err := repository.GetWorkflowByEnv(cloneCtx, ds, clone, cloneOtherProject+"-dev")
+		Expect(err).Should(BeNil())
+		Expect(workflow.Steps[0].Name).Should(BeEquivalentTo(cloneOtherProject + "-target"))
+		triggers, err := appService.ListApplicationTriggers(cloneCtx, clone)
+		Expect(err).Should(BeNil())
+		Expect(len(triggers)).Should(BeEquivalentTo(4))
+		for _, trigger := range triggers {
+			Expect(trigger.WorkflowName).Should(BeEquivalentTo(repository.ConvertWorkflowName(cloneOtherProject + "-dev")))
+		}
+	})
+})
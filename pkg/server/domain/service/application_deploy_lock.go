@@ -0,0 +1,175 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// ListDeployLocks list the locks of the application, the released and the expired locks are kept for auditing
+func (c *applicationServiceImpl) ListDeployLocks(ctx context.Context, app *model.Application, envName string) (*apisv1.ListDeployLocksResponse, error) {
+	entities, err := c.Store.List(ctx, &model.DeployLock{AppPrimaryKey: app.PrimaryKey(), EnvName: envName}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	res := &apisv1.ListDeployLocksResponse{DeployLocks: []*apisv1.DeployLockBase{}}
+	for _, entity := range entities {
+		res.DeployLocks = append(res.DeployLocks, c.convertDeployLockModelToBase(ctx, entity.(*model.DeployLock), now))
+	}
+	return res, nil
+}
+
+// CreateDeployLock lock the application in the env, only one active lock is allowed in an env
+func (c *applicationServiceImpl) CreateDeployLock(ctx context.Context, app *model.Application, req apisv1.CreateDeployLockRequest) (*apisv1.DeployLockBase, error) {
+	if err := c.Store.Get(ctx, &model.EnvBinding{AppPrimaryKey: app.PrimaryKey(), Name: req.EnvName}); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrEnvBindingNotExist
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if req.ExpireTime != nil && !req.ExpireTime.After(now) {
+		return nil, bcode.ErrDeployLockExpireTimeInvalid
+	}
+	lock, err := getActiveDeployLock(ctx, c.Store, app.PrimaryKey(), req.EnvName)
+	if err != nil {
+		return nil, err
+	}
+	if lock != nil {
+		return nil, bcode.ErrDeployLockExist
+	}
+	userName, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	lock = &model.DeployLock{
+		AppPrimaryKey: app.PrimaryKey(),
+		Name:          utils.GenerateVersion("lock"),
+		Project:       app.Project,
+		EnvName:       req.EnvName,
+		Owner:         userName,
+		Reason:        req.Reason,
+		ExpireTime:    req.ExpireTime,
+		Status:        model.DeployLockStatusLocked,
+	}
+	if err := c.Store.Add(ctx, lock); err != nil {
+		return nil, err
+	}
+	klog.Infof("the application %s is locked in the env %s by %s: %s", app.Name, req.EnvName, userName, req.Reason)
+	return c.convertDeployLockModelToBase(ctx, lock, now), nil
+}
+
+// ReleaseDeployLock release the lock. The lock of another user could only be broken with the force option
+// and the break permission, the user and the reason are recorded.
+func (c *applicationServiceImpl) ReleaseDeployLock(ctx context.Context, app *model.Application, name string, req apisv1.ReleaseDeployLockRequest) (*apisv1.DeployLockBase, error) {
+	lock := &model.DeployLock{AppPrimaryKey: app.PrimaryKey(), Name: name}
+	if err := c.Store.Get(ctx, lock); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrDeployLockNotExist
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !lock.IsActive(now) {
+		return nil, bcode.ErrDeployLockNotActive
+	}
+	userName, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	lock.Status = model.DeployLockStatusReleased
+	if userName != lock.Owner {
+		if !req.Force {
+			return nil, bcode.ErrDeployLockNotOwner
+		}
+		if err := c.RbacService.CheckProjectPerm(ctx, app.Project, "project/deployLock", "break"); err != nil {
+			return nil, err
+		}
+		lock.Status = model.DeployLockStatusBroken
+		klog.Warningf("the deploy lock %s of the application %s in the env %s held by %s is broken by %s: %s", lock.Name, app.Name, lock.EnvName, lock.Owner, userName, req.Reason)
+	}
+	lock.ReleaseUser = userName
+	lock.ReleaseReason = req.Reason
+	lock.ReleaseTime = &now
+	if err := c.Store.Put(ctx, lock); err != nil {
+		return nil, err
+	}
+	return c.convertDeployLockModelToBase(ctx, lock, now), nil
+}
+
+// checkDeployLock return ErrDeployLocked if the application is locked in the env by another user.
+// The userName is empty if the operation is not triggered by a user, such as the webhook.
+func checkDeployLock(ctx context.Context, store datastore.DataStore, appPrimaryKey, envName, userName string) error {
+	lock, err := getActiveDeployLock(ctx, store, appPrimaryKey, envName)
+	if err != nil {
+		return err
+	}
+	if lock != nil && (userName == "" || lock.Owner != userName) {
+		klog.Infof("the application %s is locked in the env %s by %s", appPrimaryKey, envName, lock.Owner)
+		return bcode.ErrDeployLocked
+	}
+	return nil
+}
+
+func getActiveDeployLock(ctx context.Context, store datastore.DataStore, appPrimaryKey, envName string) (*model.DeployLock, error) {
+	entities, err := store.List(ctx, &model.DeployLock{AppPrimaryKey: appPrimaryKey, EnvName: envName, Status: model.DeployLockStatusLocked}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, entity := range entities {
+		if lock := entity.(*model.DeployLock); lock.IsActive(now) {
+			return lock, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *applicationServiceImpl) convertDeployLockModelToBase(ctx context.Context, lock *model.DeployLock, now time.Time) *apisv1.DeployLockBase {
+	base := &apisv1.DeployLockBase{
+		Name:          lock.Name,
+		EnvName:       lock.EnvName,
+		Owner:         &apisv1.NameAlias{Name: lock.Owner},
+		Reason:        lock.Reason,
+		ExpireTime:    lock.ExpireTime,
+		Status:        lock.Status,
+		ReleaseReason: lock.ReleaseReason,
+		ReleaseTime:   lock.ReleaseTime,
+		CreateTime:    lock.CreateTime,
+		UpdateTime:    lock.UpdateTime,
+	}
+	if lock.Status == model.DeployLockStatusLocked && !lock.IsActive(now) {
+		base.Status = model.DeployLockStatusExpired
+	}
+	if user, err := c.UserService.GetUser(ctx, lock.Owner); err == nil {
+		base.Owner.Alias = user.Alias
+	}
+	if lock.ReleaseUser != "" {
+		base.ReleaseUser = &apisv1.NameAlias{Name: lock.ReleaseUser}
+		if user, err := c.UserService.GetUser(ctx, lock.ReleaseUser); err == nil {
+			base.ReleaseUser.Alias = user.Alias
+		}
+	}
+	return base
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the deploy locks of the application", func() {
+	var (
+		lockProject = "test-lock-project"
+		lockApp     = "test-lock-app"
+		lockEnv     = "test-lock-prod"
+		lockUser    = "test-lock-user"
+		adminCtx    context.Context
+		userCtx     = func(name string) context.Context {
+			return context.WithValue(context.TODO(), &apisv1.CtxKeyUser, name)
+		}
+	)
+
+	It("Init services and data", func() {
+		adminCtx = InitTestEnvWithAdmin("app-deploy-lock-test-kubevela")
+		_, err := userService.CreateUser(adminCtx, apisv1.CreateUserRequest{Name: lockUser, Email: lockUser + "@example.com", Password: "password1"})
+		Expect(err).Should(BeNil())
+		InitTestProject(adminCtx, lockProject, "test-lock-target", lockEnv)
+		InitTestApplication(adminCtx, lockProject, lockApp, `{"image":"nginx"}`, lockEnv)
+	})
+
+	It("Test locking and breaking the lock", func() {
+		app, err := appService.GetApplication(adminCtx, lockApp)
+		Expect(err).Should(BeNil())
+		_, err = appService.CreateDeployLock(userCtx(lockUser), app, apisv1.CreateDeployLockRequest{EnvName: "not-exist", Reason: "incident"})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrEnvBindingNotExist))
+		past := time.Now().Add(-time.Minute)
+		_, err = appService.CreateDeployLock(userCtx(lockUser), app, apisv1.CreateDeployLockRequest{EnvName: lockEnv, Reason: "incident", ExpireTime: &past})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrDeployLockExpireTimeInvalid))
+
+		lock, err := appService.CreateDeployLock(userCtx(lockUser), app, apisv1.CreateDeployLockRequest{EnvName: lockEnv, Reason: "incident"})
+		Expect(err).Should(BeNil())
+		Expect(lock.Status).Should(BeEquivalentTo(model.DeployLockStatusLocked))
+		_, err = appService.CreateDeployLock(adminCtx, app, apisv1.CreateDeployLockRequest{EnvName: lockEnv, Reason: "incident"})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrDeployLockExist))
+
+		workflowName := repository.ConvertWorkflowName(lockEnv)
+		_, err = appService.Deploy(adminCtx, app, apisv1.ApplicationDeployRequest{WorkflowName: workflowName, TriggerType: apisv1.TriggerTypeWeb})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrDeployLocked))
+		_, err = appService.Deploy(userCtx(lockUser), app, apisv1.ApplicationDeployRequest{WorkflowName: workflowName, TriggerType: apisv1.TriggerTypeWebhook})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrDeployLocked))
+		err = envBindingService.ApplicationEnvRecycle(adminCtx, app, &model.EnvBinding{Name: lockEnv})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrDeployLocked))
+
+		_, err = appService.ReleaseDeployLock(adminCtx, app, lock.Name, apisv1.ReleaseDeployLockRequest{})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrDeployLockNotOwner))
+		broken, err := appService.ReleaseDeployLock(adminCtx, app, lock.Name, apisv1.ReleaseDeployLockRequest{Force: true, Reason: "the incident is resolved"})
+		Expect(err).Should(BeNil())
+		Expect(broken.Status).Should(BeEquivalentTo(model.DeployLockStatusBroken))
+		Expect(broken.ReleaseUser.Name).Should(BeEquivalentTo(FakeAdminName))
+		_, err = appService.ReleaseDeployLock(adminCtx, app, lock.Name, apisv1.ReleaseDeployLockRequest{Force: true})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrDeployLockNotActive))
+
+		_, err = appService.Deploy(adminCtx, app, apisv1.ApplicationDeployRequest{WorkflowName: workflowName, TriggerType: apisv1.TriggerTypeWeb})
+		Expect(err).Should(BeNil())
+	})
+
+	It("Test the owner and the expired lock", func() {
+		app, err := appService.GetApplication(adminCtx, lockApp)
+		Expect(err).Should(BeNil())
+		expire := time.Now().Add(time.Hour)
+		lock, err := appService.CreateDeployLock(userCtx(lockUser), app, apisv1.CreateDeployLockRequest{EnvName: lockEnv, Reason: "release freeze", ExpireTime: &expire})
+		Expect(err).Should(BeNil())
+		_, err = appService.ReleaseDeployLock(userCtx("test-lock-other"), app, lock.Name, apisv1.ReleaseDeployLockRequest{Force: true})
+		Expect(err).ShouldNot(BeNil())
+
+		expired := &model.DeployLock{AppPrimaryKey: app.PrimaryKey(), Name: lock.Name}
+		Expect(ds.Get(context.TODO(), expired)).Should(BeNil())
+		expire = time.Now().Add(-time.Minute)
+		expired.ExpireTime = &expire
+		Expect(ds.Put(context.TODO(), expired)).Should(BeNil())
+
+		locks, err := appService.ListDeployLocks(adminCtx, app, lockEnv)
+		Expect(err).Should(BeNil())
+		Expect(len(locks.DeployLocks)).Should(BeEquivalentTo(2))
+		Expect(locks.DeployLocks[0].Status).Should(BeEquivalentTo("expired"))
+		Expect(checkDeployLock(context.TODO(), ds, app.PrimaryKey(), lockEnv, "")).Should(BeNil())
+	})
+})
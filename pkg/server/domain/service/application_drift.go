@@ -0,0 +1,232 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	velatypes "github.com/oam-dev/kubevela/apis/types"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// maxDriftDiffLength the diff is truncated to avoid the large application record
+const maxDriftDiffLength = 16 * 1024
+
+// GetApplicationDrift return the last detected drift, or detect it now if refresh is true
+func (c *applicationServiceImpl) GetApplicationDrift(ctx context.Context, app *model.Application, refresh bool) (*apisv1.ApplicationDriftResponse, error) {
+	drift := app.Drift
+	if refresh {
+		var err error
+		if drift, err = c.refreshDrift(ctx, app); err != nil {
+			return nil, err
+		}
+	}
+	return convertDriftToResponse(drift), nil
+}
+
+// UpdateApplicationDrift update the drift setting of the application
+func (c *applicationServiceImpl) UpdateApplicationDrift(ctx context.Context, app *model.Application, req apisv1.UpdateApplicationDriftRequest) (*apisv1.ApplicationDriftResponse, error) {
+	if app.Drift == nil {
+		app.Drift = &model.ApplicationDrift{}
+	}
+	app.Drift.AutoRemediate = req.AutoRemediate
+	if err := c.Store.Put(ctx, app); err != nil {
+		return nil, err
+	}
+	return convertDriftToResponse(app.Drift), nil
+}
+
+// DetectDrifts compare the rendered application with the application CRs of all applications managed by VelaUX,
+// the drifted applications are remediated if the auto remediation is enabled.
+func (c *applicationServiceImpl) DetectDrifts(ctx context.Context) error {
+	entities, err := c.Store.List(ctx, &model.Application{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		app := entity.(*model.Application)
+		if app.IsDeleted() || app.IsSynced() {
+			continue
+		}
+		drift, err := c.refreshDrift(ctx, app)
+		if err != nil {
+			klog.Errorf("failed to detect the drift of the application %s: %s", app.Name, err.Error())
+			continue
+		}
+		if drift.Status == model.DriftStatusDrifted && drift.AutoRemediate {
+			if err := c.remediateDrift(ctx, app, drift); err != nil {
+				klog.Errorf("failed to remediate the drift of the application %s: %s", app.Name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// refreshDrift detect the drift and save it if it is changed
+func (c *applicationServiceImpl) refreshDrift(ctx context.Context, app *model.Application) (*model.ApplicationDrift, error) {
+	drift, err := c.detectDrift(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	// get the latest record to avoid overriding the changes during the detection
+	latest := &model.Application{Name: app.Name}
+	if err := c.Store.Get(ctx, latest); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrApplicationNotExist
+		}
+		return nil, err
+	}
+	if prior := latest.Drift; prior != nil {
+		drift.AutoRemediate = prior.AutoRemediate
+		drift.LastRemediateTime = prior.LastRemediateTime
+		if prior.Status == drift.Status && reflect.DeepEqual(prior.Envs, drift.Envs) {
+			return prior, nil
+		}
+	}
+	drift.DetectTime = time.Now()
+	latest.Drift = drift
+	if err := c.Store.Put(ctx, latest); err != nil {
+		return nil, err
+	}
+	if drift.Status == model.DriftStatusDrifted {
+		klog.Warningf("the application %s is drifted from VelaUX", app.Name)
+	}
+	app.Drift = drift
+	return drift, nil
+}
+
+func (c *applicationServiceImpl) detectDrift(ctx context.Context, app *model.Application) (*model.ApplicationDrift, error) {
+	envBindings, err := repository.ListEnvBindings(ctx, c.Store, repository.EnvListOption{AppPrimaryKey: app.PrimaryKey()})
+	if err != nil {
+		return nil, err
+	}
+	drift := &model.ApplicationDrift{Status: model.DriftStatusSynced}
+	for _, envBinding := range envBindings {
+		revision, err := c.getLatestEnvRevision(ctx, app, envBinding.Name)
+		if err != nil {
+			return nil, err
+		}
+		// skip the env that is not deployed or is deploying
+		if revision == nil || revision.Status == model.RevisionStatusInit || revision.Status == model.RevisionStatusRunning {
+			continue
+		}
+		envDrift := model.EnvDrift{EnvName: envBinding.Name}
+		appCR, err := c.GetApplicationCRInEnv(ctx, app, envBinding.Name)
+		if err != nil {
+			envDrift.Message = err.Error()
+			drift.Envs = append(drift.Envs, envDrift)
+			continue
+		}
+		if appCR == nil || appCR.Labels[velatypes.LabelSourceOfTruth] != velatypes.FromUX {
+			continue
+		}
+		// compare with the deployed revision, the changes that are not deployed are not the drift
+		compareRes, err := c.CompareApp(ctx, app, apisv1.AppCompareReq{
+			CompareRevisionWithRunning: &apisv1.CompareRevisionWithRunningOption{Revision: revision.Version},
+		})
+		if err != nil {
+			envDrift.Message = err.Error()
+			drift.Envs = append(drift.Envs, envDrift)
+			continue
+		}
+		envDrift.Drifted = compareRes.IsDiff
+		if envDrift.Drifted {
+			drift.Status = model.DriftStatusDrifted
+			envDrift.Diff = compareRes.DiffReport
+			if len(envDrift.Diff) > maxDriftDiffLength {
+				envDrift.Diff = envDrift.Diff[:maxDriftDiffLength]
+			}
+		}
+		drift.Envs = append(drift.Envs, envDrift)
+	}
+	return drift, nil
+}
+
+// remediateDrift apply the latest deployed revision to the drifted envs again, the configuration of the application is not changed.
+// The protected envs are skipped because the deploys are held by the change requests.
+func (c *applicationServiceImpl) remediateDrift(ctx context.Context, app *model.Application, drift *model.ApplicationDrift) error {
+	for _, envDrift := range drift.Envs {
+		if !envDrift.Drifted {
+			continue
+		}
+		env, err := repository.GetEnv(ctx, c.Store, envDrift.EnvName)
+		if err != nil {
+			return err
+		}
+		if env.Protection != nil {
+			klog.Infof("skip remediating the application %s in the protected env %s", app.Name, env.Name)
+			continue
+		}
+		revision, err := c.getLatestEnvRevision(ctx, app, envDrift.EnvName)
+		if err != nil || revision == nil {
+			continue
+		}
+		if _, err := c.RollbackWithRevision(ctx, app, revision.Version); err != nil {
+			return err
+		}
+		klog.Infof("the drift of the application %s in the env %s is remediated by the revision %s", app.Name, env.Name, revision.Version)
+	}
+	latest := &model.Application{Name: app.Name}
+	if err := c.Store.Get(ctx, latest); err != nil {
+		return err
+	}
+	if latest.Drift != nil {
+		now := time.Now()
+		latest.Drift.LastRemediateTime = &now
+		return c.Store.Put(ctx, latest)
+	}
+	return nil
+}
+
+func (c *applicationServiceImpl) getLatestEnvRevision(ctx context.Context, app *model.Application, envName string) (*model.ApplicationRevision, error) {
+	list, err := c.Store.List(ctx, &model.ApplicationRevision{AppPrimaryKey: app.PrimaryKey(), EnvName: envName}, &datastore.ListOptions{
+		PageSize: 1, Page: 1, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0].(*model.ApplicationRevision), nil
+}
+
+func convertDriftToResponse(drift *model.ApplicationDrift) *apisv1.ApplicationDriftResponse {
+	res := &apisv1.ApplicationDriftResponse{Envs: []model.EnvDrift{}}
+	if drift == nil {
+		return res
+	}
+	res.Status = drift.Status
+	res.AutoRemediate = drift.AutoRemediate
+	res.LastRemediateTime = drift.LastRemediateTime
+	if !drift.DetectTime.IsZero() {
+		detectTime := drift.DetectTime
+		res.DetectTime = &detectTime
+	}
+	if drift.Envs != nil {
+		res.Envs = drift.Envs
+	}
+	return res
+}
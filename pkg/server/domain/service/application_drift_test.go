@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+var _ = Describe("Test the drift detection of the application", func() {
+	var (
+		driftProject = "test-drift-project"
+		driftApp     = "test-drift-app"
+		driftEnv     = "test-drift-dev"
+		driftCtx     context.Context
+	)
+
+	completeRevisions := func(app *model.Application) {
+		revisions, err := ds.List(context.TODO(), &model.ApplicationRevision{AppPrimaryKey: app.PrimaryKey()}, nil)
+		Expect(err).Should(BeNil())
+		for _, entity := range revisions {
+			revision := entity.(*model.ApplicationRevision)
+			revision.Status = model.RevisionStatusComplete
+			Expect(ds.Put(context.TODO(), revision)).Should(BeNil())
+		}
+	}
+
+	It("Init services and data", func() {
+		driftCtx = InitTestEnvWithAdmin("app-drift-test-kubevela")
+		InitTestProject(driftCtx, driftProject, "test-drift-target", driftEnv)
+		InitTestApplication(driftCtx, driftProject, driftApp, `{"image":"nginx"}`, driftEnv)
+	})
+
+	It("Test detecting and remediating the drift", func() {
+		app, err := appService.GetApplication(driftCtx, driftApp)
+		Expect(err).Should(BeNil())
+		drift, err := appService.GetApplicationDrift(driftCtx, app, true)
+		Expect(err).Should(BeNil())
+		Expect(drift.Status).Should(BeEquivalentTo(model.DriftStatusSynced))
+		Expect(len(drift.Envs)).Should(BeEquivalentTo(0))
+
+		_, err = appService.Deploy(driftCtx, app, apisv1.ApplicationDeployRequest{WorkflowName: repository.ConvertWorkflowName(driftEnv), TriggerType: apisv1.TriggerTypeWeb})
+		Expect(err).Should(BeNil())
+		completeRevisions(app)
+		drift, err = appService.GetApplicationDrift(driftCtx, app, true)
+		Expect(err).Should(BeNil())
+		Expect(drift.Status).Should(BeEquivalentTo(model.DriftStatusSynced))
+		Expect(len(drift.Envs)).Should(BeEquivalentTo(1))
+
+		By("the changes that are not deployed are not the drift")
+		component, err := appService.GetApplicationComponent(driftCtx, app, "web")
+		Expect(err).Should(BeNil())
+		properties := `{"image":"nginx:1.25"}`
+		_, err = appService.UpdateComponent(driftCtx, app, component, apisv1.UpdateApplicationComponentRequest{Properties: &properties})
+		Expect(err).Should(BeNil())
+		drift, err = appService.GetApplicationDrift(driftCtx, app, true)
+		Expect(err).Should(BeNil())
+		Expect(drift.Status).Should(BeEquivalentTo(model.DriftStatusSynced))
+
+		By("edit the application CR out of VelaUX")
+		appCR, err := appService.GetApplicationCRInEnv(driftCtx, app, driftEnv)
+		Expect(err).Should(BeNil())
+		appCR.Spec.Components = append(appCR.Spec.Components, common.ApplicationComponent{
+			Name: "manual", Type: "webservice", Properties: &runtime.RawExtension{Raw: []byte(`{"image":"busybox"}`)}})
+		Expect(k8sClient.Update(context.TODO(), appCR)).Should(BeNil())
+		drift, err = appService.GetApplicationDrift(driftCtx, app, true)
+		Expect(err).Should(BeNil())
+		Expect(drift.Status).Should(BeEquivalentTo(model.DriftStatusDrifted))
+		Expect(drift.Envs[0].Drifted).Should(BeTrue())
+		Expect(drift.Envs[0].Diff).ShouldNot(BeEmpty())
+
+		apps, err := appService.ListApplications(driftCtx, apisv1.ListApplicationOptions{DriftStatus: model.DriftStatusDrifted})
+		Expect(err).Should(BeNil())
+		Expect(len(apps)).Should(BeEquivalentTo(1))
+		Expect(apps[0].Name).Should(BeEquivalentTo(driftApp))
+
+		By("remediate the drift automatically")
+		app, err = appService.GetApplication(driftCtx, driftApp)
+		Expect(err).Should(BeNil())
+		drift, err = appService.UpdateApplicationDrift(driftCtx, app, apisv1.UpdateApplicationDriftRequest{AutoRemediate: true})
+		Expect(err).Should(BeNil())
+		Expect(drift.AutoRemediate).Should(BeTrue())
+		Expect(appService.DetectDrifts(context.TODO())).Should(BeNil())
+		revisions, err := appService.ListRevisions(driftCtx, driftApp, driftEnv, "", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(revisions.Total).Should(BeEquivalentTo(1))
+
+		var remediated v1beta1.Application
+		Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: appCR.Namespace, Name: appCR.Name}, &remediated)).Should(BeNil())
+		Expect(len(remediated.Spec.Components)).Should(BeEquivalentTo(1))
+		component, err = appService.GetApplicationComponent(driftCtx, app, "web")
+		Expect(err).Should(BeNil())
+		Expect((*component.Properties)["image"]).Should(BeEquivalentTo("nginx:1.25"))
+		app, err = appService.GetApplication(driftCtx, driftApp)
+		Expect(err).Should(BeNil())
+		Expect(app.Drift.LastRemediateTime).ShouldNot(BeNil())
+	})
+})
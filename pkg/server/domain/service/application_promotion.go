@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// PromoteApplication copy the component patches of the source env to the target env, and deploy the target env if required.
+func (c *applicationServiceImpl) PromoteApplication(ctx context.Context, app *model.Application, req apisv1.PromoteApplicationRequest) (*apisv1.PromoteApplicationResponse, error) {
+	if req.SourceEnv == req.TargetEnv {
+		return nil, bcode.ErrPromotionSameEnv
+	}
+	source, err := c.EnvBindingService.GetEnvBinding(ctx, app, req.SourceEnv)
+	if err != nil {
+		return nil, err
+	}
+	target, err := c.EnvBindingService.GetEnvBinding(ctx, app, req.TargetEnv)
+	if err != nil {
+		return nil, err
+	}
+	project, err := c.ProjectService.GetProject(ctx, app.Project)
+	if err != nil {
+		return nil, err
+	}
+	if !checkPromotionPath(project.PromotionPath, req.SourceEnv, req.TargetEnv) {
+		return nil, bcode.ErrPromotionNotAllowed
+	}
+
+	components, err := c.Store.List(ctx, &model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var sourceComponents, targetComponents []common.ApplicationComponent
+	for _, entity := range components {
+		component := entity.(*model.ApplicationComponent)
+		if patched, enabled := applyComponentPatch(*component, source.ComponentsPatch); enabled {
+			sourceComponents = append(sourceComponents, convertComponentModel2Spec(&patched))
+		}
+		if patched, enabled := applyComponentPatch(*component, target.ComponentsPatch); enabled {
+			targetComponents = append(targetComponents, convertComponentModel2Spec(&patched))
+		}
+	}
+	res := &apisv1.PromoteApplicationResponse{
+		SourceEnv: req.SourceEnv,
+		TargetEnv: req.TargetEnv,
+	}
+	if res.Components, err = diffComponents(targetComponents, sourceComponents); err != nil {
+		return nil, err
+	}
+	res.IsDiff = len(res.Components) > 0
+	if req.DryRun {
+		return res, nil
+	}
+
+	target.ComponentsPatch = source.ComponentsPatch
+	if err := c.Store.Put(ctx, target); err != nil {
+		return nil, err
+	}
+	if req.Deploy {
+		if res.Deploy, err = c.Deploy(ctx, app, apisv1.ApplicationDeployRequest{
+			WorkflowName: repository.ConvertWorkflowName(req.TargetEnv),
+			Note:         req.Note,
+			TriggerType:  apisv1.TriggerTypeWeb,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// checkPromotionPath check whether the application could be promoted from the source env to the target env.
+// All promotions are allowed if the project does not define the path.
+func checkPromotionPath(path *model.PromotionPath, sourceEnv, targetEnv string) bool {
+	if path == nil || len(path.Envs) == 0 {
+		return true
+	}
+	sourceIndex, targetIndex := -1, -1
+	for i, env := range path.Envs {
+		switch env {
+		case sourceEnv:
+			sourceIndex = i
+		case targetEnv:
+			targetIndex = i
+		}
+	}
+	if sourceIndex < 0 || targetIndex <= sourceIndex {
+		return false
+	}
+	return !path.ForbidSkip || targetIndex == sourceIndex+1
+}
+
+// applyComponentPatch return the component patched by the env, the bool is false if the component is disabled in the env
+func applyComponentPatch(component model.ApplicationComponent, patches []model.ComponentPatch) (model.ApplicationComponent, bool) {
+	for _, patch := range patches {
+		if patch.Name != component.Name {
+			continue
+		}
+		if patch.Disable {
+			return component, false
+		}
+		if patch.Properties != nil {
+			component.Properties = mergeProperties(component.Properties, patch.Properties)
+		}
+		if len(patch.TraitsPatch) == 0 {
+			continue
+		}
+		var traits []model.ApplicationTrait
+		for _, trait := range component.Traits {
+			enabled := true
+			for _, traitPatch := range patch.TraitsPatch {
+				if traitPatch.Type != trait.Type {
+					continue
+				}
+				if traitPatch.Disable {
+					enabled = false
+					break
+				}
+				if traitPatch.Properties != nil {
+					trait.Properties = mergeProperties(trait.Properties, traitPatch.Properties)
+				}
+			}
+			if enabled {
+				traits = append(traits, trait)
+			}
+		}
+		component.Traits = traits
+	}
+	return component, true
+}
+
+// mergeProperties deep merge the patch into the properties without changing the both
+func mergeProperties(properties, patch *model.JSONStruct) *model.JSONStruct {
+	var base map[string]interface{}
+	if properties != nil {
+		base = *properties
+	}
+	merged := model.JSONStruct(mergeValues(base, *patch))
+	return &merged
+}
+
+func mergeValues(base, patch map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(patch))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range patch {
+		baseValue, baseIsMap := merged[k].(map[string]interface{})
+		patchValue, patchIsMap := v.(map[string]interface{})
+		if baseIsMap && patchIsMap {
+			merged[k] = mergeValues(baseValue, patchValue)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// convertComponentModel2Spec convert the component model to the component of the application spec
+func convertComponentModel2Spec(component *model.ApplicationComponent) common.ApplicationComponent {
+	var traits []common.ApplicationTrait
+	for _, trait := range component.Traits {
+		aTrait := common.ApplicationTrait{
+			Type: trait.Type,
+		}
+		if trait.Properties != nil {
+			aTrait.Properties = trait.Properties.RawExtension()
+		}
+		traits = append(traits, aTrait)
+	}
+	bc := common.ApplicationComponent{
+		Name:             component.Name,
+		Type:             component.Type,
+		ExternalRevision: component.ExternalRevision,
+		DependsOn:        component.DependsOn,
+		Inputs:           component.Inputs,
+		Outputs:          component.Outputs,
+		Traits:           traits,
+		Scopes:           component.Scopes,
+		Properties:       component.Properties.RawExtension(),
+	}
+	if component.Properties != nil {
+		bc.Properties = component.Properties.RawExtension()
+	}
+	return bc
+}
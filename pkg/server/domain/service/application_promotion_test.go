@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test promoting the application", func() {
+	var (
+		promoteProject = "test-promote-project"
+		promoteApp     = "test-promote-app"
+		promoteCtx     context.Context
+		envs           = []string{"test-promote-dev", "test-promote-staging", "test-promote-prod"}
+	)
+
+	It("Init services and data", func() {
+		promoteCtx = InitTestEnvWithAdmin("app-promote-test-kubevela")
+		InitTestProject(promoteCtx, promoteProject, "test-promote-target", envs...)
+		InitTestApplication(promoteCtx, promoteProject, promoteApp, `{"image":"nginx","ports":[{"port":80}]}`, envs...)
+	})
+
+	It("Test updating the promotion path", func() {
+		_, err := projectService.UpdatePromotionPath(promoteCtx, promoteProject, apisv1.UpdatePromotionPathRequest{Envs: []string{envs[0], envs[0]}})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrPromotionPathInvalid))
+		_, err = projectService.UpdatePromotionPath(promoteCtx, promoteProject, apisv1.UpdatePromotionPathRequest{Envs: []string{envs[0], "not-exist"}})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrPromotionPathInvalid))
+
+		base, err := projectService.UpdatePromotionPath(promoteCtx, promoteProject, apisv1.UpdatePromotionPathRequest{Envs: envs, ForbidSkip: true})
+		Expect(err).Should(BeNil())
+		Expect(base.PromotionPath.Envs).Should(BeEquivalentTo(envs))
+	})
+
+	It("Test promoting the application", func() {
+		app, err := appService.GetApplication(promoteCtx, promoteApp)
+		Expect(err).Should(BeNil())
+		binding := &model.EnvBinding{AppPrimaryKey: app.PrimaryKey(), Name: envs[0]}
+		Expect(ds.Get(promoteCtx, binding)).Should(BeNil())
+		binding.ComponentsPatch = []model.ComponentPatch{{Name: "web", Properties: &model.JSONStruct{"image": "nginx:1.25"}}}
+		Expect(ds.Put(promoteCtx, binding)).Should(BeNil())
+
+		_, err = appService.PromoteApplication(promoteCtx, app, apisv1.PromoteApplicationRequest{SourceEnv: envs[0], TargetEnv: envs[0]})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrPromotionSameEnv))
+		_, err = appService.PromoteApplication(promoteCtx, app, apisv1.PromoteApplicationRequest{SourceEnv: envs[0], TargetEnv: envs[2]})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrPromotionNotAllowed))
+		_, err = appService.PromoteApplication(promoteCtx, app, apisv1.PromoteApplicationRequest{SourceEnv: envs[1], TargetEnv: envs[0]})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrPromotionNotAllowed))
+
+		res, err := appService.PromoteApplication(promoteCtx, app, apisv1.PromoteApplicationRequest{SourceEnv: envs[0], TargetEnv: envs[1], DryRun: true})
+		Expect(err).Should(BeNil())
+		Expect(res.IsDiff).Should(BeTrue())
+		Expect(res.Components[0].Change).Should(BeEquivalentTo(apisv1.RevisionDiffModified))
+		Expect(res.Components[0].Diff).Should(ContainSubstring("nginx:1.25"))
+		target := &model.EnvBinding{AppPrimaryKey: app.PrimaryKey(), Name: envs[1]}
+		Expect(ds.Get(promoteCtx, target)).Should(BeNil())
+		Expect(len(target.ComponentsPatch)).Should(BeEquivalentTo(0))
+
+		res, err = appService.PromoteApplication(promoteCtx, app, apisv1.PromoteApplicationRequest{SourceEnv: envs[0], TargetEnv: envs[1]})
+		Expect(err).Should(BeNil())
+		Expect(res.IsDiff).Should(BeTrue())
+		Expect(ds.Get(promoteCtx, target)).Should(BeNil())
+		Expect(len(target.ComponentsPatch)).Should(BeEquivalentTo(1))
+
+		res, err = appService.PromoteApplication(promoteCtx, app, apisv1.PromoteApplicationRequest{SourceEnv: envs[0], TargetEnv: envs[1], DryRun: true})
+		Expect(err).Should(BeNil())
+		Expect(res.IsDiff).Should(BeFalse())
+	})
+
+	It("Test applying the component patch", func() {
+		component := model.ApplicationComponent{
+			Name:       "web",
+			Properties: &model.JSONStruct{"image": "nginx", "env": map[string]interface{}{"a": "1", "b": "2"}},
+			Traits: []model.ApplicationTrait{
+				{Type: "scaler", Properties: &model.JSONStruct{"replicas": 1}},
+				{Type: "gateway"},
+			},
+		}
+		patched, enabled := applyComponentPatch(component, []model.ComponentPatch{{
+			Name:       "web",
+			Properties: &model.JSONStruct{"env": map[string]interface{}{"b": "3"}},
+			TraitsPatch: []model.TraitPatch{
+				{Type: "scaler", Properties: &model.JSONStruct{"replicas": 3}},
+				{Type: "gateway", Disable: true},
+			},
+		}})
+		Expect(enabled).Should(BeTrue())
+		Expect((*patched.Properties)["image"]).Should(BeEquivalentTo("nginx"))
+		Expect((*patched.Properties)["env"]).Should(BeEquivalentTo(map[string]interface{}{"a": "1", "b": "3"}))
+		Expect(len(patched.Traits)).Should(BeEquivalentTo(1))
+		Expect((*patched.Traits[0].Properties)["replicas"]).Should(BeEquivalentTo(3))
+		Expect((*component.Traits[0].Properties)["replicas"]).Should(BeEquivalentTo(1))
+
+		_, enabled = applyComponentPatch(component, []model.ComponentPatch{{Name: "web", Disable: true}})
+		Expect(enabled).Should(BeFalse())
+	})
+})
@@ -0,0 +1,250 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+
+	workflowv1alpha1 "github.com/kubevela/workflow/api/v1alpha1"
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	commonutil "github.com/oam-dev/kubevela/pkg/utils/common"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// CompareRevisions compare the application configurations applied by two revisions
+func (c *applicationServiceImpl) CompareRevisions(ctx context.Context, app *model.Application, req apisv1.CompareRevisionsRequest) (*apisv1.CompareRevisionsResponse, error) {
+	baseApp, err := c.getRevisionApp(ctx, app, req.BaseRevision)
+	if err != nil {
+		return nil, err
+	}
+	targetApp, err := c.getRevisionApp(ctx, app, req.TargetRevision)
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.CompareRevisionsResponse{
+		BaseRevision:   req.BaseRevision,
+		TargetRevision: req.TargetRevision,
+	}
+	if res.Components, err = diffComponents(baseApp.Spec.Components, targetApp.Spec.Components); err != nil {
+		return nil, err
+	}
+	if res.Policies, err = diffPolicies(baseApp.Spec.Policies, targetApp.Spec.Policies); err != nil {
+		return nil, err
+	}
+	var baseSteps, targetSteps []interface{}
+	if baseApp.Spec.Workflow != nil {
+		for _, step := range baseApp.Spec.Workflow.Steps {
+			baseSteps = append(baseSteps, step)
+		}
+	}
+	if targetApp.Spec.Workflow != nil {
+		for _, step := range targetApp.Spec.Workflow.Steps {
+			targetSteps = append(targetSteps, step)
+		}
+	}
+	if res.WorkflowSteps, err = diffItems(baseSteps, targetSteps, func(item interface{}) (string, string) {
+		step := item.(workflowv1alpha1.WorkflowStep)
+		return step.Name, step.Type
+	}); err != nil {
+		return nil, err
+	}
+	res.IsDiff = len(res.Components) > 0 || len(res.Policies) > 0 || len(res.WorkflowSteps) > 0
+	if res.UnifiedDiff, err = unifiedDiff(req.BaseRevision, req.TargetRevision, baseApp.Spec, targetApp.Spec); err != nil {
+		return nil, err
+	}
+	if !req.WithResources {
+		return res, nil
+	}
+	args := commonutil.Args{
+		Schema: commonutil.Scheme,
+	}
+	_ = args.SetConfig(c.KubeConfig)
+	args.SetClient(c.KubeClient)
+	baseResources, err := dryRunApplication(ctx, args, baseApp)
+	if err != nil {
+		res.ResourcesMessage = fmt.Sprintf("failed to dry run the revision %s: %s", req.BaseRevision, err.Error())
+		return res, nil
+	}
+	targetResources, err := dryRunApplication(ctx, args, targetApp)
+	if err != nil {
+		res.ResourcesMessage = fmt.Sprintf("failed to dry run the revision %s: %s", req.TargetRevision, err.Error())
+		return res, nil
+	}
+	res.ResourcesDiff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(baseResources.String()),
+		B:        difflib.SplitLines(targetResources.String()),
+		FromFile: req.BaseRevision,
+		ToFile:   req.TargetRevision,
+		Context:  3,
+	})
+	return res, err
+}
+
+func (c *applicationServiceImpl) getRevisionApp(ctx context.Context, app *model.Application, version string) (*v1beta1.Application, error) {
+	revision := &model.ApplicationRevision{AppPrimaryKey: app.PrimaryKey(), Version: version}
+	if err := c.Store.Get(ctx, revision); err != nil {
+		return nil, bcode.ErrApplicationRevisionNotExist
+	}
+	if revision.ApplyAppConfig == "" {
+		return nil, bcode.ErrApplicationRevisionNotExist
+	}
+	revisionApp := &v1beta1.Application{}
+	if err := yaml.Unmarshal([]byte(revision.ApplyAppConfig), revisionApp); err != nil {
+		return nil, err
+	}
+	return revisionApp, nil
+}
+
+func diffComponents(base, target []common.ApplicationComponent) ([]apisv1.RevisionDiffEntry, error) {
+	var baseItems, targetItems []interface{}
+	for _, component := range base {
+		baseItems = append(baseItems, component)
+	}
+	for _, component := range target {
+		targetItems = append(targetItems, component)
+	}
+	componentKey := func(item interface{}) (string, string) {
+		component := item.(common.ApplicationComponent)
+		return component.Name, component.Type
+	}
+	// the traits are compared separately
+	withoutTraits := func(item interface{}) interface{} {
+		component := item.(common.ApplicationComponent)
+		component.Traits = nil
+		return component
+	}
+	entries, err := diffItemsBy(baseItems, targetItems, componentKey, withoutTraits)
+	if err != nil {
+		return nil, err
+	}
+	baseComponents := map[string]common.ApplicationComponent{}
+	for _, component := range base {
+		baseComponents[component.Name] = component
+	}
+	traitKey := func(item interface{}) (string, string) {
+		trait := item.(common.ApplicationTrait)
+		return trait.Type, trait.Type
+	}
+	changed := map[string]int{}
+	for i, entry := range entries {
+		changed[entry.Name] = i
+	}
+	for _, component := range target {
+		baseComponent, exist := baseComponents[component.Name]
+		if !exist {
+			continue
+		}
+		var baseTraits, targetTraits []interface{}
+		for _, trait := range baseComponent.Traits {
+			baseTraits = append(baseTraits, trait)
+		}
+		for _, trait := range component.Traits {
+			targetTraits = append(targetTraits, trait)
+		}
+		traits, err := diffItems(baseTraits, targetTraits, traitKey)
+		if err != nil {
+			return nil, err
+		}
+		if len(traits) == 0 {
+			continue
+		}
+		if i, exist := changed[component.Name]; exist {
+			entries[i].Traits = traits
+			continue
+		}
+		entries = append(entries, apisv1.RevisionDiffEntry{Name: component.Name, Type: component.Type, Change: apisv1.RevisionDiffModified, Traits: traits})
+	}
+	return entries, nil
+}
+
+func diffPolicies(base, target []v1beta1.AppPolicy) ([]apisv1.RevisionDiffEntry, error) {
+	var baseItems, targetItems []interface{}
+	for _, policy := range base {
+		baseItems = append(baseItems, policy)
+	}
+	for _, policy := range target {
+		targetItems = append(targetItems, policy)
+	}
+	return diffItems(baseItems, targetItems, func(item interface{}) (string, string) {
+		policy := item.(v1beta1.AppPolicy)
+		return policy.Name, policy.Type
+	})
+}
+
+func diffItems(base, target []interface{}, key func(item interface{}) (string, string)) ([]apisv1.RevisionDiffEntry, error) {
+	return diffItemsBy(base, target, key, func(item interface{}) interface{} { return item })
+}
+
+// diffItemsBy compare the items by the names, the content function returns the part of the item to be compared
+func diffItemsBy(base, target []interface{}, key func(item interface{}) (string, string), content func(item interface{}) interface{}) ([]apisv1.RevisionDiffEntry, error) {
+	entries := []apisv1.RevisionDiffEntry{}
+	baseItems := map[string]interface{}{}
+	for _, item := range base {
+		name, _ := key(item)
+		baseItems[name] = item
+	}
+	targetItems := map[string]bool{}
+	for _, item := range target {
+		name, itemType := key(item)
+		targetItems[name] = true
+		baseItem, exist := baseItems[name]
+		if !exist {
+			entries = append(entries, apisv1.RevisionDiffEntry{Name: name, Type: itemType, Change: apisv1.RevisionDiffAdded})
+			continue
+		}
+		diff, err := unifiedDiff("base", "target", content(baseItem), content(item))
+		if err != nil {
+			return nil, err
+		}
+		if diff != "" {
+			entries = append(entries, apisv1.RevisionDiffEntry{Name: name, Type: itemType, Change: apisv1.RevisionDiffModified, Diff: diff})
+		}
+	}
+	for _, item := range base {
+		if name, itemType := key(item); !targetItems[name] {
+			entries = append(entries, apisv1.RevisionDiffEntry{Name: name, Type: itemType, Change: apisv1.RevisionDiffRemoved})
+		}
+	}
+	return entries, nil
+}
+
+// unifiedDiff return the unified diff of the YAML documents of the objects, it is empty if there is no difference
+func unifiedDiff(fromName, toName string, from, to interface{}) (string, error) {
+	fromContent, err := yaml.Marshal(from)
+	if err != nil {
+		return "", err
+	}
+	toContent, err := yaml.Marshal(to)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(fromContent)),
+		B:        difflib.SplitLines(string(toContent)),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+}
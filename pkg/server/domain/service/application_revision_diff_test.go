@@ -0,0 +1,129 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test comparing the application revisions", func() {
+	var (
+		diffApp = &model.Application{Name: "test-revision-diff"}
+		v1      = `apiVersion: core.oam.dev/v1beta1
+kind: Application
+metadata:
+  name: test-revision-diff
+spec:
+  components:
+  - name: web
+    type: webservice
+    properties:
+      image: nginx:1.20
+    traits:
+    - type: scaler
+      properties:
+        replicas: 1
+  - name: worker
+    type: worker
+    properties:
+      image: busybox
+  policies:
+  - name: topology
+    type: topology
+    properties:
+      clusters: ["local"]
+  workflow:
+    steps:
+    - name: deploy
+      type: deploy
+      properties:
+        policies: ["topology"]
+`
+		v2 = `apiVersion: core.oam.dev/v1beta1
+kind: Application
+metadata:
+  name: test-revision-diff
+spec:
+  components:
+  - name: web
+    type: webservice
+    properties:
+      image: nginx:1.20
+    traits:
+    - type: scaler
+      properties:
+        replicas: 3
+  - name: cache
+    type: webservice
+    properties:
+      image: redis
+  policies:
+  - name: topology
+    type: topology
+    properties:
+      clusters: ["local"]
+  workflow:
+    steps:
+    - name: deploy
+      type: deploy
+      properties:
+        policies: ["topology"]
+    - name: notify
+      type: notification
+`
+	)
+
+	It("Init the revisions", func() {
+		InitTestEnv("revision-diff-test-kubevela")
+		Expect(ds.Add(context.TODO(), &model.ApplicationRevision{AppPrimaryKey: diffApp.Name, Version: "v1", ApplyAppConfig: v1})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.ApplicationRevision{AppPrimaryKey: diffApp.Name, Version: "v2", ApplyAppConfig: v2})).Should(BeNil())
+	})
+
+	It("Test CompareRevisions function", func() {
+		_, err := appService.CompareRevisions(context.TODO(), diffApp, apisv1.CompareRevisionsRequest{BaseRevision: "v1", TargetRevision: "v3"})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationRevisionNotExist))
+
+		res, err := appService.CompareRevisions(context.TODO(), diffApp, apisv1.CompareRevisionsRequest{BaseRevision: "v1", TargetRevision: "v2"})
+		Expect(err).Should(BeNil())
+		Expect(res.IsDiff).Should(BeTrue())
+		Expect(res.UnifiedDiff).ShouldNot(BeEmpty())
+		Expect(len(res.Policies)).Should(BeEquivalentTo(0))
+
+		changes := map[string]apisv1.RevisionDiffEntry{}
+		for _, entry := range res.Components {
+			changes[entry.Name] = entry
+		}
+		Expect(len(changes)).Should(BeEquivalentTo(3))
+		Expect(changes["cache"].Change).Should(BeEquivalentTo(apisv1.RevisionDiffAdded))
+		Expect(changes["worker"].Change).Should(BeEquivalentTo(apisv1.RevisionDiffRemoved))
+		Expect(changes["web"].Change).Should(BeEquivalentTo(apisv1.RevisionDiffModified))
+		Expect(changes["web"].Diff).Should(BeEmpty())
+		Expect(len(changes["web"].Traits)).Should(BeEquivalentTo(1))
+		Expect(changes["web"].Traits[0].Diff).Should(ContainSubstring("replicas: 3"))
+
+		Expect(len(res.WorkflowSteps)).Should(BeEquivalentTo(1))
+		Expect(res.WorkflowSteps[0].Name).Should(BeEquivalentTo("notify"))
+		Expect(res.WorkflowSteps[0].Change).Should(BeEquivalentTo(apisv1.RevisionDiffAdded))
+	})
+})
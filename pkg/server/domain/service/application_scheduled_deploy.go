@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// scheduleDeploy save the deploy request, it is executed by the worker at the schedule time
+func (c *applicationServiceImpl) scheduleDeploy(ctx context.Context, app *model.Application, workflow *model.Workflow, req apisv1.ApplicationDeployRequest, userName string) (*apisv1.ApplicationDeployResponse, error) {
+	scheduled := &model.ScheduledDeploy{
+		AppPrimaryKey:  app.PrimaryKey(),
+		Name:           utils.GenerateVersion("sd"),
+		EnvName:        workflow.EnvName,
+		WorkflowName:   workflow.Name,
+		ScheduleTime:   *req.ScheduleTime,
+		Creator:        userName,
+		Note:           req.Note,
+		TriggerType:    req.TriggerType,
+		Force:          req.Force,
+		OverrideFreeze: req.OverrideFreeze,
+		CodeInfo:       req.CodeInfo,
+		ImageInfo:      req.ImageInfo,
+		Status:         model.ScheduledDeployStatusScheduled,
+	}
+	if err := c.Store.Add(ctx, scheduled); err != nil {
+		return nil, err
+	}
+	return &apisv1.ApplicationDeployResponse{ApplicationRevisionBase: convertScheduledDeployToRevisionBase(scheduled)}, nil
+}
+
+// CancelScheduledDeploy cancel the scheduled deploy before the schedule time
+func (c *applicationServiceImpl) CancelScheduledDeploy(ctx context.Context, app *model.Application, name string) error {
+	scheduled := &model.ScheduledDeploy{AppPrimaryKey: app.PrimaryKey(), Name: name}
+	if err := c.Store.Get(ctx, scheduled); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrScheduledDeployNotExist
+		}
+		return err
+	}
+	if scheduled.Status != model.ScheduledDeployStatusScheduled {
+		return bcode.ErrScheduledDeployNotPending
+	}
+	scheduled.Status = model.ScheduledDeployStatusCanceled
+	return c.Store.Put(ctx, scheduled)
+}
+
+// ExecuteScheduledDeploys deploy the applications that reach the schedule time
+func (c *applicationServiceImpl) ExecuteScheduledDeploys(ctx context.Context) error {
+	entities, err := c.Store.List(ctx, &model.ScheduledDeploy{Status: model.ScheduledDeployStatusScheduled}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}},
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entity := range entities {
+		scheduled := entity.(*model.ScheduledDeploy)
+		if scheduled.ScheduleTime.After(now) {
+			continue
+		}
+		scheduled.Status = model.ScheduledDeployStatusExecuted
+		// deploy as the user who schedules it
+		deployCtx := context.WithValue(ctx, &apisv1.CtxKeyUser, scheduled.Creator)
+		res, err := c.executeScheduledDeploy(deployCtx, scheduled)
+		if err != nil {
+			klog.Errorf("failed to execute the scheduled deploy %s: %s", scheduled.PrimaryKey(), err.Error())
+			scheduled.Status = model.ScheduledDeployStatusFailed
+			scheduled.Reason = err.Error()
+		} else if res.ChangeRequest != nil {
+			// the deploy is not done until the change request is approved
+			scheduled.Status = model.ScheduledDeployStatusPendingApproval
+			scheduled.ChangeRequest = res.ChangeRequest.Name
+			scheduled.Reason = fmt.Sprintf("waiting for the approval of the change request %s", res.ChangeRequest.Name)
+		} else {
+			scheduled.Revision = res.Version
+		}
+		if err := c.Store.Put(ctx, scheduled); err != nil {
+			klog.Errorf("failed to update the scheduled deploy %s: %s", scheduled.PrimaryKey(), err.Error())
+		}
+	}
+	return nil
+}
+
+func (c *applicationServiceImpl) executeScheduledDeploy(ctx context.Context, scheduled *model.ScheduledDeploy) (*apisv1.ApplicationDeployResponse, error) {
+	app := &model.Application{Name: scheduled.AppPrimaryKey}
+	if err := c.Store.Get(ctx, app); err != nil {
+		return nil, err
+	}
+	if app.IsDeleted() {
+		return nil, bcode.ErrApplicationNotExist
+	}
+	return c.Deploy(ctx, app, apisv1.ApplicationDeployRequest{
+		WorkflowName:   scheduled.WorkflowName,
+		Note:           scheduled.Note,
+		TriggerType:    scheduled.TriggerType,
+		Force:          scheduled.Force,
+		CodeInfo:       scheduled.CodeInfo,
+		ImageInfo:      scheduled.ImageInfo,
+		OverrideFreeze: scheduled.OverrideFreeze,
+	})
+}
+
+func (c *applicationServiceImpl) listPendingScheduledDeploys(ctx context.Context, appName, envName string) ([]apisv1.ApplicationRevisionBase, error) {
+	entities, err := c.Store.List(ctx, &model.ScheduledDeploy{AppPrimaryKey: appName, EnvName: envName, Status: model.ScheduledDeployStatusScheduled}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	revisions := []apisv1.ApplicationRevisionBase{}
+	for _, entity := range entities {
+		revision := convertScheduledDeployToRevisionBase(entity.(*model.ScheduledDeploy))
+		if user, err := c.UserService.GetUser(ctx, revision.DeployUser.Name); err == nil {
+			revision.DeployUser.Alias = user.Alias
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+func convertScheduledDeployToRevisionBase(scheduled *model.ScheduledDeploy) apisv1.ApplicationRevisionBase {
+	scheduleTime := scheduled.ScheduleTime
+	return apisv1.ApplicationRevisionBase{
+		CreateTime:   scheduled.CreateTime,
+		Version:      scheduled.Name,
+		Status:       scheduled.Status,
+		Reason:       scheduled.Reason,
+		DeployUser:   &apisv1.NameAlias{Name: scheduled.Creator},
+		Note:         scheduled.Note,
+		EnvName:      scheduled.EnvName,
+		TriggerType:  scheduled.TriggerType,
+		WorkflowName: scheduled.WorkflowName,
+		CodeInfo:     scheduled.CodeInfo,
+		ImageInfo:    scheduled.ImageInfo,
+		ScheduleTime: &scheduleTime,
+	}
+}
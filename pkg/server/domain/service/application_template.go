@@ -0,0 +1,434 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// PublishApplicationTemplate capture the components, traits, policies, workflows and env bindings of the application as a template version
+func (c *applicationServiceImpl) PublishApplicationTemplate(ctx context.Context, app *model.Application, req apisv1.CreateApplicationTemplateRequest, platform bool) (*apisv1.ApplicationTemplateBase, error) {
+	template := &model.ApplicationTemplate{
+		Name:        req.TemplateName,
+		Version:     req.Version,
+		Description: req.Description,
+		SourceApp:   app.Name,
+	}
+	if !platform {
+		template.Project = app.Project
+	}
+	template.CreateUser, _ = ctx.Value(&apisv1.CtxKeyUser).(string)
+
+	versions, err := c.listTemplateVersions(ctx, req.TemplateName)
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		if version.Project != template.Project {
+			return nil, bcode.ErrApplicationTemplateScopeConflict
+		}
+	}
+
+	components, err := c.Store.List(ctx, &model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range components {
+		component := entity.(*model.ApplicationComponent)
+		component.AppPrimaryKey = ""
+		template.Components = append(template.Components, *component)
+	}
+	policies, err := repository.ListApplicationPolicies(ctx, c.Store, app)
+	if err != nil {
+		return nil, err
+	}
+	for _, policy := range policies {
+		policy.AppPrimaryKey = ""
+		template.Policies = append(template.Policies, *policy)
+	}
+	workflows, err := c.Store.List(ctx, &model.Workflow{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range workflows {
+		workflow := entity.(*model.Workflow)
+		workflow.AppPrimaryKey = ""
+		template.Workflows = append(template.Workflows, *workflow)
+	}
+	envBindings, err := c.Store.List(ctx, &model.EnvBinding{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range envBindings {
+		envBinding := entity.(*model.EnvBinding)
+		envBinding.AppPrimaryKey = ""
+		envBinding.AppDeployName = ""
+		template.EnvBindings = append(template.EnvBindings, *envBinding)
+	}
+
+	if template.Parameters, err = checkTemplateParameters(template, req.Parameters); err != nil {
+		return nil, err
+	}
+
+	if err := c.Store.Add(ctx, template); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrApplicationTemplateVersionExist
+		}
+		return nil, err
+	}
+	return c.convertTemplateBase(ctx, append(versions, template)), nil
+}
+
+// ListApplicationTemplates list the templates of the project and the templates shared in the platform
+func (c *applicationServiceImpl) ListApplicationTemplates(ctx context.Context, projectName string) (*apisv1.ListApplicationTemplateResponse, error) {
+	entities, err := c.Store.List(ctx, &model.ApplicationTemplate{}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	var templates = map[string][]*model.ApplicationTemplate{}
+	for _, entity := range entities {
+		template := entity.(*model.ApplicationTemplate)
+		if template.Project != "" && template.Project != projectName {
+			continue
+		}
+		if _, exist := templates[template.Name]; !exist {
+			names = append(names, template.Name)
+		}
+		templates[template.Name] = append(templates[template.Name], template)
+	}
+	sort.Strings(names)
+	res := &apisv1.ListApplicationTemplateResponse{Templates: []*apisv1.ApplicationTemplateBase{}}
+	for _, name := range names {
+		res.Templates = append(res.Templates, c.convertTemplateBase(ctx, templates[name]))
+	}
+	return res, nil
+}
+
+// PreviewApplicationTemplate render the template with the parameter values
+func (c *applicationServiceImpl) PreviewApplicationTemplate(ctx context.Context, projectName, templateName string, req apisv1.PreviewApplicationTemplateRequest) (*apisv1.PreviewApplicationTemplateResponse, error) {
+	template, err := c.renderApplicationTemplate(ctx, projectName, templateName, req.Version, req.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	return &apisv1.PreviewApplicationTemplateResponse{
+		TemplateName: template.Name,
+		Version:      template.Version,
+		Components:   template.Components,
+		Policies:     template.Policies,
+		Workflows:    template.Workflows,
+		EnvBindings:  template.EnvBindings,
+	}, nil
+}
+
+func (c *applicationServiceImpl) listTemplateVersions(ctx context.Context, templateName string) ([]*model.ApplicationTemplate, error) {
+	entities, err := c.Store.List(ctx, &model.ApplicationTemplate{Name: templateName}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var versions []*model.ApplicationTemplate
+	for _, entity := range entities {
+		versions = append(versions, entity.(*model.ApplicationTemplate))
+	}
+	return versions, nil
+}
+
+// getApplicationTemplate get the template version visible to the project, the latest version is returned if the version is empty
+func (c *applicationServiceImpl) getApplicationTemplate(ctx context.Context, projectName, templateName, version string) (*model.ApplicationTemplate, error) {
+	versions, err := c.listTemplateVersions(ctx, templateName)
+	if err != nil {
+		return nil, err
+	}
+	var template *model.ApplicationTemplate
+	for _, v := range versions {
+		if v.Project != "" && v.Project != projectName {
+			continue
+		}
+		if version == "" && (template == nil || v.CreateTime.After(template.CreateTime)) {
+			template = v
+		}
+		if version != "" && v.Version == version {
+			template = v
+		}
+	}
+	if template == nil {
+		return nil, bcode.ErrApplicationTemplateNotExist
+	}
+	return template, nil
+}
+
+// renderApplicationTemplate set the parameter values to the properties of the components and traits
+func (c *applicationServiceImpl) renderApplicationTemplate(ctx context.Context, projectName, templateName, version string, values map[string]interface{}) (*model.ApplicationTemplate, error) {
+	template, err := c.getApplicationTemplate(ctx, projectName, templateName, version)
+	if err != nil {
+		return nil, err
+	}
+	parameters := map[string]model.ApplicationTemplateParameter{}
+	for _, parameter := range template.Parameters {
+		parameters[parameter.Name] = parameter
+	}
+	for name := range values {
+		if _, exist := parameters[name]; !exist {
+			klog.Warningf("the parameter %s is not defined in the template %s", name, template.Name)
+			return nil, bcode.ErrApplicationTemplateParameterInvalid
+		}
+	}
+	for _, parameter := range template.Parameters {
+		value, exist := values[parameter.Name]
+		if !exist {
+			if parameter.Required && parameter.Default == nil {
+				klog.Warningf("the required parameter %s of the template %s is not set", parameter.Name, template.Name)
+				return nil, bcode.ErrApplicationTemplateParameterInvalid
+			}
+			value = parameter.Default
+		}
+		if value == nil {
+			continue
+		}
+		for i, component := range template.Components {
+			if component.Name != parameter.Component {
+				continue
+			}
+			if parameter.Trait == "" {
+				properties := copyTemplateProperties(component.Properties)
+				if err := setPropertyByPath(properties, parameter.Path, value); err != nil {
+					klog.Warningf("failed to set the parameter %s of the template %s: %s", parameter.Name, template.Name, err.Error())
+					return nil, bcode.ErrApplicationTemplateParameterInvalid
+				}
+				template.Components[i].Properties = &properties
+				continue
+			}
+			for j, trait := range component.Traits {
+				if trait.Type != parameter.Trait {
+					continue
+				}
+				properties := copyTemplateProperties(trait.Properties)
+				if err := setPropertyByPath(properties, parameter.Path, value); err != nil {
+					klog.Warningf("failed to set the parameter %s of the template %s: %s", parameter.Name, template.Name, err.Error())
+					return nil, bcode.ErrApplicationTemplateParameterInvalid
+				}
+				template.Components[i].Traits[j].Properties = &properties
+			}
+		}
+	}
+	return template, nil
+}
+
+// templateEnvBindings return the env bindings of the template that the envs belong to the project
+func (c *applicationServiceImpl) templateEnvBindings(ctx context.Context, projectName string, template *model.ApplicationTemplate) []*apisv1.EnvBinding {
+	var envBindings []*apisv1.EnvBinding
+	for _, envBinding := range template.EnvBindings {
+		env, err := repository.GetEnv(ctx, c.Store, envBinding.Name)
+		if err != nil || env.Project != projectName {
+			klog.Infof("skip the env %s of the template %s, it does not belong to the project %s", envBinding.Name, template.Name, projectName)
+			continue
+		}
+		envBindings = append(envBindings, &apisv1.EnvBinding{Name: envBinding.Name})
+	}
+	return envBindings
+}
+
+// createTemplateComponents add the components and the policies of the template, the added records are returned even if it fails
+func (c *applicationServiceImpl) createTemplateComponents(ctx context.Context, app *model.Application, template *model.ApplicationTemplate) ([]datastore.Entity, error) {
+	var records []datastore.Entity
+	for _, component := range template.Components {
+		component := component
+		component.AppPrimaryKey = app.PrimaryKey()
+		if err := c.Store.Add(ctx, &component); err != nil {
+			if errors.Is(err, datastore.ErrRecordExist) {
+				return records, bcode.ErrApplicationComponentExist
+			}
+			return records, err
+		}
+		records = append(records, &component)
+	}
+	for _, policy := range template.Policies {
+		if policy.EnvName != "" {
+			continue
+		}
+		policy := policy
+		policy.AppPrimaryKey = app.PrimaryKey()
+		if err := c.Store.Add(ctx, &policy); err != nil {
+			if errors.Is(err, datastore.ErrRecordExist) {
+				return records, bcode.ErrApplicationPolicyExist
+			}
+			return records, err
+		}
+		records = append(records, &policy)
+	}
+	return records, nil
+}
+
+// saveTemplateEnvResources override the env policies, workflows and component patches created by the env bindings,
+// the added records are returned even if it fails
+func (c *applicationServiceImpl) saveTemplateEnvResources(ctx context.Context, app *model.Application, template *model.ApplicationTemplate, envBindings []*apisv1.EnvBinding) ([]datastore.Entity, error) {
+	envs := map[string]bool{}
+	for _, envBinding := range envBindings {
+		envs[envBinding.Name] = true
+	}
+	var records []datastore.Entity
+	save := func(entity datastore.Entity) error {
+		exist, err := c.Store.IsExist(ctx, entity)
+		if err != nil {
+			return err
+		}
+		if exist {
+			return c.Store.Put(ctx, entity)
+		}
+		if err := c.Store.Add(ctx, entity); err != nil {
+			return err
+		}
+		records = append(records, entity)
+		return nil
+	}
+	for _, policy := range template.Policies {
+		if policy.EnvName == "" || !envs[policy.EnvName] {
+			continue
+		}
+		policy := policy
+		policy.AppPrimaryKey = app.PrimaryKey()
+		if err := save(&policy); err != nil {
+			return records, err
+		}
+	}
+	for _, workflow := range template.Workflows {
+		if workflow.EnvName != "" && !envs[workflow.EnvName] {
+			continue
+		}
+		workflow := workflow
+		workflow.AppPrimaryKey = app.PrimaryKey()
+		if err := save(&workflow); err != nil {
+			return records, err
+		}
+	}
+	for _, envBinding := range template.EnvBindings {
+		if !envs[envBinding.Name] || len(envBinding.ComponentsPatch) == 0 {
+			continue
+		}
+		binding := &model.EnvBinding{AppPrimaryKey: app.PrimaryKey(), Name: envBinding.Name}
+		if err := c.Store.Get(ctx, binding); err != nil {
+			return records, err
+		}
+		binding.ComponentsPatch = envBinding.ComponentsPatch
+		if err := c.Store.Put(ctx, binding); err != nil {
+			return records, err
+		}
+	}
+	return records, nil
+}
+
+// deleteTemplateRecords delete the records added from the template when the application fails to be created
+func (c *applicationServiceImpl) deleteTemplateRecords(ctx context.Context, records []datastore.Entity) {
+	for i := len(records) - 1; i >= 0; i-- {
+		if err := c.Store.Delete(ctx, records[i]); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("failed to delete the %s record %s of the template: %s", records[i].TableName(), records[i].PrimaryKey(), err.Error())
+		}
+	}
+}
+
+func (c *applicationServiceImpl) convertTemplateBase(ctx context.Context, versions []*model.ApplicationTemplate) *apisv1.ApplicationTemplateBase {
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].CreateTime.After(versions[j].CreateTime)
+	})
+	base := &apisv1.ApplicationTemplateBase{TemplateName: versions[0].Name}
+	if projectName := versions[0].Project; projectName != "" {
+		base.Project = &apisv1.NameAlias{Name: projectName}
+		if project, err := c.ProjectService.GetProject(ctx, projectName); err == nil {
+			base.Project.Alias = project.Alias
+		}
+	}
+	for _, version := range versions {
+		base.Versions = append(base.Versions, &apisv1.ApplicationTemplateVersion{
+			Version:     version.Version,
+			Description: version.Description,
+			CreateUser:  version.CreateUser,
+			SourceApp:   version.SourceApp,
+			Parameters:  version.Parameters,
+			CreateTime:  version.CreateTime,
+			UpdateTime:  version.UpdateTime,
+		})
+		if base.CreateTime.IsZero() || version.CreateTime.Before(base.CreateTime) {
+			base.CreateTime = version.CreateTime
+		}
+		if version.UpdateTime.After(base.UpdateTime) {
+			base.UpdateTime = version.UpdateTime
+		}
+	}
+	return base
+}
+
+// checkTemplateParameters check the parameters refer to the existing components and traits, the default values are taken from the application
+func checkTemplateParameters(template *model.ApplicationTemplate, parameters []model.ApplicationTemplateParameter) ([]model.ApplicationTemplateParameter, error) {
+	names := map[string]bool{}
+	for i, parameter := range parameters {
+		if parameter.Name == "" || parameter.Path == "" || names[parameter.Name] {
+			return nil, bcode.ErrApplicationTemplateParameterInvalid
+		}
+		if _, err := parsePropertyPath(parameter.Path); err != nil {
+			return nil, bcode.ErrApplicationTemplateParameterInvalid
+		}
+		names[parameter.Name] = true
+		var properties *model.JSONStruct
+		var found bool
+		for _, component := range template.Components {
+			if component.Name != parameter.Component {
+				continue
+			}
+			if parameter.Trait == "" {
+				properties, found = component.Properties, true
+				break
+			}
+			for _, trait := range component.Traits {
+				if trait.Type == parameter.Trait {
+					properties, found = trait.Properties, true
+				}
+			}
+		}
+		if !found {
+			klog.Warningf("the component or trait of the parameter %s is not exist", parameter.Name)
+			return nil, bcode.ErrApplicationTemplateParameterInvalid
+		}
+		if parameter.Default == nil && properties != nil {
+			parameters[i].Default = getPropertyByPath(*properties, parameter.Path)
+		}
+	}
+	return parameters, nil
+}
+
+// copyTemplateProperties deep copy the properties because the template may be shared by the components
+func copyTemplateProperties(properties *model.JSONStruct) model.JSONStruct {
+	result := model.JSONStruct{}
+	if properties != nil {
+		if bs, err := json.Marshal(properties); err == nil {
+			_ = json.Unmarshal(bs, &result)
+		}
+	}
+	return result
+}
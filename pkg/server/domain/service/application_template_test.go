@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test application template functions", func() {
+	var (
+		templateProject      = "test-template-project"
+		templateOtherProject = "test-template-other"
+		templateApp          = "test-template-app"
+		templateCtx          context.Context
+	)
+
+	It("Init services and data", func() {
+		templateCtx = InitTestEnvWithAdmin("app-template-test-kubevela")
+		InitTestProject(templateCtx, templateProject, "")
+		InitTestProject(templateCtx, templateOtherProject, "")
+		InitTestApplication(templateCtx, templateProject, templateApp, `{"image":"nginx","ports":[{"port":80}]}`)
+	})
+
+	It("Test publishing the application template", func() {
+		app, err := appService.GetApplication(templateCtx, templateApp)
+		Expect(err).Should(BeNil())
+		_, err = appService.PublishApplicationTemplate(templateCtx, app, apisv1.CreateApplicationTemplateRequest{
+			TemplateName: "web-template",
+			Version:      "v1",
+			Parameters:   []model.ApplicationTemplateParameter{{Name: "image", Component: "not-exist", Path: "image"}},
+		}, false)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationTemplateParameterInvalid))
+
+		base, err := appService.PublishApplicationTemplate(templateCtx, app, apisv1.CreateApplicationTemplateRequest{
+			TemplateName: "web-template",
+			Version:      "v1",
+			Parameters: []model.ApplicationTemplateParameter{
+				{Name: "image", Component: "web", Path: "image", Required: true},
+				{Name: "cpu", Component: "web", Path: "resources.cpu"},
+			},
+		}, false)
+		Expect(err).Should(BeNil())
+		Expect(base.Project.Name).Should(BeEquivalentTo(templateProject))
+		Expect(len(base.Versions)).Should(BeEquivalentTo(1))
+		Expect(base.Versions[0].Parameters[0].Default).Should(BeEquivalentTo("nginx"))
+		Expect(base.Versions[0].SourceApp).Should(BeEquivalentTo(templateApp))
+
+		_, err = appService.PublishApplicationTemplate(templateCtx, app, apisv1.CreateApplicationTemplateRequest{TemplateName: "web-template", Version: "v1"}, false)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationTemplateVersionExist))
+		_, err = appService.PublishApplicationTemplate(templateCtx, app, apisv1.CreateApplicationTemplateRequest{TemplateName: "web-template", Version: "v2"}, true)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationTemplateScopeConflict))
+	})
+
+	It("Test listing the application templates", func() {
+		templates, err := appService.ListApplicationTemplates(templateCtx, templateProject)
+		Expect(err).Should(BeNil())
+		Expect(len(templates.Templates)).Should(BeEquivalentTo(1))
+		templates, err = appService.ListApplicationTemplates(templateCtx, templateOtherProject)
+		Expect(err).Should(BeNil())
+		Expect(len(templates.Templates)).Should(BeEquivalentTo(0))
+	})
+
+	It("Test previewing the application template", func() {
+		_, err := appService.PreviewApplicationTemplate(templateCtx, templateProject, "web-template", apisv1.PreviewApplicationTemplateRequest{
+			Parameters: map[string]interface{}{"unknown": "value"},
+		})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationTemplateParameterInvalid))
+		_, err = appService.PreviewApplicationTemplate(templateCtx, templateOtherProject, "web-template", apisv1.PreviewApplicationTemplateRequest{})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationTemplateNotExist))
+
+		preview, err := appService.PreviewApplicationTemplate(templateCtx, templateProject, "web-template", apisv1.PreviewApplicationTemplateRequest{
+			Parameters: map[string]interface{}{"image": "nginx:1.21", "cpu": "500m"},
+		})
+		Expect(err).Should(BeNil())
+		Expect(preview.Version).Should(BeEquivalentTo("v1"))
+		Expect(len(preview.Components)).Should(BeEquivalentTo(1))
+		properties := *preview.Components[0].Properties
+		Expect(properties["image"]).Should(BeEquivalentTo("nginx:1.21"))
+		Expect(properties["resources"]).Should(BeEquivalentTo(map[string]interface{}{"cpu": "500m"}))
+	})
+
+	It("Test creating the application from the template", func() {
+		_, err := appService.CreateApplication(templateCtx, apisv1.CreateApplicationRequest{
+			Name:      "test-template-conflict",
+			Project:   templateProject,
+			Template:  &apisv1.ApplicationTemplateReference{Name: "web-template"},
+			Component: &apisv1.CreateComponentRequest{Name: "web", ComponentType: "webservice"},
+		})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationTemplateComponentConflict))
+
+		_, err = appService.CreateApplication(templateCtx, apisv1.CreateApplicationRequest{
+			Name:     "test-template-instance",
+			Project:  templateProject,
+			Template: &apisv1.ApplicationTemplateReference{Name: "web-template", Parameters: map[string]interface{}{"image": "nginx:1.22"}},
+		})
+		Expect(err).Should(BeNil())
+		component, err := appService.GetApplicationComponent(templateCtx, &model.Application{Name: "test-template-instance"}, "web")
+		Expect(err).Should(BeNil())
+		Expect((*component.Properties)["image"]).Should(BeEquivalentTo("nginx:1.22"))
+
+		_, err = appService.CreateApplication(templateCtx, apisv1.CreateApplicationRequest{
+			Name:       "test-template-failed",
+			Project:    templateProject,
+			Template:   &apisv1.ApplicationTemplateReference{Name: "web-template", Parameters: map[string]interface{}{"image": "nginx:1.22"}},
+			EnvBinding: []*apisv1.EnvBinding{{Name: "not-exist-env"}},
+		})
+		Expect(err).ShouldNot(BeNil())
+		exist, err := appService.Store.IsExist(templateCtx, &model.ApplicationComponent{AppPrimaryKey: "test-template-failed", Name: "web"})
+		Expect(err).Should(BeNil())
+		Expect(exist).Should(BeFalse())
+	})
+
+	It("Test the primary key of the template versions", func() {
+		app, err := appService.GetApplication(templateCtx, templateApp)
+		Expect(err).Should(BeNil())
+		_, err = appService.PublishApplicationTemplate(templateCtx, app, apisv1.CreateApplicationTemplateRequest{TemplateName: "key-template-1", Version: "2"}, false)
+		Expect(err).Should(BeNil())
+		_, err = appService.PublishApplicationTemplate(templateCtx, app, apisv1.CreateApplicationTemplateRequest{TemplateName: "key-template", Version: "1-2"}, false)
+		Expect(err).Should(BeNil())
+	})
+})
+
+var _ = Describe("Test setting the property by path", func() {
+	It("Test setPropertyByPath function", func() {
+		properties := &model.JSONStruct{"image": "nginx", "env": map[string]interface{}{"a": "b"}, "ports": []interface{}{map[string]interface{}{"port": 80}}}
+		result := copyTemplateProperties(properties)
+		Expect(setPropertyByPath(result, "env.c", "d")).Should(BeNil())
+		Expect(result["env"]).Should(BeEquivalentTo(map[string]interface{}{"a": "b", "c": "d"}))
+		Expect((*properties)["env"]).Should(BeEquivalentTo(map[string]interface{}{"a": "b"}))
+		Expect(getPropertyByPath(result, "env.c")).Should(BeEquivalentTo("d"))
+		Expect(getPropertyByPath(result, "image.tag")).Should(BeNil())
+		Expect(setPropertyByPath(result, "ports[0].port", 8080)).Should(BeNil())
+		Expect(getPropertyByPath(result, "ports[0].port")).Should(BeEquivalentTo(8080))
+		Expect(setPropertyByPath(result, "volumes[0].name", "data")).ShouldNot(BeNil())
+	})
+})
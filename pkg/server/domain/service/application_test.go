@@ -146,6 +146,8 @@ var _ = Describe("Test application service function", func() {
 		Expect(err).Should(BeNil())
 		err = appService.DeleteApplication(context.TODO(), &model.Application{Project: testProject, Name: "test-cloud-application"})
 		Expect(err).Should(BeNil())
+		err = appService.PurgeApplication(context.TODO(), &model.Application{Project: testProject, Name: "test-cloud-application"})
+		Expect(err).Should(BeNil())
 		err = k8sClient.Delete(context.TODO(), &cd)
 		Expect(err).Should(BeNil())
 	})
@@ -693,6 +695,10 @@ var _ = Describe("Test application service function", func() {
 		time.Sleep(time.Second * 3)
 		err = appService.DeleteApplication(context.TODO(), appModel)
 		Expect(err).Should(BeNil())
+		_, err = appService.GetApplication(context.TODO(), testApp)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationNotExist))
+		err = appService.PurgeApplication(context.TODO(), appModel)
+		Expect(err).Should(BeNil())
 		components, err := appService.ListComponents(context.TODO(), appModel, v1.ListApplicationComponentOptions{})
 		Expect(err).Should(BeNil())
 		Expect(cmp.Diff(len(components), 0)).Should(BeEmpty())
@@ -260,6 +260,7 @@ var _ = Describe("Test config service", func() {
 			Expect(err).To(BeNil())
 			defer func() {
 				Expect(projectService.DeleteProject(context.Background(), "some-project")).To(BeNil())
+				Expect(projectService.PurgeProject(context.Background(), "some-project")).To(BeNil())
 			}()
 			By("create a common global config")
 			_, err = configService.CreateConfig(context.TODO(), NoProject, v1.CreateConfigRequest{
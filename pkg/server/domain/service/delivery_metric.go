@@ -0,0 +1,319 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+const (
+	defaultDeliveryMetricDays = 30
+	maxDeliveryMetricDays     = 365
+	// the rollups of the recent days are recalculated because the deploys may be finished or rolled back later
+	deliveryMetricRecalculateDays = 7
+)
+
+// DeliveryMetricService calculate the DORA metrics from the revisions and the workflow records
+type DeliveryMetricService interface {
+	GetDeliveryMetrics(ctx context.Context, options apisv1.DeliveryMetricsOptions) (*apisv1.DeliveryMetrics, error)
+	// RollupDeliveryMetrics calculate and save the daily rollups until today, the rollups of today are refreshed by each run
+	RollupDeliveryMetrics(ctx context.Context) error
+}
+
+type deliveryMetricServiceImpl struct {
+	Store datastore.DataStore `inject:"datastore"`
+}
+
+// NewDeliveryMetricService new delivery metric service
+func NewDeliveryMetricService() DeliveryMetricService {
+	return &deliveryMetricServiceImpl{}
+}
+
+func (d *deliveryMetricServiceImpl) GetDeliveryMetrics(ctx context.Context, options apisv1.DeliveryMetricsOptions) (*apisv1.DeliveryMetrics, error) {
+	return queryDeliveryMetrics(ctx, d.Store, options)
+}
+
+func (d *deliveryMetricServiceImpl) RollupDeliveryMetrics(ctx context.Context) error {
+	entities, err := d.Store.List(ctx, &model.Application{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	tomorrow := truncateDay(time.Now()).AddDate(0, 0, 1)
+	for _, entity := range entities {
+		app := entity.(*model.Application)
+		if app.IsDeleted() {
+			continue
+		}
+		if err := d.rollupApplication(ctx, app, tomorrow); err != nil {
+			klog.Errorf("failed to rollup the delivery metrics of the application %s: %s", app.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// rollupApplication save the daily rollups of the application before the day `to`, the recent days are recalculated
+func (d *deliveryMetricServiceImpl) rollupApplication(ctx context.Context, app *model.Application, to time.Time) error {
+	entities, err := d.Store.List(ctx, &model.DeliveryMetricRollup{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	from := truncateDay(app.CreateTime)
+	var latest string
+	for _, entity := range entities {
+		if rollup := entity.(*model.DeliveryMetricRollup); rollup.Date > latest {
+			latest = rollup.Date
+		}
+	}
+	if latestDate, err := time.Parse(model.DeliveryMetricDateFormat, latest); err == nil {
+		from = latestDate.AddDate(0, 0, -deliveryMetricRecalculateDays)
+	}
+	if earliest := to.AddDate(0, 0, -maxDeliveryMetricDays); from.Before(earliest) {
+		from = earliest
+	}
+	if !from.Before(to) {
+		return nil
+	}
+	rollups, err := calculateDeliveryRollups(ctx, d.Store, app, from, to)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, entity := range entities {
+		rollup := entity.(*model.DeliveryMetricRollup)
+		if !inDateRange(rollup.Date, from, to) {
+			continue
+		}
+		if _, exist := rollups[rollup.PrimaryKey()]; !exist {
+			if err := d.Store.Delete(ctx, rollup); err != nil {
+				return err
+			}
+			continue
+		}
+		existing[rollup.PrimaryKey()] = true
+	}
+	for key, rollup := range rollups {
+		if existing[key] {
+			err = d.Store.Put(ctx, rollup)
+		} else {
+			err = d.Store.Add(ctx, rollup)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryDeliveryMetrics aggregate the saved rollups in the window, the rollups of today are refreshed by the rollup job
+func queryDeliveryMetrics(ctx context.Context, store datastore.DataStore, options apisv1.DeliveryMetricsOptions) (*apisv1.DeliveryMetrics, error) {
+	days := options.Days
+	if days <= 0 {
+		days = defaultDeliveryMetricDays
+	}
+	if days > maxDeliveryMetricDays {
+		days = maxDeliveryMetricDays
+	}
+	today := truncateDay(time.Now())
+	start, end := today.AddDate(0, 0, 1-days), today.AddDate(0, 0, 1)
+	var rollups []*model.DeliveryMetricRollup
+	entities, err := store.List(ctx, &model.DeliveryMetricRollup{AppPrimaryKey: options.AppPrimaryKey, EnvName: options.EnvName, Project: options.Project}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range entities {
+		if rollup := entity.(*model.DeliveryMetricRollup); inDateRange(rollup.Date, start, end) {
+			rollups = append(rollups, rollup)
+		}
+	}
+	return aggregateDeliveryRollups(rollups, start, days), nil
+}
+
+// calculateDeliveryRollups calculate the daily rollups of the application in [from, to).
+// The deploy is failed if the revision is failed or terminated, or it is rolled back by the next revision.
+// The time to restore is from the failed deploy to the next successful deploy or rollback in the same env.
+func calculateDeliveryRollups(ctx context.Context, store datastore.DataStore, app *model.Application, from, to time.Time) (map[string]*model.DeliveryMetricRollup, error) {
+	entities, err := store.List(ctx, &model.ApplicationRevision{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	records, err := store.List(ctx, &model.WorkflowRecord{AppPrimaryKey: app.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	endTimes := map[string]time.Time{}
+	for _, entity := range records {
+		record := entity.(*model.WorkflowRecord)
+		if record.Finished == "true" && !record.EndTime.IsZero() {
+			endTimes[record.RevisionPrimaryKey] = record.EndTime
+		}
+	}
+	finishTime := func(revision *model.ApplicationRevision) time.Time {
+		if endTime, exist := endTimes[revision.Version]; exist {
+			return endTime
+		}
+		return revision.UpdateTime
+	}
+	envRevisions := map[string][]*model.ApplicationRevision{}
+	for _, entity := range entities {
+		revision := entity.(*model.ApplicationRevision)
+		envRevisions[revision.EnvName] = append(envRevisions[revision.EnvName], revision)
+	}
+
+	rollups := map[string]*model.DeliveryMetricRollup{}
+	getRollup := func(envName string, t time.Time) *model.DeliveryMetricRollup {
+		if t.Before(from) || !t.Before(to) {
+			return nil
+		}
+		rollup := &model.DeliveryMetricRollup{
+			AppPrimaryKey: app.PrimaryKey(),
+			EnvName:       envName,
+			Date:          t.UTC().Format(model.DeliveryMetricDateFormat),
+			Project:       app.Project,
+		}
+		if exist, ok := rollups[rollup.PrimaryKey()]; ok {
+			return exist
+		}
+		rollups[rollup.PrimaryKey()] = rollup
+		return rollup
+	}
+	for envName, revisions := range envRevisions {
+		rolledBack := map[int]bool{}
+		for i, revision := range revisions {
+			if revision.Status != model.RevisionStatusRollback {
+				continue
+			}
+			for j := i - 1; j >= 0; j-- {
+				if revisions[j].Status != model.RevisionStatusRollback {
+					rolledBack[j] = true
+					break
+				}
+			}
+		}
+		for i, revision := range revisions {
+			if revision.Status != model.RevisionStatusComplete && revision.Status != model.RevisionStatusFail && revision.Status != model.RevisionStatusTerminated {
+				continue
+			}
+			doneTime := finishTime(revision)
+			failed := revision.Status != model.RevisionStatusComplete || rolledBack[i]
+			if rollup := getRollup(envName, doneTime); rollup != nil {
+				rollup.Deployments++
+				if failed {
+					rollup.Failures++
+				} else if revision.CodeInfo != nil && revision.CodeInfo.CommitTime != nil && doneTime.After(*revision.CodeInfo.CommitTime) {
+					rollup.LeadTimes = append(rollup.LeadTimes, int64(doneTime.Sub(*revision.CodeInfo.CommitTime).Seconds()))
+				}
+			}
+			if !failed {
+				continue
+			}
+			for j := i + 1; j < len(revisions); j++ {
+				next := revisions[j]
+				if next.Status != model.RevisionStatusRollback && (next.Status != model.RevisionStatusComplete || rolledBack[j]) {
+					continue
+				}
+				restoreTime := finishTime(next)
+				if restoreTime.After(doneTime) {
+					if rollup := getRollup(envName, restoreTime); rollup != nil {
+						rollup.RestoreTimes = append(rollup.RestoreTimes, int64(restoreTime.Sub(doneTime).Seconds()))
+					}
+				}
+				break
+			}
+		}
+	}
+	return rollups, nil
+}
+
+func aggregateDeliveryRollups(rollups []*model.DeliveryMetricRollup, start time.Time, days int) *apisv1.DeliveryMetrics {
+	metrics := &apisv1.DeliveryMetrics{
+		StartDate: start.Format(model.DeliveryMetricDateFormat),
+		EndDate:   start.AddDate(0, 0, days-1).Format(model.DeliveryMetricDateFormat),
+	}
+	type dailyValues struct {
+		deployments, failures   int64
+		leadTimes, restoreTimes []int64
+	}
+	daily := map[string]*dailyValues{}
+	var failures int64
+	var leadTimes, restoreTimes []int64
+	for _, rollup := range rollups {
+		values, exist := daily[rollup.Date]
+		if !exist {
+			values = &dailyValues{}
+			daily[rollup.Date] = values
+		}
+		values.deployments += rollup.Deployments
+		values.failures += rollup.Failures
+		values.leadTimes = append(values.leadTimes, rollup.LeadTimes...)
+		values.restoreTimes = append(values.restoreTimes, rollup.RestoreTimes...)
+		metrics.Deployments += rollup.Deployments
+		failures += rollup.Failures
+		leadTimes = append(leadTimes, rollup.LeadTimes...)
+		restoreTimes = append(restoreTimes, rollup.RestoreTimes...)
+	}
+	metrics.DeploymentFrequency = float64(metrics.Deployments) / float64(days)
+	if metrics.Deployments > 0 {
+		metrics.ChangeFailureRate = float64(failures) / float64(metrics.Deployments)
+	}
+	metrics.LeadTimeSeconds = median(leadTimes)
+	metrics.TimeToRestoreSeconds = median(restoreTimes)
+	metrics.Daily = []apisv1.DeliveryMetricsDaily{}
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i).Format(model.DeliveryMetricDateFormat)
+		item := apisv1.DeliveryMetricsDaily{Date: date}
+		if values, exist := daily[date]; exist {
+			item.Deployments = values.deployments
+			item.Failures = values.failures
+			item.LeadTimeSeconds = median(values.leadTimes)
+			item.TimeToRestoreSeconds = median(values.restoreTimes)
+		}
+		metrics.Daily = append(metrics.Daily, item)
+	}
+	return metrics
+}
+
+func median(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64{}, values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+func truncateDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// inDateRange check whether the date is in [from, to)
+func inDateRange(date string, from, to time.Time) bool {
+	return date >= from.Format(model.DeliveryMetricDateFormat) && date < to.Format(model.DeliveryMetricDateFormat)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+var _ = Describe("Test the delivery metrics", func() {
+	var (
+		metricProject = "test-metric-project"
+		metricApp     = "test-metric-app"
+		metricEnv     = "test-metric-prod"
+		metricService *deliveryMetricServiceImpl
+	)
+
+	It("Init the revisions and the workflow records", func() {
+		InitTestEnv("delivery-metric-test-kubevela")
+		metricService = &deliveryMetricServiceImpl{Store: ds}
+		Expect(ds.Add(context.TODO(), &model.Application{Name: metricApp, Project: metricProject})).Should(BeNil())
+		now := time.Now()
+		commitTime := now.Add(-2 * time.Hour)
+		revisions := []struct {
+			version  string
+			status   string
+			endTime  time.Time
+			codeInfo *model.CodeInfo
+		}{
+			{"v1", model.RevisionStatusComplete, now.Add(-time.Hour), &model.CodeInfo{Commit: "c1", CommitTime: &commitTime}},
+			{"v2", model.RevisionStatusFail, now.Add(-50 * time.Minute), nil},
+			{"v3", model.RevisionStatusComplete, now.Add(-40 * time.Minute), nil},
+			{"v4", model.RevisionStatusComplete, now.Add(-30 * time.Minute), nil},
+			// rollback the v4
+			{"v5", model.RevisionStatusRollback, now.Add(-20 * time.Minute), nil},
+			{"v6", model.RevisionStatusRunning, time.Time{}, nil},
+		}
+		for _, item := range revisions {
+			Expect(ds.Add(context.TODO(), &model.ApplicationRevision{
+				AppPrimaryKey: metricApp,
+				Version:       item.version,
+				EnvName:       metricEnv,
+				Status:        item.status,
+				CodeInfo:      item.codeInfo,
+			})).Should(BeNil())
+			if item.endTime.IsZero() {
+				continue
+			}
+			Expect(ds.Add(context.TODO(), &model.WorkflowRecord{
+				AppPrimaryKey:      metricApp,
+				Name:               "record-" + item.version,
+				RevisionPrimaryKey: item.version,
+				Finished:           "true",
+				StartTime:          item.endTime.Add(-time.Minute),
+				EndTime:            item.endTime,
+			})).Should(BeNil())
+		}
+	})
+
+	It("Test calculating the metrics", func() {
+		today := truncateDay(time.Now())
+		app := &model.Application{Name: metricApp, Project: metricProject}
+		rollups, err := calculateDeliveryRollups(context.TODO(), ds, app, today.AddDate(0, 0, -1), today.AddDate(0, 0, 1))
+		Expect(err).Should(BeNil())
+		var deployments, failures int64
+		var leadTimes, restoreTimes []int64
+		for _, rollup := range rollups {
+			deployments += rollup.Deployments
+			failures += rollup.Failures
+			leadTimes = append(leadTimes, rollup.LeadTimes...)
+			restoreTimes = append(restoreTimes, rollup.RestoreTimes...)
+		}
+		Expect(deployments).Should(BeEquivalentTo(4))
+		Expect(failures).Should(BeEquivalentTo(2))
+		Expect(leadTimes).Should(Equal([]int64{3600}))
+		Expect(len(restoreTimes)).Should(BeEquivalentTo(2))
+
+		// the metrics of today are served from the rollups
+		metrics, err := metricService.GetDeliveryMetrics(context.TODO(), apisv1.DeliveryMetricsOptions{Project: metricProject, EnvName: metricEnv, Days: 7})
+		Expect(err).Should(BeNil())
+		Expect(metrics.Deployments).Should(BeEquivalentTo(0))
+		Expect(metricService.RollupDeliveryMetrics(context.TODO())).Should(BeNil())
+		metrics, err = metricService.GetDeliveryMetrics(context.TODO(), apisv1.DeliveryMetricsOptions{Project: metricProject, EnvName: metricEnv, Days: 7})
+		Expect(err).Should(BeNil())
+		Expect(len(metrics.Daily)).Should(BeEquivalentTo(7))
+		Expect(metrics.Deployments).Should(BeEquivalentTo(4))
+		Expect(metrics.ChangeFailureRate).Should(BeEquivalentTo(0.5))
+		Expect(metrics.LeadTimeSeconds).Should(BeEquivalentTo(3600))
+		Expect(metrics.TimeToRestoreSeconds).Should(BeEquivalentTo(600))
+
+		metrics, err = metricService.GetDeliveryMetrics(context.TODO(), apisv1.DeliveryMetricsOptions{Project: metricProject, EnvName: "not-exist"})
+		Expect(err).Should(BeNil())
+		Expect(metrics.Deployments).Should(BeEquivalentTo(0))
+	})
+
+	It("Test rolling up the metrics", func() {
+		app := &model.Application{Name: metricApp}
+		Expect(ds.Get(context.TODO(), app)).Should(BeNil())
+		tomorrow := truncateDay(time.Now()).AddDate(0, 0, 1)
+		Expect(metricService.rollupApplication(context.TODO(), app, tomorrow)).Should(BeNil())
+		// recalculate the saved rollups
+		Expect(metricService.rollupApplication(context.TODO(), app, tomorrow)).Should(BeNil())
+		entities, err := ds.List(context.TODO(), &model.DeliveryMetricRollup{AppPrimaryKey: metricApp}, nil)
+		Expect(err).Should(BeNil())
+		var deployments int64
+		for _, entity := range entities {
+			deployments += entity.(*model.DeliveryMetricRollup).Deployments
+		}
+		Expect(deployments).Should(BeEquivalentTo(4))
+	})
+
+	It("Test the median", func() {
+		Expect(median(nil)).Should(BeEquivalentTo(0))
+		Expect(median([]int64{3, 1, 2})).Should(BeEquivalentTo(2))
+		Expect(median([]int64{4, 1, 2, 3})).Should(BeEquivalentTo(2))
+	})
+})
@@ -50,6 +50,7 @@ type EnvService interface {
 	DeleteEnv(ctx context.Context, envName string) error
 	CreateEnv(ctx context.Context, req apisv1.CreateEnvRequest) (*apisv1.Env, error)
 	UpdateEnv(ctx context.Context, envName string, req apisv1.UpdateEnvRequest) (*apisv1.Env, error)
+	UpdateEnvProtection(ctx context.Context, envName string, req apisv1.UpdateEnvProtectionRequest) (*apisv1.Env, error)
 }
 
 type envServiceImpl struct {
@@ -247,6 +248,42 @@ func (p *envServiceImpl) UpdateEnv(ctx context.Context, name string, req apisv1.
 	return resp, nil
 }
 
+// UpdateEnvProtection update the approval rules of deploying the applications to the env
+func (p *envServiceImpl) UpdateEnvProtection(ctx context.Context, envName string, req apisv1.UpdateEnvProtectionRequest) (*apisv1.Env, error) {
+	env := &model.Env{Name: envName}
+	if err := p.Store.Get(ctx, env); err != nil {
+		return nil, bcode.ErrEnvNotExisted
+	}
+	env.Protection = nil
+	if len(req.Approvers) > 0 || len(req.ApproverRoles) > 0 {
+		if req.MinApprovals < 1 {
+			req.MinApprovals = 1
+		}
+		for _, approver := range req.Approvers {
+			if err := p.Store.Get(ctx, &model.User{Name: approver}); err != nil {
+				return nil, bcode.ErrEnvProtectionInvalid
+			}
+		}
+		// the users with the roles are uncountable, only check the number of the approvers if no role is set
+		if len(req.ApproverRoles) == 0 && len(req.Approvers) < req.MinApprovals {
+			return nil, bcode.ErrEnvProtectionInvalid
+		}
+		env.Protection = &model.EnvProtection{
+			Approvers:     req.Approvers,
+			ApproverRoles: req.ApproverRoles,
+			MinApprovals:  req.MinApprovals,
+		}
+	}
+	if err := p.Store.Put(ctx, env); err != nil {
+		return nil, err
+	}
+	targets, err := repository.ListTarget(ctx, p.Store, env.Project, nil)
+	if err != nil {
+		return nil, err
+	}
+	return convertEnvModel2Base(env, targets), nil
+}
+
 func (p *envServiceImpl) GetAppCountInEnv(ctx context.Context, env *model.Env) (int, error) {
 	var appList v1beta1.ApplicationList
 	if err := p.KubeClient.List(ctx, &appList, client.InNamespace(env.Namespace), client.MatchingLabels{types.LabelSourceOfTruth: types.FromUX}); err != nil {
@@ -335,6 +372,7 @@ func convertEnvModel2Base(env *model.Env, targets []*model.Target) *apisv1.Env {
 		Description: env.Description,
 		Project:     apisv1.NameAlias{Name: env.Project},
 		Namespace:   env.Namespace,
+		Protection:  env.Protection,
 		CreateTime:  env.CreateTime,
 		UpdateTime:  env.UpdateTime,
 	}
@@ -285,6 +285,10 @@ func (e *envBindingServiceImpl) ApplicationEnvRecycle(ctx context.Context, appMo
 	if err != nil {
 		return err
 	}
+	userName, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if err := checkDeployLock(ctx, e.Store, appModel.PrimaryKey(), env.Name, userName); err != nil {
+		return err
+	}
 	var app v1beta1.Application
 	name := envBinding.AppDeployName
 	if name == "" {
@@ -0,0 +1,240 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kubevela/pkg/util/slices"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+)
+
+const (
+	// eventWatcherBuffer the events are dropped for the watcher if the buffer is full
+	eventWatcherBuffer = 100
+	// eventOutboxRedeliverDelay the events in the outbox older than the delay are considered lost
+	eventOutboxRedeliverDelay = time.Minute
+	// eventOutboxMaxAttempts the event is removed from the outbox after the attempts even if some subscribers still fail
+	eventOutboxMaxAttempts = 5
+)
+
+// EventHandler handle the domain event
+type EventHandler func(ctx context.Context, event *model.DomainEvent) error
+
+// EventBus dispatch the domain events to the subscribers in the process
+type EventBus interface {
+	// Publish save the event to the outbox if it is enabled, and dispatch it asynchronously
+	Publish(ctx context.Context, event *model.DomainEvent)
+	// Subscribe register the handler of the event types, all types are subscribed if the types are empty
+	Subscribe(name string, handler EventHandler, types ...string)
+	// Watch stream the events until the context is done, the events are dropped if the watcher is slow
+	Watch(ctx context.Context) <-chan *model.DomainEvent
+	// RedeliverOutbox dispatch the events in the outbox that are not handled, such as the process exits before dispatching
+	RedeliverOutbox(ctx context.Context) error
+}
+
+type eventSubscriber struct {
+	name    string
+	types   []string
+	handler EventHandler
+}
+
+type eventBusImpl struct {
+	Store       datastore.DataStore `inject:"datastore"`
+	outbox      bool
+	lock        sync.RWMutex
+	subscribers []eventSubscriber
+	watchers    map[chan *model.DomainEvent]struct{}
+	// wait is used by the tests to wait for the dispatching
+	wait sync.WaitGroup
+}
+
+// NewEventBus new the domain event bus
+func NewEventBus(outbox bool) EventBus {
+	return &eventBusImpl{outbox: outbox, watchers: map[chan *model.DomainEvent]struct{}{}}
+}
+
+func (e *eventBusImpl) Publish(ctx context.Context, event *model.DomainEvent) {
+	if event.ID == "" {
+		event.ID = fmt.Sprintf("%s-%s", utils.GenerateVersion(""), rand.String(6))
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.User == "" {
+		event.User, _ = ctx.Value(&apisv1.CtxKeyUser).(string)
+	}
+	if e.outbox {
+		if err := e.Store.Add(ctx, event); err != nil {
+			klog.Errorf("failed to save the event %s to the outbox: %s", event.ID, err.Error())
+		}
+	}
+	e.wait.Add(1)
+	go func() {
+		defer e.wait.Done()
+		// the request may be finished before dispatching
+		e.dispatch(context.WithoutCancel(ctx), event)
+	}()
+}
+
+func (e *eventBusImpl) Subscribe(name string, handler EventHandler, types ...string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.subscribers = append(e.subscribers, eventSubscriber{name: name, types: types, handler: handler})
+}
+
+func (e *eventBusImpl) Watch(ctx context.Context) <-chan *model.DomainEvent {
+	watcher := make(chan *model.DomainEvent, eventWatcherBuffer)
+	e.lock.Lock()
+	e.watchers[watcher] = struct{}{}
+	e.lock.Unlock()
+	go func() {
+		<-ctx.Done()
+		e.lock.Lock()
+		delete(e.watchers, watcher)
+		close(watcher)
+		e.lock.Unlock()
+	}()
+	return watcher
+}
+
+func (e *eventBusImpl) RedeliverOutbox(ctx context.Context) error {
+	if !e.outbox {
+		return nil
+	}
+	entities, err := e.Store.List(ctx, &model.DomainEvent{}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}},
+	})
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		event := entity.(*model.DomainEvent)
+		if time.Since(event.Time) < eventOutboxRedeliverDelay {
+			continue
+		}
+		klog.Infof("redeliver the event %s(%s) in the outbox", event.ID, event.Type)
+		e.dispatch(ctx, event)
+	}
+	return nil
+}
+
+// dispatch send the event to the watchers and the subscribers, the retried event is only sent to the subscribers that failed.
+// The event is kept in the outbox until all subscribers handle it or the attempts are used up.
+func (e *eventBusImpl) dispatch(ctx context.Context, event *model.DomainEvent) {
+	retry := len(event.FailedSubscribers) > 0
+	e.lock.RLock()
+	subscribers := append([]eventSubscriber{}, e.subscribers...)
+	for watcher := range e.watchers {
+		if retry {
+			break
+		}
+		select {
+		case watcher <- event:
+		default:
+			klog.Warningf("drop the event %s for the slow watcher", event.ID)
+		}
+	}
+	e.lock.RUnlock()
+	var failed []string
+	for _, subscriber := range subscribers {
+		if len(subscriber.types) > 0 && !slices.Contains(subscriber.types, event.Type) {
+			continue
+		}
+		if retry && !slices.Contains(event.FailedSubscribers, subscriber.name) {
+			continue
+		}
+		if !e.handle(ctx, subscriber, event) {
+			failed = append(failed, subscriber.name)
+		}
+	}
+	if !e.outbox {
+		return
+	}
+	event.Attempts++
+	if len(failed) > 0 && event.Attempts < eventOutboxMaxAttempts {
+		event.FailedSubscribers = failed
+		if err := e.Store.Put(ctx, event); err != nil {
+			klog.Errorf("failed to keep the event %s in the outbox for retrying: %s", event.ID, err.Error())
+		}
+		return
+	}
+	if len(failed) > 0 {
+		klog.Errorf("give up the event %s after %d attempts, the subscribers %v failed", event.ID, event.Attempts, failed)
+	}
+	if err := e.Store.Delete(ctx, event); err != nil {
+		klog.Errorf("failed to remove the event %s from the outbox: %s", event.ID, err.Error())
+	}
+}
+
+// handle call the handler of the subscriber, return false if it fails or panics
+func (e *eventBusImpl) handle(ctx context.Context, subscriber eventSubscriber, event *model.DomainEvent) (handled bool) {
+	// don't let the subscriber panic crash whole api-server process
+	defer func() {
+		if r := recover(); r != nil {
+			klog.Errorf("the subscriber %s panics when handling the event %s: %v", subscriber.name, event.ID, r)
+			handled = false
+		}
+	}()
+	if err := subscriber.handler(ctx, event); err != nil {
+		klog.Errorf("the subscriber %s failed to handle the event %s: %s", subscriber.name, event.ID, err.Error())
+		return false
+	}
+	return true
+}
+
+// newDomainEvent build the event with the typed payload
+func newDomainEvent(eventType, project, resource string, data interface{}) *model.DomainEvent {
+	event := &model.DomainEvent{Type: eventType, Project: project, Resource: resource}
+	if data != nil {
+		body, err := json.Marshal(data)
+		if err != nil {
+			klog.Errorf("failed to marshal the data of the event %s: %s", eventType, err.Error())
+		}
+		event.Data = string(body)
+	}
+	return event
+}
+
+// publishEvent publish the event if the bus is set, the services built for the tests may not have the bus
+func publishEvent(ctx context.Context, bus EventBus, event *model.DomainEvent) {
+	if bus == nil {
+		return
+	}
+	bus.Publish(ctx, event)
+}
+
+// newAppEventData build the payload of the application event
+func newAppEventData(app *model.Application, envName, revision string) model.AppEventData {
+	return model.AppEventData{
+		AppName:   app.Name,
+		AppAlias:  app.Alias,
+		AppLabels: app.Labels,
+		EnvName:   envName,
+		Revision:  revision,
+	}
+}
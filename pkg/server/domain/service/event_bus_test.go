@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+)
+
+var _ = Describe("Test the domain event bus", func() {
+	var (
+		bus     *eventBusImpl
+		busCtx  context.Context
+		mutex   sync.Mutex
+		handled []string
+	)
+
+	It("Init the event bus", func() {
+		InitTestEnv("event-bus-test-kubevela")
+		busCtx = context.WithValue(context.TODO(), &apisv1.CtxKeyUser, FakeAdminName)
+		bus = NewEventBus(true).(*eventBusImpl)
+		bus.Store = ds
+		bus.Subscribe("test", func(ctx context.Context, event *model.DomainEvent) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			var data model.AppEventData
+			if err := event.DecodeData(&data); err != nil {
+				return err
+			}
+			handled = append(handled, event.Type+":"+data.AppName)
+			return nil
+		}, model.EventAppCreated)
+		// the subscribers fail at the first attempt, the events are retried by the redelivery
+		bus.Subscribe("panic", func(ctx context.Context, event *model.DomainEvent) error {
+			if event.Attempts == 0 {
+				panic("the subscriber should not crash the process")
+			}
+			return nil
+		})
+		bus.Subscribe("error", func(ctx context.Context, event *model.DomainEvent) error {
+			if event.Attempts == 0 {
+				return fmt.Errorf("the error is retried")
+			}
+			return nil
+		})
+	})
+
+	It("Test publishing the events", func() {
+		watchCtx, cancel := context.WithCancel(busCtx)
+		events := bus.Watch(watchCtx)
+		publishEvent(busCtx, bus, newDomainEvent(model.EventAppCreated, "default", "project/application", model.AppEventData{AppName: "web"}))
+		publishEvent(busCtx, bus, newDomainEvent(model.EventAppDeleted, "default", "project/application", model.AppEventData{AppName: "web"}))
+		bus.wait.Wait()
+
+		mutex.Lock()
+		Expect(handled).Should(BeEquivalentTo([]string{model.EventAppCreated + ":web"}))
+		mutex.Unlock()
+		for _, eventType := range []string{model.EventAppCreated, model.EventAppDeleted} {
+			var event *model.DomainEvent
+			Eventually(events).Should(Receive(&event))
+			Expect(event.Type).Should(BeEquivalentTo(eventType))
+			Expect(event.User).Should(BeEquivalentTo(FakeAdminName))
+		}
+		cancel()
+		Eventually(events).Should(BeClosed())
+
+		// the events are kept in the outbox for the failed subscribers
+		entities, err := ds.List(busCtx, &model.DomainEvent{}, nil)
+		Expect(err).Should(BeNil())
+		Expect(len(entities)).Should(Equal(2))
+		for _, entity := range entities {
+			event := entity.(*model.DomainEvent)
+			Expect(event.FailedSubscribers).Should(ConsistOf("panic", "error"))
+			bus.dispatch(busCtx, event)
+		}
+		// the retried events are only sent to the failed subscribers, and removed from the outbox
+		mutex.Lock()
+		Expect(handled).Should(BeEquivalentTo([]string{model.EventAppCreated + ":web"}))
+		mutex.Unlock()
+		count, err := ds.Count(busCtx, &model.DomainEvent{}, nil)
+		Expect(err).Should(BeNil())
+		Expect(count).Should(BeEquivalentTo(0))
+
+		// the publisher without the bus is ignored
+		publishEvent(busCtx, nil, newDomainEvent(model.EventAppCreated, "default", "project/application", nil))
+	})
+
+	It("Test redelivering the events in the outbox", func() {
+		lost := newDomainEvent(model.EventAppCreated, "default", "project/application", model.AppEventData{AppName: "lost"})
+		lost.ID = "lost-event"
+		lost.Time = time.Now().Add(-2 * eventOutboxRedeliverDelay)
+		Expect(ds.Add(busCtx, lost)).Should(BeNil())
+
+		Expect(bus.RedeliverOutbox(busCtx)).Should(BeNil())
+		mutex.Lock()
+		Expect(handled).Should(ContainElement(model.EventAppCreated + ":lost"))
+		mutex.Unlock()
+		Expect(ds.Get(busCtx, &model.DomainEvent{ID: "lost-event"})).Should(BeNil())
+		Expect(bus.RedeliverOutbox(busCtx)).Should(BeNil())
+		err := ds.Get(busCtx, &model.DomainEvent{ID: "lost-event"})
+		Expect(errors.Is(err, datastore.ErrRecordNotExist)).Should(BeTrue())
+	})
+})
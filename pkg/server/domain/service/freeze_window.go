@@ -0,0 +1,313 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// FreezeWindowService manage the windows that block the deploys and the pipeline runs
+type FreezeWindowService interface {
+	// ListFreezeWindows list the windows of the project, list the platform windows if the project is empty
+	ListFreezeWindows(ctx context.Context, projectName string) (*apisv1.ListFreezeWindowsResponse, error)
+	CreateFreezeWindow(ctx context.Context, projectName string, req apisv1.CreateFreezeWindowRequest) (*apisv1.FreezeWindowBase, error)
+	UpdateFreezeWindow(ctx context.Context, projectName, name string, req apisv1.UpdateFreezeWindowRequest) (*apisv1.FreezeWindowBase, error)
+	DeleteFreezeWindow(ctx context.Context, projectName, name string) error
+}
+
+type freezeWindowServiceImpl struct {
+	Store datastore.DataStore `inject:"datastore"`
+}
+
+// NewFreezeWindowService new freeze window service
+func NewFreezeWindowService() FreezeWindowService {
+	return &freezeWindowServiceImpl{}
+}
+
+func (f *freezeWindowServiceImpl) ListFreezeWindows(ctx context.Context, projectName string) (*apisv1.ListFreezeWindowsResponse, error) {
+	windows, err := listFreezeWindows(ctx, f.Store)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	res := &apisv1.ListFreezeWindowsResponse{FreezeWindows: []*apisv1.FreezeWindowBase{}}
+	for _, window := range windows {
+		if window.Project == projectName {
+			res.FreezeWindows = append(res.FreezeWindows, convertFreezeWindowModelToBase(window, now))
+		}
+	}
+	return res, nil
+}
+
+func (f *freezeWindowServiceImpl) CreateFreezeWindow(ctx context.Context, projectName string, req apisv1.CreateFreezeWindowRequest) (*apisv1.FreezeWindowBase, error) {
+	window := &model.FreezeWindow{Name: req.Name, Project: projectName}
+	if err := f.Store.Get(ctx, window); err == nil {
+		return nil, bcode.ErrFreezeWindowExist
+	}
+	window.Creator, _ = ctx.Value(&apisv1.CtxKeyUser).(string)
+	if err := f.setFreezeWindow(ctx, window, req.UpdateFreezeWindowRequest); err != nil {
+		return nil, err
+	}
+	if err := f.Store.Add(ctx, window); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrFreezeWindowExist
+		}
+		return nil, err
+	}
+	return convertFreezeWindowModelToBase(window, time.Now()), nil
+}
+
+func (f *freezeWindowServiceImpl) UpdateFreezeWindow(ctx context.Context, projectName, name string, req apisv1.UpdateFreezeWindowRequest) (*apisv1.FreezeWindowBase, error) {
+	window, err := f.getFreezeWindow(ctx, projectName, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.setFreezeWindow(ctx, window, req); err != nil {
+		return nil, err
+	}
+	if err := f.Store.Put(ctx, window); err != nil {
+		return nil, err
+	}
+	return convertFreezeWindowModelToBase(window, time.Now()), nil
+}
+
+func (f *freezeWindowServiceImpl) DeleteFreezeWindow(ctx context.Context, projectName, name string) error {
+	window, err := f.getFreezeWindow(ctx, projectName, name)
+	if err != nil {
+		return err
+	}
+	return f.Store.Delete(ctx, window)
+}
+
+func (f *freezeWindowServiceImpl) getFreezeWindow(ctx context.Context, projectName, name string) (*model.FreezeWindow, error) {
+	window := &model.FreezeWindow{Name: name, Project: projectName}
+	if err := f.Store.Get(ctx, window); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrFreezeWindowNotExist
+		}
+		return nil, err
+	}
+	if window.Project != projectName {
+		return nil, bcode.ErrFreezeWindowNotExist
+	}
+	return window, nil
+}
+
+func (f *freezeWindowServiceImpl) setFreezeWindow(ctx context.Context, window *model.FreezeWindow, req apisv1.UpdateFreezeWindowRequest) error {
+	if req.EnvName != "" {
+		env, err := repository.GetEnv(ctx, f.Store, req.EnvName)
+		if err != nil || window.Project == "" || env.Project != window.Project {
+			return bcode.ErrFreezeWindowInvalid
+		}
+	}
+	window.Alias = req.Alias
+	window.Description = req.Description
+	window.EnvName = req.EnvName
+	window.Recurrence = req.Recurrence
+	window.Duration = req.Duration
+	window.Timezone = req.Timezone
+	window.StartTime = req.StartTime
+	window.EndTime = req.EndTime
+	window.Disabled = req.Disabled
+	if _, err := isFreezeWindowActive(window, time.Now()); err != nil {
+		klog.Warningf("the freeze window %s is invalid: %s", window.Name, err.Error())
+		return bcode.ErrFreezeWindowInvalid
+	}
+	return nil
+}
+
+// checkFreezeWindows return ErrDeployFrozen if any window of the platform, the project or the env is active.
+// The active windows are ignored if the user overrides them with the permission of the project, the platform windows require the platform permission.
+func checkFreezeWindows(ctx context.Context, store datastore.DataStore, rbacService RBACService, projectName, envName string, override bool) error {
+	windows, err := listFreezeWindows(ctx, store)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, window := range windows {
+		if window.Project != "" && window.Project != projectName {
+			continue
+		}
+		if window.EnvName != "" && window.EnvName != envName {
+			continue
+		}
+		active, err := isFreezeWindowActive(window, now)
+		if err != nil {
+			klog.Warningf("skip the invalid freeze window %s: %s", window.PrimaryKey(), err.Error())
+			continue
+		}
+		if !active {
+			continue
+		}
+		if override && rbacService != nil {
+			// the platform windows could only be overridden by the platform permission
+			resource := "project/freezeWindow"
+			if window.Project == "" {
+				resource = "platformFreezeWindow"
+			}
+			if err := rbacService.CheckProjectPerm(ctx, projectName, resource, "override"); err == nil {
+				klog.Infof("the freeze window %s is overridden", window.PrimaryKey())
+				continue
+			}
+		}
+		klog.Infof("the deploy of the project %s is blocked by the freeze window %s", projectName, window.PrimaryKey())
+		return bcode.ErrDeployFrozen
+	}
+	return nil
+}
+
+func listFreezeWindows(ctx context.Context, store datastore.DataStore) ([]*model.FreezeWindow, error) {
+	entities, err := store.List(ctx, &model.FreezeWindow{}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var windows []*model.FreezeWindow
+	for _, entity := range entities {
+		windows = append(windows, entity.(*model.FreezeWindow))
+	}
+	return windows, nil
+}
+
+// isFreezeWindowActive check whether the time is in the one-off range or in a recurring window
+func isFreezeWindowActive(window *model.FreezeWindow, now time.Time) (bool, error) {
+	if window.Recurrence == "" {
+		if window.StartTime == nil || window.EndTime == nil || !window.EndTime.After(*window.StartTime) {
+			return false, fmt.Errorf("the start time and the end time are required for the one-off window")
+		}
+		return !window.Disabled && !now.Before(*window.StartTime) && now.Before(*window.EndTime), nil
+	}
+	duration, err := time.ParseDuration(window.Duration)
+	if err != nil || duration <= 0 {
+		return false, fmt.Errorf("the duration %q is invalid", window.Duration)
+	}
+	location := time.UTC
+	if window.Timezone != "" {
+		if location, err = time.LoadLocation(window.Timezone); err != nil {
+			return false, err
+		}
+	}
+	schedule, err := parseRecurrence(window.Recurrence)
+	if err != nil {
+		return false, err
+	}
+	if window.Disabled {
+		return false, nil
+	}
+	// the window is active if it starts in the last duration
+	start := schedule.Next(now.In(location).Add(-duration))
+	return !start.After(now), nil
+}
+
+var rruleWeekdays = map[string]string{"SU": "0", "MO": "1", "TU": "2", "WE": "3", "TH": "4", "FR": "5", "SA": "6"}
+
+// parseRecurrence parse the cron spec, or convert the RRULE to the cron spec.
+// The RRULE supports FREQ(DAILY, WEEKLY, MONTHLY and YEARLY), BYDAY, BYMONTHDAY, BYMONTH, BYHOUR and BYMINUTE.
+func parseRecurrence(recurrence string) (cron.Schedule, error) {
+	if !strings.HasPrefix(recurrence, "RRULE:") {
+		return cron.ParseStandard(recurrence)
+	}
+	rule := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(recurrence, "RRULE:"), ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rule %q", part)
+		}
+		rule[strings.ToUpper(kv[0])] = strings.ToUpper(kv[1])
+	}
+	field := func(key, defaultValue string) string {
+		if value, exist := rule[key]; exist {
+			return value
+		}
+		return defaultValue
+	}
+	if interval := field("INTERVAL", "1"); interval != "1" {
+		return nil, fmt.Errorf("the interval %s is not supported", interval)
+	}
+	var weekdays []string
+	if byDay, exist := rule["BYDAY"]; exist {
+		for _, day := range strings.Split(byDay, ",") {
+			weekday, exist := rruleWeekdays[day]
+			if !exist {
+				return nil, fmt.Errorf("invalid weekday %q", day)
+			}
+			weekdays = append(weekdays, weekday)
+		}
+	}
+	minute, hour := field("BYMINUTE", "0"), field("BYHOUR", "0")
+	dayOfMonth, month, dayOfWeek := "*", "*", "*"
+	if len(weekdays) > 0 {
+		dayOfWeek = strings.Join(weekdays, ",")
+	}
+	switch rule["FREQ"] {
+	case "DAILY":
+	case "WEEKLY":
+		if len(weekdays) == 0 {
+			return nil, fmt.Errorf("BYDAY is required for the weekly rule")
+		}
+	case "MONTHLY":
+		dayOfMonth = field("BYMONTHDAY", "1")
+	case "YEARLY":
+		dayOfMonth, month = field("BYMONTHDAY", "1"), field("BYMONTH", "1")
+	default:
+		return nil, fmt.Errorf("the frequency %q is not supported", rule["FREQ"])
+	}
+	for _, value := range []string{minute, hour, dayOfMonth, month} {
+		for _, item := range strings.Split(value, ",") {
+			if _, err := strconv.Atoi(item); err != nil && item != "*" {
+				return nil, fmt.Errorf("invalid value %q", item)
+			}
+		}
+	}
+	return cron.ParseStandard(strings.Join([]string{minute, hour, dayOfMonth, month, dayOfWeek}, " "))
+}
+
+func convertFreezeWindowModelToBase(window *model.FreezeWindow, now time.Time) *apisv1.FreezeWindowBase {
+	active, _ := isFreezeWindowActive(window, now)
+	return &apisv1.FreezeWindowBase{
+		Name:        window.Name,
+		Alias:       window.Alias,
+		Description: window.Description,
+		Project:     window.Project,
+		EnvName:     window.EnvName,
+		Recurrence:  window.Recurrence,
+		Duration:    window.Duration,
+		Timezone:    window.Timezone,
+		StartTime:   window.StartTime,
+		EndTime:     window.EndTime,
+		Disabled:    window.Disabled,
+		Creator:     window.Creator,
+		Active:      active,
+		CreateTime:  window.CreateTime,
+		UpdateTime:  window.UpdateTime,
+	}
+}
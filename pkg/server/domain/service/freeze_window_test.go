@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the freeze windows and the scheduled deploys", func() {
+	var (
+		freezeProject = "test-freeze-project"
+		freezeApp     = "test-freeze-app"
+		freezeEnv     = "test-freeze-prod"
+		freezeCtx     context.Context
+		freezeService *freezeWindowServiceImpl
+	)
+
+	It("Init services and data", func() {
+		freezeCtx = InitTestEnvWithAdmin("freeze-window-test-kubevela")
+		freezeService = &freezeWindowServiceImpl{Store: ds}
+		InitTestProject(freezeCtx, freezeProject, "test-freeze-target", freezeEnv)
+		InitTestApplication(freezeCtx, freezeProject, freezeApp, `{"image":"nginx"}`, freezeEnv)
+	})
+
+	It("Test checking whether the window is active", func() {
+		now := time.Date(2023, 6, 3, 12, 0, 0, 0, time.UTC)
+		// the weekend window starts at 00:00 on Saturday
+		weekend := &model.FreezeWindow{Recurrence: "RRULE:FREQ=WEEKLY;BYDAY=SA", Duration: "48h"}
+		active, err := isFreezeWindowActive(weekend, now)
+		Expect(err).Should(BeNil())
+		Expect(active).Should(BeTrue())
+		active, err = isFreezeWindowActive(weekend, now.Add(-24*time.Hour))
+		Expect(err).Should(BeNil())
+		Expect(active).Should(BeFalse())
+
+		nightly := &model.FreezeWindow{Recurrence: "0 20 * * *", Duration: "2h", Timezone: "Asia/Shanghai"}
+		active, err = isFreezeWindowActive(nightly, now)
+		Expect(err).Should(BeNil())
+		Expect(active).Should(BeTrue())
+
+		start, end := now.Add(-time.Hour), now.Add(time.Hour)
+		active, err = isFreezeWindowActive(&model.FreezeWindow{StartTime: &start, EndTime: &end}, now)
+		Expect(err).Should(BeNil())
+		Expect(active).Should(BeTrue())
+		active, err = isFreezeWindowActive(&model.FreezeWindow{StartTime: &start, EndTime: &end, Disabled: true}, now)
+		Expect(err).Should(BeNil())
+		Expect(active).Should(BeFalse())
+
+		_, err = isFreezeWindowActive(&model.FreezeWindow{StartTime: &end, EndTime: &start}, now)
+		Expect(err).ShouldNot(BeNil())
+		_, err = isFreezeWindowActive(&model.FreezeWindow{Recurrence: "RRULE:FREQ=WEEKLY", Duration: "1h"}, now)
+		Expect(err).ShouldNot(BeNil())
+		_, err = isFreezeWindowActive(&model.FreezeWindow{Recurrence: "0 0 * * *"}, now)
+		Expect(err).ShouldNot(BeNil())
+	})
+
+	It("Test blocking the deploys by the freeze window", func() {
+		start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+		_, err := freezeService.CreateFreezeWindow(freezeCtx, "", apisv1.CreateFreezeWindowRequest{
+			Name: "test-freeze", UpdateFreezeWindowRequest: apisv1.UpdateFreezeWindowRequest{EnvName: freezeEnv, StartTime: &start, EndTime: &end}})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrFreezeWindowInvalid))
+		window, err := freezeService.CreateFreezeWindow(freezeCtx, freezeProject, apisv1.CreateFreezeWindowRequest{
+			Name: "test-freeze", UpdateFreezeWindowRequest: apisv1.UpdateFreezeWindowRequest{EnvName: freezeEnv, StartTime: &start, EndTime: &end}})
+		Expect(err).Should(BeNil())
+		Expect(window.Active).Should(BeTrue())
+
+		app, err := appService.GetApplication(freezeCtx, freezeApp)
+		Expect(err).Should(BeNil())
+		_, err = appService.Deploy(freezeCtx, app, apisv1.ApplicationDeployRequest{WorkflowName: repository.ConvertWorkflowName(freezeEnv), TriggerType: apisv1.TriggerTypeWeb})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrDeployFrozen))
+		// the admin has the override permission
+		_, err = appService.Deploy(freezeCtx, app, apisv1.ApplicationDeployRequest{WorkflowName: repository.ConvertWorkflowName(freezeEnv), TriggerType: apisv1.TriggerTypeWeb, OverrideFreeze: true})
+		Expect(err).Should(BeNil())
+
+		// the platform window could not be overridden by the project permission
+		Expect(checkFreezeWindows(freezeCtx, ds, projectFreezeRBAC{}, freezeProject, freezeEnv, true)).Should(BeNil())
+		_, err = freezeService.CreateFreezeWindow(freezeCtx, "", apisv1.CreateFreezeWindowRequest{
+			Name: "test-platform-freeze", UpdateFreezeWindowRequest: apisv1.UpdateFreezeWindowRequest{StartTime: &start, EndTime: &end}})
+		Expect(err).Should(BeNil())
+		Expect(checkFreezeWindows(freezeCtx, ds, projectFreezeRBAC{}, freezeProject, freezeEnv, true)).Should(BeEquivalentTo(bcode.ErrDeployFrozen))
+		Expect(freezeService.DeleteFreezeWindow(freezeCtx, "", "test-platform-freeze")).Should(BeNil())
+
+		// the platform window does not share the record with the window of the project
+		_, err = freezeService.CreateFreezeWindow(freezeCtx, "", apisv1.CreateFreezeWindowRequest{
+			Name: freezeProject + "-test-freeze", UpdateFreezeWindowRequest: apisv1.UpdateFreezeWindowRequest{StartTime: &start, EndTime: &end}})
+		Expect(err).Should(BeNil())
+		_, err = freezeService.UpdateFreezeWindow(freezeCtx, freezeProject, "test-freeze", apisv1.UpdateFreezeWindowRequest{EnvName: freezeEnv, StartTime: &start, EndTime: &end, Disabled: true})
+		Expect(err).Should(BeNil())
+		platformWindow, err := freezeService.getFreezeWindow(freezeCtx, "", freezeProject+"-test-freeze")
+		Expect(err).Should(BeNil())
+		Expect(platformWindow.Disabled).Should(BeFalse())
+		Expect(freezeService.DeleteFreezeWindow(freezeCtx, "", freezeProject+"-test-freeze")).Should(BeNil())
+
+		Expect(freezeService.DeleteFreezeWindow(freezeCtx, freezeProject, "test-freeze")).Should(BeNil())
+	})
+
+	It("Test scheduling the deploys", func() {
+		app, err := appService.GetApplication(freezeCtx, freezeApp)
+		Expect(err).Should(BeNil())
+		scheduleTime := time.Now().Add(time.Hour)
+		res, err := appService.Deploy(freezeCtx, app, apisv1.ApplicationDeployRequest{
+			WorkflowName: repository.ConvertWorkflowName(freezeEnv), TriggerType: apisv1.TriggerTypeWeb, ScheduleTime: &scheduleTime})
+		Expect(err).Should(BeNil())
+		Expect(res.Status).Should(BeEquivalentTo(model.ScheduledDeployStatusScheduled))
+		revisions, err := appService.ListRevisions(freezeCtx, freezeApp, freezeEnv, "", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(revisions.Revisions[0].Version).Should(BeEquivalentTo(res.Version))
+		Expect(revisions.Revisions[0].ScheduleTime).ShouldNot(BeNil())
+		// the scheduled deploy takes the first item of the pages
+		firstPage, err := appService.ListRevisions(freezeCtx, freezeApp, freezeEnv, "", 1, 1)
+		Expect(err).Should(BeNil())
+		Expect(len(firstPage.Revisions)).Should(Equal(1))
+		Expect(firstPage.Revisions[0].Version).Should(BeEquivalentTo(res.Version))
+		Expect(firstPage.Total).Should(BeEquivalentTo(revisions.Total))
+		secondPage, err := appService.ListRevisions(freezeCtx, freezeApp, freezeEnv, "", 2, 1)
+		Expect(err).Should(BeNil())
+		Expect(len(secondPage.Revisions)).Should(Equal(1))
+		Expect(secondPage.Revisions[0].Version).Should(BeEquivalentTo(revisions.Revisions[1].Version))
+
+		Expect(appService.CancelScheduledDeploy(freezeCtx, app, res.Version)).Should(BeNil())
+		Expect(appService.CancelScheduledDeploy(freezeCtx, app, res.Version)).Should(BeEquivalentTo(bcode.ErrScheduledDeployNotPending))
+
+		res, err = appService.Deploy(freezeCtx, app, apisv1.ApplicationDeployRequest{
+			WorkflowName: repository.ConvertWorkflowName(freezeEnv), TriggerType: apisv1.TriggerTypeWeb, ScheduleTime: &scheduleTime, Force: true})
+		Expect(err).Should(BeNil())
+		scheduled := &model.ScheduledDeploy{AppPrimaryKey: app.PrimaryKey(), Name: res.Version}
+		Expect(ds.Get(freezeCtx, scheduled)).Should(BeNil())
+		scheduled.ScheduleTime = time.Now().Add(-time.Minute)
+		Expect(ds.Put(freezeCtx, scheduled)).Should(BeNil())
+
+		Expect(appService.ExecuteScheduledDeploys(context.TODO())).Should(BeNil())
+		Expect(ds.Get(freezeCtx, scheduled)).Should(BeNil())
+		Expect(scheduled.Status).Should(BeEquivalentTo(model.ScheduledDeployStatusExecuted))
+		revision, err := appService.DetailRevision(freezeCtx, freezeApp, scheduled.Revision)
+		Expect(err).Should(BeNil())
+		Expect(revision.DeployUser.Name).Should(BeEquivalentTo(FakeAdminName))
+	})
+})
+
+// projectFreezeRBAC only grants the permission of overriding the project freeze windows
+type projectFreezeRBAC struct {
+	RBACService
+}
+
+func (projectFreezeRBAC) CheckProjectPerm(ctx context.Context, projectName, resource string, actions ...string) error {
+	if resource == "project/freezeWindow" {
+		return nil
+	}
+	return bcode.ErrForbidden
+}
@@ -0,0 +1,569 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/kubevela/pkg/util/slices"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// notificationMaxAttempts the delivery is failed after the attempts
+	notificationMaxAttempts = 5
+	// notificationRetryInterval the interval before the first retry, it is doubled after each attempt
+	notificationRetryInterval = 30 * time.Second
+)
+
+const defaultNotificationTemplate = `[{{.Project}}] {{.Type}}` +
+	`{{if .AppName}} application: {{.AppName}}{{end}}{{if .EnvName}} env: {{.EnvName}}{{end}}` +
+	`{{if .PipelineName}} pipeline: {{.PipelineName}} run: {{.PipelineRunName}}{{end}}` +
+	`{{if .Status}} status: {{.Status}}{{end}}{{if .User}} by {{.User}}{{end}}{{if .Message}}, {{.Message}}{{end}}`
+
+// NotificationEvent the event that could be sent to the channels, the fields could be used in the message template
+type NotificationEvent struct {
+	Type            string            `json:"type"`
+	Project         string            `json:"project"`
+	AppName         string            `json:"appName,omitempty"`
+	AppAlias        string            `json:"appAlias,omitempty"`
+	AppLabels       map[string]string `json:"appLabels,omitempty"`
+	EnvName         string            `json:"envName,omitempty"`
+	WorkflowName    string            `json:"workflowName,omitempty"`
+	RecordName      string            `json:"recordName,omitempty"`
+	Revision        string            `json:"revision,omitempty"`
+	PipelineName    string            `json:"pipelineName,omitempty"`
+	PipelineRunName string            `json:"pipelineRunName,omitempty"`
+	Status          string            `json:"status,omitempty"`
+	Message         string            `json:"message,omitempty"`
+	User            string            `json:"user,omitempty"`
+	Time            time.Time         `json:"time"`
+}
+
+// NotificationService manage the notification channels and the subscriptions of the project, and deliver the notifications
+type NotificationService interface {
+	ListNotificationChannels(ctx context.Context, projectName string) (*apisv1.ListNotificationChannelsResponse, error)
+	CreateNotificationChannel(ctx context.Context, projectName string, req apisv1.CreateNotificationChannelRequest) (*apisv1.NotificationChannelBase, error)
+	UpdateNotificationChannel(ctx context.Context, projectName, name string, req apisv1.UpdateNotificationChannelRequest) (*apisv1.NotificationChannelBase, error)
+	DeleteNotificationChannel(ctx context.Context, projectName, name string) error
+	ListNotificationSubscriptions(ctx context.Context, projectName string) (*apisv1.ListNotificationSubscriptionsResponse, error)
+	CreateNotificationSubscription(ctx context.Context, projectName string, req apisv1.CreateNotificationSubscriptionRequest) (*apisv1.NotificationSubscriptionBase, error)
+	UpdateNotificationSubscription(ctx context.Context, projectName, name string, req apisv1.UpdateNotificationSubscriptionRequest) (*apisv1.NotificationSubscriptionBase, error)
+	DeleteNotificationSubscription(ctx context.Context, projectName, name string) error
+	ListNotificationDeliveries(ctx context.Context, projectName, subscription, status string, page, pageSize int) (*apisv1.ListNotificationDeliveriesResponse, error)
+	// DeliverNotifications send the pending notifications, the failed deliveries are retried with backoff
+	DeliverNotifications(ctx context.Context) error
+	// PurgeExpiredDeliveries remove the finished deliveries that are older than the retention
+	PurgeExpiredDeliveries(ctx context.Context) error
+	Init(ctx context.Context) error
+}
+
+type notificationServiceImpl struct {
+	Store    datastore.DataStore `inject:"datastore"`
+	EventBus EventBus            `inject:""`
+	// Retention the finished deliveries are purged after the period
+	Retention time.Duration
+}
+
+// NewNotificationService new notification service
+func NewNotificationService(retention time.Duration) NotificationService {
+	return &notificationServiceImpl{Retention: retention}
+}
+
+// Init subscribe the domain events that could be notified
+func (n *notificationServiceImpl) Init(_ context.Context) error {
+	n.EventBus.Subscribe("notification", n.handleDomainEvent, model.EventAppDeployed, model.EventAppDeployFailed,
+		model.EventWorkflowRecordStatusChanged, model.EventPipelineRunStatusChanged)
+	return nil
+}
+
+func (n *notificationServiceImpl) ListNotificationChannels(ctx context.Context, projectName string) (*apisv1.ListNotificationChannelsResponse, error) {
+	entities, err := n.Store.List(ctx, &model.NotificationChannel{Project: projectName}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListNotificationChannelsResponse{Channels: []*apisv1.NotificationChannelBase{}}
+	for _, entity := range entities {
+		res.Channels = append(res.Channels, convertNotificationChannelModelToBase(entity.(*model.NotificationChannel)))
+	}
+	return res, nil
+}
+
+func (n *notificationServiceImpl) CreateNotificationChannel(ctx context.Context, projectName string, req apisv1.CreateNotificationChannelRequest) (*apisv1.NotificationChannelBase, error) {
+	channel := &model.NotificationChannel{Name: req.Name, Project: projectName}
+	if err := n.Store.Get(ctx, channel); err == nil {
+		return nil, bcode.ErrNotificationChannelExist
+	}
+	channel.Creator, _ = ctx.Value(&apisv1.CtxKeyUser).(string)
+	if err := setNotificationChannel(channel, req.UpdateNotificationChannelRequest); err != nil {
+		return nil, err
+	}
+	if err := n.Store.Add(ctx, channel); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrNotificationChannelExist
+		}
+		return nil, err
+	}
+	return convertNotificationChannelModelToBase(channel), nil
+}
+
+func (n *notificationServiceImpl) UpdateNotificationChannel(ctx context.Context, projectName, name string, req apisv1.UpdateNotificationChannelRequest) (*apisv1.NotificationChannelBase, error) {
+	channel, err := getNotificationChannel(ctx, n.Store, projectName, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := setNotificationChannel(channel, req); err != nil {
+		return nil, err
+	}
+	if err := n.Store.Put(ctx, channel); err != nil {
+		return nil, err
+	}
+	return convertNotificationChannelModelToBase(channel), nil
+}
+
+func (n *notificationServiceImpl) DeleteNotificationChannel(ctx context.Context, projectName, name string) error {
+	channel, err := getNotificationChannel(ctx, n.Store, projectName, name)
+	if err != nil {
+		return err
+	}
+	count, err := n.Store.Count(ctx, &model.NotificationSubscription{Project: projectName, Channel: name}, nil)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return bcode.ErrNotificationChannelInUse
+	}
+	return n.Store.Delete(ctx, channel)
+}
+
+func (n *notificationServiceImpl) ListNotificationSubscriptions(ctx context.Context, projectName string) (*apisv1.ListNotificationSubscriptionsResponse, error) {
+	subscriptions, err := listNotificationSubscriptions(ctx, n.Store, projectName)
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListNotificationSubscriptionsResponse{Subscriptions: []*apisv1.NotificationSubscriptionBase{}}
+	for _, subscription := range subscriptions {
+		res.Subscriptions = append(res.Subscriptions, convertNotificationSubscriptionModelToBase(subscription))
+	}
+	return res, nil
+}
+
+func (n *notificationServiceImpl) CreateNotificationSubscription(ctx context.Context, projectName string, req apisv1.CreateNotificationSubscriptionRequest) (*apisv1.NotificationSubscriptionBase, error) {
+	subscription := &model.NotificationSubscription{Name: req.Name, Project: projectName}
+	if err := n.Store.Get(ctx, subscription); err == nil {
+		return nil, bcode.ErrNotificationSubscriptionExist
+	}
+	subscription.Creator, _ = ctx.Value(&apisv1.CtxKeyUser).(string)
+	if err := n.setNotificationSubscription(ctx, subscription, req.UpdateNotificationSubscriptionRequest); err != nil {
+		return nil, err
+	}
+	if err := n.Store.Add(ctx, subscription); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrNotificationSubscriptionExist
+		}
+		return nil, err
+	}
+	return convertNotificationSubscriptionModelToBase(subscription), nil
+}
+
+func (n *notificationServiceImpl) UpdateNotificationSubscription(ctx context.Context, projectName, name string, req apisv1.UpdateNotificationSubscriptionRequest) (*apisv1.NotificationSubscriptionBase, error) {
+	subscription, err := n.getNotificationSubscription(ctx, projectName, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.setNotificationSubscription(ctx, subscription, req); err != nil {
+		return nil, err
+	}
+	if err := n.Store.Put(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return convertNotificationSubscriptionModelToBase(subscription), nil
+}
+
+func (n *notificationServiceImpl) DeleteNotificationSubscription(ctx context.Context, projectName, name string) error {
+	subscription, err := n.getNotificationSubscription(ctx, projectName, name)
+	if err != nil {
+		return err
+	}
+	return n.Store.Delete(ctx, subscription)
+}
+
+func (n *notificationServiceImpl) ListNotificationDeliveries(ctx context.Context, projectName, subscription, status string, page, pageSize int) (*apisv1.ListNotificationDeliveriesResponse, error) {
+	delivery := &model.NotificationDelivery{Project: projectName, Subscription: subscription, Status: status}
+	entities, err := n.Store.List(ctx, delivery, &datastore.ListOptions{
+		Page:     page,
+		PageSize: pageSize,
+		SortBy:   []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &apisv1.ListNotificationDeliveriesResponse{Deliveries: []*apisv1.NotificationDeliveryBase{}}
+	for _, entity := range entities {
+		res.Deliveries = append(res.Deliveries, convertNotificationDeliveryModelToBase(entity.(*model.NotificationDelivery)))
+	}
+	count, err := n.Store.Count(ctx, delivery, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Total = count
+	return res, nil
+}
+
+func (n *notificationServiceImpl) DeliverNotifications(ctx context.Context) error {
+	entities, err := n.Store.List(ctx, &model.NotificationDelivery{Status: model.NotificationDeliveryPending}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}},
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entity := range entities {
+		delivery := entity.(*model.NotificationDelivery)
+		if delivery.NextTime.After(now) {
+			continue
+		}
+		deliverNotification(ctx, n.Store, delivery)
+	}
+	return nil
+}
+
+// PurgeExpiredDeliveries remove the succeeded and failed deliveries that are older than the retention, the pending ones are kept
+func (n *notificationServiceImpl) PurgeExpiredDeliveries(ctx context.Context) error {
+	if n.Retention <= 0 {
+		return nil
+	}
+	for _, status := range []string{model.NotificationDeliverySucceeded, model.NotificationDeliveryFailed} {
+		entities, err := n.Store.List(ctx, &model.NotificationDelivery{Status: status}, &datastore.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, entity := range entities {
+			delivery := entity.(*model.NotificationDelivery)
+			if time.Since(delivery.CreateTime) < n.Retention {
+				continue
+			}
+			if err := n.Store.Delete(ctx, delivery); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+				klog.Errorf("failed to delete the notification delivery %s: %s", delivery.Name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// handleDomainEvent convert the domain event to the notification event and emit it
+func (n *notificationServiceImpl) handleDomainEvent(ctx context.Context, event *model.DomainEvent) error {
+	var data model.StatusChangedData
+	if event.Data != "" {
+		if err := event.DecodeData(&data); err != nil {
+			return err
+		}
+	}
+	notification := NotificationEvent{
+		Project:         event.Project,
+		AppName:         data.AppName,
+		AppAlias:        data.AppAlias,
+		AppLabels:       data.AppLabels,
+		EnvName:         data.EnvName,
+		WorkflowName:    data.WorkflowName,
+		RecordName:      data.RecordName,
+		Revision:        data.Revision,
+		PipelineName:    data.PipelineName,
+		PipelineRunName: data.PipelineRunName,
+		Status:          data.To,
+		Message:         data.Message,
+		User:            event.User,
+		Time:            event.Time,
+	}
+	switch event.Type {
+	case model.EventAppDeployed:
+		notification.Type = model.NotificationEventDeploy
+	case model.EventAppDeployFailed:
+		notification.Type = model.NotificationEventDeployFailed
+	case model.EventWorkflowRecordStatusChanged:
+		notification.Type = "workflow." + data.To
+	case model.EventPipelineRunStatusChanged:
+		notification.Type = "pipeline." + data.To
+	}
+	if !slices.Contains(model.NotificationEventTypes, notification.Type) {
+		return nil
+	}
+	emitNotification(ctx, n.Store, notification)
+	return nil
+}
+
+func (n *notificationServiceImpl) getNotificationSubscription(ctx context.Context, projectName, name string) (*model.NotificationSubscription, error) {
+	subscription := &model.NotificationSubscription{Name: name, Project: projectName}
+	if err := n.Store.Get(ctx, subscription); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrNotificationSubscriptionNotExist
+		}
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (n *notificationServiceImpl) setNotificationSubscription(ctx context.Context, subscription *model.NotificationSubscription, req apisv1.UpdateNotificationSubscriptionRequest) error {
+	if _, err := getNotificationChannel(ctx, n.Store, subscription.Project, req.Channel); err != nil {
+		return err
+	}
+	for _, eventType := range req.EventTypes {
+		if !slices.Contains(model.NotificationEventTypes, eventType) {
+			return bcode.ErrNotificationEventTypeInvalid
+		}
+	}
+	if _, err := template.New(subscription.Name).Parse(req.Template); err != nil {
+		return bcode.ErrNotificationTemplateInvalid
+	}
+	subscription.Alias = req.Alias
+	subscription.Description = req.Description
+	subscription.Channel = req.Channel
+	subscription.EventTypes = req.EventTypes
+	subscription.EnvNames = req.EnvNames
+	subscription.AppSelector = req.AppSelector
+	subscription.Template = req.Template
+	subscription.Disabled = req.Disabled
+	return nil
+}
+
+func getNotificationChannel(ctx context.Context, store datastore.DataStore, projectName, name string) (*model.NotificationChannel, error) {
+	channel := &model.NotificationChannel{Name: name, Project: projectName}
+	if err := store.Get(ctx, channel); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrNotificationChannelNotExist
+		}
+		return nil, err
+	}
+	return channel, nil
+}
+
+func setNotificationChannel(channel *model.NotificationChannel, req apisv1.UpdateNotificationChannelRequest) error {
+	if req.Type == model.NotificationChannelEmail {
+		if req.Email == nil || req.Email.Host == "" || req.Email.Port == 0 || req.Email.From == "" || len(req.Email.To) == 0 {
+			return bcode.ErrNotificationChannelInvalid
+		}
+	} else if req.URL == "" {
+		return bcode.ErrNotificationChannelInvalid
+	}
+	// keep the existing secret and password if they are not changed
+	if req.Secret == "" && req.Type == channel.Type {
+		req.Secret = channel.Secret
+	}
+	if req.Email != nil && req.Email.Password == "" && channel.Email != nil && channel.Email.Host == req.Email.Host {
+		req.Email.Password = channel.Email.Password
+	}
+	channel.Alias = req.Alias
+	channel.Description = req.Description
+	channel.Type = req.Type
+	channel.URL = req.URL
+	channel.Secret = req.Secret
+	channel.Headers = req.Headers
+	channel.Email = req.Email
+	return nil
+}
+
+func listNotificationSubscriptions(ctx context.Context, store datastore.DataStore, projectName string) ([]*model.NotificationSubscription, error) {
+	entities, err := store.List(ctx, &model.NotificationSubscription{Project: projectName}, &datastore.ListOptions{
+		SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var subscriptions []*model.NotificationSubscription
+	for _, entity := range entities {
+		subscriptions = append(subscriptions, entity.(*model.NotificationSubscription))
+	}
+	return subscriptions, nil
+}
+
+func matchNotificationSubscription(subscription *model.NotificationSubscription, event NotificationEvent) bool {
+	if subscription.Disabled {
+		return false
+	}
+	if len(subscription.EventTypes) > 0 && !slices.Contains(subscription.EventTypes, event.Type) {
+		return false
+	}
+	if len(subscription.EnvNames) > 0 && !slices.Contains(subscription.EnvNames, event.EnvName) {
+		return false
+	}
+	for key, value := range subscription.AppSelector {
+		if event.AppName == "" || event.AppLabels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func renderNotificationMessage(subscription *model.NotificationSubscription, event NotificationEvent) (string, error) {
+	text := subscription.Template
+	if text == "" {
+		text = defaultNotificationTemplate
+	}
+	tmpl, err := template.New(subscription.Name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, event); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// emitNotification create the pending deliveries for the subscriptions that match the event.
+// The errors are logged only, the notification must not break the business process.
+func emitNotification(ctx context.Context, store datastore.DataStore, event NotificationEvent) {
+	if store == nil || event.Project == "" {
+		return
+	}
+	subscriptions, err := listNotificationSubscriptions(ctx, store, event.Project)
+	if err != nil {
+		klog.Errorf("failed to list the notification subscriptions of the project %s: %s", event.Project, err.Error())
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		klog.Errorf("failed to marshal the notification event: %s", err.Error())
+		return
+	}
+	for _, subscription := range subscriptions {
+		if !matchNotificationSubscription(subscription, event) {
+			continue
+		}
+		delivery := &model.NotificationDelivery{
+			Name:         fmt.Sprintf("%s-%s", utils.GenerateVersion(subscription.Name), rand.String(4)),
+			Project:      event.Project,
+			Subscription: subscription.Name,
+			Channel:      subscription.Channel,
+			EventType:    event.Type,
+			Event:        string(body),
+			Status:       model.NotificationDeliveryPending,
+			NextTime:     event.Time,
+		}
+		message, err := renderNotificationMessage(subscription, event)
+		if err != nil {
+			delivery.Status = model.NotificationDeliveryFailed
+			delivery.Error = fmt.Sprintf("failed to render the message: %s", err.Error())
+		}
+		delivery.Message = message
+		if err := store.Add(ctx, delivery); err != nil {
+			klog.Errorf("failed to add the notification delivery of the subscription %s: %s", subscription.Name, err.Error())
+		}
+	}
+}
+
+// deliverNotification send the message to the channel and record the result
+func deliverNotification(ctx context.Context, store datastore.DataStore, delivery *model.NotificationDelivery) {
+	delivery.Attempts++
+	channel, err := getNotificationChannel(ctx, store, delivery.Project, delivery.Channel)
+	if err == nil {
+		err = sendNotification(ctx, channel, delivery)
+	}
+	now := time.Now()
+	switch {
+	case err == nil:
+		delivery.Status = model.NotificationDeliverySucceeded
+		delivery.Error = ""
+		delivery.DeliverTime = &now
+	case delivery.Attempts >= notificationMaxAttempts || errors.Is(err, bcode.ErrNotificationChannelNotExist):
+		delivery.Status = model.NotificationDeliveryFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Error = err.Error()
+		delivery.NextTime = now.Add(notificationRetryInterval << (delivery.Attempts - 1))
+	}
+	if err != nil {
+		klog.Warningf("failed to deliver the notification %s (attempt %d): %s", delivery.Name, delivery.Attempts, err.Error())
+	}
+	if err := store.Put(ctx, delivery); err != nil {
+		klog.Errorf("failed to update the notification delivery %s: %s", delivery.Name, err.Error())
+	}
+}
+
+func convertNotificationChannelModelToBase(channel *model.NotificationChannel) *apisv1.NotificationChannelBase {
+	base := &apisv1.NotificationChannelBase{
+		Name:        channel.Name,
+		Alias:       channel.Alias,
+		Description: channel.Description,
+		Project:     channel.Project,
+		Type:        channel.Type,
+		URL:         channel.URL,
+		HasSecret:   channel.Secret != "",
+		Headers:     channel.Headers,
+		Creator:     channel.Creator,
+		CreateTime:  channel.CreateTime,
+		UpdateTime:  channel.UpdateTime,
+	}
+	if channel.Email != nil {
+		email := *channel.Email
+		email.Password = ""
+		base.Email = &email
+	}
+	return base
+}
+
+func convertNotificationSubscriptionModelToBase(subscription *model.NotificationSubscription) *apisv1.NotificationSubscriptionBase {
+	return &apisv1.NotificationSubscriptionBase{
+		Name:        subscription.Name,
+		Alias:       subscription.Alias,
+		Description: subscription.Description,
+		Project:     subscription.Project,
+		Channel:     subscription.Channel,
+		EventTypes:  subscription.EventTypes,
+		EnvNames:    subscription.EnvNames,
+		AppSelector: subscription.AppSelector,
+		Template:    subscription.Template,
+		Disabled:    subscription.Disabled,
+		Creator:     subscription.Creator,
+		CreateTime:  subscription.CreateTime,
+		UpdateTime:  subscription.UpdateTime,
+	}
+}
+
+func convertNotificationDeliveryModelToBase(delivery *model.NotificationDelivery) *apisv1.NotificationDeliveryBase {
+	return &apisv1.NotificationDeliveryBase{
+		Name:         delivery.Name,
+		Subscription: delivery.Subscription,
+		Channel:      delivery.Channel,
+		EventType:    delivery.EventType,
+		Message:      delivery.Message,
+		Status:       delivery.Status,
+		Attempts:     delivery.Attempts,
+		NextTime:     delivery.NextTime,
+		Error:        delivery.Error,
+		DeliverTime:  delivery.DeliverTime,
+		CreateTime:   delivery.CreateTime,
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+)
+
+const (
+	// HeaderNotificationSignature the HMAC-SHA256 signature of the webhook body, the format is sha256=<hex>
+	HeaderNotificationSignature = "X-VelaUX-Signature"
+	// HeaderNotificationEvent the type of the event
+	HeaderNotificationEvent = "X-VelaUX-Event"
+	// HeaderNotificationDelivery the name of the delivery, the retries use the same name
+	HeaderNotificationDelivery = "X-VelaUX-Delivery"
+)
+
+var notificationHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// webhookNotification the body of the generic webhook
+type webhookNotification struct {
+	Event   json.RawMessage `json:"event"`
+	Message string          `json:"message"`
+}
+
+// robotResponse the common response of the DingTalk, Lark and WeCom robots
+type robotResponse struct {
+	ErrCode *int   `json:"errcode,omitempty"`
+	ErrMsg  string `json:"errmsg,omitempty"`
+	Code    *int   `json:"code,omitempty"`
+	Msg     string `json:"msg,omitempty"`
+}
+
+func sendNotification(ctx context.Context, channel *model.NotificationChannel, delivery *model.NotificationDelivery) error {
+	switch channel.Type {
+	case model.NotificationChannelWebhook:
+		body, err := json.Marshal(webhookNotification{Event: json.RawMessage(delivery.Event), Message: delivery.Message})
+		if err != nil {
+			return err
+		}
+		headers := map[string]string{
+			HeaderNotificationEvent:    delivery.EventType,
+			HeaderNotificationDelivery: delivery.Name,
+		}
+		if channel.Secret != "" {
+			headers[HeaderNotificationSignature] = "sha256=" + signNotification(channel.Secret, body)
+		}
+		_, err = postNotification(ctx, channel, channel.URL, body, headers)
+		return err
+	case model.NotificationChannelSlack:
+		return postRobotNotification(ctx, channel, channel.URL, map[string]interface{}{"text": delivery.Message})
+	case model.NotificationChannelDingTalk:
+		address := channel.URL
+		if channel.Secret != "" {
+			timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+			mac := hmac.New(sha256.New, []byte(channel.Secret))
+			mac.Write([]byte(timestamp + "\n" + channel.Secret))
+			sign := url.QueryEscape(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+			address = fmt.Sprintf("%s&timestamp=%s&sign=%s", address, timestamp, sign)
+		}
+		return postRobotNotification(ctx, channel, address, map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": delivery.Message},
+		})
+	case model.NotificationChannelLark:
+		body := map[string]interface{}{
+			"msg_type": "text",
+			"content":  map[string]string{"text": delivery.Message},
+		}
+		if channel.Secret != "" {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			mac := hmac.New(sha256.New, []byte(timestamp+"\n"+channel.Secret))
+			body["timestamp"] = timestamp
+			body["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		}
+		return postRobotNotification(ctx, channel, channel.URL, body)
+	case model.NotificationChannelWeCom:
+		return postRobotNotification(ctx, channel, channel.URL, map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": delivery.Message},
+		})
+	case model.NotificationChannelEmail:
+		return sendEmailNotification(channel.Email, delivery)
+	default:
+		return fmt.Errorf("the channel type %s is not supported", channel.Type)
+	}
+}
+
+// signNotification return the hex HMAC-SHA256 of the body
+func signNotification(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func postRobotNotification(ctx context.Context, channel *model.NotificationChannel, address string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resBody, err := postNotification(ctx, channel, address, data, nil)
+	if err != nil {
+		return err
+	}
+	// the robots return 200 with the error code in the body
+	var res robotResponse
+	if err := json.Unmarshal(resBody, &res); err != nil {
+		return nil
+	}
+	if res.ErrCode != nil && *res.ErrCode != 0 {
+		return fmt.Errorf("the robot returns the error %d: %s", *res.ErrCode, res.ErrMsg)
+	}
+	if res.Code != nil && *res.Code != 0 {
+		return fmt.Errorf("the robot returns the error %d: %s", *res.Code, res.Msg)
+	}
+	return nil
+}
+
+func postNotification(ctx context.Context, channel *model.NotificationChannel, address string, body []byte, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range channel.Headers {
+		req.Header.Set(key, value)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	res, err := notificationHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, fmt.Errorf("the server returns the status code %d: %s", res.StatusCode, string(resBody))
+	}
+	return resBody, nil
+}
+
+func sendEmailNotification(email *model.EmailChannel, delivery *model.NotificationDelivery) error {
+	if email == nil {
+		return fmt.Errorf("the email configuration is required")
+	}
+	var auth smtp.Auth
+	if email.Username != "" {
+		auth = smtp.PlainAuth("", email.Username, email.Password, email.Host)
+	}
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("From: %s\r\n", email.From))
+	message.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(email.To, ",")))
+	message.WriteString(fmt.Sprintf("Subject: [KubeVela] %s\r\n", delivery.EventType))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	message.WriteString(delivery.Message)
+	address := net.JoinHostPort(email.Host, strconv.Itoa(email.Port))
+	return smtp.SendMail(address, auth, email.From, email.To, []byte(message.String()))
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test the notifications", func() {
+	var (
+		notifyProject = "test-notify-project"
+		notifyCtx     context.Context
+		notifyService *notificationServiceImpl
+		server        *httptest.Server
+		mutex         sync.Mutex
+		bodies        [][]byte
+		signatures    []string
+		statusCode    = http.StatusOK
+	)
+
+	It("Init services and data", func() {
+		notifyCtx = InitTestEnvWithAdmin("notification-test-kubevela")
+		notifyService = &notificationServiceImpl{Store: ds}
+		InitTestProject(notifyCtx, notifyProject, "")
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, body)
+			signatures = append(signatures, r.Header.Get(HeaderNotificationSignature))
+			w.WriteHeader(statusCode)
+		}))
+	})
+
+	It("Test managing the channels and the subscriptions", func() {
+		_, err := notifyService.CreateNotificationChannel(notifyCtx, notifyProject, apisv1.CreateNotificationChannelRequest{
+			Name: "invalid", UpdateNotificationChannelRequest: apisv1.UpdateNotificationChannelRequest{Type: model.NotificationChannelSlack}})
+		Expect(err).Should(BeEquivalentTo(bcode.ErrNotificationChannelInvalid))
+
+		channel, err := notifyService.CreateNotificationChannel(notifyCtx, notifyProject, apisv1.CreateNotificationChannelRequest{
+			Name: "hook", UpdateNotificationChannelRequest: apisv1.UpdateNotificationChannelRequest{
+				Type: model.NotificationChannelWebhook, URL: server.URL, Secret: "secret"}})
+		Expect(err).Should(BeNil())
+		Expect(channel.HasSecret).Should(BeTrue())
+		// the secret is kept if it is not changed
+		channel, err = notifyService.UpdateNotificationChannel(notifyCtx, notifyProject, "hook", apisv1.UpdateNotificationChannelRequest{
+			Type: model.NotificationChannelWebhook, URL: server.URL, Alias: "Hook"})
+		Expect(err).Should(BeNil())
+		Expect(channel.HasSecret).Should(BeTrue())
+
+		subscription := apisv1.CreateNotificationSubscriptionRequest{Name: "deploy", UpdateNotificationSubscriptionRequest: apisv1.UpdateNotificationSubscriptionRequest{
+			Channel: "not-exist"}}
+		_, err = notifyService.CreateNotificationSubscription(notifyCtx, notifyProject, subscription)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrNotificationChannelNotExist))
+		subscription.Channel = "hook"
+		subscription.EventTypes = []string{"unknown"}
+		_, err = notifyService.CreateNotificationSubscription(notifyCtx, notifyProject, subscription)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrNotificationEventTypeInvalid))
+		subscription.EventTypes = []string{model.NotificationEventDeploy}
+		subscription.Template = "{{.AppName"
+		_, err = notifyService.CreateNotificationSubscription(notifyCtx, notifyProject, subscription)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrNotificationTemplateInvalid))
+		subscription.Template = "{{.AppName}} is deployed to {{.EnvName}} by {{.User}}"
+		subscription.EnvNames = []string{"prod"}
+		subscription.AppSelector = map[string]string{"team": "a"}
+		_, err = notifyService.CreateNotificationSubscription(notifyCtx, notifyProject, subscription)
+		Expect(err).Should(BeNil())
+
+		Expect(notifyService.DeleteNotificationChannel(notifyCtx, notifyProject, "hook")).Should(BeEquivalentTo(bcode.ErrNotificationChannelInUse))
+		list, err := notifyService.ListNotificationSubscriptions(notifyCtx, notifyProject)
+		Expect(err).Should(BeNil())
+		Expect(len(list.Subscriptions)).Should(BeEquivalentTo(1))
+	})
+
+	It("Test emitting and delivering the notifications", func() {
+		event := NotificationEvent{Type: model.NotificationEventDeploy, Project: notifyProject, AppName: "web", AppLabels: map[string]string{"team": "a"}, EnvName: "prod", User: "admin"}
+		emitNotification(notifyCtx, ds, event)
+		// the events that do not match the subscription are ignored
+		emitNotification(notifyCtx, ds, NotificationEvent{Type: model.NotificationEventDeploy, Project: notifyProject, AppName: "web", EnvName: "prod"})
+		emitNotification(notifyCtx, ds, NotificationEvent{Type: model.NotificationEventDeployFailed, Project: notifyProject, AppName: "web", AppLabels: map[string]string{"team": "a"}, EnvName: "prod"})
+		emitNotification(notifyCtx, ds, NotificationEvent{Type: model.NotificationEventDeploy, Project: notifyProject, AppName: "web", AppLabels: map[string]string{"team": "a"}, EnvName: "dev"})
+
+		deliveries, err := notifyService.ListNotificationDeliveries(notifyCtx, notifyProject, "", "", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(deliveries.Total).Should(BeEquivalentTo(1))
+		Expect(deliveries.Deliveries[0].Message).Should(BeEquivalentTo("web is deployed to prod by admin"))
+		Expect(deliveries.Deliveries[0].Status).Should(BeEquivalentTo(model.NotificationDeliveryPending))
+
+		Expect(notifyService.DeliverNotifications(notifyCtx)).Should(BeNil())
+		deliveries, err = notifyService.ListNotificationDeliveries(notifyCtx, notifyProject, "deploy", model.NotificationDeliverySucceeded, 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(deliveries.Total).Should(BeEquivalentTo(1))
+		Expect(deliveries.Deliveries[0].Attempts).Should(BeEquivalentTo(1))
+
+		mutex.Lock()
+		Expect(len(bodies)).Should(BeEquivalentTo(1))
+		Expect(signatures[0]).Should(BeEquivalentTo("sha256=" + signNotification("secret", bodies[0])))
+		var body webhookNotification
+		Expect(json.Unmarshal(bodies[0], &body)).Should(BeNil())
+		Expect(body.Message).Should(BeEquivalentTo("web is deployed to prod by admin"))
+		statusCode = http.StatusInternalServerError
+		mutex.Unlock()
+
+		// the failed delivery is retried later
+		emitNotification(notifyCtx, ds, event)
+		Expect(notifyService.DeliverNotifications(notifyCtx)).Should(BeNil())
+		deliveries, err = notifyService.ListNotificationDeliveries(notifyCtx, notifyProject, "deploy", model.NotificationDeliveryPending, 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(deliveries.Total).Should(BeEquivalentTo(1))
+		Expect(deliveries.Deliveries[0].Attempts).Should(BeEquivalentTo(1))
+		Expect(deliveries.Deliveries[0].Error).ShouldNot(BeEmpty())
+		Expect(deliveries.Deliveries[0].NextTime.After(time.Now())).Should(BeTrue())
+
+		// the finished deliveries are purged after the retention, the pending ones are kept
+		Expect(notifyService.PurgeExpiredDeliveries(notifyCtx)).Should(BeNil())
+		deliveries, err = notifyService.ListNotificationDeliveries(notifyCtx, notifyProject, "", "", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(deliveries.Total).Should(BeEquivalentTo(2))
+		purgeService := &notificationServiceImpl{Store: ds, Retention: time.Nanosecond}
+		Expect(purgeService.PurgeExpiredDeliveries(notifyCtx)).Should(BeNil())
+		deliveries, err = notifyService.ListNotificationDeliveries(notifyCtx, notifyProject, "", "", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(deliveries.Total).Should(BeEquivalentTo(1))
+		Expect(deliveries.Deliveries[0].Status).Should(BeEquivalentTo(model.NotificationDeliveryPending))
+	})
+
+	It("Clean the server", func() {
+		server.Close()
+	})
+})
@@ -20,10 +20,12 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"hash/fnv"
 	"io"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -61,6 +63,15 @@ import (
 const (
 	labelContext  = "pipeline.oam.dev/context"
 	labelPipeline = "pipeline.oam.dev/name"
+	labelSchedule = "pipeline.oam.dev/schedule"
+	// annotationPipelineVersion records the version of the pipeline spec used by the run
+	annotationPipelineVersion = "pipeline.oam.dev/version"
+	// annotationPipelineParameters records the effective parameters of the run
+	annotationPipelineParameters = "pipeline.oam.dev/parameters"
+	// labelTrigger the name of the trigger that starts the run
+	labelTrigger = "pipeline.oam.dev/trigger"
+	// annotationTriggerChain the pipelines that lead to the run by the pipeline triggers
+	annotationTriggerChain = "pipeline.oam.dev/trigger-chain"
 )
 
 // PipelineService is the interface for pipeline service
@@ -70,7 +81,25 @@ type PipelineService interface {
 	GetPipeline(ctx context.Context, name string, getInfo bool) (*apis.GetPipelineResponse, error)
 	UpdatePipeline(ctx context.Context, name string, req apis.UpdatePipelineRequest) (*apis.PipelineBase, error)
 	DeletePipeline(ctx context.Context, base apis.PipelineBase) error
+	RestorePipeline(ctx context.Context, pipeline *model.Pipeline) error
+	PurgePipeline(ctx context.Context, base apis.PipelineBase) error
 	RunPipeline(ctx context.Context, pipeline apis.PipelineBase, req apis.RunPipelineRequest) (*apis.PipelineRun, error)
+	// ExecutePipelineSchedules run the pipelines whose cron schedules are due
+	ExecutePipelineSchedules(ctx context.Context) error
+	ListPipelineVersions(ctx context.Context, pipeline apis.PipelineBase) (*apis.ListPipelineVersionsResponse, error)
+	GetPipelineVersion(ctx context.Context, pipeline apis.PipelineBase, version int64) (*apis.PipelineVersionDetail, error)
+	ComparePipelineVersions(ctx context.Context, pipeline apis.PipelineBase, req apis.ComparePipelineVersionsRequest) (*apis.ComparePipelineVersionsResponse, error)
+	RestorePipelineVersion(ctx context.Context, pipeline apis.PipelineBase, version int64, req apis.RestorePipelineVersionRequest) (*apis.PipelineBase, error)
+	// ValidatePipelineSpec check the step definitions, properties, dependencies, inputs, conditions and timeouts of the spec
+	ValidatePipelineSpec(ctx context.Context, spec model.WorkflowSpec) (*apis.ValidatePipelineResponse, error)
+	ListPipelineTriggers(ctx context.Context, pipeline apis.PipelineBase) (*apis.ListPipelineTriggersResponse, error)
+	CreatePipelineTrigger(ctx context.Context, pipeline apis.PipelineBase, req apis.CreatePipelineTriggerRequest) (*apis.PipelineTriggerBase, error)
+	UpdatePipelineTrigger(ctx context.Context, pipeline apis.PipelineBase, name string, req apis.UpdatePipelineTriggerRequest) (*apis.PipelineTriggerBase, error)
+	DeletePipelineTrigger(ctx context.Context, pipeline apis.PipelineBase, name string) error
+	// RunPipelineByTrigger run the pipeline by the payload of the webhook request
+	RunPipelineByTrigger(ctx context.Context, trigger *model.PipelineTrigger, payload map[string]interface{}) (interface{}, error)
+	// ExecutePipelineTriggers run the pipelines whose source pipelines are completed
+	ExecutePipelineTriggers(ctx context.Context) error
 }
 
 type pipelineServiceImpl struct {
@@ -80,6 +109,8 @@ type pipelineServiceImpl struct {
 	KubeClient         client.Client       `inject:"kubeClient"`
 	KubeConfig         *rest.Config        `inject:"kubeConfig"`
 	PipelineRunService PipelineRunService  `inject:""`
+	RbacService        RBACService         `inject:""`
+	DefinitionService  DefinitionService   `inject:""`
 	Version            string
 }
 
@@ -94,6 +125,8 @@ type PipelineRunService interface {
 	GetPipelineRunLog(ctx context.Context, meta apis.PipelineRun, step string) (apis.GetPipelineRunLogResponse, error)
 	ResumePipelineRun(ctx context.Context, meta apis.PipelineRunMeta, step string) error
 	TerminatePipelineRun(ctx context.Context, meta apis.PipelineRunMeta) error
+	// SyncPipelineRunEvents publish the events of the phase changes of the pipeline runs
+	SyncPipelineRunEvents(ctx context.Context) error
 }
 
 type pipelineRunServiceImpl struct {
@@ -102,6 +135,7 @@ type pipelineRunServiceImpl struct {
 	KubeConfig     *rest.Config        `inject:"kubeConfig"`
 	ContextService ContextService      `inject:""`
 	ProjectService ProjectService      `inject:""`
+	EventBus       EventBus            `inject:""`
 }
 
 // ContextService is the interface for context service
@@ -116,7 +150,8 @@ type ContextService interface {
 }
 
 type contextServiceImpl struct {
-	Store datastore.DataStore `inject:"datastore"`
+	Store      datastore.DataStore `inject:"datastore"`
+	KubeClient client.Client       `inject:"kubeClient"`
 }
 
 // NewPipelineService new pipeline service
@@ -137,7 +172,15 @@ func NewContextService() ContextService {
 // CreatePipeline will create a pipeline
 func (p pipelineServiceImpl) CreatePipeline(ctx context.Context, req apis.CreatePipelineRequest) (*apis.PipelineBase, error) {
 	project := ctx.Value(&apis.CtxKeyProject).(*model.Project)
-	if err := checkPipelineSpec(req.Spec); err != nil {
+	if err := p.checkPipelineSpec(ctx, req.Spec); err != nil {
+		return nil, err
+	}
+	if err := checkPipelineParameters(req.Parameters); err != nil {
+		return nil, err
+	}
+	userName, _ := ctx.Value(&apis.CtxKeyUser).(string)
+	schedules, err := mergePipelineSchedules(nil, req.Schedules, userName, time.Now())
+	if err != nil {
 		return nil, err
 	}
 	pipeline := &model.Pipeline{
@@ -146,6 +189,9 @@ func (p pipelineServiceImpl) CreatePipeline(ctx context.Context, req apis.Create
 		Alias:       req.Alias,
 		Project:     project.Name,
 		Spec:        req.Spec,
+		Schedules:   schedules,
+		Parameters:  req.Parameters,
+		Version:     1,
 	}
 	if err := p.Store.Add(ctx, pipeline); err != nil {
 		if errors.Is(err, datastore.ErrRecordExist) {
@@ -153,6 +199,17 @@ func (p pipelineServiceImpl) CreatePipeline(ctx context.Context, req apis.Create
 		}
 		return nil, err
 	}
+	if err := p.addPipelineVersion(ctx, &model.PipelineVersion{
+		Project:      project.Name,
+		PipelineName: pipeline.Name,
+		Version:      pipeline.Version,
+		Spec:         pipeline.Spec,
+		Parameters:   pipeline.Parameters,
+		Author:       userName,
+		Message:      req.Message,
+	}); err != nil {
+		return nil, err
+	}
 	return &apis.PipelineBase{
 		PipelineMeta: apis.PipelineMeta{
 			Name:  req.Name,
@@ -163,7 +220,10 @@ func (p pipelineServiceImpl) CreatePipeline(ctx context.Context, req apis.Create
 			},
 			Description: req.Description,
 		},
-		Spec: pipeline.Spec,
+		Spec:       pipeline.Spec,
+		Schedules:  convertPipelineSchedules(pipeline.Schedules),
+		Version:    pipeline.Version,
+		Parameters: pipeline.Parameters,
 	}, nil
 }
 
@@ -216,7 +276,7 @@ func (p pipelineServiceImpl) ListPipelines(ctx context.Context, req apis.ListPip
 	res := apis.ListPipelineResponse{}
 	for _, _p := range pipelines {
 		pipeline := _p.(*model.Pipeline)
-		if !slices.Contains(availableProjectNames, pipeline.Project) {
+		if pipeline.IsDeleted() || !slices.Contains(availableProjectNames, pipeline.Project) {
 			continue
 		}
 		if fuzzyMatch(pipeline, req.Query) {
@@ -249,14 +309,8 @@ func (p pipelineServiceImpl) ListPipelines(ctx context.Context, req apis.ListPip
 // GetPipeline will get a pipeline
 func (p pipelineServiceImpl) GetPipeline(ctx context.Context, name string, getInfo bool) (*apis.GetPipelineResponse, error) {
 	project := ctx.Value(&apis.CtxKeyProject).(*model.Project)
-	pipeline := &model.Pipeline{
-		Name:    name,
-		Project: project.Name,
-	}
-	if err := p.Store.Get(ctx, pipeline); err != nil {
-		if errors.Is(err, datastore.ErrRecordNotExist) {
-			return nil, bcode.ErrPipelineNotExist
-		}
+	pipeline, err := getPipeline(ctx, p.Store, project.Name, name)
+	if err != nil {
 		return nil, err
 	}
 	base := pipeline2PipelineBase(pipeline, *project)
@@ -281,21 +335,32 @@ func (p pipelineServiceImpl) GetPipeline(ctx context.Context, name string, getIn
 // UpdatePipeline will update a pipeline
 func (p pipelineServiceImpl) UpdatePipeline(ctx context.Context, name string, req apis.UpdatePipelineRequest) (*apis.PipelineBase, error) {
 	project := ctx.Value(&apis.CtxKeyProject).(*model.Project)
-	if err := checkPipelineSpec(req.Spec); err != nil {
+	if err := p.checkPipelineSpec(ctx, req.Spec); err != nil {
 		return nil, err
 	}
-	pipeline := &model.Pipeline{
-		Name:    name,
-		Project: project.Name,
+	pipeline, err := getPipeline(ctx, p.Store, project.Name, name)
+	if err != nil {
+		return nil, err
 	}
-	if err := p.Store.Get(ctx, pipeline); err != nil {
-		if errors.Is(err, datastore.ErrRecordNotExist) {
-			return nil, bcode.ErrPipelineNotExist
+
+	if req.Schedules != nil {
+		userName, _ := ctx.Value(&apis.CtxKeyUser).(string)
+		schedules, err := mergePipelineSchedules(pipeline.Schedules, req.Schedules, userName, time.Now())
+		if err != nil {
+			return nil, err
 		}
+		pipeline.Schedules = schedules
+	}
+	parameters := pipeline.Parameters
+	if req.Parameters != nil {
+		if err := checkPipelineParameters(req.Parameters); err != nil {
+			return nil, err
+		}
+		parameters = req.Parameters
+	}
+	if err := p.recordPipelineVersion(ctx, pipeline, req.Spec, parameters, req.Message, 0); err != nil {
 		return nil, err
 	}
-
-	pipeline.Spec = req.Spec
 	pipeline.Description = req.Description
 	pipeline.Alias = req.Alias
 
@@ -305,19 +370,31 @@ func (p pipelineServiceImpl) UpdatePipeline(ctx context.Context, name string, re
 	return pipeline2PipelineBase(pipeline, *project), nil
 }
 
-// DeletePipeline will delete a pipeline
+// DeletePipeline will move a pipeline into the recycle bin
 func (p pipelineServiceImpl) DeletePipeline(ctx context.Context, pl apis.PipelineBase) error {
+	project := ctx.Value(&apis.CtxKeyProject).(*model.Project)
+	pipeline, err := getPipeline(ctx, p.Store, project.Name, pl.Name)
+	if err != nil {
+		return err
+	}
+	userName, _ := ctx.Value(&apis.CtxKeyUser).(string)
+	pipeline.MarkDeleted(userName, time.Now())
+	return p.Store.Put(ctx, pipeline)
+}
+
+// RestorePipeline will restore a pipeline from the recycle bin
+func (p pipelineServiceImpl) RestorePipeline(ctx context.Context, pipeline *model.Pipeline) error {
+	pipeline.ClearDeleted()
+	return p.Store.Put(ctx, pipeline)
+}
+
+// PurgePipeline will delete a pipeline and its runs and contexts permanently
+func (p pipelineServiceImpl) PurgePipeline(ctx context.Context, pl apis.PipelineBase) error {
 	project := ctx.Value(&apis.CtxKeyProject).(*model.Project)
 	pipeline := &model.Pipeline{
 		Name:    pl.Name,
 		Project: project.Name,
 	}
-	if err := p.Store.Get(ctx, pipeline); err != nil {
-		if errors.Is(err, datastore.ErrRecordNotExist) {
-			return bcode.ErrPipelineNotExist
-		}
-		return err
-	}
 	// Clean up pipeline: 1. delete pipeline runs 2. delete contexts 3. delete pipeline
 	if err := p.PipelineRunService.CleanPipelineRuns(ctx, pl); err != nil {
 		klog.Errorf("delete pipeline all pipeline-runs failure: %s", err.Error())
@@ -327,6 +404,14 @@ func (p pipelineServiceImpl) DeletePipeline(ctx context.Context, pl apis.Pipelin
 		klog.Errorf("delete pipeline all context failure: %s", err.Error())
 		return err
 	}
+	if err := p.deletePipelineVersions(ctx, project.Name, pl.Name); err != nil {
+		klog.Errorf("delete pipeline all versions failure: %s", err.Error())
+		return err
+	}
+	if err := p.deletePipelineTriggers(ctx, project.Name, pl.Name); err != nil {
+		klog.Errorf("delete pipeline all triggers failure: %s", err.Error())
+		return err
+	}
 	return p.Store.Delete(ctx, pipeline)
 }
 
@@ -377,6 +462,15 @@ func (p pipelineRunServiceImpl) GetPipelineRunOutput(ctx context.Context, pipeli
 			break
 		}
 	}
+	secretValues, err := p.getRunSecretValues(ctx, pipelineRun)
+	if err != nil {
+		return apis.GetPipelineRunOutputResponse{}, err
+	}
+	for i := range stepOutputs {
+		for j := range stepOutputs[i].Values {
+			stepOutputs[i].Values[j].Value = maskSecretValues(stepOutputs[i].Values[j].Value, secretValues)
+		}
+	}
 	return apis.GetPipelineRunOutputResponse{StepOutputs: stepOutputs}, nil
 }
 
@@ -409,7 +503,7 @@ func (p pipelineRunServiceImpl) GetPipelineRunInput(ctx context.Context, pipelin
 	}
 	ctxBackend := pipelineRun.Status.ContextBackend
 	if ctxBackend == nil {
-		return apis.GetPipelineRunInputResponse{}, nil
+		return apis.GetPipelineRunInputResponse{Parameters: pipelineRun.Parameters}, nil
 	}
 	v, err := wfUtils.GetDataFromContext(ctx, p.KubeClient, ctxBackend.Name, pipelineRun.PipelineRunName, ctxBackend.Namespace)
 	if err != nil {
@@ -439,7 +533,22 @@ func (p pipelineRunServiceImpl) GetPipelineRunInput(ctx context.Context, pipelin
 			break
 		}
 	}
-	return apis.GetPipelineRunInputResponse{StepInputs: stepInputs}, nil
+	secretValues, err := p.getRunSecretValues(ctx, pipelineRun)
+	if err != nil {
+		return apis.GetPipelineRunInputResponse{}, err
+	}
+	for i := range stepInputs {
+		for j := range stepInputs[i].Values {
+			stepInputs[i].Values[j].Value = maskSecretValues(stepInputs[i].Values[j].Value, secretValues)
+		}
+	}
+	return apis.GetPipelineRunInputResponse{StepInputs: stepInputs, Parameters: pipelineRun.Parameters}, nil
+}
+
+// getRunSecretValues return the secret values of the context used by the run, they must not be returned in plain text
+func (p pipelineRunServiceImpl) getRunSecretValues(ctx context.Context, pipelineRun apis.PipelineRun) ([]string, error) {
+	project := ctx.Value(&apis.CtxKeyProject).(*model.Project)
+	return getContextSecretValues(ctx, p.KubeClient, project.GetNamespace(), pipelineRun.PipelineName, pipelineRun.ContextName)
 }
 
 func haveSubSteps(step v1alpha1.WorkflowStepStatus, subStep string) (*v1alpha1.StepStatus, bool) {
@@ -519,6 +628,11 @@ func (p pipelineRunServiceImpl) GetPipelineRunLog(ctx context.Context, pipelineR
 			logs = logsBuilder.String()
 		}
 	}
+	secretValues, err := p.getRunSecretValues(ctx, pipelineRun)
+	if err != nil {
+		return apis.GetPipelineRunLogResponse{}, err
+	}
+	logs = maskSecretValues(logs, secretValues)
 	return apis.GetPipelineRunLogResponse{
 		StepBase: getStepBase(pipelineRun, step),
 		Log:      logs,
@@ -753,10 +867,33 @@ func pipelineSpec2WorkflowSpec(spec model.WorkflowSpec) *v1alpha1.WorkflowSpec {
 
 // RunPipeline will run a pipeline
 func (p pipelineServiceImpl) RunPipeline(ctx context.Context, pipeline apis.PipelineBase, req apis.RunPipelineRequest) (*apis.PipelineRun, error) {
+	return p.runPipeline(ctx, pipeline, req, runOptions{})
+}
+
+// runOptions the extra settings of the runs started by the schedules and the triggers
+type runOptions struct {
+	labels      map[string]string
+	annotations map[string]string
+	// context the values are merged over the context values, the parameters are merged over them
+	context map[string]interface{}
+}
+
+// runPipeline create the workflow run of the pipeline
+func (p pipelineServiceImpl) runPipeline(ctx context.Context, pipeline apis.PipelineBase, req apis.RunPipelineRequest, opts runOptions) (*apis.PipelineRun, error) {
 	if err := checkRunMode(&req.Mode); err != nil {
 		return nil, err
 	}
+	// the step definitions may be changed after the pipeline is saved
+	if err := p.checkPipelineSpec(ctx, pipeline.Spec); err != nil {
+		return nil, err
+	}
 	project := ctx.Value(&apis.CtxKeyProject).(*model.Project)
+	if err := checkFreezeWindows(ctx, p.Store, p.RbacService, project.Name, "", req.OverrideFreeze); err != nil {
+		return nil, err
+	}
+	if err := checkPipelineProtectedEnvs(ctx, p.Store, project.GetNamespace(), pipeline.Spec); err != nil {
+		return nil, err
+	}
 	run := v1alpha1.WorkflowRun{}
 	version := utils.GenerateVersion("")
 	name := fmt.Sprintf("%s-%s", pipeline.Name, version)
@@ -770,22 +907,57 @@ func (p pipelineServiceImpl) RunPipeline(ctx context.Context, pipeline apis.Pipe
 		labelPipeline:                pipeline.Name,
 		velatypes.LabelSourceOfTruth: velatypes.FromUX,
 	})
+	for k, v := range opts.labels {
+		run.Labels[k] = v
+	}
+	for k, v := range opts.annotations {
+		if err := k8s.AddAnnotation(&run, k, v); err != nil {
+			return nil, err
+		}
+	}
 	if p.Version != "" {
 		if err := k8s.AddAnnotation(&run, wfTypes.AnnotationControllerRequirement, p.Version); err != nil {
 			return nil, err
 		}
 	}
+	if pipeline.Version != 0 {
+		if err := k8s.AddAnnotation(&run, annotationPipelineVersion, strconv.FormatInt(pipeline.Version, 10)); err != nil {
+			return nil, err
+		}
+	}
 	// process the context
+	contextData := make(map[string]interface{})
+	var contextValues []model.Value
 	if req.ContextName != "" {
 		ppContext, err := p.ContextService.GetContext(ctx, pipeline.Project.Name, pipeline.Name, req.ContextName)
 		if err != nil {
 			return nil, err
 		}
-		contextData := make(map[string]interface{})
-		for _, pair := range ppContext.Values {
-			contextData[pair.Key] = pair.Value
-		}
 		run.Labels[labelContext] = req.ContextName
+		contextData = contextValues2RunContext(pipeline.Name, req.ContextName, ppContext.Values)
+		contextValues = ppContext.Values
+	}
+	for k, v := range opts.context {
+		contextData[k] = v
+	}
+	// the parameters are merged over the context
+	parameters, parameterData, err := p.resolveRunParameters(ctx, run.Namespace, pipeline.Parameters, req.Parameters, contextValues)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range parameterData {
+		contextData[k] = v
+	}
+	if len(parameters) > 0 {
+		content, err := json.Marshal(parameters)
+		if err != nil {
+			return nil, err
+		}
+		if err := k8s.AddAnnotation(&run, annotationPipelineParameters, string(content)); err != nil {
+			return nil, err
+		}
+	}
+	if len(contextData) > 0 {
 		run.Spec.Context = util.Object2RawExtension(contextData)
 	}
 
@@ -936,7 +1108,7 @@ func (p pipelineRunServiceImpl) GetPipelineRun(ctx context.Context, meta apis.Pi
 			}
 			return nil, err
 		}
-		run.Spec.WorkflowSpec = pipelineSpec2WorkflowSpec(pipeline.Spec)
+		run.Spec.WorkflowSpec = pipelineSpec2WorkflowSpec(getRunPipelineSpec(ctx, p.Store, run, pipeline))
 
 	}
 	return workflowRun2PipelineRun(run, project, p.ContextService)
@@ -1016,7 +1188,7 @@ func (c contextServiceImpl) GetContext(ctx context.Context, projectName, pipelin
 	if !ok {
 		return nil, bcode.ErrContextNotFound
 	}
-	return &apis.Context{Name: name, Values: vals}, nil
+	return &apis.Context{Name: name, Values: maskContextValues(vals)}, nil
 }
 
 // CreateContext will create a context
@@ -1039,7 +1211,11 @@ func (c contextServiceImpl) CreateContext(ctx context.Context, projectName, pipe
 		klog.Errorf("context %s already exists", pkgutils.Sanitize(context.Name))
 		return nil, bcode.ErrContextAlreadyExist
 	}
-	modelCtx.Contexts[context.Name] = context.Values
+	values, err := c.saveContextSecrets(ctx, projectName, pipelineName, context.Name, context.Values, nil)
+	if err != nil {
+		return nil, err
+	}
+	modelCtx.Contexts[context.Name] = values
 	if err := c.Store.Put(ctx, modelCtx); err != nil {
 		return nil, err
 	}
@@ -1055,7 +1231,11 @@ func (c contextServiceImpl) UpdateContext(ctx context.Context, projectName, pipe
 	if err := c.Store.Get(ctx, &modelCtx); err != nil {
 		return nil, err
 	}
-	modelCtx.Contexts[context.Name] = context.Values
+	values, err := c.saveContextSecrets(ctx, projectName, pipelineName, context.Name, context.Values, modelCtx.Contexts[context.Name])
+	if err != nil {
+		return nil, err
+	}
+	modelCtx.Contexts[context.Name] = values
 	if err := c.Store.Put(ctx, &modelCtx); err != nil {
 		return nil, err
 	}
@@ -1077,9 +1257,13 @@ func (c contextServiceImpl) ListContexts(ctx context.Context, projectName, pipel
 		}
 		return nil, err
 	}
+	contexts := make(map[string][]model.Value, len(modelCtx.Contexts))
+	for name, values := range modelCtx.Contexts {
+		contexts[name] = maskContextValues(values)
+	}
 	return &apis.ListContextValueResponse{
 		Total:    len(modelCtx.Contexts),
-		Contexts: modelCtx.Contexts,
+		Contexts: contexts,
 	}, nil
 }
 
@@ -1092,6 +1276,11 @@ func (c contextServiceImpl) DeleteContext(ctx context.Context, projectName, pipe
 	if err := c.Store.Get(ctx, &modelCtx); err != nil {
 		return err
 	}
+	if hasSecretValue(modelCtx.Contexts[name]) {
+		if err := c.deleteContextSecrets(ctx, projectName, pipelineName, name); err != nil {
+			return err
+		}
+	}
 	delete(modelCtx.Contexts, name)
 	return c.Store.Put(ctx, &modelCtx)
 }
@@ -1102,9 +1291,36 @@ func (c contextServiceImpl) DeleteAllContexts(ctx context.Context, projectName,
 		ProjectName:  projectName,
 		PipelineName: pipelineName,
 	}
+	if err := c.Store.Get(ctx, &modelCtx); err == nil {
+		for _, values := range modelCtx.Contexts {
+			if hasSecretValue(values) {
+				if err := c.deleteContextSecrets(ctx, projectName, pipelineName, ""); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
 	return c.Store.Delete(ctx, &modelCtx)
 }
 
+func getPipeline(ctx context.Context, ds datastore.DataStore, projectName, name string) (*model.Pipeline, error) {
+	pipeline := &model.Pipeline{
+		Name:    name,
+		Project: projectName,
+	}
+	if err := ds.Get(ctx, pipeline); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrPipelineNotExist
+		}
+		return nil, err
+	}
+	if pipeline.IsDeleted() {
+		return nil, bcode.ErrPipelineNotExist
+	}
+	return pipeline, nil
+}
+
 func fuzzyMatch(wf *model.Pipeline, q string) bool {
 	if strings.Contains(wf.Name, q) {
 		return true
@@ -1130,7 +1346,10 @@ func pipeline2PipelineBase(wf *model.Pipeline, project model.Project) *apis.Pipe
 			Alias:       wf.Alias,
 			CreateTime:  wf.CreateTime,
 		},
-		Spec: wf.Spec,
+		Spec:       wf.Spec,
+		Schedules:  convertPipelineSchedules(wf.Schedules),
+		Version:    wf.Version,
+		Parameters: wf.Parameters,
 	}
 }
 
@@ -1147,7 +1366,9 @@ func workflowRun2PipelineRun(run v1alpha1.WorkflowRun, project *model.Project, c
 				},
 				PipelineRunName: run.Name,
 			},
-			Spec: run.Spec,
+			PipelineVersion: getRunPipelineVersion(run),
+			Parameters:      getRunParameters(run),
+			Spec:            run.Spec,
 		},
 		Status: run.Status,
 	}
@@ -1161,6 +1382,8 @@ func workflowRun2PipelineRun(run v1alpha1.WorkflowRun, project *model.Project, c
 			pipelineRun.PipelineRunBase.ContextName = ctxName
 			pipelineRun.PipelineRunBase.ContextValues = ctx.Values
 		}
+		pipelineRun.PipelineRunBase.Schedule = labels[labelSchedule]
+		pipelineRun.PipelineRunBase.Trigger = labels[labelTrigger]
 	}
 	return pipelineRun, nil
 }
@@ -1233,6 +1456,10 @@ func (p pipelineRunServiceImpl) workflowRun2runBriefing(ctx context.Context, run
 		Message:         run.Status.Message,
 		StartTime:       run.Status.StartTime,
 		EndTime:         run.Status.EndTime,
+		Schedule:        run.Labels[labelSchedule],
+		Trigger:         run.Labels[labelTrigger],
+		PipelineVersion: getRunPipelineVersion(run),
+		Parameters:      getRunParameters(run),
 	}
 	if apiContext != nil {
 		briefing.ContextName = apiContext.Name
@@ -1274,10 +1501,6 @@ func (p pipelineRunServiceImpl) TerminatePipelineRun(ctx context.Context, meta a
 	return wfUtils.TerminateWorkflow(ctx, p.KubeClient, &run)
 }
 
-func checkPipelineSpec(_ model.WorkflowSpec) error {
-	return nil
-}
-
 func checkRunMode(mode *v1alpha1.WorkflowExecuteMode) error {
 	if mode.Steps == "" {
 		mode.Steps = v1alpha1.WorkflowModeStep
@@ -1295,7 +1518,7 @@ func checkRunMode(mode *v1alpha1.WorkflowExecuteMode) error {
 // NewTestPipelineService create the pipeline service instance for testing
 func NewTestPipelineService(ds datastore.DataStore, c client.Client, cfg *rest.Config) PipelineService {
 	projectService := NewTestProjectService(ds, c)
-	contextService := NewTestContextService(ds)
+	contextService := NewTestContextService(ds, c)
 	ppRunService := NewTestPipelineRunService(ds, c, cfg)
 	pipelineService := &pipelineServiceImpl{
 		ProjectService:     projectService,
@@ -1303,6 +1526,7 @@ func NewTestPipelineService(ds datastore.DataStore, c client.Client, cfg *rest.C
 		KubeClient:         c,
 		KubeConfig:         cfg,
 		PipelineRunService: ppRunService,
+		DefinitionService:  &definitionServiceImpl{KubeClient: c},
 		Store:              ds,
 	}
 	return pipelineService
@@ -1310,7 +1534,7 @@ func NewTestPipelineService(ds datastore.DataStore, c client.Client, cfg *rest.C
 
 // NewTestPipelineRunService create the pipeline run service instance for testing
 func NewTestPipelineRunService(ds datastore.DataStore, c client.Client, cfg *rest.Config) PipelineRunService {
-	contextService := NewTestContextService(ds)
+	contextService := NewTestContextService(ds, c)
 	projectService := NewTestProjectService(ds, c)
 	return &pipelineRunServiceImpl{
 		KubeClient:     c,
@@ -1321,8 +1545,9 @@ func NewTestPipelineRunService(ds datastore.DataStore, c client.Client, cfg *res
 }
 
 // NewTestContextService create the context service instance for testing
-func NewTestContextService(ds datastore.DataStore) ContextService {
+func NewTestContextService(ds datastore.DataStore, c client.Client) ContextService {
 	return &contextServiceImpl{
-		Store: ds,
+		Store:      ds,
+		KubeClient: c,
 	}
 }
@@ -0,0 +1,229 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// maskedContextValue replaces the secret values in the responses, the logs and the step inputs
+	maskedContextValue = "******"
+	// contextSecretRefKey the secret value is injected into the run context as the reference to the secret key
+	contextSecretRefKey = "secretKeyRef"
+)
+
+// contextSecretKeyRegexp the context name and the key are joined with a dot in the secret, so neither of them could contain it
+var contextSecretKeyRegexp = regexp.MustCompile(`^[-_a-zA-Z0-9]+$`)
+
+// contextSecretName the secret in the project namespace stores the secret values of all contexts of the pipeline
+func contextSecretName(pipelineName string) string {
+	return fmt.Sprintf("%s-context-secret", pipelineName)
+}
+
+func contextSecretKey(contextName, key string) string {
+	return contextName + "." + key
+}
+
+// saveContextSecrets store the secret values of the context in the secret, the returned values do not contain the secret values.
+// The empty or masked secret value keeps the stored value, so the values read from the API could be submitted again.
+func (c contextServiceImpl) saveContextSecrets(ctx context.Context, projectName, pipelineName, contextName string, values, previous []model.Value) ([]model.Value, error) {
+	var hasSecret bool
+	res := make([]model.Value, 0, len(values))
+	for _, value := range values {
+		switch value.Type {
+		case "", model.ContextValueTypePlain:
+		case model.ContextValueTypeSecret:
+			if !contextSecretKeyRegexp.MatchString(contextName) {
+				return nil, bcode.ErrContextValueInvalid.SetMessage(fmt.Sprintf("the name of the context %s with the secret values must consist of alphanumeric characters, '-' or '_'", contextName))
+			}
+			if !contextSecretKeyRegexp.MatchString(value.Key) {
+				return nil, bcode.ErrContextValueInvalid.SetMessage(fmt.Sprintf("the key %s of the secret value must consist of alphanumeric characters, '-' or '_'", value.Key))
+			}
+			hasSecret = true
+		default:
+			return nil, bcode.ErrContextValueInvalid.SetMessage(fmt.Sprintf("the value type %s is not supported", value.Type))
+		}
+	}
+	for _, value := range previous {
+		hasSecret = hasSecret || value.Type == model.ContextValueTypeSecret
+	}
+	if !hasSecret {
+		return values, nil
+	}
+
+	namespace, err := c.getProjectNamespace(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{}
+	exist := true
+	if err := c.KubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: contextSecretName(pipelineName)}, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		exist = false
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      contextSecretName(pipelineName),
+				Namespace: namespace,
+				Labels:    map[string]string{labelPipeline: pipelineName},
+			},
+		}
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	keys := map[string]bool{}
+	for _, value := range values {
+		if value.Type != model.ContextValueTypeSecret {
+			res = append(res, value)
+			continue
+		}
+		key := contextSecretKey(contextName, value.Key)
+		keys[key] = true
+		if value.Value != "" && value.Value != maskedContextValue {
+			secret.Data[key] = []byte(value.Value)
+		} else if _, stored := secret.Data[key]; !stored {
+			return nil, bcode.ErrContextValueInvalid.SetMessage(fmt.Sprintf("the value of the secret %s is required", value.Key))
+		}
+		res = append(res, model.Value{Key: value.Key, Type: model.ContextValueTypeSecret})
+	}
+	for key := range secret.Data {
+		if strings.HasPrefix(key, contextName+".") && !keys[key] {
+			delete(secret.Data, key)
+		}
+	}
+	if exist {
+		err = c.KubeClient.Update(ctx, secret)
+	} else {
+		err = c.KubeClient.Create(ctx, secret)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// deleteContextSecrets delete the secret values of the context, all secret values of the pipeline are deleted if the context name is empty
+func (c contextServiceImpl) deleteContextSecrets(ctx context.Context, projectName, pipelineName, contextName string) error {
+	namespace, err := c.getProjectNamespace(ctx, projectName)
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{}
+	if err := c.KubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: contextSecretName(pipelineName)}, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if contextName == "" {
+		return client.IgnoreNotFound(c.KubeClient.Delete(ctx, secret))
+	}
+	for key := range secret.Data {
+		if strings.HasPrefix(key, contextName+".") {
+			delete(secret.Data, key)
+		}
+	}
+	return c.KubeClient.Update(ctx, secret)
+}
+
+func (c contextServiceImpl) getProjectNamespace(ctx context.Context, projectName string) (string, error) {
+	project := &model.Project{Name: projectName}
+	if err := c.Store.Get(ctx, project); err != nil {
+		return "", err
+	}
+	return project.GetNamespace(), nil
+}
+
+func hasSecretValue(values []model.Value) bool {
+	for _, value := range values {
+		if value.Type == model.ContextValueTypeSecret {
+			return true
+		}
+	}
+	return false
+}
+
+// maskContextValues replace the secret values with the mask
+func maskContextValues(values []model.Value) []model.Value {
+	res := make([]model.Value, 0, len(values))
+	for _, value := range values {
+		if value.Type == model.ContextValueTypeSecret {
+			value.Value = maskedContextValue
+		}
+		res = append(res, value)
+	}
+	return res
+}
+
+// contextValues2RunContext convert the context values to the run context, the secret values are injected as the references of the secret keys
+func contextValues2RunContext(pipelineName, contextName string, values []model.Value) map[string]interface{} {
+	contextData := make(map[string]interface{})
+	for _, pair := range values {
+		if pair.Type == model.ContextValueTypeSecret {
+			contextData[pair.Key] = map[string]interface{}{
+				contextSecretRefKey: map[string]interface{}{
+					"name": contextSecretName(pipelineName),
+					"key":  contextSecretKey(contextName, pair.Key),
+				},
+			}
+			continue
+		}
+		contextData[pair.Key] = pair.Value
+	}
+	return contextData
+}
+
+// getContextSecretValues return the secret values of the context, they are masked in the logs, the inputs and the outputs of the run
+func getContextSecretValues(ctx context.Context, cli client.Client, namespace, pipelineName, contextName string) ([]string, error) {
+	if contextName == "" {
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: contextSecretName(pipelineName)}, secret); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	var secretValues []string
+	for key, value := range secret.Data {
+		if strings.HasPrefix(key, contextName+".") && len(value) > 0 {
+			secretValues = append(secretValues, string(value))
+		}
+	}
+	// replace the longer values first, the value may contain another one
+	sort.Slice(secretValues, func(i, j int) bool {
+		return len(secretValues[i]) > len(secretValues[j])
+	})
+	return secretValues, nil
+}
+
+func maskSecretValues(content string, secretValues []string) string {
+	for _, secretValue := range secretValues {
+		content = strings.ReplaceAll(content, secretValue, maskedContextValue)
+	}
+	return content
+}
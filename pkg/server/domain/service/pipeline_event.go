@@ -0,0 +1,88 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/kubevela/workflow/api/v1alpha1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+)
+
+// annotationPublishedPhase records the last phase of the pipeline run that is published
+const annotationPublishedPhase = "pipeline.oam.dev/published-phase"
+
+func (p pipelineRunServiceImpl) SyncPipelineRunEvents(ctx context.Context) error {
+	entities, err := p.Store.List(ctx, &model.Project{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	projects := map[string]string{}
+	for _, entity := range entities {
+		project := entity.(*model.Project)
+		projects[project.GetNamespace()] = project.Name
+	}
+	runs := v1alpha1.WorkflowRunList{}
+	if err := p.KubeClient.List(ctx, &runs, client.HasLabels{labelPipeline}); err != nil {
+		return err
+	}
+	for i := range runs.Items {
+		run := &runs.Items[i]
+		projectName, exist := projects[run.Namespace]
+		if !exist {
+			continue
+		}
+		if err := p.syncPipelineRunEvent(ctx, projectName, run); err != nil {
+			klog.Errorf("failed to sync the event of the pipeline run %s: %s", run.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func (p pipelineRunServiceImpl) syncPipelineRunEvent(ctx context.Context, projectName string, run *v1alpha1.WorkflowRun) error {
+	phase := string(run.Status.Phase)
+	published := run.GetAnnotations()[annotationPublishedPhase]
+	if phase == "" || phase == string(v1alpha1.WorkflowStateInitializing) || phase == published {
+		return nil
+	}
+	origin := run.DeepCopy()
+	if run.Annotations == nil {
+		run.Annotations = map[string]string{}
+	}
+	run.Annotations[annotationPublishedPhase] = phase
+	if err := p.KubeClient.Patch(ctx, run, client.MergeFrom(origin)); err != nil {
+		return err
+	}
+	// the runs that finished before the upgrade are not published
+	if published == "" && run.Status.Finished && time.Since(run.Status.EndTime.Time) > 10*time.Minute {
+		return nil
+	}
+	data := model.StatusChangedData{
+		PipelineName:    run.Labels[labelPipeline],
+		PipelineRunName: run.Name,
+		From:            published,
+		To:              phase,
+	}
+	data.Message = run.Status.Message
+	publishEvent(ctx, p.EventBus, newDomainEvent(model.EventPipelineRunStatusChanged, projectName, "project/pipeline", data))
+	return nil
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kubevela/workflow/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// the parameters are referenced as `context.<name>` by the step definitions, so the name must be a valid identifier
+var parameterNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// checkPipelineParameters check the declarations and the default values of the parameters
+func checkPipelineParameters(parameters []model.PipelineParameter) error {
+	names := map[string]bool{}
+	for _, parameter := range parameters {
+		if !parameterNameRegexp.MatchString(parameter.Name) {
+			return bcode.ErrPipelineParameterInvalid.SetMessage(fmt.Sprintf("the parameter name %s must start with a letter or '_' and consist of alphanumeric characters or '_'", parameter.Name))
+		}
+		if names[parameter.Name] {
+			return bcode.ErrPipelineParameterInvalid.SetMessage(fmt.Sprintf("the parameter %s is duplicated", parameter.Name))
+		}
+		names[parameter.Name] = true
+		switch parameter.Type {
+		case model.PipelineParameterTypeString, model.PipelineParameterTypeNumber, model.PipelineParameterTypeBool, model.PipelineParameterTypeSecretRef:
+		case model.PipelineParameterTypeEnum:
+			if len(parameter.Options) == 0 {
+				return bcode.ErrPipelineParameterInvalid.SetMessage(fmt.Sprintf("the options of the enum parameter %s are required", parameter.Name))
+			}
+		default:
+			return bcode.ErrPipelineParameterInvalid.SetMessage(fmt.Sprintf("the type %s of the parameter %s is not supported", parameter.Type, parameter.Name))
+		}
+		if parameter.Default != nil {
+			if _, err := convertParameterValue(parameter, parameter.Default); err != nil {
+				return bcode.ErrPipelineParameterInvalid.SetMessage(fmt.Sprintf("the default value of the parameter %s is invalid: %s", parameter.Name, err.Error()))
+			}
+		}
+	}
+	return nil
+}
+
+// convertParameterValue convert the value to the type of the parameter, the string value is accepted for the number and bool parameters
+func convertParameterValue(parameter model.PipelineParameter, value interface{}) (interface{}, error) {
+	switch parameter.Type {
+	case model.PipelineParameterTypeNumber:
+		switch v := value.(type) {
+		case float64, float32, int, int32, int64:
+			return v, nil
+		case json.Number:
+			return v.Float64()
+		case string:
+			number, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", v)
+			}
+			return number, nil
+		}
+		return nil, fmt.Errorf("%v is not a number", value)
+	case model.PipelineParameterTypeBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a bool", v)
+			}
+			return b, nil
+		}
+		return nil, fmt.Errorf("%v is not a bool", value)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a string", value)
+	}
+	switch parameter.Type {
+	case model.PipelineParameterTypeEnum:
+		for _, option := range parameter.Options {
+			if option == s {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %s", s, strings.Join(parameter.Options, ", "))
+	case model.PipelineParameterTypeSecretRef:
+		if _, _, err := parseSecretRef(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func parseSecretRef(ref string) (string, string, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("%q is not in the format <secret name>/<key>", ref)
+	}
+	return parts[0], parts[1], nil
+}
+
+// resolveRunParameters merge the requested values, the context values and the default values of the parameters.
+// The requested value overrides the context value, the default value is used only if the context does not set it.
+// The values from the context are validated and converted as the others, except the secret values that are injected by reference.
+func (p pipelineServiceImpl) resolveRunParameters(ctx context.Context, namespace string, parameters []model.PipelineParameter,
+	values map[string]interface{}, contextValues []model.Value) ([]apis.PipelineRunParameter, map[string]interface{}, error) {
+	declared := map[string]bool{}
+	for _, parameter := range parameters {
+		declared[parameter.Name] = true
+	}
+	for name := range values {
+		if !declared[name] {
+			return nil, nil, bcode.ErrPipelineParameterInvalid.SetMessage(fmt.Sprintf("the parameter %s is not declared by the pipeline", name))
+		}
+	}
+	fromContext := map[string]model.Value{}
+	for _, value := range contextValues {
+		fromContext[value.Key] = value
+	}
+
+	var res []apis.PipelineRunParameter
+	runContext := map[string]interface{}{}
+	for _, parameter := range parameters {
+		runParameter := apis.PipelineRunParameter{Name: parameter.Name, Type: parameter.Type}
+		value, exist := values[parameter.Name]
+		switch {
+		case exist && value != nil:
+			runParameter.Source = apis.ParameterSourceValue
+		case fromContext[parameter.Name].Key != "":
+			runParameter.Source = apis.ParameterSourceContext
+			if fromContext[parameter.Name].Type == model.ContextValueTypeSecret {
+				runParameter.Value = maskedContextValue
+				res = append(res, runParameter)
+				continue
+			}
+			value = fromContext[parameter.Name].Value
+		case parameter.Default != nil:
+			runParameter.Source = apis.ParameterSourceDefault
+			value = parameter.Default
+		case parameter.Required:
+			return nil, nil, bcode.ErrPipelineParameterInvalid.SetMessage(fmt.Sprintf("the parameter %s is required", parameter.Name))
+		default:
+			continue
+		}
+		converted, err := convertParameterValue(parameter, value)
+		if err != nil {
+			return nil, nil, bcode.ErrPipelineParameterInvalid.SetMessage(fmt.Sprintf("the value of the parameter %s is invalid: %s", parameter.Name, err.Error()))
+		}
+		runParameter.Value = converted
+		runContext[parameter.Name] = converted
+		if parameter.Type == model.PipelineParameterTypeSecretRef {
+			ref, err := p.checkSecretRef(ctx, namespace, converted.(string))
+			if err != nil {
+				return nil, nil, err
+			}
+			runContext[parameter.Name] = ref
+		}
+		res = append(res, runParameter)
+	}
+	return res, runContext, nil
+}
+
+// checkSecretRef check the secret key exists, return the reference injected into the run context
+func (p pipelineServiceImpl) checkSecretRef(ctx context.Context, namespace, ref string) (map[string]interface{}, error) {
+	name, key, err := parseSecretRef(ref)
+	if err != nil {
+		return nil, bcode.ErrPipelineParameterInvalid.SetMessage(err.Error())
+	}
+	secret := &corev1.Secret{}
+	if err := p.KubeClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, bcode.ErrPipelineParameterInvalid.SetMessage(fmt.Sprintf("the secret %s is not found in the namespace %s", name, namespace))
+		}
+		return nil, err
+	}
+	if _, exist := secret.Data[key]; !exist {
+		return nil, bcode.ErrPipelineParameterInvalid.SetMessage(fmt.Sprintf("the key %s is not found in the secret %s", key, name))
+	}
+	return map[string]interface{}{
+		contextSecretRefKey: map[string]interface{}{"name": name, "key": key},
+	}, nil
+}
+
+// getRunParameters return the effective parameters recorded in the annotation of the run
+func getRunParameters(run v1alpha1.WorkflowRun) []apis.PipelineRunParameter {
+	content, exist := run.GetAnnotations()[annotationPipelineParameters]
+	if !exist {
+		return nil
+	}
+	var parameters []apis.PipelineRunParameter
+	if err := json.Unmarshal([]byte(content), &parameters); err != nil {
+		klog.Warningf("failed to parse the parameters of the run %s/%s: %s", run.Namespace, run.Name, err.Error())
+		return nil
+	}
+	return parameters
+}
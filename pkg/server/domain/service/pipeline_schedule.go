@@ -0,0 +1,265 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kubevela/workflow/api/v1alpha1"
+	"github.com/robfig/cron/v3"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// maxMissedFires the missed fires of the schedule are collapsed into one run, the loop is stopped after the limit
+const maxMissedFires = 100000
+
+// ExecutePipelineSchedules run the pipelines whose schedules are due, the missed fires are collapsed into one run
+func (p pipelineServiceImpl) ExecutePipelineSchedules(ctx context.Context) error {
+	entities, err := p.Store.List(ctx, &model.Pipeline{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entity := range entities {
+		pipeline := entity.(*model.Pipeline)
+		if pipeline.IsDeleted() || len(pipeline.Schedules) == 0 {
+			continue
+		}
+		fired := map[string]model.PipelineSchedule{}
+		for _, schedule := range pipeline.Schedules {
+			if schedule.Suspend {
+				continue
+			}
+			fireTime, due := dueFireTime(schedule, now)
+			if !due {
+				continue
+			}
+			schedule.LastFireTime = &fireTime
+			schedule.LastRunName, schedule.Message = "", ""
+			runName, err := p.fireSchedule(ctx, pipeline, schedule)
+			if err != nil {
+				klog.Errorf("failed to run the pipeline %s/%s by the schedule %s: %s", pipeline.Project, pipeline.Name, schedule.Name, err.Error())
+				schedule.Message = err.Error()
+			}
+			schedule.LastRunName = runName
+			fired[schedule.Name] = schedule
+		}
+		if len(fired) > 0 {
+			if err := p.saveScheduleStatus(ctx, pipeline, fired); err != nil {
+				klog.Errorf("failed to update the schedules of the pipeline %s/%s: %s", pipeline.Project, pipeline.Name, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// fireSchedule run the pipeline as the creator of the schedule, return the name of the run.
+// The run is skipped with the error if it is forbidden by the concurrency policy.
+func (p pipelineServiceImpl) fireSchedule(ctx context.Context, pipeline *model.Pipeline, schedule model.PipelineSchedule) (string, error) {
+	project := &model.Project{Name: pipeline.Project}
+	if err := p.Store.Get(ctx, project); err != nil {
+		return "", err
+	}
+	runCtx := context.WithValue(ctx, &apis.CtxKeyProject, project)
+	runCtx = context.WithValue(runCtx, &apis.CtxKeyUser, schedule.Creator)
+
+	if schedule.ConcurrencyPolicy == model.ConcurrencyPolicyForbid || schedule.ConcurrencyPolicy == model.ConcurrencyPolicyReplace {
+		var runs v1alpha1.WorkflowRunList
+		if err := p.KubeClient.List(ctx, &runs, client.InNamespace(project.GetNamespace()),
+			client.MatchingLabels{labelPipeline: pipeline.Name, labelSchedule: schedule.Name}); err != nil {
+			return "", err
+		}
+		for _, run := range runs.Items {
+			if run.Status.Finished || run.Status.Terminated {
+				continue
+			}
+			if schedule.ConcurrencyPolicy == model.ConcurrencyPolicyForbid {
+				return "", fmt.Errorf("skipped because the run %s is not finished", run.Name)
+			}
+			if err := p.PipelineRunService.TerminatePipelineRun(runCtx, apis.PipelineRunMeta{PipelineRunName: run.Name}); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	run, err := p.runPipeline(runCtx, *pipeline2PipelineBase(pipeline, *project), apis.RunPipelineRequest{ContextName: schedule.ContextName},
+		runOptions{labels: map[string]string{labelSchedule: schedule.Name}})
+	if err != nil {
+		return "", err
+	}
+	return run.PipelineRunName, nil
+}
+
+// saveScheduleStatus reload the pipeline and update the status of the fired schedules, the schedules may be changed during the runs
+func (p pipelineServiceImpl) saveScheduleStatus(ctx context.Context, pipeline *model.Pipeline, fired map[string]model.PipelineSchedule) error {
+	latest, err := getPipeline(ctx, p.Store, pipeline.Project, pipeline.Name)
+	if err != nil {
+		return err
+	}
+	for i, schedule := range latest.Schedules {
+		status, exist := fired[schedule.Name]
+		if !exist {
+			continue
+		}
+		latest.Schedules[i].LastFireTime = status.LastFireTime
+		latest.Schedules[i].LastRunName = status.LastRunName
+		latest.Schedules[i].Message = status.Message
+	}
+	return p.Store.Put(ctx, latest)
+}
+
+// dueFireTime return the latest fire time that is not later than now, it is false if the schedule is not due
+func dueFireTime(schedule model.PipelineSchedule, now time.Time) (time.Time, bool) {
+	sched, location, err := parseSchedule(schedule.Cron, schedule.TimeZone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	next := sched.Next(scheduleBaseTime(schedule).In(location))
+	if next.IsZero() || next.After(now) {
+		return time.Time{}, false
+	}
+	for i := 0; i < maxMissedFires; i++ {
+		following := sched.Next(next)
+		if following.IsZero() || following.After(now) {
+			break
+		}
+		next = following
+	}
+	return next, true
+}
+
+func nextFireTime(schedule model.PipelineSchedule) *time.Time {
+	if schedule.Suspend {
+		return nil
+	}
+	sched, location, err := parseSchedule(schedule.Cron, schedule.TimeZone)
+	if err != nil {
+		return nil
+	}
+	next := sched.Next(scheduleBaseTime(schedule).In(location))
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+func scheduleBaseTime(schedule model.PipelineSchedule) time.Time {
+	if schedule.LastFireTime != nil && schedule.LastFireTime.After(schedule.StartTime) {
+		return *schedule.LastFireTime
+	}
+	return schedule.StartTime
+}
+
+func parseSchedule(expression, timeZone string) (cron.Schedule, *time.Location, error) {
+	location := time.UTC
+	if timeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(timeZone); err != nil {
+			return nil, nil, err
+		}
+	}
+	sched, err := cron.ParseStandard(expression)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sched, location, nil
+}
+
+// mergePipelineSchedules convert the requested schedules, the status is kept if the schedule exists.
+// The start time is reset if the schedule is changed, so the missed fires of the previous settings are not run.
+// The creator is only replaced when the schedule is changed.
+func mergePipelineSchedules(existing []model.PipelineSchedule, schedules []apis.PipelineSchedule, creator string, now time.Time) ([]model.PipelineSchedule, error) {
+	existingMap := make(map[string]model.PipelineSchedule, len(existing))
+	for _, schedule := range existing {
+		existingMap[schedule.Name] = schedule
+	}
+	var res []model.PipelineSchedule
+	names := map[string]bool{}
+	for _, req := range schedules {
+		if names[req.Name] {
+			return nil, bcode.ErrPipelineScheduleInvalid.SetMessage(fmt.Sprintf("the schedule %s is duplicated", req.Name))
+		}
+		names[req.Name] = true
+		if _, _, err := parseSchedule(req.Cron, req.TimeZone); err != nil {
+			return nil, bcode.ErrPipelineScheduleInvalid.SetMessage(fmt.Sprintf("the schedule %s is invalid: %s", req.Name, err.Error()))
+		}
+		policy := req.ConcurrencyPolicy
+		switch policy {
+		case "":
+			policy = model.ConcurrencyPolicyAllow
+		case model.ConcurrencyPolicyAllow, model.ConcurrencyPolicyForbid, model.ConcurrencyPolicyReplace:
+		default:
+			return nil, bcode.ErrPipelineScheduleInvalid.SetMessage(fmt.Sprintf("the concurrency policy %s is not supported", policy))
+		}
+		schedule := model.PipelineSchedule{
+			Name:              req.Name,
+			Cron:              req.Cron,
+			TimeZone:          req.TimeZone,
+			ContextName:       req.ContextName,
+			ConcurrencyPolicy: policy,
+			Suspend:           req.Suspend,
+			Creator:           creator,
+			StartTime:         now,
+		}
+		if current, exist := existingMap[req.Name]; exist {
+			schedule.LastFireTime = current.LastFireTime
+			schedule.LastRunName = current.LastRunName
+			schedule.Message = current.Message
+			if current.Cron == req.Cron && current.TimeZone == req.TimeZone && current.Suspend == req.Suspend {
+				schedule.StartTime = current.StartTime
+				// the schedule runs as the user who set it, so the unchanged schedule keeps its creator
+				if current.ContextName == req.ContextName && current.ConcurrencyPolicy == policy {
+					schedule.Creator = current.Creator
+				}
+			}
+		}
+		res = append(res, schedule)
+	}
+	return res, nil
+}
+
+func convertPipelineSchedules(schedules []model.PipelineSchedule) []apis.PipelineScheduleBase {
+	if len(schedules) == 0 {
+		return nil
+	}
+	res := make([]apis.PipelineScheduleBase, 0, len(schedules))
+	for _, schedule := range schedules {
+		res = append(res, apis.PipelineScheduleBase{
+			PipelineSchedule: apis.PipelineSchedule{
+				Name:              schedule.Name,
+				Cron:              schedule.Cron,
+				TimeZone:          schedule.TimeZone,
+				ContextName:       schedule.ContextName,
+				ConcurrencyPolicy: schedule.ConcurrencyPolicy,
+				Suspend:           schedule.Suspend,
+			},
+			NextFireTime: nextFireTime(schedule),
+			LastFireTime: schedule.LastFireTime,
+			LastRunName:  schedule.LastRunName,
+			Message:      schedule.Message,
+		})
+	}
+	return res
+}
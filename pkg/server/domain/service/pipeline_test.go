@@ -18,13 +18,21 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/kubevela/workflow/api/v1alpha1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
 )
 
 var (
@@ -139,4 +147,428 @@ var _ = Describe("Test pipeline service functions", func() {
 		Expect(err).Should(BeNil())
 		Expect(len(context.Contexts)).Should(Equal(1))
 	})
+
+	It("secret context values", func() {
+		contextName := "secret-context"
+		By("the value type is not supported")
+		_, err := contextService.CreateContext(ctx, projectName, pipelineName, apisv1.Context{
+			Name:   contextName,
+			Values: []model.Value{{Key: "token", Value: "abc", Type: "encrypted"}},
+		})
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrContextValueInvalid.BusinessCode))
+
+		By("the context name and the key of the secret value could not contain the dot")
+		_, err = contextService.CreateContext(ctx, projectName, pipelineName, apisv1.Context{
+			Name:   contextName + ".eu",
+			Values: []model.Value{{Key: "token", Value: "abc", Type: model.ContextValueTypeSecret}},
+		})
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrContextValueInvalid.BusinessCode))
+		_, err = contextService.CreateContext(ctx, projectName, pipelineName, apisv1.Context{
+			Name:   contextName,
+			Values: []model.Value{{Key: "eu.token", Value: "abc", Type: model.ContextValueTypeSecret}},
+		})
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrContextValueInvalid.BusinessCode))
+
+		By("create the context with a secret value")
+		_, err = contextService.CreateContext(ctx, projectName, pipelineName, apisv1.Context{
+			Name: contextName,
+			Values: []model.Value{
+				{Key: "user", Value: "admin"},
+				{Key: "token", Value: "my-secret-token", Type: model.ContextValueTypeSecret},
+			},
+		})
+		Expect(err).Should(BeNil())
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: ctx.Value(&apisv1.CtxKeyProject).(*model.Project).GetNamespace(), Name: contextSecretName(pipelineName)}, secret)).Should(BeNil())
+		Expect(string(secret.Data[contextName+".token"])).Should(Equal("my-secret-token"))
+
+		By("the secret value is masked")
+		apiContext, err := contextService.GetContext(ctx, projectName, pipelineName, contextName)
+		Expect(err).Should(BeNil())
+		Expect(apiContext.Values).Should(ContainElement(model.Value{Key: "token", Value: maskedContextValue, Type: model.ContextValueTypeSecret}))
+		Expect(apiContext.Values).Should(ContainElement(model.Value{Key: "user", Value: "admin"}))
+		contexts, err := contextService.ListContexts(ctx, projectName, pipelineName)
+		Expect(err).Should(BeNil())
+		Expect(contexts.Contexts[contextName]).Should(ContainElement(model.Value{Key: "token", Value: maskedContextValue, Type: model.ContextValueTypeSecret}))
+
+		By("update with the masked value keeps the secret")
+		_, err = contextService.UpdateContext(ctx, projectName, pipelineName, apisv1.Context{Name: contextName, Values: apiContext.Values})
+		Expect(err).Should(BeNil())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(secret), secret)).Should(BeNil())
+		Expect(string(secret.Data[contextName+".token"])).Should(Equal("my-secret-token"))
+
+		By("the secret value is referenced by the run context")
+		runContext := contextValues2RunContext(pipelineName, contextName, apiContext.Values)
+		Expect(runContext["user"]).Should(Equal("admin"))
+		Expect(runContext["token"]).Should(Equal(map[string]interface{}{
+			"secretKeyRef": map[string]interface{}{"name": contextSecretName(pipelineName), "key": contextName + ".token"},
+		}))
+		Expect(maskSecretValues("login with my-secret-token", []string{"my-secret-token"})).Should(Equal("login with ******"))
+
+		By("delete the context")
+		Expect(contextService.DeleteContext(ctx, projectName, pipelineName, contextName)).Should(BeNil())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(secret), secret)).Should(BeNil())
+		Expect(secret.Data).ShouldNot(HaveKey(contextName + ".token"))
+	})
+
+	It("pipeline schedules", func() {
+		base, err := pipelineService.GetPipeline(ctx, pipelineName, false)
+		Expect(err).Should(BeNil())
+
+		By("invalid cron expression")
+		_, err = pipelineService.UpdatePipeline(ctx, pipelineName, apisv1.UpdatePipelineRequest{
+			Spec:      base.Spec,
+			Schedules: []apisv1.PipelineSchedule{{Name: "nightly", Cron: "* * *"}},
+		})
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineScheduleInvalid.BusinessCode))
+
+		By("invalid time zone")
+		_, err = pipelineService.UpdatePipeline(ctx, pipelineName, apisv1.UpdatePipelineRequest{
+			Spec:      base.Spec,
+			Schedules: []apisv1.PipelineSchedule{{Name: "nightly", Cron: "0 2 * * *", TimeZone: "Mars/Olympus"}},
+		})
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineScheduleInvalid.BusinessCode))
+
+		By("add a schedule")
+		updated, err := pipelineService.UpdatePipeline(ctx, pipelineName, apisv1.UpdatePipelineRequest{
+			Spec:      base.Spec,
+			Schedules: []apisv1.PipelineSchedule{{Name: "nightly", Cron: "0 2 * * *", TimeZone: "Asia/Shanghai", ContextName: "test-context"}},
+		})
+		Expect(err).Should(BeNil())
+		Expect(len(updated.Schedules)).Should(Equal(1))
+		Expect(updated.Schedules[0].ConcurrencyPolicy).Should(Equal(model.ConcurrencyPolicyAllow))
+		Expect(updated.Schedules[0].NextFireTime).ShouldNot(BeNil())
+		Expect(updated.Schedules[0].NextFireTime.After(time.Now())).Should(BeTrue())
+		Expect(updated.Schedules[0].LastFireTime).Should(BeNil())
+
+		By("the schedules are kept if not specified")
+		updated, err = pipelineService.UpdatePipeline(ctx, pipelineName, apisv1.UpdatePipelineRequest{Spec: base.Spec, Description: "nightly build"})
+		Expect(err).Should(BeNil())
+		Expect(len(updated.Schedules)).Should(Equal(1))
+
+		By("the unchanged schedules keep the creator")
+		existing := []model.PipelineSchedule{{Name: "nightly", Cron: "0 2 * * *", ConcurrencyPolicy: model.ConcurrencyPolicyAllow, Creator: "admin"}}
+		merged, err := mergePipelineSchedules(existing, []apisv1.PipelineSchedule{{Name: "nightly", Cron: "0 2 * * *"}, {Name: "weekly", Cron: "0 2 * * 0"}}, "dev", time.Now())
+		Expect(err).Should(BeNil())
+		Expect(merged[0].Creator).Should(Equal("admin"))
+		Expect(merged[1].Creator).Should(Equal("dev"))
+		merged, err = mergePipelineSchedules(existing, []apisv1.PipelineSchedule{{Name: "nightly", Cron: "0 3 * * *"}}, "dev", time.Now())
+		Expect(err).Should(BeNil())
+		Expect(merged[0].Creator).Should(Equal("dev"))
+
+		By("fire the missed schedules only once")
+		pipeline, err := getPipeline(context.TODO(), ds, projectName, pipelineName)
+		Expect(err).Should(BeNil())
+		pipeline.Schedules[0].StartTime = time.Now().Add(-72 * time.Hour)
+		Expect(ds.Put(context.TODO(), pipeline)).Should(BeNil())
+		Expect(pipelineService.ExecutePipelineSchedules(context.TODO())).Should(BeNil())
+
+		detail, err := pipelineService.GetPipeline(ctx, pipelineName, false)
+		Expect(err).Should(BeNil())
+		Expect(detail.Schedules[0].LastFireTime).ShouldNot(BeNil())
+		Expect(time.Since(*detail.Schedules[0].LastFireTime) < 24*time.Hour).Should(BeTrue())
+		Expect(detail.Schedules[0].NextFireTime.After(time.Now())).Should(BeTrue())
+
+		By("suspend the schedule")
+		updated, err = pipelineService.UpdatePipeline(ctx, pipelineName, apisv1.UpdatePipelineRequest{
+			Spec:      base.Spec,
+			Schedules: []apisv1.PipelineSchedule{{Name: "nightly", Cron: "0 2 * * *", TimeZone: "Asia/Shanghai", Suspend: true}},
+		})
+		Expect(err).Should(BeNil())
+		Expect(updated.Schedules[0].NextFireTime).Should(BeNil())
+		Expect(updated.Schedules[0].LastFireTime).ShouldNot(BeNil())
+
+		By("clear the schedules")
+		updated, err = pipelineService.UpdatePipeline(ctx, pipelineName, apisv1.UpdatePipelineRequest{Spec: base.Spec, Schedules: []apisv1.PipelineSchedule{}})
+		Expect(err).Should(BeNil())
+		Expect(len(updated.Schedules)).Should(Equal(0))
+	})
+
+	It("pipeline versions", func() {
+		base, err := pipelineService.GetPipeline(ctx, pipelineName, false)
+		Expect(err).Should(BeNil())
+		Expect(base.Version).Should(Equal(int64(1)))
+
+		versions, err := pipelineService.ListPipelineVersions(ctx, base.PipelineBase)
+		Expect(err).Should(BeNil())
+		Expect(versions.Total).Should(Equal(int64(1)))
+		Expect(versions.Versions[0].Author).Should(Equal(FakeAdminName))
+
+		By("the version is not changed if the spec is not changed")
+		updated, err := pipelineService.UpdatePipeline(ctx, pipelineName, apisv1.UpdatePipelineRequest{Spec: base.Spec, Description: "no spec change"})
+		Expect(err).Should(BeNil())
+		Expect(updated.Version).Should(Equal(int64(1)))
+
+		By("record a new version when the spec is changed")
+		spec := base.Spec
+		spec.Steps = append(append([]model.WorkflowStep{}, base.Spec.Steps...), model.WorkflowStep{
+			WorkflowStepBase: model.WorkflowStepBase{Name: "approve", Type: "suspend"},
+		})
+		updated, err = pipelineService.UpdatePipeline(ctx, pipelineName, apisv1.UpdatePipelineRequest{Spec: spec, Message: "add the approval"})
+		Expect(err).Should(BeNil())
+		Expect(updated.Version).Should(Equal(int64(2)))
+
+		versions, err = pipelineService.ListPipelineVersions(ctx, base.PipelineBase)
+		Expect(err).Should(BeNil())
+		Expect(versions.Total).Should(Equal(int64(2)))
+		Expect(versions.Versions[0].Version).Should(Equal(int64(2)))
+		Expect(versions.Versions[0].Message).Should(Equal("add the approval"))
+
+		diff, err := pipelineService.ComparePipelineVersions(ctx, base.PipelineBase, apisv1.ComparePipelineVersionsRequest{BaseVersion: 1, TargetVersion: 2})
+		Expect(err).Should(BeNil())
+		Expect(diff.IsDiff).Should(BeTrue())
+		Expect(len(diff.Steps)).Should(Equal(1))
+		Expect(diff.Steps[0].Name).Should(Equal("approve"))
+		Expect(diff.Steps[0].Change).Should(Equal(apisv1.RevisionDiffAdded))
+
+		By("restore the first version")
+		restored, err := pipelineService.RestorePipelineVersion(ctx, base.PipelineBase, 1, apisv1.RestorePipelineVersionRequest{})
+		Expect(err).Should(BeNil())
+		Expect(restored.Version).Should(Equal(int64(3)))
+		Expect(len(restored.Spec.Steps)).Should(Equal(len(base.Spec.Steps)))
+		detail, err := pipelineService.GetPipelineVersion(ctx, base.PipelineBase, 3)
+		Expect(err).Should(BeNil())
+		Expect(detail.RestoredFrom).Should(Equal(int64(1)))
+
+		_, err = pipelineService.GetPipelineVersion(ctx, base.PipelineBase, 99)
+		Expect(err).Should(Equal(bcode.ErrPipelineVersionNotExist))
+
+		By("the run shows the spec of the version it used")
+		pipeline, err := getPipeline(context.TODO(), ds, projectName, pipelineName)
+		Expect(err).Should(BeNil())
+		run := v1alpha1.WorkflowRun{}
+		run.SetAnnotations(map[string]string{annotationPipelineVersion: "2"})
+		Expect(getRunPipelineVersion(run)).Should(Equal(int64(2)))
+		Expect(len(getRunPipelineSpec(context.TODO(), ds, run, pipeline).Steps)).Should(Equal(len(spec.Steps)))
+	})
+
+	It("validate the pipeline spec", func() {
+		Expect(k8sClient.Create(context.TODO(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "workflowstep-schema-request", Namespace: types.DefaultKubeVelaNS},
+			Data: map[string]string{
+				types.OpenapiV3JSONSchema: `{"properties":{"url":{"title":"url","type":"string"},"method":{"default":"GET","enum":["GET","POST","PUT","DELETE"],"title":"method","type":"string"}},"required":["url"],"type":"object"}`,
+			},
+		})).Should(Succeed())
+
+		validProps := model.JSONStruct{"url": "https://kubevela.io"}
+		res, err := pipelineService.ValidatePipelineSpec(ctx, model.WorkflowSpec{Steps: []model.WorkflowStep{
+			{WorkflowStepBase: model.WorkflowStepBase{Name: "request", Type: "request", Properties: &validProps, Timeout: "1m",
+				Outputs: v1alpha1.StepOutputs{{Name: "body", ValueFrom: "response"}}}},
+			{WorkflowStepBase: model.WorkflowStepBase{Name: "approve", Type: "suspend", If: `inputs.body != ""`,
+				Inputs: v1alpha1.StepInputs{{From: "body", ParameterKey: "body"}}}},
+		}})
+		Expect(err).Should(BeNil())
+		Expect(res.Valid).Should(BeTrue())
+
+		invalidProps := model.JSONStruct{"url": 1, "method": "PATCH"}
+		spec := model.WorkflowSpec{Steps: []model.WorkflowStep{
+			{WorkflowStepBase: model.WorkflowStepBase{Name: "request", Type: "request", Properties: &invalidProps, Timeout: "soon"}},
+			{WorkflowStepBase: model.WorkflowStepBase{Name: "unknown", Type: "not-exist", DependsOn: []string{"missing"}}},
+			{WorkflowStepBase: model.WorkflowStepBase{Name: "a", Type: "suspend", DependsOn: []string{"b"}, If: "status ==",
+				Inputs: v1alpha1.StepInputs{{From: "later", ParameterKey: "value"}}}},
+			{WorkflowStepBase: model.WorkflowStepBase{Name: "b", Type: "suspend", DependsOn: []string{"a"},
+				Outputs: v1alpha1.StepOutputs{{Name: "later", ValueFrom: "context.name"}}}},
+		}}
+		res, err = pipelineService.ValidatePipelineSpec(ctx, spec)
+		Expect(err).Should(BeNil())
+		Expect(res.Valid).Should(BeFalse())
+		fields := map[string]bool{}
+		for _, specError := range res.Errors {
+			fields[specError.Step+":"+specError.Field] = true
+		}
+		Expect(fields).Should(HaveKey("request:properties.url"))
+		Expect(fields).Should(HaveKey("request:properties.method"))
+		Expect(fields).Should(HaveKey("request:timeout"))
+		Expect(fields).Should(HaveKey("unknown:type"))
+		Expect(fields).Should(HaveKey("unknown:dependsOn"))
+		Expect(fields).Should(HaveKey("a:dependsOn"))
+		Expect(fields).Should(HaveKey("a:if"))
+		Expect(fields).Should(HaveKey("a:inputs"))
+
+		By("the invalid spec can not be saved")
+		_, err = pipelineService.CreatePipeline(ctx, apisv1.CreatePipelineRequest{Name: "invalid-pipeline", Spec: spec})
+		var specErr *PipelineSpecInvalidError
+		Expect(errors.As(err, &specErr)).Should(BeTrue())
+		Expect(specErr.BusinessCode).Should(Equal(bcode.ErrPipelineSpecInvalid.BusinessCode))
+		Expect(len(specErr.Errors)).Should(Equal(len(res.Errors)))
+	})
+
+	It("pipeline parameters", func() {
+		base, err := pipelineService.GetPipeline(ctx, pipelineName, false)
+		Expect(err).Should(BeNil())
+
+		By("invalid parameter declarations")
+		for _, parameters := range [][]model.PipelineParameter{
+			{{Name: "image-tag", Type: model.PipelineParameterTypeString}},
+			{{Name: "replicas", Type: "integer"}},
+			{{Name: "env", Type: model.PipelineParameterTypeEnum}},
+			{{Name: "replicas", Type: model.PipelineParameterTypeNumber, Default: "three"}},
+			{{Name: "debug", Type: model.PipelineParameterTypeBool}, {Name: "debug", Type: model.PipelineParameterTypeBool}},
+		} {
+			_, err = pipelineService.UpdatePipeline(ctx, pipelineName, apisv1.UpdatePipelineRequest{Spec: base.Spec, Parameters: parameters})
+			Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineParameterInvalid.BusinessCode))
+		}
+
+		By("declare the parameters")
+		parameters := []model.PipelineParameter{
+			{Name: "image", Type: model.PipelineParameterTypeString, Required: true},
+			{Name: "replicas", Type: model.PipelineParameterTypeNumber, Default: 1},
+			{Name: "debug", Type: model.PipelineParameterTypeBool, Default: false},
+			{Name: "env", Type: model.PipelineParameterTypeEnum, Options: []string{"dev", "prod"}, Default: "dev"},
+			{Name: "token", Type: model.PipelineParameterTypeSecretRef},
+		}
+		updated, err := pipelineService.UpdatePipeline(ctx, pipelineName, apisv1.UpdatePipelineRequest{Spec: base.Spec, Parameters: parameters})
+		Expect(err).Should(BeNil())
+		Expect(len(updated.Parameters)).Should(Equal(5))
+
+		By("the parameters are versioned with the spec")
+		Expect(updated.Version).Should(Equal(base.Version + 1))
+		restored, err := pipelineService.RestorePipelineVersion(ctx, base.PipelineBase, base.Version, apisv1.RestorePipelineVersionRequest{})
+		Expect(err).Should(BeNil())
+		Expect(len(restored.Parameters)).Should(Equal(0))
+		restored, err = pipelineService.RestorePipelineVersion(ctx, base.PipelineBase, updated.Version, apisv1.RestorePipelineVersionRequest{})
+		Expect(err).Should(BeNil())
+		Expect(len(restored.Parameters)).Should(Equal(5))
+
+		By("the required parameter is missing")
+		namespace := ctx.Value(&apisv1.CtxKeyProject).(*model.Project).GetNamespace()
+		_, _, err = pipelineService.resolveRunParameters(ctx, namespace, parameters, nil, nil)
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineParameterInvalid.BusinessCode))
+
+		By("the value is not declared or invalid")
+		_, _, err = pipelineService.resolveRunParameters(ctx, namespace, parameters, map[string]interface{}{"image": "nginx", "unknown": "1"}, nil)
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineParameterInvalid.BusinessCode))
+		_, _, err = pipelineService.resolveRunParameters(ctx, namespace, parameters, map[string]interface{}{"image": "nginx", "env": "staging"}, nil)
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineParameterInvalid.BusinessCode))
+		_, _, err = pipelineService.resolveRunParameters(ctx, namespace, parameters, map[string]interface{}{"image": "nginx", "token": "not-exist/token"}, nil)
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineParameterInvalid.BusinessCode))
+
+		By("merge the values over the context")
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: namespace},
+			Data:       map[string][]byte{"token": []byte("registry-token")},
+		})).Should(Succeed())
+		runParameters, runContext, err := pipelineService.resolveRunParameters(ctx, namespace, parameters,
+			map[string]interface{}{"image": "nginx", "replicas": "3", "token": "registry/token"},
+			[]model.Value{{Key: "env", Value: "prod"}, {Key: "image", Value: "busybox"}})
+		Expect(err).Should(BeNil())
+		sources := map[string]string{}
+		for _, parameter := range runParameters {
+			sources[parameter.Name] = parameter.Source
+		}
+		Expect(sources).Should(Equal(map[string]string{
+			"image":    apisv1.ParameterSourceValue,
+			"replicas": apisv1.ParameterSourceValue,
+			"debug":    apisv1.ParameterSourceDefault,
+			"env":      apisv1.ParameterSourceContext,
+			"token":    apisv1.ParameterSourceValue,
+		}))
+		Expect(runContext).Should(Equal(map[string]interface{}{
+			"image":    "nginx",
+			"replicas": float64(3),
+			"debug":    false,
+			"env":      "prod",
+			"token":    map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": "registry", "key": "token"}},
+		}))
+
+		By("the values from the context are validated")
+		_, _, err = pipelineService.resolveRunParameters(ctx, namespace, parameters, map[string]interface{}{"image": "nginx"}, []model.Value{{Key: "replicas", Value: "abc"}})
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineParameterInvalid.BusinessCode))
+		_, _, err = pipelineService.resolveRunParameters(ctx, namespace, parameters, map[string]interface{}{"image": "nginx"}, []model.Value{{Key: "env", Value: "staging"}})
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineParameterInvalid.BusinessCode))
+		_, runContext, err = pipelineService.resolveRunParameters(ctx, namespace, parameters, map[string]interface{}{"image": "nginx"}, []model.Value{{Key: "replicas", Value: "2"}})
+		Expect(err).Should(BeNil())
+		Expect(runContext["replicas"]).Should(Equal(float64(2)))
+	})
+
+	It("pipeline triggers", func() {
+		base, err := pipelineService.GetPipeline(ctx, pipelineName, false)
+		Expect(err).Should(BeNil())
+		downstream, err := pipelineService.CreatePipeline(ctx, apisv1.CreatePipelineRequest{Name: "downstream-pipeline", Spec: base.Spec})
+		Expect(err).Should(BeNil())
+
+		By("invalid triggers")
+		for _, req := range []apisv1.CreatePipelineTriggerRequest{
+			{Name: "webhook", Type: model.PipelineTriggerTypeWebhook},
+			{Name: "webhook", Type: model.PipelineTriggerTypeWebhook, PipelineTriggerSpec: apisv1.PipelineTriggerSpec{
+				PayloadType: model.PayloadTypeGitHub, Parameters: map[string]string{"unknown": "$.event.branch"}}},
+			{Name: "webhook", Type: model.PipelineTriggerTypeWebhook, PipelineTriggerSpec: apisv1.PipelineTriggerSpec{
+				PayloadType: model.PayloadTypeGitHub, Parameters: map[string]string{"image": "$.event[("}}},
+			{Name: "chain", Type: model.PipelineTriggerTypePipeline},
+		} {
+			_, err = pipelineService.CreatePipelineTrigger(ctx, base.PipelineBase, req)
+			Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineTriggerInvalid.BusinessCode))
+		}
+		_, err = pipelineService.CreatePipelineTrigger(ctx, base.PipelineBase, apisv1.CreatePipelineTriggerRequest{
+			Name: "chain", Type: model.PipelineTriggerTypePipeline, PipelineTriggerSpec: apisv1.PipelineTriggerSpec{SourcePipeline: "not-exist"}})
+		Expect(err).ShouldNot(BeNil())
+
+		By("create the webhook trigger")
+		webhook, err := pipelineService.CreatePipelineTrigger(ctx, base.PipelineBase, apisv1.CreatePipelineTriggerRequest{
+			Name: "webhook", Type: model.PipelineTriggerTypeWebhook, PipelineTriggerSpec: apisv1.PipelineTriggerSpec{
+				PayloadType: model.PayloadTypeGitHub,
+				Secret:      "webhook-secret",
+				Conditions:  []model.PayloadCondition{{Path: "$.event.branch", Operator: model.PayloadConditionEquals, Values: []string{"main"}}},
+				Parameters:  map[string]string{"image": "$.body.image"},
+			}})
+		Expect(err).Should(BeNil())
+		Expect(webhook.Token).ShouldNot(BeEmpty())
+		Expect(webhook.HasSecret).Should(BeTrue())
+		_, err = pipelineService.CreatePipelineTrigger(ctx, base.PipelineBase, apisv1.CreatePipelineTriggerRequest{
+			Name: "webhook", Type: model.PipelineTriggerTypeWebhook, PipelineTriggerSpec: apisv1.PipelineTriggerSpec{PayloadType: model.PayloadTypeGitHub}})
+		Expect(err).Should(Equal(bcode.ErrPipelineTriggerExist))
+
+		By("the secret is kept if it is not changed")
+		webhook, err = pipelineService.UpdatePipelineTrigger(ctx, base.PipelineBase, "webhook", apisv1.UpdatePipelineTriggerRequest{
+			Description: "run on the main branch",
+			PipelineTriggerSpec: apisv1.PipelineTriggerSpec{
+				PayloadType: model.PayloadTypeGitHub,
+				Conditions:  []model.PayloadCondition{{Path: "$.event.branch", Operator: model.PayloadConditionEquals, Values: []string{"main"}}},
+			}})
+		Expect(err).Should(BeNil())
+		Expect(webhook.HasSecret).Should(BeTrue())
+
+		By("the payload does not match the conditions")
+		trigger := &model.PipelineTrigger{Token: webhook.Token}
+		Expect(ds.Get(ctx, trigger)).Should(BeNil())
+		res, err := pipelineService.RunPipelineByTrigger(ctx, trigger, map[string]interface{}{"event": map[string]interface{}{"branch": "dev"}})
+		Expect(err).Should(BeNil())
+		Expect(res.(*apisv1.ApplicationWebhookSkippedResponse).State).Should(Equal(model.WebhookDeliverySkipped))
+
+		By("chain the pipelines")
+		_, err = pipelineService.CreatePipelineTrigger(ctx, *downstream, apisv1.CreatePipelineTriggerRequest{
+			Name: "chain", Type: model.PipelineTriggerTypePipeline, PipelineTriggerSpec: apisv1.PipelineTriggerSpec{
+				SourcePipeline: pipelineName,
+				SourcePhases:   []string{"succeeded", "failed"},
+			}})
+		Expect(err).Should(BeNil())
+		_, err = pipelineService.CreatePipelineTrigger(ctx, base.PipelineBase, apisv1.CreatePipelineTriggerRequest{
+			Name: "chain", Type: model.PipelineTriggerTypePipeline, PipelineTriggerSpec: apisv1.PipelineTriggerSpec{SourcePipeline: downstream.Name}})
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineTriggerLoop.BusinessCode))
+		_, err = pipelineService.CreatePipelineTrigger(ctx, base.PipelineBase, apisv1.CreatePipelineTriggerRequest{
+			Name: "self", Type: model.PipelineTriggerTypePipeline, PipelineTriggerSpec: apisv1.PipelineTriggerSpec{SourcePipeline: pipelineName}})
+		Expect(err.(*bcode.Bcode).BusinessCode).Should(Equal(bcode.ErrPipelineTriggerLoop.BusinessCode))
+
+		By("the run of the chain is skipped")
+		chain := &model.PipelineTrigger{Project: downstream.Project.Name, PipelineName: downstream.Name, Name: "chain"}
+		entities, err := ds.List(ctx, chain, nil)
+		Expect(err).Should(BeNil())
+		Expect(len(entities)).Should(Equal(1))
+		res, err = pipelineService.runByTrigger(ctx, entities[0].(*model.PipelineTrigger), nil, []string{downstream.Name, pipelineName})
+		Expect(err).Should(BeNil())
+		Expect(res.(*apisv1.ApplicationWebhookSkippedResponse).State).Should(Equal(model.WebhookDeliverySkipped))
+		Expect(pipelineService.ExecutePipelineTriggers(context.TODO())).Should(BeNil())
+
+		By("list and delete the triggers")
+		triggers, err := pipelineService.ListPipelineTriggers(ctx, base.PipelineBase)
+		Expect(err).Should(BeNil())
+		Expect(len(triggers.Triggers)).Should(Equal(1))
+		Expect(triggers.Triggers[0].Description).Should(Equal("run on the main branch"))
+		Expect(pipelineService.DeletePipelineTrigger(ctx, base.PipelineBase, "webhook")).Should(BeNil())
+		Expect(pipelineService.DeletePipelineTrigger(ctx, base.PipelineBase, "webhook")).Should(Equal(bcode.ErrPipelineTriggerNotExist))
+		Expect(pipelineService.PurgePipeline(ctx, *downstream)).Should(BeNil())
+		triggers, err = pipelineService.ListPipelineTriggers(ctx, *downstream)
+		Expect(err).Should(BeNil())
+		Expect(len(triggers.Triggers)).Should(Equal(0))
+	})
 })
@@ -0,0 +1,478 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubevela/pkg/util/slices"
+	"github.com/kubevela/workflow/api/v1alpha1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// defaultSourcePhases the completed source runs handled by the pipeline trigger if the phases are not specified
+var defaultSourcePhases = []string{string(v1alpha1.WorkflowStateSucceeded)}
+
+// ListPipelineTriggers list the triggers of the pipeline
+func (p pipelineServiceImpl) ListPipelineTriggers(ctx context.Context, pipeline apis.PipelineBase) (*apis.ListPipelineTriggersResponse, error) {
+	entities, err := p.Store.List(ctx, &model.PipelineTrigger{Project: pipeline.Project.Name, PipelineName: pipeline.Name}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	res := &apis.ListPipelineTriggersResponse{Triggers: []apis.PipelineTriggerBase{}}
+	for _, entity := range entities {
+		res.Triggers = append(res.Triggers, *pipelineTrigger2Base(entity.(*model.PipelineTrigger)))
+	}
+	sort.Slice(res.Triggers, func(i, j int) bool {
+		return res.Triggers[i].Name < res.Triggers[j].Name
+	})
+	return res, nil
+}
+
+// CreatePipelineTrigger create the webhook trigger or the pipeline trigger, the pipeline is run as the creator
+func (p pipelineServiceImpl) CreatePipelineTrigger(ctx context.Context, pipeline apis.PipelineBase, req apis.CreatePipelineTriggerRequest) (*apis.PipelineTriggerBase, error) {
+	if _, err := p.getPipelineTrigger(ctx, pipeline, req.Name); err == nil {
+		return nil, bcode.ErrPipelineTriggerExist
+	} else if !errors.Is(err, bcode.ErrPipelineTriggerNotExist) {
+		return nil, err
+	}
+	userName, _ := ctx.Value(&apis.CtxKeyUser).(string)
+	trigger := &model.PipelineTrigger{
+		Project:      pipeline.Project.Name,
+		PipelineName: pipeline.Name,
+		Name:         req.Name,
+		Alias:        req.Alias,
+		Description:  req.Description,
+		Type:         req.Type,
+		Token:        genWebhookToken(),
+		Creator:      userName,
+	}
+	setPipelineTriggerSpec(trigger, req.PipelineTriggerSpec)
+	if err := p.checkPipelineTrigger(ctx, pipeline, trigger); err != nil {
+		return nil, err
+	}
+	if err := p.Store.Add(ctx, trigger); err != nil {
+		return nil, err
+	}
+	return pipelineTrigger2Base(trigger), nil
+}
+
+// UpdatePipelineTrigger update the settings of the trigger, the token and the type are not changed
+func (p pipelineServiceImpl) UpdatePipelineTrigger(ctx context.Context, pipeline apis.PipelineBase, name string, req apis.UpdatePipelineTriggerRequest) (*apis.PipelineTriggerBase, error) {
+	trigger, err := p.getPipelineTrigger(ctx, pipeline, name)
+	if err != nil {
+		return nil, err
+	}
+	trigger.Alias = req.Alias
+	trigger.Description = req.Description
+	secret := trigger.Secret
+	setPipelineTriggerSpec(trigger, req.PipelineTriggerSpec)
+	if trigger.Secret == "" {
+		trigger.Secret = secret
+	}
+	if err := p.checkPipelineTrigger(ctx, pipeline, trigger); err != nil {
+		return nil, err
+	}
+	if err := p.Store.Put(ctx, trigger); err != nil {
+		return nil, err
+	}
+	return pipelineTrigger2Base(trigger), nil
+}
+
+// DeletePipelineTrigger delete the trigger of the pipeline
+func (p pipelineServiceImpl) DeletePipelineTrigger(ctx context.Context, pipeline apis.PipelineBase, name string) error {
+	trigger, err := p.getPipelineTrigger(ctx, pipeline, name)
+	if err != nil {
+		return err
+	}
+	return p.Store.Delete(ctx, trigger)
+}
+
+// RunPipelineByTrigger run the pipeline with the parameters and the context values mapped from the payload,
+// the skipped response is returned if the payload does not match the conditions of the trigger.
+func (p pipelineServiceImpl) RunPipelineByTrigger(ctx context.Context, trigger *model.PipelineTrigger, payload map[string]interface{}) (interface{}, error) {
+	return p.runByTrigger(ctx, trigger, payload, nil)
+}
+
+// ExecutePipelineTriggers handle the source runs completed since the last check, the triggered runs record the chain of
+// the pipelines so that the loop is stopped even if the triggers are changed after the check of the loop.
+func (p pipelineServiceImpl) ExecutePipelineTriggers(ctx context.Context) error {
+	entities, err := p.Store.List(ctx, &model.PipelineTrigger{Type: model.PipelineTriggerTypePipeline}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	projects := map[string]*model.Project{}
+	for _, entity := range entities {
+		trigger := entity.(*model.PipelineTrigger)
+		project, exist := projects[trigger.Project]
+		if !exist {
+			project = &model.Project{Name: trigger.Project}
+			if err := p.Store.Get(ctx, project); err != nil {
+				klog.Errorf("failed to get the project %s of the pipeline trigger %s: %s", trigger.Project, trigger.Name, err.Error())
+				continue
+			}
+			projects[trigger.Project] = project
+		}
+		if err := p.handleSourceRuns(ctx, project, trigger); err != nil {
+			klog.Errorf("failed to handle the runs of the pipeline %s/%s by the trigger %s: %s", trigger.Project, trigger.SourcePipeline, trigger.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func (p pipelineServiceImpl) handleSourceRuns(ctx context.Context, project *model.Project, trigger *model.PipelineTrigger) error {
+	var runs v1alpha1.WorkflowRunList
+	if err := p.KubeClient.List(ctx, &runs, client.InNamespace(project.GetNamespace()), client.MatchingLabels{labelPipeline: trigger.SourcePipeline}); err != nil {
+		return err
+	}
+	handledTime := trigger.CreateTime
+	if trigger.HandledTime != nil {
+		handledTime = *trigger.HandledTime
+	}
+	var completed []v1alpha1.WorkflowRun
+	for _, run := range runs.Items {
+		if !run.Status.Finished && !run.Status.Terminated || run.Status.EndTime.IsZero() {
+			continue
+		}
+		endTime := run.Status.EndTime.Time
+		if endTime.Before(handledTime) || endTime.Equal(handledTime) && slices.Contains(trigger.HandledRuns, run.Name) {
+			continue
+		}
+		completed = append(completed, run)
+	}
+	if len(completed) == 0 {
+		return nil
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i].Status.EndTime.Before(&completed[j].Status.EndTime)
+	})
+
+	phases := trigger.SourcePhases
+	if len(phases) == 0 {
+		phases = defaultSourcePhases
+	}
+	for _, run := range completed {
+		endTime := run.Status.EndTime.Time
+		if endTime.After(handledTime) {
+			handledTime = endTime
+			trigger.HandledRuns = nil
+		}
+		trigger.HandledRuns = append(trigger.HandledRuns, run.Name)
+		if !slices.Contains(phases, string(run.Status.Phase)) {
+			continue
+		}
+		chain := getRunTriggerChain(run)
+		payload := map[string]interface{}{
+			"event": map[string]interface{}{
+				"pipeline":    trigger.SourcePipeline,
+				"run":         run.Name,
+				"phase":       string(run.Status.Phase),
+				"contextName": run.Labels[labelContext],
+				"parameters":  runParameterValues(getRunParameters(run)),
+			},
+		}
+		if _, err := p.runByTrigger(ctx, trigger, payload, append(chain, trigger.SourcePipeline)); err != nil {
+			klog.Errorf("failed to run the pipeline %s/%s by the trigger %s: %s", trigger.Project, trigger.PipelineName, trigger.Name, err.Error())
+		}
+	}
+
+	// reload the trigger, the settings may be changed during the runs
+	latest := &model.PipelineTrigger{Token: trigger.Token}
+	if err := p.Store.Get(ctx, latest); err != nil {
+		return err
+	}
+	latest.HandledTime = &handledTime
+	latest.HandledRuns = trigger.HandledRuns
+	return p.Store.Put(ctx, latest)
+}
+
+// runByTrigger run the pipeline as the creator of the trigger, the chain is the pipelines that lead to the run
+func (p pipelineServiceImpl) runByTrigger(ctx context.Context, trigger *model.PipelineTrigger, payload map[string]interface{}, chain []string) (interface{}, error) {
+	for _, condition := range trigger.Conditions {
+		if !matchPayloadCondition(payload, condition) {
+			return &apis.ApplicationWebhookSkippedResponse{
+				State:       model.WebhookDeliverySkipped,
+				Description: fmt.Sprintf("the condition %s %s %s is not matched", condition.Path, condition.Operator, strings.Join(condition.Values, ",")),
+			}, nil
+		}
+	}
+	if slices.Contains(chain, trigger.PipelineName) {
+		klog.Warningf("skip the trigger %s of the pipeline %s/%s, the pipelines trigger each other: %s", trigger.Name, trigger.Project, trigger.PipelineName, strings.Join(chain, " -> "))
+		return &apis.ApplicationWebhookSkippedResponse{
+			State:       model.WebhookDeliverySkipped,
+			Description: fmt.Sprintf("the pipelines trigger each other: %s -> %s", strings.Join(chain, " -> "), trigger.PipelineName),
+		}, nil
+	}
+
+	project := &model.Project{Name: trigger.Project}
+	if err := p.Store.Get(ctx, project); err != nil {
+		return nil, err
+	}
+	pipeline, err := getPipeline(ctx, p.Store, trigger.Project, trigger.PipelineName)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline.IsDeleted() {
+		return nil, bcode.ErrPipelineNotExist
+	}
+	runCtx := context.WithValue(ctx, &apis.CtxKeyProject, project)
+	runCtx = context.WithValue(runCtx, &apis.CtxKeyUser, trigger.Creator)
+
+	req := apis.RunPipelineRequest{ContextName: trigger.ContextName, Parameters: map[string]interface{}{}}
+	for name, expression := range trigger.Parameters {
+		if value, exist := lookupPayloadValue(payload, expression); exist {
+			req.Parameters[name] = value
+		}
+	}
+	opts := runOptions{
+		labels:  map[string]string{labelTrigger: trigger.Name},
+		context: map[string]interface{}{},
+	}
+	for key, expression := range trigger.ContextValues {
+		if value, exist := lookupPayloadValue(payload, expression); exist {
+			opts.context[key] = formatPayloadValue(value)
+		}
+	}
+	if len(chain) > 0 {
+		opts.annotations = map[string]string{annotationTriggerChain: strings.Join(chain, ",")}
+	}
+	return p.runPipeline(runCtx, *pipeline2PipelineBase(pipeline, *project), req, opts)
+}
+
+// checkPipelineTrigger check the settings of the trigger, the pipeline trigger must not form a loop
+func (p pipelineServiceImpl) checkPipelineTrigger(ctx context.Context, pipeline apis.PipelineBase, trigger *model.PipelineTrigger) error {
+	switch trigger.Type {
+	case model.PipelineTriggerTypeWebhook:
+		if trigger.PayloadType == "" {
+			return bcode.ErrPipelineTriggerInvalid.SetMessage("the payload type of the webhook trigger is required")
+		}
+		if err := checkAllowedCIDRs(trigger.AllowedCIDRs); err != nil {
+			return err
+		}
+		if err := checkGitTriggerRules(trigger.BranchFilters, trigger.TagFilters, ""); err != nil {
+			return err
+		}
+		if err := checkImageTriggerRules(nil, trigger.ImageTagFilter); err != nil {
+			return err
+		}
+	case model.PipelineTriggerTypePipeline:
+		if trigger.SourcePipeline == "" {
+			return bcode.ErrPipelineTriggerInvalid.SetMessage("the source pipeline of the pipeline trigger is required")
+		}
+		if _, err := getPipeline(ctx, p.Store, pipeline.Project.Name, trigger.SourcePipeline); err != nil {
+			return err
+		}
+		if err := p.checkPipelineTriggerLoop(ctx, trigger); err != nil {
+			return err
+		}
+	default:
+		return bcode.ErrPipelineTriggerInvalid.SetMessage(fmt.Sprintf("the trigger type %s is not supported", trigger.Type))
+	}
+	if err := checkPayloadMapping("", &model.PayloadMapping{Conditions: trigger.Conditions}); err != nil {
+		return bcode.ErrPipelineTriggerInvalid.SetMessage("the conditions of the trigger are invalid")
+	}
+	declared := map[string]bool{}
+	for _, parameter := range pipeline.Parameters {
+		declared[parameter.Name] = true
+	}
+	var expressions []string
+	for name, expression := range trigger.Parameters {
+		if !declared[name] {
+			return bcode.ErrPipelineTriggerInvalid.SetMessage(fmt.Sprintf("the parameter %s is not declared by the pipeline", name))
+		}
+		expressions = append(expressions, expression)
+	}
+	for _, expression := range trigger.ContextValues {
+		expressions = append(expressions, expression)
+	}
+	for _, expression := range expressions {
+		if !strings.HasPrefix(expression, "$") {
+			continue
+		}
+		if _, err := utils.Compile(expression); err != nil {
+			return bcode.ErrPipelineTriggerInvalid.SetMessage(fmt.Sprintf("the expression %s is invalid", expression))
+		}
+	}
+	if trigger.ContextName != "" {
+		if _, err := p.ContextService.GetContext(ctx, pipeline.Project.Name, pipeline.Name, trigger.ContextName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPipelineTriggerLoop check the pipeline is not run by the completion of itself through the pipeline triggers of the project
+func (p pipelineServiceImpl) checkPipelineTriggerLoop(ctx context.Context, trigger *model.PipelineTrigger) error {
+	entities, err := p.Store.List(ctx, &model.PipelineTrigger{Project: trigger.Project, Type: model.PipelineTriggerTypePipeline}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	// targets the pipelines run by the completion of the source pipeline
+	targets := map[string][]string{}
+	for _, entity := range entities {
+		existing := entity.(*model.PipelineTrigger)
+		if existing.Token == trigger.Token {
+			continue
+		}
+		targets[existing.SourcePipeline] = append(targets[existing.SourcePipeline], existing.PipelineName)
+	}
+	targets[trigger.SourcePipeline] = append(targets[trigger.SourcePipeline], trigger.PipelineName)
+
+	// the loop exists if the source pipeline is reached from the pipeline of the trigger
+	path := []string{trigger.SourcePipeline, trigger.PipelineName}
+	visited := map[string]bool{}
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		if name == trigger.SourcePipeline {
+			return true
+		}
+		if visited[name] {
+			return false
+		}
+		visited[name] = true
+		for _, target := range targets[name] {
+			path = append(path, target)
+			if visit(target) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+	if visit(trigger.PipelineName) {
+		return bcode.ErrPipelineTriggerLoop.SetMessage(fmt.Sprintf("the pipelines trigger each other: %s", strings.Join(path, " -> ")))
+	}
+	return nil
+}
+
+// deletePipelineTriggers delete all triggers of the pipeline
+func (p pipelineServiceImpl) deletePipelineTriggers(ctx context.Context, projectName, pipelineName string) error {
+	entities, err := p.Store.List(ctx, &model.PipelineTrigger{Project: projectName, PipelineName: pipelineName}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		if err := p.Store.Delete(ctx, entity); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p pipelineServiceImpl) getPipelineTrigger(ctx context.Context, pipeline apis.PipelineBase, name string) (*model.PipelineTrigger, error) {
+	entities, err := p.Store.List(ctx, &model.PipelineTrigger{Project: pipeline.Project.Name, PipelineName: pipeline.Name, Name: name}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		return nil, bcode.ErrPipelineTriggerNotExist
+	}
+	return entities[0].(*model.PipelineTrigger), nil
+}
+
+// getRunTriggerChain return the pipelines that lead to the run by the pipeline triggers
+func getRunTriggerChain(run v1alpha1.WorkflowRun) []string {
+	chain := run.GetAnnotations()[annotationTriggerChain]
+	if chain == "" {
+		return nil
+	}
+	return strings.Split(chain, ",")
+}
+
+func runParameterValues(parameters []apis.PipelineRunParameter) map[string]interface{} {
+	values := make(map[string]interface{}, len(parameters))
+	for _, parameter := range parameters {
+		values[parameter.Name] = parameter.Value
+	}
+	return values
+}
+
+func setPipelineTriggerSpec(trigger *model.PipelineTrigger, spec apis.PipelineTriggerSpec) {
+	trigger.PayloadType = spec.PayloadType
+	trigger.Registry = spec.Registry
+	trigger.Secret = spec.Secret
+	trigger.SignatureType = spec.SignatureType
+	trigger.AllowedCIDRs = spec.AllowedCIDRs
+	trigger.GitEvents = spec.GitEvents
+	trigger.BranchFilters = spec.BranchFilters
+	trigger.TagFilters = spec.TagFilters
+	trigger.ImageTagFilter = spec.ImageTagFilter
+	trigger.SourcePipeline = spec.SourcePipeline
+	trigger.SourcePhases = spec.SourcePhases
+	trigger.ContextName = spec.ContextName
+	trigger.Conditions = spec.Conditions
+	trigger.Parameters = spec.Parameters
+	trigger.ContextValues = spec.ContextValues
+}
+
+func pipelineTrigger2Base(trigger *model.PipelineTrigger) *apis.PipelineTriggerBase {
+	return &apis.PipelineTriggerBase{
+		Name:           trigger.Name,
+		Alias:          trigger.Alias,
+		Description:    trigger.Description,
+		Type:           trigger.Type,
+		Token:          trigger.Token,
+		PayloadType:    trigger.PayloadType,
+		Registry:       trigger.Registry,
+		HasSecret:      trigger.Secret != "",
+		SignatureType:  trigger.SignatureType,
+		AllowedCIDRs:   trigger.AllowedCIDRs,
+		GitEvents:      trigger.GitEvents,
+		BranchFilters:  trigger.BranchFilters,
+		TagFilters:     trigger.TagFilters,
+		ImageTagFilter: trigger.ImageTagFilter,
+		SourcePipeline: trigger.SourcePipeline,
+		SourcePhases:   trigger.SourcePhases,
+		ContextName:    trigger.ContextName,
+		Conditions:     trigger.Conditions,
+		Parameters:     trigger.Parameters,
+		ContextValues:  trigger.ContextValues,
+		Creator:        trigger.Creator,
+		CreateTime:     trigger.CreateTime,
+		UpdateTime:     trigger.UpdateTime,
+	}
+}
+
+// pipelineTrigger2Webhook convert the webhook settings of the pipeline trigger, so the requests are verified and parsed
+// in the same way as the application triggers
+func pipelineTrigger2Webhook(trigger *model.PipelineTrigger) *model.ApplicationTrigger {
+	return &model.ApplicationTrigger{
+		Name:           trigger.Name,
+		Token:          trigger.Token,
+		Type:           trigger.Type,
+		PayloadType:    trigger.PayloadType,
+		Registry:       trigger.Registry,
+		Secret:         trigger.Secret,
+		SignatureType:  trigger.SignatureType,
+		AllowedCIDRs:   trigger.AllowedCIDRs,
+		GitEvents:      trigger.GitEvents,
+		BranchFilters:  trigger.BranchFilters,
+		TagFilters:     trigger.TagFilters,
+		ImageTagFilter: trigger.ImageTagFilter,
+	}
+}
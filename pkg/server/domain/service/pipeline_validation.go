@@ -0,0 +1,375 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cuelang.org/go/cue/parser"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/kubevela/workflow/api/v1alpha1"
+	wfTypes "github.com/kubevela/workflow/pkg/types"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// PipelineSpecInvalidError is returned if the pipeline spec is invalid, the errors of the steps are written to the response body
+type PipelineSpecInvalidError struct {
+	*bcode.Bcode
+	Errors []apis.PipelineSpecError `json:"Errors"`
+}
+
+// Unwrap return the business code of the error
+func (e *PipelineSpecInvalidError) Unwrap() error {
+	return e.Bcode
+}
+
+// ValidatePipelineSpec validate the pipeline spec without saving it
+func (p pipelineServiceImpl) ValidatePipelineSpec(ctx context.Context, spec model.WorkflowSpec) (*apis.ValidatePipelineResponse, error) {
+	specErrors, err := p.validatePipelineSpec(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &apis.ValidatePipelineResponse{Valid: len(specErrors) == 0, Errors: specErrors}, nil
+}
+
+func (p pipelineServiceImpl) checkPipelineSpec(ctx context.Context, spec model.WorkflowSpec) error {
+	specErrors, err := p.validatePipelineSpec(ctx, spec)
+	if err != nil {
+		return err
+	}
+	if len(specErrors) == 0 {
+		return nil
+	}
+	var messages []string
+	for _, specError := range specErrors {
+		name := specError.Step
+		if specError.SubStep != "" {
+			name = specError.Step + "/" + specError.SubStep
+		}
+		if strings.HasPrefix(specError.Field, "properties.") {
+			name = name + " " + specError.Field
+		}
+		messages = append(messages, fmt.Sprintf("step %s: %s", name, specError.Message))
+	}
+	return &PipelineSpecInvalidError{
+		Bcode:  bcode.ErrPipelineSpecInvalid.SetMessage(strings.Join(messages, "; ")),
+		Errors: specErrors,
+	}
+}
+
+func (p pipelineServiceImpl) validatePipelineSpec(ctx context.Context, spec model.WorkflowSpec) ([]apis.PipelineSpecError, error) {
+	v := &pipelineSpecValidator{
+		ctx:               ctx,
+		definitionService: p.DefinitionService,
+		schemas:           map[string]*openapi3.Schema{},
+		specErrors:        []apis.PipelineSpecError{},
+	}
+	if err := v.validate(spec); err != nil {
+		return nil, err
+	}
+	return v.specErrors, nil
+}
+
+// pipelineSpecValidator collect the errors of the steps, the schemas of the step definitions are cached during the validation
+type pipelineSpecValidator struct {
+	ctx               context.Context
+	definitionService DefinitionService
+	// schemas the nil value means the definition has no parameter schema
+	schemas    map[string]*openapi3.Schema
+	specErrors []apis.PipelineSpecError
+}
+
+func (v *pipelineSpecValidator) addError(step, subStep, field, format string, args ...interface{}) {
+	v.specErrors = append(v.specErrors, apis.PipelineSpecError{
+		Step:    step,
+		SubStep: subStep,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *pipelineSpecValidator) validate(spec model.WorkflowSpec) error {
+	stepMode, subStepMode := v1alpha1.WorkflowModeStep, v1alpha1.WorkflowModeDAG
+	if spec.Mode != nil {
+		if !isValidWorkflowMode(spec.Mode.Steps) || !isValidWorkflowMode(spec.Mode.SubSteps) {
+			v.addError("", "", "mode", "only DAG and StepByStep modes are supported")
+		}
+		if spec.Mode.Steps != "" {
+			stepMode = spec.Mode.Steps
+		}
+		if spec.Mode.SubSteps != "" {
+			subStepMode = spec.Mode.SubSteps
+		}
+	}
+
+	names := map[string]bool{}
+	checkName := func(name, step, subStep string) {
+		if name == "" {
+			return
+		}
+		if names[name] {
+			v.addError(step, subStep, "name", "the step name %s is duplicated", name)
+		}
+		names[name] = true
+	}
+	var steps []model.WorkflowStepBase
+	for _, step := range spec.Steps {
+		steps = append(steps, step.WorkflowStepBase)
+		checkName(step.Name, step.Name, "")
+		if err := v.validateStep(step.WorkflowStepBase, step.Name, ""); err != nil {
+			return err
+		}
+		if step.Type != wfTypes.WorkflowStepTypeStepGroup {
+			if len(step.SubSteps) > 0 {
+				v.addError(step.Name, "", "subSteps", "only the step group has sub-steps")
+			}
+			continue
+		}
+		if step.Mode != "" && !isValidWorkflowMode(step.Mode) {
+			v.addError(step.Name, "", "mode", "only DAG and StepByStep modes are supported")
+		}
+		for _, subStep := range step.SubSteps {
+			checkName(subStep.Name, step.Name, subStep.Name)
+			if subStep.Type == wfTypes.WorkflowStepTypeStepGroup {
+				v.addError(step.Name, subStep.Name, "type", "the step group can not be nested")
+				continue
+			}
+			if err := v.validateStep(subStep, step.Name, subStep.Name); err != nil {
+				return err
+			}
+		}
+		v.validateDependsOn(step.SubSteps, step.Name)
+	}
+	v.validateDependsOn(steps, "")
+	v.validateInputs(spec.Steps, stepMode, subStepMode)
+	return nil
+}
+
+func (v *pipelineSpecValidator) validateStep(step model.WorkflowStepBase, stepName, subStepName string) error {
+	if step.Name == "" {
+		v.addError(stepName, subStepName, "name", "the name is required")
+	}
+	if step.If != "" {
+		if _, err := parser.ParseExpr("if", step.If); err != nil {
+			v.addError(stepName, subStepName, "if", "the if expression is invalid: %s", err.Error())
+		}
+	}
+	if step.Timeout != "" {
+		if timeout, err := time.ParseDuration(step.Timeout); err != nil || timeout <= 0 {
+			v.addError(stepName, subStepName, "timeout", "the timeout %s is not a positive duration, such as 10m", step.Timeout)
+		}
+	}
+	switch step.Type {
+	case "":
+		v.addError(stepName, subStepName, "type", "the type is required")
+		return nil
+	case wfTypes.WorkflowStepTypeStepGroup, wfTypes.WorkflowStepTypeSuspend:
+		return nil
+	}
+	schema, exist, err := v.getSchema(step.Type)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		v.addError(stepName, subStepName, "type", "the workflow step definition %s is not exist", step.Type)
+		return nil
+	}
+	if schema == nil {
+		return nil
+	}
+	var properties interface{} = map[string]interface{}{}
+	if step.Properties != nil {
+		// convert the numbers to float64 as the schema validation requires
+		content, err := json.Marshal(step.Properties)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(content, &properties); err != nil {
+			return err
+		}
+	}
+	if err := schema.VisitJSON(properties, openapi3.MultiErrors()); err != nil {
+		v.addSchemaErrors(stepName, subStepName, err)
+	}
+	return nil
+}
+
+func (v *pipelineSpecValidator) addSchemaErrors(stepName, subStepName string, err error) {
+	var multiError openapi3.MultiError
+	if errors.As(err, &multiError) {
+		for _, e := range multiError {
+			v.addSchemaErrors(stepName, subStepName, e)
+		}
+		return
+	}
+	var schemaError *openapi3.SchemaError
+	if errors.As(err, &schemaError) {
+		field := "properties"
+		if pointer := schemaError.JSONPointer(); len(pointer) > 0 {
+			field = field + "." + strings.Join(pointer, ".")
+		}
+		v.addError(stepName, subStepName, field, "%s", schemaError.Reason)
+		return
+	}
+	v.addError(stepName, subStepName, "properties", "%s", err.Error())
+}
+
+// getSchema return the parameter schema of the workflow step definition, it is false if the definition is not exist
+func (v *pipelineSpecValidator) getSchema(stepType string) (*openapi3.Schema, bool, error) {
+	if schema, exist := v.schemas[stepType]; exist {
+		return schema, true, nil
+	}
+	definition, err := v.definitionService.DetailDefinition(v.ctx, stepType, "workflowstep")
+	if err != nil {
+		if errors.Is(err, bcode.ErrDefinitionNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	v.schemas[stepType] = definition.APISchema
+	return definition.APISchema, true, nil
+}
+
+// validateDependsOn check the dependencies of the steps in the same level exist and are not circular
+func (v *pipelineSpecValidator) validateDependsOn(steps []model.WorkflowStepBase, group string) {
+	addError := func(name, format string, args ...interface{}) {
+		if group == "" {
+			v.addError(name, "", "dependsOn", format, args...)
+			return
+		}
+		v.addError(group, name, "dependsOn", format, args...)
+	}
+	names := map[string]bool{}
+	for _, step := range steps {
+		names[step.Name] = true
+	}
+	dependencies := map[string][]string{}
+	for _, step := range steps {
+		for _, dependency := range step.DependsOn {
+			switch {
+			case dependency == step.Name:
+				addError(step.Name, "the step can not depend on itself")
+			case !names[dependency]:
+				addError(step.Name, "the step %s is not exist", dependency)
+			default:
+				dependencies[step.Name] = append(dependencies[step.Name], dependency)
+			}
+		}
+	}
+
+	// remove the steps without the unresolved dependencies until no step could be removed, the rest steps form the cycles
+	resolved := map[string]bool{}
+	for changed := true; changed; {
+		changed = false
+		for _, step := range steps {
+			if resolved[step.Name] {
+				continue
+			}
+			ready := true
+			for _, dependency := range dependencies[step.Name] {
+				if !resolved[dependency] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				resolved[step.Name] = true
+				changed = true
+			}
+		}
+	}
+	var circular []string
+	for _, step := range steps {
+		if !resolved[step.Name] {
+			circular = append(circular, step.Name)
+		}
+	}
+	if len(circular) > 0 {
+		addError(circular[0], "the steps %s depend on each other", strings.Join(circular, ", "))
+	}
+}
+
+// validateInputs check the inputs reference the outputs of the earlier steps.
+// In the DAG mode, the input could reference the output of any other step because the step waits for it.
+func (v *pipelineSpecValidator) validateInputs(steps []model.WorkflowStep, stepMode, subStepMode v1alpha1.WorkflowMode) {
+	stepOutputs := make([]map[string]bool, len(steps))
+	for i, step := range steps {
+		stepOutputs[i] = map[string]bool{}
+		for _, output := range step.Outputs {
+			stepOutputs[i][output.Name] = true
+		}
+		for _, subStep := range step.SubSteps {
+			for _, output := range subStep.Outputs {
+				stepOutputs[i][output.Name] = true
+			}
+		}
+	}
+	for i, step := range steps {
+		available := map[string]bool{}
+		for j := range steps {
+			if j == i || (stepMode != v1alpha1.WorkflowModeDAG && j > i) {
+				continue
+			}
+			for name := range stepOutputs[j] {
+				available[name] = true
+			}
+		}
+		for _, input := range step.Inputs {
+			if !available[input.From] {
+				v.addError(step.Name, "", "inputs", "the input %s is not the output of the earlier steps", input.From)
+			}
+		}
+		mode := subStepMode
+		if step.Mode != "" {
+			mode = step.Mode
+		}
+		for k, subStep := range step.SubSteps {
+			for _, input := range subStep.Inputs {
+				if available[input.From] || isSiblingOutput(step.SubSteps, k, input.From, mode) {
+					continue
+				}
+				v.addError(step.Name, subStep.Name, "inputs", "the input %s is not the output of the earlier steps", input.From)
+			}
+		}
+	}
+}
+
+func isSiblingOutput(subSteps []model.WorkflowStepBase, index int, name string, mode v1alpha1.WorkflowMode) bool {
+	for i, subStep := range subSteps {
+		if i == index || (mode != v1alpha1.WorkflowModeDAG && i > index) {
+			continue
+		}
+		for _, output := range subStep.Outputs {
+			if output.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isValidWorkflowMode(mode v1alpha1.WorkflowMode) bool {
+	return mode == "" || mode == v1alpha1.WorkflowModeStep || mode == v1alpha1.WorkflowModeDAG
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/kubevela/workflow/api/v1alpha1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// ListPipelineVersions list the versions of the pipeline, the latest version is the first
+func (p pipelineServiceImpl) ListPipelineVersions(ctx context.Context, pipeline apis.PipelineBase) (*apis.ListPipelineVersionsResponse, error) {
+	entities, err := p.Store.List(ctx, &model.PipelineVersion{Project: pipeline.Project.Name, PipelineName: pipeline.Name}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	res := &apis.ListPipelineVersionsResponse{Versions: []apis.PipelineVersionBase{}}
+	for _, entity := range entities {
+		res.Versions = append(res.Versions, pipelineVersion2Base(entity.(*model.PipelineVersion)))
+	}
+	sort.Slice(res.Versions, func(i, j int) bool {
+		return res.Versions[i].Version > res.Versions[j].Version
+	})
+	res.Total = int64(len(res.Versions))
+	return res, nil
+}
+
+// GetPipelineVersion get the spec of the pipeline version
+func (p pipelineServiceImpl) GetPipelineVersion(ctx context.Context, pipeline apis.PipelineBase, version int64) (*apis.PipelineVersionDetail, error) {
+	pipelineVersion, err := getPipelineVersion(ctx, p.Store, pipeline.Project.Name, pipeline.Name, version)
+	if err != nil {
+		return nil, err
+	}
+	return &apis.PipelineVersionDetail{
+		PipelineVersionBase: pipelineVersion2Base(pipelineVersion),
+		Spec:                pipelineVersion.Spec,
+		Parameters:          pipelineVersion.Parameters,
+	}, nil
+}
+
+// ComparePipelineVersions compare the specs of two pipeline versions
+func (p pipelineServiceImpl) ComparePipelineVersions(ctx context.Context, pipeline apis.PipelineBase, req apis.ComparePipelineVersionsRequest) (*apis.ComparePipelineVersionsResponse, error) {
+	base, err := getPipelineVersion(ctx, p.Store, pipeline.Project.Name, pipeline.Name, req.BaseVersion)
+	if err != nil {
+		return nil, err
+	}
+	target, err := getPipelineVersion(ctx, p.Store, pipeline.Project.Name, pipeline.Name, req.TargetVersion)
+	if err != nil {
+		return nil, err
+	}
+	var baseSteps, targetSteps []interface{}
+	for _, step := range base.Spec.Steps {
+		baseSteps = append(baseSteps, step)
+	}
+	for _, step := range target.Spec.Steps {
+		targetSteps = append(targetSteps, step)
+	}
+	res := &apis.ComparePipelineVersionsResponse{
+		BaseVersion:   req.BaseVersion,
+		TargetVersion: req.TargetVersion,
+	}
+	if res.Steps, err = diffItems(baseSteps, targetSteps, func(item interface{}) (string, string) {
+		step := item.(model.WorkflowStep)
+		return step.Name, step.Type
+	}); err != nil {
+		return nil, err
+	}
+	baseName, targetName := fmt.Sprintf("v%d", req.BaseVersion), fmt.Sprintf("v%d", req.TargetVersion)
+	if res.UnifiedDiff, err = unifiedDiff(baseName, targetName, base.Spec, target.Spec); err != nil {
+		return nil, err
+	}
+	res.IsDiff = res.UnifiedDiff != ""
+	return res, nil
+}
+
+// RestorePipelineVersion replace the pipeline spec with the spec of the version, a new version is recorded
+func (p pipelineServiceImpl) RestorePipelineVersion(ctx context.Context, base apis.PipelineBase, version int64, req apis.RestorePipelineVersionRequest) (*apis.PipelineBase, error) {
+	project := ctx.Value(&apis.CtxKeyProject).(*model.Project)
+	pipelineVersion, err := getPipelineVersion(ctx, p.Store, project.Name, base.Name, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkPipelineSpec(ctx, pipelineVersion.Spec); err != nil {
+		return nil, err
+	}
+	if err := checkPipelineParameters(pipelineVersion.Parameters); err != nil {
+		return nil, err
+	}
+	pipeline, err := getPipeline(ctx, p.Store, project.Name, base.Name)
+	if err != nil {
+		return nil, err
+	}
+	message := req.Message
+	if message == "" {
+		message = fmt.Sprintf("restore the version %d", version)
+	}
+	if err := p.recordPipelineVersion(ctx, pipeline, pipelineVersion.Spec, pipelineVersion.Parameters, message, version); err != nil {
+		return nil, err
+	}
+	if err := p.Store.Put(ctx, pipeline); err != nil {
+		return nil, err
+	}
+	return pipeline2PipelineBase(pipeline, *project), nil
+}
+
+// recordPipelineVersion set the spec and the parameters of the pipeline and record a new version if either of them is changed.
+// The current spec of the pipeline created before the versions are recorded is saved as the first version.
+func (p pipelineServiceImpl) recordPipelineVersion(ctx context.Context, pipeline *model.Pipeline, spec model.WorkflowSpec, parameters []model.PipelineParameter, message string, restoredFrom int64) error {
+	changed, err := pipelineContentChanged(pipeline.Spec, spec)
+	if err != nil {
+		return err
+	}
+	if !changed && (len(pipeline.Parameters) > 0 || len(parameters) > 0) {
+		if changed, err = pipelineContentChanged(pipeline.Parameters, parameters); err != nil {
+			return err
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if pipeline.Version == 0 && len(pipeline.Spec.Steps) > 0 {
+		if err := p.addPipelineVersion(ctx, &model.PipelineVersion{
+			Project:      pipeline.Project,
+			PipelineName: pipeline.Name,
+			Version:      1,
+			Spec:         pipeline.Spec,
+			Parameters:   pipeline.Parameters,
+			Message:      "the spec before the versions are recorded",
+		}); err != nil {
+			return err
+		}
+		pipeline.Version = 1
+	}
+	userName, _ := ctx.Value(&apis.CtxKeyUser).(string)
+	pipelineVersion := &model.PipelineVersion{
+		Project:      pipeline.Project,
+		PipelineName: pipeline.Name,
+		Version:      pipeline.Version + 1,
+		Spec:         spec,
+		Parameters:   parameters,
+		Author:       userName,
+		Message:      message,
+		RestoredFrom: restoredFrom,
+	}
+	if err := p.addPipelineVersion(ctx, pipelineVersion); err != nil {
+		return err
+	}
+	pipeline.Spec = spec
+	pipeline.Parameters = parameters
+	pipeline.Version = pipelineVersion.Version
+	return nil
+}
+
+func (p pipelineServiceImpl) addPipelineVersion(ctx context.Context, pipelineVersion *model.PipelineVersion) error {
+	if err := p.Store.Add(ctx, pipelineVersion); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return bcode.ErrPipelineVersionConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// deletePipelineVersions delete all versions of the pipeline
+func (p pipelineServiceImpl) deletePipelineVersions(ctx context.Context, projectName, pipelineName string) error {
+	entities, err := p.Store.List(ctx, &model.PipelineVersion{Project: projectName, PipelineName: pipelineName}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		if err := p.Store.Delete(ctx, entity); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getRunPipelineVersion return the version of the pipeline spec used by the run, it is zero if the run is not annotated
+func getRunPipelineVersion(run v1alpha1.WorkflowRun) int64 {
+	version, err := strconv.ParseInt(run.GetAnnotations()[annotationPipelineVersion], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// getRunPipelineSpec return the pipeline spec used by the run, the current spec is returned if the version is unknown
+func getRunPipelineSpec(ctx context.Context, store datastore.DataStore, run v1alpha1.WorkflowRun, pipeline *model.Pipeline) model.WorkflowSpec {
+	version := getRunPipelineVersion(run)
+	if version == 0 || version == pipeline.Version {
+		return pipeline.Spec
+	}
+	pipelineVersion, err := getPipelineVersion(ctx, store, pipeline.Project, pipeline.Name, version)
+	if err != nil {
+		klog.Warningf("failed to get the version %d of the pipeline %s/%s: %s", version, pipeline.Project, pipeline.Name, err.Error())
+		return pipeline.Spec
+	}
+	return pipelineVersion.Spec
+}
+
+func getPipelineVersion(ctx context.Context, store datastore.DataStore, projectName, pipelineName string, version int64) (*model.PipelineVersion, error) {
+	pipelineVersion := &model.PipelineVersion{Project: projectName, PipelineName: pipelineName, Version: version}
+	if err := store.Get(ctx, pipelineVersion); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrPipelineVersionNotExist
+		}
+		return nil, err
+	}
+	return pipelineVersion, nil
+}
+
+// pipelineContentChanged compare the versioned content of the pipeline, such as the spec and the parameters
+func pipelineContentChanged(current, spec interface{}) (bool, error) {
+	currentContent, err := json.Marshal(current)
+	if err != nil {
+		return false, err
+	}
+	content, err := json.Marshal(spec)
+	if err != nil {
+		return false, err
+	}
+	return string(currentContent) != string(content), nil
+}
+
+func pipelineVersion2Base(pipelineVersion *model.PipelineVersion) apis.PipelineVersionBase {
+	return apis.PipelineVersionBase{
+		Version:      pipelineVersion.Version,
+		Author:       pipelineVersion.Author,
+		Message:      pipelineVersion.Message,
+		CreateTime:   pipelineVersion.CreateTime,
+		RestoredFrom: pipelineVersion.RestoredFrom,
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"time"
 
 	terraformapi "github.com/oam-dev/terraform-controller/api/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -32,6 +33,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/utils"
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/repository"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 	apiutils "github.com/kubevela/velaux/pkg/server/utils"
@@ -46,7 +48,10 @@ type ProjectService interface {
 	ListUserProjects(ctx context.Context, userName string) ([]*apisv1.ProjectBase, error)
 	CreateProject(ctx context.Context, req apisv1.CreateProjectRequest) (*apisv1.ProjectBase, error)
 	DeleteProject(ctx context.Context, projectName string) error
+	RestoreProject(ctx context.Context, project *model.Project) error
+	PurgeProject(ctx context.Context, projectName string) error
 	UpdateProject(ctx context.Context, projectName string, req apisv1.UpdateProjectRequest) (*apisv1.ProjectBase, error)
+	UpdatePromotionPath(ctx context.Context, projectName string, req apisv1.UpdatePromotionPathRequest) (*apisv1.ProjectBase, error)
 	ListProjectUser(ctx context.Context, projectName string, page, pageSize int) (*apisv1.ListProjectUsersResponse, error)
 	AddProjectUser(ctx context.Context, projectName string, req apisv1.AddProjectUserRequest) (*apisv1.ProjectUserBase, error)
 	DeleteProjectUser(ctx context.Context, projectName string, userName string) error
@@ -61,6 +66,7 @@ type projectServiceImpl struct {
 	TargetService TargetService       `inject:""`
 	UserService   UserService         `inject:""`
 	EnvService    EnvService          `inject:""`
+	EventBus      EventBus            `inject:""`
 }
 
 // NewProjectService new project service
@@ -77,6 +83,9 @@ func (p *projectServiceImpl) GetProject(ctx context.Context, projectName string)
 		}
 		return nil, err
 	}
+	if project.IsDeleted() {
+		return nil, bcode.ErrProjectIsNotExist
+	}
 	if _, err := utils.GetNamespace(ctx, p.K8sClient, project.GetNamespace()); err != nil {
 		if apierrors.IsNotFound(err) {
 			if err := utils.CreateNamespace(ctx, p.K8sClient, projectName); err != nil && !apierrors.IsAlreadyExists(err) {
@@ -103,13 +112,30 @@ func (p *projectServiceImpl) DetailProject(ctx context.Context, projectName stri
 
 func listProjects(ctx context.Context, ds datastore.DataStore, page, pageSize int) (*apisv1.ListProjectResponse, error) {
 	var project = model.Project{}
-	entities, err := ds.List(ctx, &project, &datastore.ListOptions{Page: page, PageSize: pageSize, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	entities, err := ds.List(ctx, &project, &datastore.ListOptions{SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
 	if err != nil {
 		return nil, err
 	}
-	var projects []*apisv1.ProjectBase
+	// the projects in the recycle bin are filtered, so paginate after filtering
+	var available []*model.Project
 	for _, entity := range entities {
-		project := entity.(*model.Project)
+		if project := entity.(*model.Project); !project.IsDeleted() {
+			available = append(available, project)
+		}
+	}
+	total := int64(len(available))
+	if page > 0 && pageSize > 0 {
+		start, end := (page-1)*pageSize, page*pageSize
+		if start > len(available) {
+			start = len(available)
+		}
+		if end > len(available) {
+			end = len(available)
+		}
+		available = available[start:end]
+	}
+	var projects []*apisv1.ProjectBase
+	for _, project := range available {
 		var user = &model.User{Name: project.Owner}
 		if project.Owner != "" {
 			if err := ds.Get(ctx, user); err != nil {
@@ -118,10 +144,6 @@ func listProjects(ctx context.Context, ds datastore.DataStore, page, pageSize in
 		}
 		projects = append(projects, ConvertProjectModel2Base(project, user))
 	}
-	total, err := ds.Count(ctx, &project, nil)
-	if err != nil {
-		return nil, err
-	}
 	return &apisv1.ListProjectResponse{Projects: projects, Total: total}, nil
 }
 
@@ -149,7 +171,11 @@ func (p *projectServiceImpl) ListUserProjects(ctx context.Context, userName stri
 	}
 	var projectBases []*apisv1.ProjectBase
 	for _, entity := range projectEntities {
-		projectBases = append(projectBases, ConvertProjectModel2Base(entity.(*model.Project), nil))
+		project := entity.(*model.Project)
+		if project.IsDeleted() {
+			continue
+		}
+		projectBases = append(projectBases, ConvertProjectModel2Base(project, nil))
 	}
 	return projectBases, nil
 }
@@ -159,22 +185,41 @@ func (p *projectServiceImpl) ListProjects(ctx context.Context, page, pageSize in
 	return listProjects(ctx, p.Store, page, pageSize)
 }
 
-// DeleteProject delete a project
+// DeleteProject move a project into the recycle bin, the applications in the recycle bin don't block it
 func (p *projectServiceImpl) DeleteProject(ctx context.Context, name string) error {
-	_, err := p.GetProject(ctx, name)
+	project, err := p.GetProject(ctx, name)
 	if err != nil {
 		return err
 	}
-
-	count, err := p.Store.Count(ctx, &model.Application{Project: name}, nil)
+	apps, err := p.Store.List(ctx, &model.Application{Project: name}, nil)
 	if err != nil {
 		return err
 	}
-	if count > 0 {
-		return bcode.ErrProjectDenyDeleteByApplication
+	for _, entity := range apps {
+		if !entity.(*model.Application).IsDeleted() {
+			return bcode.ErrProjectDenyDeleteByApplication
+		}
 	}
+	if err := p.checkProjectResources(ctx, name); err != nil {
+		return err
+	}
+	userName, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	project.MarkDeleted(userName, time.Now())
+	if err := p.Store.Put(ctx, project); err != nil {
+		return err
+	}
+	publishEvent(ctx, p.EventBus, newDomainEvent(model.EventProjectDeleted, project.Name, "project", model.ProjectEventData{ProjectName: project.Name, Owner: project.Owner}))
+	return nil
+}
 
-	count, err = p.Store.Count(ctx, &model.Target{Project: name}, nil)
+// RestoreProject restore a project from the recycle bin
+func (p *projectServiceImpl) RestoreProject(ctx context.Context, project *model.Project) error {
+	project.ClearDeleted()
+	return p.Store.Put(ctx, project)
+}
+
+func (p *projectServiceImpl) checkProjectResources(ctx context.Context, name string) error {
+	count, err := p.Store.Count(ctx, &model.Target{Project: name}, nil)
 	if err != nil {
 		return err
 	}
@@ -189,11 +234,26 @@ func (p *projectServiceImpl) DeleteProject(ctx context.Context, name string) err
 	if count > 0 {
 		return bcode.ErrProjectDenyDeleteByEnvironment
 	}
+	return nil
+}
+
+// PurgeProject delete a project and its users, roles and permissions permanently
+func (p *projectServiceImpl) PurgeProject(ctx context.Context, name string) error {
+	count, err := p.Store.Count(ctx, &model.Application{Project: name}, nil)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return bcode.ErrProjectDenyDeleteByApplication
+	}
+	if err := p.checkProjectResources(ctx, name); err != nil {
+		return err
+	}
 
 	users, _ := p.ListProjectUser(ctx, name, 0, 0)
 	for _, user := range users.Users {
-		err := p.DeleteProjectUser(ctx, name, user.UserName)
-		if err != nil {
+		err := p.Store.Delete(ctx, &model.ProjectUser{Username: user.UserName, ProjectName: name})
+		if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
 			return err
 		}
 	}
@@ -271,6 +331,7 @@ func (p *projectServiceImpl) CreateProject(ctx context.Context, req apisv1.Creat
 	if err := p.RbacService.SyncDefaultRoleAndUsersForProject(ctx, newProject); err != nil {
 		klog.Errorf("fail to sync the default role and users for the project: %s", err.Error())
 	}
+	publishEvent(ctx, p.EventBus, newDomainEvent(model.EventProjectCreated, newProject.Name, "project", model.ProjectEventData{ProjectName: newProject.Name, Owner: owner}))
 
 	return ConvertProjectModel2Base(newProject, user), nil
 }
@@ -325,6 +386,30 @@ func (p *projectServiceImpl) UpdateProject(ctx context.Context, projectName stri
 	return ConvertProjectModel2Base(project, user), nil
 }
 
+// UpdatePromotionPath update the ordered envs that the applications are promoted through
+func (p *projectServiceImpl) UpdatePromotionPath(ctx context.Context, projectName string, req apisv1.UpdatePromotionPathRequest) (*apisv1.ProjectBase, error) {
+	project, err := p.GetProject(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	envs := map[string]bool{}
+	for _, envName := range req.Envs {
+		env, err := repository.GetEnv(ctx, p.Store, envName)
+		if err != nil || env.Project != project.Name || envs[envName] {
+			return nil, bcode.ErrPromotionPathInvalid
+		}
+		envs[envName] = true
+	}
+	project.PromotionPath = nil
+	if len(req.Envs) > 0 {
+		project.PromotionPath = &model.PromotionPath{Envs: req.Envs, ForbidSkip: req.ForbidSkip}
+	}
+	if err := p.Store.Put(ctx, project); err != nil {
+		return nil, err
+	}
+	return ConvertProjectModel2Base(project, nil), nil
+}
+
 func (p *projectServiceImpl) ListProjectUser(ctx context.Context, projectName string, page, pageSize int) (*apisv1.ListProjectUsersResponse, error) {
 	var projectUser = model.ProjectUser{
 		ProjectName: projectName,
@@ -481,13 +566,14 @@ func (p *projectServiceImpl) ListTerraformProviders(ctx context.Context, _ strin
 // ConvertProjectModel2Base convert project model to base struct
 func ConvertProjectModel2Base(project *model.Project, owner *model.User) *apisv1.ProjectBase {
 	base := &apisv1.ProjectBase{
-		Name:        project.Name,
-		Description: project.Description,
-		Alias:       project.Alias,
-		CreateTime:  project.CreateTime,
-		UpdateTime:  project.UpdateTime,
-		Owner:       apisv1.NameAlias{Name: project.Owner},
-		Namespace:   project.GetNamespace(),
+		Name:          project.Name,
+		Description:   project.Description,
+		Alias:         project.Alias,
+		CreateTime:    project.CreateTime,
+		UpdateTime:    project.UpdateTime,
+		Owner:         apisv1.NameAlias{Name: project.Owner},
+		Namespace:     project.GetNamespace(),
+		PromotionPath: project.PromotionPath,
 	}
 	if owner != nil && owner.Name == project.Owner {
 		base.Owner = apisv1.NameAlias{Name: owner.Name, Alias: owner.Alias}
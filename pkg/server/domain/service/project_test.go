@@ -58,6 +58,7 @@ var _ = Describe("Test project service functions", func() {
 		// reset all projects
 		for _, p := range pp.Projects {
 			_ = projectService.DeleteProject(context.TODO(), p.Name)
+			_ = projectService.PurgeProject(context.TODO(), p.Name)
 		}
 		ctx := context.WithValue(context.TODO(), &apisv1.CtxKeyUser, FakeAdminName)
 		envs, err := envService.ListEnvs(ctx, 0, 0, apisv1.ListEnvOptions{})
@@ -86,6 +87,8 @@ var _ = Describe("Test project service functions", func() {
 		Expect(err).Should(BeNil())
 		err = projectService.DeleteProject(context.TODO(), "test-project")
 		Expect(err).Should(BeNil())
+		err = projectService.PurgeProject(context.TODO(), "test-project")
+		Expect(err).Should(BeNil())
 	})
 
 	It("Test Update project function", func() {
@@ -135,6 +138,8 @@ var _ = Describe("Test project service functions", func() {
 		Expect(err).Should(BeEquivalentTo(bcode.ErrProjectOwnerInvalid))
 		err = projectService.DeleteProject(context.TODO(), "test-project")
 		Expect(err).Should(BeNil())
+		err = projectService.PurgeProject(context.TODO(), "test-project")
+		Expect(err).Should(BeNil())
 	})
 
 	It("Test Create project user function", func() {
@@ -200,6 +205,8 @@ var _ = Describe("Test project service functions", func() {
 		Expect(err).Should(BeNil())
 		err = projectService.DeleteProject(context.TODO(), "test-project")
 		Expect(err).Should(BeNil())
+		err = projectService.PurgeProject(context.TODO(), "test-project")
+		Expect(err).Should(BeNil())
 		perms, err := projectService.RbacService.ListPermissions(context.TODO(), "test-project")
 		Expect(err).Should(BeNil())
 		Expect(len(perms)).Should(BeEquivalentTo(0))
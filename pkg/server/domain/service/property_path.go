@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// propertyPathSegment the key of the object or the index of the array
+type propertyPathSegment struct {
+	key   string
+	index int
+}
+
+// parsePropertyPath parse the path of the properties such as `values.image.tag` and `containers[1].image`,
+// the application templates and the webhook triggers share the syntax.
+func parsePropertyPath(p string) ([]propertyPathSegment, error) {
+	var segments []propertyPathSegment
+	for _, part := range strings.Split(p, ".") {
+		key := part
+		var indexes []string
+		if i := strings.Index(part, "["); i >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return nil, fmt.Errorf("invalid index of %s", part)
+			}
+			key = part[:i]
+			indexes = strings.Split(part[i+1:len(part)-1], "][")
+		}
+		if key == "" {
+			return nil, fmt.Errorf("empty key in the path %s", p)
+		}
+		segments = append(segments, propertyPathSegment{key: key, index: -1})
+		for _, index := range indexes {
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid index %s", index)
+			}
+			segments = append(segments, propertyPathSegment{index: i})
+		}
+	}
+	return segments, nil
+}
+
+// getPropertyByPath get the value by the path, nil is returned if the path is invalid or does not exist
+func getPropertyByPath(properties map[string]interface{}, p string) interface{} {
+	segments, err := parsePropertyPath(p)
+	if err != nil {
+		return nil
+	}
+	var current interface{} = properties
+	for _, segment := range segments {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			if segment.index >= 0 {
+				return nil
+			}
+			current = node[segment.key]
+		case []interface{}:
+			if segment.index < 0 || segment.index >= len(node) {
+				return nil
+			}
+			current = node[segment.index]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+// setPropertyByPath set the value by the path, the missing objects are created but the arrays must exist
+func setPropertyByPath(properties map[string]interface{}, p string, value interface{}) error {
+	segments, err := parsePropertyPath(p)
+	if err != nil {
+		return err
+	}
+	var current interface{} = properties
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch node := current.(type) {
+		case map[string]interface{}:
+			if segment.index >= 0 {
+				return fmt.Errorf("%s is not an array", segments[i-1].key)
+			}
+			if last {
+				node[segment.key] = value
+				return nil
+			}
+			next, exist := node[segment.key]
+			if !exist || next == nil {
+				if segments[i+1].index >= 0 {
+					return fmt.Errorf("the array %s does not exist", segment.key)
+				}
+				next = map[string]interface{}{}
+				node[segment.key] = next
+			}
+			current = next
+		case []interface{}:
+			if segment.index < 0 || segment.index >= len(node) {
+				return fmt.Errorf("the index of %s is out of range", p)
+			}
+			if last {
+				node[segment.index] = value
+				return nil
+			}
+			current = node[segment.index]
+		default:
+			return fmt.Errorf("the property of %s is not an object or an array", p)
+		}
+	}
+	return nil
+}
@@ -69,6 +69,11 @@ var defaultProjectPermissionTemplate = []*model.PermissionTemplate{
 			"project:{projectName}/environment:*",
 			"project:{projectName}/application:*/*",
 			"project:{projectName}/pipeline:*/*",
+			"project:{projectName}/recycleBin:*",
+			"project:{projectName}/freezeWindow:*",
+			"project:{projectName}/notificationChannel:*",
+			"project:{projectName}/notificationSubscription:*",
+			"project:{projectName}/notificationDelivery:*",
 		},
 		Actions: []string{"detail", "list"},
 		Effect:  "Allow",
@@ -116,6 +121,14 @@ var defaultProjectPermissionTemplate = []*model.PermissionTemplate{
 		Effect:  "Allow",
 		Scope:   "project",
 	},
+	{
+		Name:      "recycle-bin-management",
+		Alias:     "Recycle Bin Management",
+		Resources: []string{"project:{projectName}/recycleBin:*"},
+		Actions:   []string{"*"},
+		Effect:    "Allow",
+		Scope:     "project",
+	},
 }
 
 var defaultPlatformPermission = []*model.PermissionTemplate{
@@ -239,6 +252,12 @@ var ResourceMaps = map[string]resourceMetadata{
 						},
 					},
 					"trigger": {},
+					"changeRequest": {
+						pathName: "changeRequest",
+					},
+					"deployLock": {
+						pathName: "deployLock",
+					},
 				},
 			},
 			"environment": {
@@ -258,7 +277,19 @@ var ResourceMaps = map[string]resourceMetadata{
 			"config": {
 				pathName: "configName",
 			},
-			"provider": {},
+			"provider":   {},
+			"recycleBin": {},
+			"freezeWindow": {
+				pathName: "freezeWindowName",
+			},
+			"deployLock": {},
+			"notificationChannel": {
+				pathName: "channelName",
+			},
+			"notificationSubscription": {
+				pathName: "subscriptionName",
+			},
+			"notificationDelivery": {},
 			"pipeline": {
 				pathName: "pipelineName",
 				subResources: map[string]resourceMetadata{
@@ -307,11 +338,16 @@ var ResourceMaps = map[string]resourceMetadata{
 			},
 		},
 	},
-	"cloudshell":     {},
-	"config":         {},
-	"configTemplate": {},
-	"plugin":         {},
-	"managePlugin":   {},
+	"cloudshell":          {},
+	"config":              {},
+	"configTemplate":      {},
+	"plugin":              {},
+	"managePlugin":        {},
+	"recycleBin":          {},
+	"platformAppTemplate": {},
+	"platformFreezeWindow": {
+		pathName: "freezeWindowName",
+	},
 }
 
 var existResourcePaths = convertSources(ResourceMaps)
@@ -461,6 +497,8 @@ type rbacServiceImpl struct {
 // RBACService implement RBAC-related business logic.
 type RBACService interface {
 	CheckPerm(resource string, actions ...string) func(req *restful.Request, res *restful.Response, chain *restful.FilterChain)
+	// CheckProjectPerm check the permission of the login user in the project that is not the one of the request
+	CheckProjectPerm(ctx context.Context, projectName, resource string, actions ...string) error
 	CheckPluginRequestPerm(httpParams httprouter.Params, r2 *plugintypes.Route) func(req *http.Request, res http.ResponseWriter) bool
 	GetUserPermissions(ctx context.Context, user *model.User, projectName string, withPlatform bool) ([]*model.Permission, error)
 	CreateRole(ctx context.Context, projectName string, req apisv1.CreateRoleRequest) (*apisv1.RoleBase, error)
@@ -715,6 +753,40 @@ func (p *rbacServiceImpl) CheckPerm(resource string, actions ...string) func(req
 	return f
 }
 
+// CheckProjectPerm check whether the login user could operate the resource in the project
+func (p *rbacServiceImpl) CheckProjectPerm(ctx context.Context, projectName, resource string, actions ...string) error {
+	userName, ok := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if !ok {
+		return bcode.ErrUnauthorized
+	}
+	user := &model.User{Name: userName}
+	if err := p.Store.Get(ctx, user); err != nil {
+		return bcode.ErrUnauthorized
+	}
+	path, err := checkResourcePath(resource)
+	if err != nil {
+		klog.Errorf("check resource path failure %s", err.Error())
+		return bcode.ErrForbidden
+	}
+	ra := &RequestResourceAction{}
+	ra.SetResourceWithName(path, func(name string) string {
+		if name == ResourceMaps["project"].pathName {
+			return projectName
+		}
+		return ""
+	})
+	ra.SetActions(actions)
+	permissions, err := p.GetUserPermissions(ctx, user, projectName, true)
+	if err != nil {
+		klog.Errorf("get user's perm policies failure %s, user is %s", err.Error(), user.Name)
+		return bcode.ErrForbidden
+	}
+	if !ra.Match(permissions) {
+		return bcode.ErrForbidden
+	}
+	return nil
+}
+
 // CheckPluginRequestPerm handle RBAC checking for the http request to plugin backend
 // pathFormat: eg. nodes/{node}/status
 func (p *rbacServiceImpl) CheckPluginRequestPerm(httpParams httprouter.Params, r2 *plugintypes.Route) func(req *http.Request, res http.ResponseWriter) bool {
@@ -0,0 +1,315 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// RecycleBinService manage the deleted applications, projects and pipelines
+type RecycleBinService interface {
+	// ListRecycleBinItems list the deleted items, list all items in the platform if the project is empty
+	ListRecycleBinItems(ctx context.Context, projectName, itemType string) (*apisv1.ListRecycleBinItemsResponse, error)
+	RestoreRecycleBinItem(ctx context.Context, projectName, itemType, name string) error
+	PurgeRecycleBinItem(ctx context.Context, projectName, itemType, name string) error
+	// PurgeExpiredItems delete the items that exceed the retention period permanently
+	PurgeExpiredItems(ctx context.Context) error
+}
+
+type recycleBinServiceImpl struct {
+	Store              datastore.DataStore `inject:"datastore"`
+	ApplicationService ApplicationService  `inject:""`
+	ProjectService     ProjectService      `inject:""`
+	PipelineService    PipelineService     `inject:""`
+	Retention          time.Duration
+}
+
+// NewRecycleBinService new recycle bin service
+func NewRecycleBinService(retention time.Duration) RecycleBinService {
+	return &recycleBinServiceImpl{Retention: retention}
+}
+
+// recycleBinItem the deleted record with its type
+type recycleBinItem struct {
+	itemType    string
+	application *model.Application
+	project     *model.Project
+	pipeline    *model.Pipeline
+}
+
+func (r recycleBinItem) name() string {
+	switch r.itemType {
+	case apisv1.RecycleBinItemTypeApplication:
+		return r.application.Name
+	case apisv1.RecycleBinItemTypePipeline:
+		return r.pipeline.Name
+	default:
+		return r.project.Name
+	}
+}
+
+func (r recycleBinItem) projectName() string {
+	switch r.itemType {
+	case apisv1.RecycleBinItemTypeApplication:
+		return r.application.Project
+	case apisv1.RecycleBinItemTypePipeline:
+		return r.pipeline.Project
+	default:
+		return r.project.Name
+	}
+}
+
+func (r recycleBinItem) softDelete() *model.SoftDeleteModel {
+	switch r.itemType {
+	case apisv1.RecycleBinItemTypeApplication:
+		return &r.application.SoftDeleteModel
+	case apisv1.RecycleBinItemTypePipeline:
+		return &r.pipeline.SoftDeleteModel
+	default:
+		return &r.project.SoftDeleteModel
+	}
+}
+
+func checkRecycleBinItemType(itemType string) error {
+	switch itemType {
+	case "", apisv1.RecycleBinItemTypeApplication, apisv1.RecycleBinItemTypeProject, apisv1.RecycleBinItemTypePipeline:
+		return nil
+	default:
+		return bcode.ErrRecycleBinItemTypeNotSupport
+	}
+}
+
+func (r *recycleBinServiceImpl) listItems(ctx context.Context, projectName, itemType string) ([]recycleBinItem, error) {
+	var items []recycleBinItem
+	if itemType == "" || itemType == apisv1.RecycleBinItemTypeApplication {
+		entities, err := r.Store.List(ctx, &model.Application{Project: projectName}, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, entity := range entities {
+			if app := entity.(*model.Application); app.IsDeleted() {
+				items = append(items, recycleBinItem{itemType: apisv1.RecycleBinItemTypeApplication, application: app})
+			}
+		}
+	}
+	if itemType == "" || itemType == apisv1.RecycleBinItemTypePipeline {
+		entities, err := r.Store.List(ctx, &model.Pipeline{Project: projectName}, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, entity := range entities {
+			if pipeline := entity.(*model.Pipeline); pipeline.IsDeleted() {
+				items = append(items, recycleBinItem{itemType: apisv1.RecycleBinItemTypePipeline, pipeline: pipeline})
+			}
+		}
+	}
+	// the projects could only be managed in the platform recycle bin
+	if projectName == "" && (itemType == "" || itemType == apisv1.RecycleBinItemTypeProject) {
+		entities, err := r.Store.List(ctx, &model.Project{}, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, entity := range entities {
+			if project := entity.(*model.Project); project.IsDeleted() {
+				items = append(items, recycleBinItem{itemType: apisv1.RecycleBinItemTypeProject, project: project})
+			}
+		}
+	}
+	return items, nil
+}
+
+func (r *recycleBinServiceImpl) getItem(ctx context.Context, projectName, itemType, name string) (*recycleBinItem, error) {
+	var item recycleBinItem
+	var entity datastore.Entity
+	switch itemType {
+	case apisv1.RecycleBinItemTypeApplication:
+		item.application = &model.Application{Name: name}
+		entity = item.application
+	case apisv1.RecycleBinItemTypePipeline:
+		item.pipeline = &model.Pipeline{Name: name, Project: projectName}
+		entity = item.pipeline
+	case apisv1.RecycleBinItemTypeProject:
+		// the projects could only be managed in the platform recycle bin
+		if projectName != "" {
+			return nil, bcode.ErrRecycleBinItemTypeNotSupport
+		}
+		item.project = &model.Project{Name: name}
+		entity = item.project
+	default:
+		return nil, bcode.ErrRecycleBinItemTypeNotSupport
+	}
+	item.itemType = itemType
+	if err := r.Store.Get(ctx, entity); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrRecycleBinItemNotExist
+		}
+		return nil, err
+	}
+	if !item.softDelete().IsDeleted() {
+		return nil, bcode.ErrRecycleBinItemNotExist
+	}
+	if projectName != "" && item.projectName() != projectName {
+		return nil, bcode.ErrRecycleBinItemNotExist
+	}
+	return &item, nil
+}
+
+func (r *recycleBinServiceImpl) expireTime(item recycleBinItem) time.Time {
+	return item.softDelete().DeleteTime.Add(r.Retention)
+}
+
+// ListRecycleBinItems list the deleted items
+func (r *recycleBinServiceImpl) ListRecycleBinItems(ctx context.Context, projectName, itemType string) (*apisv1.ListRecycleBinItemsResponse, error) {
+	if err := checkRecycleBinItemType(itemType); err != nil {
+		return nil, err
+	}
+	items, err := r.listItems(ctx, projectName, itemType)
+	if err != nil {
+		return nil, err
+	}
+	projects := map[string]*model.Project{}
+	res := &apisv1.ListRecycleBinItemsResponse{Items: []apisv1.RecycleBinItem{}}
+	for _, item := range items {
+		dto := apisv1.RecycleBinItem{
+			Type:       item.itemType,
+			Name:       item.name(),
+			Deleter:    item.softDelete().Deleter,
+			DeleteTime: *item.softDelete().DeleteTime,
+			ExpireTime: r.expireTime(item),
+		}
+		switch item.itemType {
+		case apisv1.RecycleBinItemTypeApplication:
+			dto.Alias, dto.Description = item.application.Alias, item.application.Description
+		case apisv1.RecycleBinItemTypePipeline:
+			dto.Alias, dto.Description = item.pipeline.Alias, item.pipeline.Description
+		case apisv1.RecycleBinItemTypeProject:
+			dto.Alias, dto.Description = item.project.Alias, item.project.Description
+		}
+		project, exist := projects[item.projectName()]
+		if !exist {
+			project = &model.Project{Name: item.projectName()}
+			if err := r.Store.Get(ctx, project); err != nil {
+				klog.Warningf("get the project %s of the recycle bin item failure %s", project.Name, err.Error())
+			}
+			projects[project.Name] = project
+		}
+		dto.Project = apisv1.NameAlias{Name: project.Name, Alias: project.Alias}
+		res.Items = append(res.Items, dto)
+	}
+	sort.Slice(res.Items, func(i, j int) bool {
+		return res.Items[i].DeleteTime.After(res.Items[j].DeleteTime)
+	})
+	res.Total = int64(len(res.Items))
+	return res, nil
+}
+
+// RestoreRecycleBinItem restore the item if it does not exceed the retention period
+func (r *recycleBinServiceImpl) RestoreRecycleBinItem(ctx context.Context, projectName, itemType, name string) error {
+	item, err := r.getItem(ctx, projectName, itemType, name)
+	if err != nil {
+		return err
+	}
+	if time.Now().After(r.expireTime(*item)) {
+		return bcode.ErrRecycleBinItemExpired
+	}
+	if item.itemType == apisv1.RecycleBinItemTypeProject {
+		return r.ProjectService.RestoreProject(ctx, item.project)
+	}
+	project := &model.Project{Name: item.projectName()}
+	if err := r.Store.Get(ctx, project); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrProjectIsNotExist
+		}
+		return err
+	}
+	if project.IsDeleted() {
+		return bcode.ErrRecycleBinProjectDeleted
+	}
+	if item.itemType == apisv1.RecycleBinItemTypeApplication {
+		return r.ApplicationService.RestoreApplication(ctx, item.application)
+	}
+	return r.PipelineService.RestorePipeline(ctx, item.pipeline)
+}
+
+// PurgeRecycleBinItem delete the item permanently
+func (r *recycleBinServiceImpl) PurgeRecycleBinItem(ctx context.Context, projectName, itemType, name string) error {
+	item, err := r.getItem(ctx, projectName, itemType, name)
+	if err != nil {
+		return err
+	}
+	return r.purge(ctx, *item)
+}
+
+func (r *recycleBinServiceImpl) purge(ctx context.Context, item recycleBinItem) error {
+	switch item.itemType {
+	case apisv1.RecycleBinItemTypeApplication:
+		return r.ApplicationService.PurgeApplication(ctx, item.application)
+	case apisv1.RecycleBinItemTypePipeline:
+		project := &model.Project{Name: item.pipeline.Project}
+		if err := r.Store.Get(ctx, project); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		ctx = context.WithValue(ctx, &apisv1.CtxKeyProject, project)
+		return r.PipelineService.PurgePipeline(ctx, apisv1.PipelineBase{PipelineMeta: apisv1.PipelineMeta{
+			Name:    item.pipeline.Name,
+			Project: apisv1.NameAlias{Name: project.Name, Alias: project.Alias},
+		}})
+	default:
+		// purge the deleted applications and pipelines of the project at first
+		children, err := r.listItems(ctx, item.project.Name, "")
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := r.purge(ctx, child); err != nil {
+				return err
+			}
+		}
+		return r.ProjectService.PurgeProject(ctx, item.project.Name)
+	}
+}
+
+// PurgeExpiredItems delete the expired items permanently
+func (r *recycleBinServiceImpl) PurgeExpiredItems(ctx context.Context) error {
+	items, err := r.listItems(ctx, "", "")
+	if err != nil {
+		return err
+	}
+	var purgeErr error
+	for _, item := range items {
+		if time.Now().Before(r.expireTime(item)) {
+			continue
+		}
+		if err := r.purge(ctx, item); err != nil {
+			klog.Errorf("purge the %s %s in the recycle bin failure %s", item.itemType, item.name(), err.Error())
+			purgeErr = err
+			continue
+		}
+		klog.Infof("the %s %s in the recycle bin is purged", item.itemType, item.name())
+	}
+	return purgeErr
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var _ = Describe("Test recycle bin service functions", func() {
+	var (
+		recycleBinService *recycleBinServiceImpl
+		recycleProject    = "test-recycle-project"
+		recycleApp        = "test-recycle-app"
+		recyclePipeline   = "test-recycle-pipeline"
+		recycleCtx        context.Context
+	)
+
+	It("Init services and data", func() {
+		recycleCtx = InitTestEnvWithAdmin("recycle-bin-test-kubevela")
+		recycleBinService = &recycleBinServiceImpl{
+			Store:              ds,
+			ApplicationService: appService,
+			ProjectService:     projectService,
+			PipelineService:    pipelineService,
+			Retention:          time.Hour,
+		}
+
+		InitTestProject(recycleCtx, recycleProject, "")
+		project, err := projectService.GetProject(recycleCtx, recycleProject)
+		Expect(err).Should(BeNil())
+		recycleCtx = context.WithValue(recycleCtx, &apisv1.CtxKeyProject, project)
+
+		_, err = appService.CreateApplication(recycleCtx, apisv1.CreateApplicationRequest{Name: recycleApp, Project: recycleProject})
+		Expect(err).Should(BeNil())
+		_, err = pipelineService.CreatePipeline(recycleCtx, apisv1.CreatePipelineRequest{Name: recyclePipeline, Spec: model.WorkflowSpec{}})
+		Expect(err).Should(BeNil())
+	})
+
+	It("Test deleting and restoring the application", func() {
+		app, err := appService.GetApplication(recycleCtx, recycleApp)
+		Expect(err).Should(BeNil())
+		Expect(appService.DeleteApplication(recycleCtx, app)).Should(BeNil())
+
+		_, err = appService.GetApplication(recycleCtx, recycleApp)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrApplicationNotExist))
+		apps, err := appService.ListApplications(recycleCtx, apisv1.ListApplicationOptions{Projects: []string{recycleProject}})
+		Expect(err).Should(BeNil())
+		Expect(len(apps)).Should(BeEquivalentTo(0))
+
+		items, err := recycleBinService.ListRecycleBinItems(recycleCtx, recycleProject, "")
+		Expect(err).Should(BeNil())
+		Expect(items.Total).Should(BeEquivalentTo(1))
+		Expect(items.Items[0].Type).Should(BeEquivalentTo(apisv1.RecycleBinItemTypeApplication))
+		Expect(items.Items[0].Deleter).Should(BeEquivalentTo(FakeAdminName))
+
+		Expect(recycleBinService.RestoreRecycleBinItem(recycleCtx, recycleProject, apisv1.RecycleBinItemTypeApplication, recycleApp)).Should(BeNil())
+		_, err = appService.GetApplication(recycleCtx, recycleApp)
+		Expect(err).Should(BeNil())
+	})
+
+	It("Test purging the pipeline", func() {
+		Expect(pipelineService.DeletePipeline(recycleCtx, apisv1.PipelineBase{PipelineMeta: apisv1.PipelineMeta{
+			Name: recyclePipeline, Project: apisv1.NameAlias{Name: recycleProject},
+		}})).Should(BeNil())
+		_, err := pipelineService.GetPipeline(recycleCtx, recyclePipeline, false)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrPipelineNotExist))
+
+		err = recycleBinService.PurgeRecycleBinItem(recycleCtx, recycleProject, apisv1.RecycleBinItemTypeProject, recycleProject)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrRecycleBinItemTypeNotSupport))
+		Expect(recycleBinService.PurgeRecycleBinItem(recycleCtx, recycleProject, apisv1.RecycleBinItemTypePipeline, recyclePipeline)).Should(BeNil())
+		err = recycleBinService.RestoreRecycleBinItem(recycleCtx, recycleProject, apisv1.RecycleBinItemTypePipeline, recyclePipeline)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrRecycleBinItemNotExist))
+	})
+
+	It("Test purging the expired project", func() {
+		app, err := appService.GetApplication(recycleCtx, recycleApp)
+		Expect(err).Should(BeNil())
+		Expect(appService.DeleteApplication(recycleCtx, app)).Should(BeNil())
+		Expect(projectService.DeleteProject(recycleCtx, recycleProject)).Should(BeNil())
+		_, err = projectService.GetProject(recycleCtx, recycleProject)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrProjectIsNotExist))
+
+		err = recycleBinService.RestoreRecycleBinItem(recycleCtx, "", apisv1.RecycleBinItemTypeApplication, recycleApp)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrRecycleBinProjectDeleted))
+
+		items, err := recycleBinService.ListRecycleBinItems(recycleCtx, "", "")
+		Expect(err).Should(BeNil())
+		Expect(items.Total).Should(BeEquivalentTo(2))
+
+		recycleBinService.Retention = 0
+		err = recycleBinService.RestoreRecycleBinItem(recycleCtx, "", apisv1.RecycleBinItemTypeProject, recycleProject)
+		Expect(err).Should(BeEquivalentTo(bcode.ErrRecycleBinItemExpired))
+		Expect(recycleBinService.PurgeExpiredItems(recycleCtx)).Should(BeNil())
+		items, err = recycleBinService.ListRecycleBinItems(recycleCtx, "", "")
+		Expect(err).Should(BeNil())
+		Expect(items.Total).Should(BeEquivalentTo(0))
+	})
+})
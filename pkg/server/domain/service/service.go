@@ -45,19 +45,25 @@ func InitServiceBean(c config.Config) []interface{} {
 	authenticationService := NewAuthenticationService()
 	configService := NewConfigService()
 	applicationService := NewApplicationService()
-	webhookService := NewWebhookService()
+	webhookService := NewWebhookService(c.WebhookTrustedProxies)
 	pipelineService := NewPipelineService(c.WorkflowVersion)
 	pipelineRunService := NewPipelineRunService()
 	contextService := NewContextService()
 	pluginService := NewPluginService(c.PluginConfig)
 	resourceService := NewResourceService()
+	recycleBinService := NewRecycleBinService(c.RecycleBinRetention)
+	freezeWindowService := NewFreezeWindowService()
+	deliveryMetricService := NewDeliveryMetricService()
+	notificationService := NewNotificationService(c.NotificationDeliveryRetention)
+	eventBus := NewEventBus(c.EnableEventOutbox)
 
-	needInitData = []DataInit{pluginService, clusterService, rbacService, targetService, systemInfoService, addonService}
+	needInitData = []DataInit{pluginService, clusterService, rbacService, targetService, systemInfoService, addonService, notificationService}
 	return []interface{}{
 		clusterService, rbacService, projectService, envService, targetService, workflowService, oamApplicationService,
 		velaQLService, definitionService, addonService, envBindingService, systemInfoService, helmService, userService,
 		authenticationService, configService, applicationService, webhookService, pipelineService, pipelineRunService,
-		contextService, NewImageService(), NewCloudShellService(), pluginService, resourceService,
+		contextService, NewImageService(), NewCloudShellService(), pluginService, resourceService, recycleBinService,
+		freezeWindowService, deliveryMetricService, notificationService, eventBus,
 	}
 }
 
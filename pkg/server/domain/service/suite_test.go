@@ -48,6 +48,7 @@ import (
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/kubeapi"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore/mongodb"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
 )
 
 var cfg *rest.Config
@@ -86,7 +87,7 @@ var err error
 
 func InitAllServices(ds datastore.DataStore) {
 	userService = NewTestUserService(ds, k8sClient).(*userServiceImpl)
-	contextService = NewTestContextService(ds).(*contextServiceImpl)
+	contextService = NewTestContextService(ds, k8sClient).(*contextServiceImpl)
 	projectService = NewTestProjectService(ds, k8sClient).(*projectServiceImpl)
 	appService = NewTestApplicationService(ds, k8sClient, cfg).(*applicationServiceImpl)
 	workflowService = NewTestWorkflowService(ds, k8sClient).(*workflowServiceImpl)
@@ -115,6 +116,50 @@ func InitTestEnv(dbName string) {
 	ctx = context.Background()
 }
 
+// InitTestEnvWithAdmin init the test env and the fake admin, the context of the admin is returned
+func InitTestEnvWithAdmin(dbName string) context.Context {
+	InitTestEnv(dbName)
+	ok, err := InitTestAdmin(userService)
+	Expect(err).Should(BeNil())
+	Expect(ok).Should(BeTrue())
+	return context.WithValue(context.TODO(), &apisv1.CtxKeyUser, FakeAdminName)
+}
+
+// InitTestProject create the project, the target of the local cluster and the envs with the target
+func InitTestProject(ctx context.Context, project, target string, envs ...string) {
+	_, err := projectService.CreateProject(ctx, apisv1.CreateProjectRequest{Name: project, Owner: FakeAdminName})
+	Expect(err).Should(BeNil())
+	if target == "" {
+		return
+	}
+	_, err = targetService.CreateTarget(ctx, apisv1.CreateTargetRequest{
+		Name: target, Project: project, Cluster: &apisv1.ClusterTarget{ClusterName: "local", Namespace: project}})
+	Expect(err).Should(BeNil())
+	for _, env := range envs {
+		_, err = envService.CreateEnv(ctx, apisv1.CreateEnvRequest{Name: env, Namespace: env, Targets: []string{target}, Project: project})
+		Expect(err).Should(BeNil())
+	}
+}
+
+// InitTestApplication create the application with the webservice component and bind it to the envs
+func InitTestApplication(ctx context.Context, project, app, properties string, envs ...string) {
+	var bindings []*apisv1.EnvBinding
+	for _, env := range envs {
+		bindings = append(bindings, &apisv1.EnvBinding{Name: env})
+	}
+	_, err := appService.CreateApplication(ctx, apisv1.CreateApplicationRequest{
+		Name:       app,
+		Project:    project,
+		EnvBinding: bindings,
+		Component: &apisv1.CreateComponentRequest{
+			Name:          "web",
+			ComponentType: "webservice",
+			Properties:    properties,
+		},
+	})
+	Expect(err).Should(BeNil())
+}
+
 var _ = BeforeSuite(func(ctx SpecContext) {
 	rand.Seed(time.Now().UnixNano())
 	By("bootstrapping test environment")
@@ -210,4 +255,11 @@ func initDefinitions(k8sClient client.Client) {
 	err = yaml.Unmarshal(deploy, &wsd)
 	Expect(err).Should(BeNil())
 	Expect(k8sClient.Create(context.TODO(), &wsd))
+
+	request, err := os.ReadFile("./testdata/request.yaml")
+	Expect(err).Should(BeNil())
+	var requestDef v1beta1.WorkflowStepDefinition
+	err = yaml.Unmarshal(request, &requestDef)
+	Expect(err).Should(BeNil())
+	Expect(k8sClient.Create(context.TODO(), &requestDef))
 }
@@ -61,6 +61,7 @@ type userServiceImpl struct {
 	SysService     SystemInfoService   `inject:""`
 	TargetService  TargetService       `inject:""`
 	EnvService     EnvService          `inject:""`
+	EventBus       EventBus            `inject:""`
 }
 
 // NewUserService new User service
@@ -288,6 +289,7 @@ func (u *userServiceImpl) DeleteUser(ctx context.Context, username string) error
 		klog.Errorf("failed to delete user %s %v", pkgUtils.Sanitize(username), err.Error())
 		return err
 	}
+	publishEvent(ctx, u.EventBus, newDomainEvent(model.EventUserDeleted, "", "user", model.UserEventData{UserName: username}))
 	return nil
 }
 
@@ -317,6 +319,7 @@ func (u *userServiceImpl) CreateUser(ctx context.Context, req apisv1.CreateUserR
 	if err := u.Store.Add(ctx, user); err != nil {
 		return nil, err
 	}
+	publishEvent(ctx, u.EventBus, newDomainEvent(model.EventUserAdded, "", "user", model.UserEventData{UserName: user.Name, Email: user.Email}))
 	return convertUserBase(user), nil
 }
 
@@ -41,12 +41,22 @@ import (
 // WebhookService webhook service
 type WebhookService interface {
 	HandleApplicationWebhook(ctx context.Context, token string, req *restful.Request) (interface{}, error)
+	HandlePipelineWebhook(ctx context.Context, token string, req *restful.Request) (interface{}, error)
+	PurgeExpiredNonces(ctx context.Context) error
+	TestApplicationTrigger(ctx context.Context, app *model.Application, token string, payload map[string]interface{}) (*apisv1.TestApplicationTriggerResponse, error)
+	ListWebhookDeliveries(ctx context.Context, app *model.Application, token, status string, page, pageSize int) (*apisv1.ListWebhookDeliveriesResponse, error)
+	GetWebhookDelivery(ctx context.Context, app *model.Application, token, deliveryID string) (*apisv1.WebhookDeliveryDetail, error)
+	ReplayWebhookDelivery(ctx context.Context, app *model.Application, token, deliveryID string) (*apisv1.WebhookDeliveryBase, error)
+	PurgeExpiredDeliveries(ctx context.Context) error
 }
 
 type webhookServiceImpl struct {
 	Store              datastore.DataStore `inject:"datastore"`
 	ApplicationService ApplicationService  `inject:""`
 	WorkflowService    WorkflowService     `inject:""`
+	PipelineService    PipelineService     `inject:""`
+	// TrustedProxies the forwarded headers are trusted only if the requests come from these addresses
+	TrustedProxies []string
 }
 
 // WebhookHandlers is the webhook handlers
@@ -65,9 +75,9 @@ const (
 )
 
 // NewWebhookService new webhook service
-func NewWebhookService() WebhookService {
+func NewWebhookService(trustedProxies []string) WebhookService {
 	registerHandlers()
-	return &webhookServiceImpl{}
+	return &webhookServiceImpl{TrustedProxies: trustedProxies}
 }
 
 func registerHandlers() {
@@ -76,6 +86,13 @@ func registerHandlers() {
 	new(dockerHubHandlerImpl).install()
 	new(harborHandlerImpl).install()
 	new(jfrogHandlerImpl).install()
+	new(mappedHandlerImpl).install()
+	for _, provider := range []string{model.PayloadTypeGitHub, model.PayloadTypeGitLab, model.PayloadTypeGitea, model.PayloadTypeBitbucket} {
+		(&gitHandlerImpl{provider: provider}).install()
+	}
+	for _, provider := range registryPayloadTypes {
+		(&registryHandlerImpl{provider: provider}).install()
+	}
 }
 
 type webhookHandler interface {
@@ -85,7 +102,9 @@ type webhookHandler interface {
 
 type customHandlerImpl struct {
 	req apisv1.HandleApplicationTriggerWebhookRequest
-	w   *webhookServiceImpl
+	// imageInfo is extracted by the mapped payload
+	imageInfo *model.ImageInfo
+	w         *webhookServiceImpl
 }
 
 type acrHandlerImpl struct {
@@ -141,6 +160,23 @@ func (c *webhookServiceImpl) HandleApplicationWebhook(ctx context.Context, token
 		}
 		return nil, err
 	}
+	delivery, body, err := c.newWebhookDelivery(webhookTrigger, req)
+	if err != nil {
+		c.saveWebhookDelivery(ctx, delivery, nil, err, delivery.Time)
+		return nil, err
+	}
+	return c.handleWebhookDelivery(ctx, webhookTrigger, req, delivery, true, body)
+}
+
+// handleWebhookDelivery handle the request and record the result, the verification is skipped for the replayed delivery
+func (c *webhookServiceImpl) handleWebhookDelivery(ctx context.Context, webhookTrigger *model.ApplicationTrigger, req *restful.Request, delivery *model.WebhookDelivery, verify bool, body []byte) (interface{}, error) {
+	start := time.Now()
+	res, err := c.dispatchWebhook(withWebhookDelivery(ctx, delivery), webhookTrigger, req, verify, body)
+	c.saveWebhookDelivery(ctx, delivery, res, err, start)
+	return res, err
+}
+
+func (c *webhookServiceImpl) dispatchWebhook(ctx context.Context, webhookTrigger *model.ApplicationTrigger, req *restful.Request, verify bool, body []byte) (interface{}, error) {
 	app := &model.Application{
 		Name: webhookTrigger.AppPrimaryKey,
 	}
@@ -150,6 +186,36 @@ func (c *webhookServiceImpl) HandleApplicationWebhook(ctx context.Context, token
 		}
 		return nil, err
 	}
+	if app.IsDeleted() {
+		return nil, bcode.ErrApplicationNotExist
+	}
+	if verify {
+		nonce, retention, err := c.verifyWebhookSignature(webhookTrigger, req, body)
+		if err != nil {
+			return nil, err
+		}
+		var key string
+		for _, header := range idempotencyHeaders {
+			if key = req.HeaderParameter(header); key != "" {
+				break
+			}
+		}
+		// the delivery ID is reserved as the idempotency key, so the repeated delivery is reported as duplicated instead of replayed
+		if nonce != "" && nonce != key {
+			if err := c.checkWebhookNonce(ctx, webhookTrigger.Token, nonce, retention); err != nil {
+				return nil, err
+			}
+		}
+		if key != "" {
+			duplicated, err := c.checkDuplicatedDelivery(ctx, "delivery:"+key)
+			if err != nil {
+				return nil, err
+			}
+			if duplicated != nil {
+				return duplicated, nil
+			}
+		}
+	}
 
 	var handler webhookHandler
 	var err error
@@ -179,6 +245,21 @@ func (c *webhookServiceImpl) HandleApplicationWebhook(ctx context.Context, token
 		if err != nil {
 			return nil, err
 		}
+	case model.PayloadTypeMapped:
+		handler, err = c.newMappedHandler(req)
+		if err != nil {
+			return nil, err
+		}
+	case model.PayloadTypeGitHub, model.PayloadTypeGitLab, model.PayloadTypeGitea, model.PayloadTypeBitbucket:
+		handler, err = c.newGitHandler(webhookTrigger.PayloadType, req)
+		if err != nil {
+			return nil, err
+		}
+	case model.PayloadTypeGHCR, model.PayloadTypeQuay, model.PayloadTypeECR, model.PayloadTypeArtifactRegistry, model.PayloadTypeGitLabRegistry:
+		handler, err = c.newRegistryHandler(webhookTrigger, req)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, bcode.ErrInvalidWebhookPayloadType
 	}
@@ -286,6 +367,7 @@ func (c *customHandlerImpl) handle(ctx context.Context, webhookTrigger *model.Ap
 				TriggerType:  apisv1.TriggerTypeWebhook,
 				Force:        true,
 				CodeInfo:     c.req.CodeInfo,
+				ImageInfo:    c.imageInfo,
 			})
 		}
 	default:
@@ -297,20 +379,27 @@ func (c *customHandlerImpl) install() {
 	WebhookHandlers = append(WebhookHandlers, model.PayloadTypeCustom)
 }
 
-func (c *acrHandlerImpl) handle(ctx context.Context, webhookTrigger *model.ApplicationTrigger, app *model.Application) (interface{}, error) {
-	component, err := getComponent(ctx, c.w.Store, webhookTrigger)
-	if err != nil {
-		return nil, err
-	}
+func (c *acrHandlerImpl) pushedImage(webhookTrigger *model.ApplicationTrigger) (*imageEvent, string, error) {
 	acrReq := c.req
 	registry := webhookTrigger.Registry
 	if registry == "" {
 		registry = fmt.Sprintf("registry.%s.aliyuncs.com", acrReq.Repository.Region)
 	}
 	image := fmt.Sprintf("%s/%s:%s", registry, acrReq.Repository.RepoFullName, acrReq.PushData.Tag)
-	if err := c.w.patchComponentProperties(ctx, component, &runtime.RawExtension{
-		Raw: []byte(fmt.Sprintf(`{"image": "%s"}`, image)),
-	}); err != nil {
+	return newImageEvent(image, acrReq.PushData.Tag, acrReq.PushData.Digest), "", nil
+}
+
+func (c *acrHandlerImpl) handle(ctx context.Context, webhookTrigger *model.ApplicationTrigger, app *model.Application) (interface{}, error) {
+	acrReq := c.req
+	event, _, _ := c.pushedImage(webhookTrigger)
+	skipped, err := c.w.skipImageEvent(ctx, webhookTrigger, event)
+	if err != nil {
+		return nil, err
+	}
+	if skipped != nil {
+		return skipped, nil
+	}
+	if err := c.w.patchImageProperties(ctx, webhookTrigger, event); err != nil {
 		return nil, err
 	}
 
@@ -324,7 +413,7 @@ func (c *acrHandlerImpl) handle(ctx context.Context, webhookTrigger *model.Appli
 			Resource: &model.ImageResource{
 				Digest:     acrReq.PushData.Digest,
 				Tag:        acrReq.PushData.Tag,
-				URL:        image,
+				URL:        event.Image,
 				CreateTime: parseTimeString(acrReq.PushData.PushedAt),
 			},
 			Repository: &model.ImageRepository{
@@ -343,23 +432,40 @@ func (c *acrHandlerImpl) install() {
 	WebhookHandlers = append(WebhookHandlers, model.PayloadTypeACR)
 }
 
-func (c dockerHubHandlerImpl) handle(ctx context.Context, trigger *model.ApplicationTrigger, app *model.Application) (interface{}, error) {
+func (c dockerHubHandlerImpl) pushedImage(_ *model.ApplicationTrigger) (*imageEvent, string, error) {
 	dockerHubReq := c.req
 	if dockerHubReq.Repository.Status != "Active" {
+		return nil, "not create event", nil
+	}
+	image := fmt.Sprintf("docker.io/%s:%s", dockerHubReq.Repository.RepoName, dockerHubReq.PushData.Tag)
+	return newImageEvent(image, dockerHubReq.PushData.Tag, ""), "", nil
+}
+
+func (c dockerHubHandlerImpl) handle(ctx context.Context, trigger *model.ApplicationTrigger, app *model.Application) (interface{}, error) {
+	dockerHubReq := c.req
+	event, reason, _ := c.pushedImage(trigger)
+	if event == nil {
 		klog.Infof("receive dockerhub webhook but not create event: %v", dockerHubReq)
 		return &apisv1.ApplicationDockerhubWebhookResponse{
 			State:       "failed",
-			Description: "not create event",
+			Description: reason,
+		}, nil
+	}
+	skipped, err := c.w.skipImageEvent(ctx, trigger, event)
+	if err != nil {
+		return nil, err
+	}
+	if skipped != nil {
+		return &apisv1.ApplicationDockerhubWebhookResponse{
+			State:       "success",
+			Description: skipped.Description,
 		}, nil
 	}
 	component, err := getComponent(ctx, c.w.Store, trigger)
 	if err != nil {
 		return nil, err
 	}
-	image := fmt.Sprintf("docker.io/%s:%s", dockerHubReq.Repository.RepoName, dockerHubReq.PushData.Tag)
-	if err := c.w.patchComponentProperties(ctx, component, &runtime.RawExtension{
-		Raw: []byte(fmt.Sprintf(`{"image": "%s"}`, image)),
-	}); err != nil {
+	if err := c.w.patchImageProperties(ctx, trigger, event); err != nil {
 		return nil, err
 	}
 
@@ -377,7 +483,7 @@ func (c dockerHubHandlerImpl) handle(ctx context.Context, trigger *model.Applica
 			Type: model.PayloadTypeDockerhub,
 			Resource: &model.ImageResource{
 				Tag:        dockerHubReq.PushData.Tag,
-				URL:        image,
+				URL:        event.Image,
 				CreateTime: time.Unix(dockerHubReq.PushData.PushedAt, 0),
 			},
 			Repository: &model.ImageRepository{
@@ -443,22 +549,28 @@ func (c *harborHandlerImpl) install() {
 	WebhookHandlers = append(WebhookHandlers, model.PayloadTypeHarbor)
 }
 
-func (c *harborHandlerImpl) handle(ctx context.Context, webhookTrigger *model.ApplicationTrigger, app *model.Application) (interface{}, error) {
+func (c *harborHandlerImpl) pushedImage(_ *model.ApplicationTrigger) (*imageEvent, string, error) {
 	resources := c.req.EventData.Resources
 	if len(resources) < 1 {
-		return nil, bcode.ErrInvalidWebhookPayloadBody
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
 	}
-	imageURL := resources[0].ResourceURL
-	digest := resources[0].Digest
-	tag := resources[0].Tag
-	component, err := getComponent(ctx, c.w.Store, webhookTrigger)
+	return newImageEvent(resources[0].ResourceURL, resources[0].Tag, resources[0].Digest), "", nil
+}
+
+func (c *harborHandlerImpl) handle(ctx context.Context, webhookTrigger *model.ApplicationTrigger, app *model.Application) (interface{}, error) {
+	event, _, err := c.pushedImage(webhookTrigger)
+	if err != nil {
+		return nil, err
+	}
+	skipped, err := c.w.skipImageEvent(ctx, webhookTrigger, event)
 	if err != nil {
 		return nil, err
 	}
+	if skipped != nil {
+		return skipped, nil
+	}
 	harborReq := c.req
-	if err := c.w.patchComponentProperties(ctx, component, &runtime.RawExtension{
-		Raw: []byte(fmt.Sprintf(`{"image": "%s"}`, imageURL)),
-	}); err != nil {
+	if err := c.w.patchImageProperties(ctx, webhookTrigger, event); err != nil {
 		return nil, err
 	}
 	return c.w.ApplicationService.Deploy(ctx, app, apisv1.ApplicationDeployRequest{
@@ -469,9 +581,9 @@ func (c *harborHandlerImpl) handle(ctx context.Context, webhookTrigger *model.Ap
 		ImageInfo: &model.ImageInfo{
 			Type: model.PayloadTypeHarbor,
 			Resource: &model.ImageResource{
-				Digest:     digest,
-				Tag:        tag,
-				URL:        imageURL,
+				Digest:     event.Digest,
+				Tag:        event.Tag,
+				URL:        event.Image,
 				CreateTime: time.Unix(harborReq.OccurAt, 0),
 			},
 			Repository: &model.ImageRepository{
@@ -506,12 +618,8 @@ func (c *webhookServiceImpl) newJFrogHandler(req *restful.Request) (webhookHandl
 	}, nil
 }
 
-func (j *jfrogHandlerImpl) handle(ctx context.Context, webhookTrigger *model.ApplicationTrigger, app *model.Application) (interface{}, error) {
+func (j *jfrogHandlerImpl) pushedImage(_ *model.ApplicationTrigger) (*imageEvent, string, error) {
 	jfrogReq := j.req
-	component, err := getComponent(ctx, j.w.Store, webhookTrigger)
-	if err != nil {
-		return nil, err
-	}
 	image := fmt.Sprintf("%s/%s:%s", jfrogReq.Data.RepoKey, jfrogReq.Data.ImageName, jfrogReq.Data.Tag)
 	pathArray := strings.Split(jfrogReq.Data.Path, "/")
 	if len(pathArray) > 2 {
@@ -521,9 +629,20 @@ func (j *jfrogHandlerImpl) handle(ctx context.Context, webhookTrigger *model.App
 	if jfrogReq.Data.URL != "" {
 		image = fmt.Sprintf("%s/%s", jfrogReq.Data.URL, image)
 	}
-	if err := j.w.patchComponentProperties(ctx, component, &runtime.RawExtension{
-		Raw: []byte(fmt.Sprintf(`{"image": "%s"}`, image)),
-	}); err != nil {
+	return newImageEvent(image, jfrogReq.Data.Tag, jfrogReq.Data.Digest), "", nil
+}
+
+func (j *jfrogHandlerImpl) handle(ctx context.Context, webhookTrigger *model.ApplicationTrigger, app *model.Application) (interface{}, error) {
+	jfrogReq := j.req
+	event, _, _ := j.pushedImage(webhookTrigger)
+	skipped, err := j.w.skipImageEvent(ctx, webhookTrigger, event)
+	if err != nil {
+		return nil, err
+	}
+	if skipped != nil {
+		return skipped, nil
+	}
+	if err := j.w.patchImageProperties(ctx, webhookTrigger, event); err != nil {
 		return nil, err
 	}
 
@@ -537,7 +656,7 @@ func (j *jfrogHandlerImpl) handle(ctx context.Context, webhookTrigger *model.App
 			Resource: &model.ImageResource{
 				Digest: jfrogReq.Data.Digest,
 				Tag:    jfrogReq.Data.Tag,
-				URL:    image,
+				URL:    event.Image,
 			},
 			Repository: &model.ImageRepository{
 				Name:      jfrogReq.Data.ImageName,
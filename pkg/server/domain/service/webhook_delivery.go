@@ -0,0 +1,356 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/google/uuid"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	assembler "github.com/kubevela/velaux/pkg/server/interfaces/api/assembler/v1"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// webhookDeliveryBodyLimit the larger body is truncated and the delivery could not be replayed
+	webhookDeliveryBodyLimit = 64 << 10
+	// webhookDeliveryHistory the deliveries are purged after the period
+	webhookDeliveryHistory = 7 * 24 * time.Hour
+	// webhookIdempotencyWindow the same delivery or image is deployed once in the window
+	webhookIdempotencyWindow = 24 * time.Hour
+
+	redactedHeaderValue = "******"
+)
+
+// ctxKeyWebhookDelivery the handlers record the idempotency key to the delivery in the context
+var ctxKeyWebhookDelivery = "webhookDelivery"
+
+// idempotencyHeaders the delivery IDs of the providers, the first one that is not empty is used
+var idempotencyHeaders = []string{"Idempotency-Key", HeaderWebhookDelivery, headerGitHubDelivery, headerGiteaDelivery, headerGitLabEventUUID, headerBitbucketDelivery}
+
+// sensitiveHeaders the headers that contain the words are redacted
+var sensitiveHeaders = []string{"authorization", "token", "secret", "signature", "cookie", "password", "api-key", "apikey"}
+
+// newWebhookDelivery record the request, the body is read and restored for the handlers
+func (c *webhookServiceImpl) newWebhookDelivery(trigger *model.ApplicationTrigger, req *restful.Request) (*model.WebhookDelivery, []byte, error) {
+	delivery := &model.WebhookDelivery{
+		ID:            uuid.New().String(),
+		AppPrimaryKey: trigger.AppPrimaryKey,
+		TriggerName:   trigger.Name,
+		PayloadType:   trigger.PayloadType,
+		Headers:       map[string]string{},
+		Time:          time.Now(),
+	}
+	if req == nil || req.Request == nil {
+		return delivery, nil, nil
+	}
+	delivery.SourceIP = webhookSourceIP(req.Request, c.TrustedProxies)
+	for name, values := range req.Request.Header {
+		delivery.Headers[name] = redactHeader(name, strings.Join(values, ", "))
+	}
+	if req.Request.Body == nil {
+		return delivery, nil, nil
+	}
+	body, err := io.ReadAll(req.Request.Body)
+	if err != nil {
+		return delivery, nil, bcode.ErrInvalidWebhookPayloadBody
+	}
+	req.Request.Body = io.NopCloser(bytes.NewReader(body))
+	delivery.Body = string(body)
+	if len(body) > webhookDeliveryBodyLimit {
+		delivery.Body = string(body[:webhookDeliveryBodyLimit])
+		delivery.BodyTruncated = true
+	}
+	return delivery, body, nil
+}
+
+func redactHeader(name, value string) string {
+	lower := strings.ToLower(name)
+	for _, word := range sensitiveHeaders {
+		if strings.Contains(lower, word) {
+			return redactedHeaderValue
+		}
+	}
+	return value
+}
+
+func hashIdempotencyKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:16])
+}
+
+func withWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery) context.Context {
+	return context.WithValue(ctx, &ctxKeyWebhookDelivery, delivery)
+}
+
+func getWebhookDelivery(ctx context.Context) *model.WebhookDelivery {
+	delivery, _ := ctx.Value(&ctxKeyWebhookDelivery).(*model.WebhookDelivery)
+	return delivery
+}
+
+// checkDuplicatedDelivery set the idempotency key of the delivery in the context, and reserve the key for the delivery.
+// Return the response if the same key has been reserved by another delivery of the trigger in the window.
+// The replayed deliveries are always handled.
+func (c *webhookServiceImpl) checkDuplicatedDelivery(ctx context.Context, key string) (*apisv1.ApplicationWebhookSkippedResponse, error) {
+	delivery := getWebhookDelivery(ctx)
+	if delivery == nil || key == "" {
+		return nil, nil
+	}
+	delivery.IdempotencyKey = hashIdempotencyKey(key)
+	if delivery.ReplayOf != "" {
+		return nil, nil
+	}
+	// the key is reserved atomically, so the concurrent deliveries are not deployed twice
+	record := &model.WebhookNonce{
+		ID:         hashIdempotencyKey(delivery.AppPrimaryKey + "/" + delivery.TriggerName + ":" + delivery.IdempotencyKey),
+		DeliveryID: delivery.ID,
+		ExpireTime: time.Now().Add(webhookIdempotencyWindow),
+	}
+	err := c.Store.Add(ctx, record)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, datastore.ErrRecordExist) {
+		return nil, err
+	}
+	existing := &model.WebhookNonce{ID: record.ID}
+	if err := c.Store.Get(ctx, existing); err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(existing.ExpireTime) {
+		// the key is expired but not purged yet
+		return nil, c.Store.Put(ctx, record)
+	}
+	klog.Infof("skip the duplicated delivery %s of the trigger %s, it has been handled by %s", delivery.ID, delivery.TriggerName, existing.DeliveryID)
+	delivery.Status = model.WebhookDeliveryDuplicated
+	delivery.Message = fmt.Sprintf("the delivery has been handled by %s", existing.DeliveryID)
+	return &apisv1.ApplicationWebhookSkippedResponse{
+		State:       model.WebhookDeliveryDuplicated,
+		Description: delivery.Message,
+	}, nil
+}
+
+// releaseIdempotencyKeys remove the keys reserved by the delivery that is not succeeded, so the retry of the provider is handled
+func (c *webhookServiceImpl) releaseIdempotencyKeys(ctx context.Context, delivery *model.WebhookDelivery) {
+	entities, err := c.Store.List(ctx, &model.WebhookNonce{DeliveryID: delivery.ID}, &datastore.ListOptions{})
+	if err != nil {
+		klog.Errorf("failed to list the idempotency keys of the webhook delivery %s: %s", delivery.ID, err.Error())
+		return
+	}
+	for _, entity := range entities {
+		if err := c.Store.Delete(ctx, entity); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("failed to release the idempotency key of the webhook delivery %s: %s", delivery.ID, err.Error())
+		}
+	}
+}
+
+// checkDuplicatedImage the registries may send the same push event more than once.
+// The event without the digest is not checked, the tag may be pushed again with another image.
+func (c *webhookServiceImpl) checkDuplicatedImage(ctx context.Context, event *imageEvent) (*apisv1.ApplicationWebhookSkippedResponse, error) {
+	if event.Digest == "" {
+		return nil, nil
+	}
+	return c.checkDuplicatedDelivery(ctx, "image:"+event.Image+"@"+event.Digest)
+}
+
+// saveWebhookDelivery record the result of the delivery, the failure of saving does not affect the response
+func (c *webhookServiceImpl) saveWebhookDelivery(ctx context.Context, delivery *model.WebhookDelivery, res interface{}, handleErr error, start time.Time) {
+	delivery.Latency = time.Since(start).Milliseconds()
+	switch r := res.(type) {
+	case *apisv1.ApplicationDeployResponse:
+		delivery.Revision = r.Version
+		delivery.RecordName = r.WorkflowRecord.Name
+	case *apisv1.ApplicationWebhookSkippedResponse:
+		if delivery.Status == "" {
+			delivery.Status = r.State
+		}
+		delivery.Message = r.Description
+	case *apisv1.ApplicationDockerhubWebhookResponse:
+		if delivery.Status == "" && r.State == "failed" {
+			delivery.Status = model.WebhookDeliveryFailed
+		}
+		delivery.Message = r.Description
+	}
+	if handleErr != nil {
+		delivery.Status = model.WebhookDeliveryFailed
+		delivery.Error = handleErr.Error()
+		var bcodeErr *bcode.Bcode
+		if errors.As(handleErr, &bcodeErr) {
+			delivery.Error = bcodeErr.Message
+			delivery.ErrorCode = bcodeErr.BusinessCode
+		}
+	}
+	if delivery.Status == "" {
+		delivery.Status = model.WebhookDeliverySucceeded
+	}
+	if delivery.Status != model.WebhookDeliverySucceeded && delivery.IdempotencyKey != "" && delivery.ReplayOf == "" {
+		c.releaseIdempotencyKeys(ctx, delivery)
+	}
+	if err := c.Store.Add(ctx, delivery); err != nil {
+		klog.Errorf("failed to save the webhook delivery %s of the trigger %s: %s", delivery.ID, delivery.TriggerName, err.Error())
+	}
+}
+
+func (c *webhookServiceImpl) getAppTrigger(ctx context.Context, app *model.Application, token string) (*model.ApplicationTrigger, error) {
+	trigger := &model.ApplicationTrigger{AppPrimaryKey: app.PrimaryKey(), Token: token}
+	if err := c.Store.Get(ctx, trigger); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrApplicationTriggerNotExist
+		}
+		return nil, err
+	}
+	if trigger.AppPrimaryKey != app.PrimaryKey() {
+		return nil, bcode.ErrApplicationTriggerNotExist
+	}
+	return trigger, nil
+}
+
+func (c *webhookServiceImpl) getTriggerDelivery(ctx context.Context, trigger *model.ApplicationTrigger, deliveryID string) (*model.WebhookDelivery, error) {
+	delivery := &model.WebhookDelivery{ID: deliveryID}
+	if err := c.Store.Get(ctx, delivery); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrWebhookDeliveryNotExist
+		}
+		return nil, err
+	}
+	// the deliveries are kept after the token is rotated
+	if delivery.AppPrimaryKey != trigger.AppPrimaryKey || delivery.TriggerName != trigger.Name {
+		return nil, bcode.ErrWebhookDeliveryNotExist
+	}
+	return delivery, nil
+}
+
+// ListWebhookDeliveries list the deliveries of the trigger
+func (c *webhookServiceImpl) ListWebhookDeliveries(ctx context.Context, app *model.Application, token, status string, page, pageSize int) (*apisv1.ListWebhookDeliveriesResponse, error) {
+	trigger, err := c.getAppTrigger(ctx, app, token)
+	if err != nil {
+		return nil, err
+	}
+	query := &model.WebhookDelivery{AppPrimaryKey: trigger.AppPrimaryKey, TriggerName: trigger.Name, Status: status}
+	entities, err := c.Store.List(ctx, query, &datastore.ListOptions{
+		Page:     page,
+		PageSize: pageSize,
+		SortBy:   []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &apisv1.ListWebhookDeliveriesResponse{Deliveries: []*apisv1.WebhookDeliveryBase{}}
+	for _, entity := range entities {
+		resp.Deliveries = append(resp.Deliveries, assembler.ConvertWebhookDelivery2DTO(*entity.(*model.WebhookDelivery)))
+	}
+	count, err := c.Store.Count(ctx, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Total = count
+	return resp, nil
+}
+
+// GetWebhookDelivery get the delivery with the headers and the body
+func (c *webhookServiceImpl) GetWebhookDelivery(ctx context.Context, app *model.Application, token, deliveryID string) (*apisv1.WebhookDeliveryDetail, error) {
+	trigger, err := c.getAppTrigger(ctx, app, token)
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := c.getTriggerDelivery(ctx, trigger, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return &apisv1.WebhookDeliveryDetail{
+		WebhookDeliveryBase: *assembler.ConvertWebhookDelivery2DTO(*delivery),
+		Headers:             delivery.Headers,
+		Body:                delivery.Body,
+		BodyTruncated:       delivery.BodyTruncated,
+	}, nil
+}
+
+// ReplayWebhookDelivery handle the stored delivery again, the signature and the idempotency are not checked.
+// The deliveries rejected by the verification could not be replayed. The result is recorded as a new delivery.
+func (c *webhookServiceImpl) ReplayWebhookDelivery(ctx context.Context, app *model.Application, token, deliveryID string) (*apisv1.WebhookDeliveryBase, error) {
+	trigger, err := c.getAppTrigger(ctx, app, token)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := c.getTriggerDelivery(ctx, trigger, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if stored.BodyTruncated {
+		return nil, bcode.ErrWebhookDeliveryNotReplayable
+	}
+	for _, rejected := range []*bcode.Bcode{bcode.ErrInvalidWebhookSignature, bcode.ErrWebhookSourceForbidden, bcode.ErrWebhookReplayed} {
+		if stored.ErrorCode == rejected.BusinessCode {
+			return nil, bcode.ErrWebhookDeliveryNotVerified
+		}
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", strings.NewReader(stored.Body))
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range stored.Headers {
+		if value != redactedHeaderValue {
+			httpReq.Header.Set(name, value)
+		}
+	}
+	if httpReq.Header.Get(restful.HEADER_ContentType) == "" {
+		httpReq.Header.Set(restful.HEADER_ContentType, restful.MIME_JSON)
+	}
+	req := restful.NewRequest(httpReq)
+	delivery, _, err := c.newWebhookDelivery(trigger, req)
+	if err != nil {
+		return nil, err
+	}
+	delivery.ReplayOf = stored.ID
+	delivery.SourceIP = ""
+	delivery.User, _ = ctx.Value(&apisv1.CtxKeyUser).(string)
+	// the error is recorded in the delivery
+	_, _ = c.handleWebhookDelivery(ctx, trigger, req, delivery, false, nil)
+	return assembler.ConvertWebhookDelivery2DTO(*delivery), nil
+}
+
+// PurgeExpiredDeliveries remove the deliveries that are older than the history period
+func (c *webhookServiceImpl) PurgeExpiredDeliveries(ctx context.Context) error {
+	entities, err := c.Store.List(ctx, &model.WebhookDelivery{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		delivery := entity.(*model.WebhookDelivery)
+		if time.Since(delivery.Time) < webhookDeliveryHistory {
+			continue
+		}
+		if err := c.Store.Delete(ctx, delivery); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("failed to delete the webhook delivery %s: %s", delivery.ID, err.Error())
+		}
+	}
+	return nil
+}
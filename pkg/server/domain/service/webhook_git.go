@@ -0,0 +1,431 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/kubevela/pkg/util/slices"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	headerGitHubEvent    = "X-GitHub-Event"
+	headerGiteaEvent     = "X-Gitea-Event"
+	headerBitbucketEvent = "X-Event-Key"
+
+	gitZeroCommit  = "0000000000000000000000000000000000000000"
+	gitBranchRef   = "refs/heads/"
+	gitTagRef      = "refs/tags/"
+	gitShortCommit = 7
+)
+
+// defaultGitEvents the events handled if the trigger does not specify them
+var defaultGitEvents = []string{model.GitEventPush, model.GitEventTag}
+
+// gitEvent the git event of the providers, it is also the data of the properties template
+type gitEvent struct {
+	Event       string
+	Repository  string
+	Branch      string
+	Tag         string
+	Commit      string
+	ShortCommit string
+	User        string
+	// Number the number of the merge request
+	Number     int64
+	CommitTime *time.Time
+}
+
+type gitHandlerImpl struct {
+	provider string
+	event    *gitEvent
+	// skipped the reason why the event is not handled
+	skipped string
+	w       *webhookServiceImpl
+}
+
+func (c *webhookServiceImpl) newGitHandler(provider string, req *restful.Request) (webhookHandler, error) {
+	var event *gitEvent
+	var skipped string
+	var err error
+	switch provider {
+	case model.PayloadTypeGitHub:
+		event, skipped, err = parseGitHubEvent(req.HeaderParameter(headerGitHubEvent), req)
+	case model.PayloadTypeGitea:
+		event, skipped, err = parseGitHubEvent(req.HeaderParameter(headerGiteaEvent), req)
+	case model.PayloadTypeGitLab:
+		event, skipped, err = parseGitLabEvent(req)
+	case model.PayloadTypeBitbucket:
+		event, skipped, err = parseBitbucketEvent(req.HeaderParameter(headerBitbucketEvent), req)
+	default:
+		return nil, bcode.ErrInvalidWebhookPayloadType
+	}
+	if err != nil {
+		return nil, err
+	}
+	if event != nil && event.ShortCommit == "" {
+		event.ShortCommit = event.Commit
+		if len(event.ShortCommit) > gitShortCommit {
+			event.ShortCommit = event.ShortCommit[:gitShortCommit]
+		}
+	}
+	return &gitHandlerImpl{provider: provider, event: event, skipped: skipped, w: c}, nil
+}
+
+func (g *gitHandlerImpl) handle(ctx context.Context, webhookTrigger *model.ApplicationTrigger, app *model.Application) (interface{}, error) {
+	if g.event == nil {
+		klog.Infof("skip the %s webhook of the application %s: %s", g.provider, app.Name, g.skipped)
+		return &apisv1.ApplicationWebhookSkippedResponse{State: "skipped", Description: g.skipped}, nil
+	}
+	event := g.event
+	if !matchGitTriggerRules(webhookTrigger, event) {
+		return &apisv1.ApplicationWebhookSkippedResponse{
+			State:       "skipped",
+			Description: fmt.Sprintf("the %s event of %s%s is filtered by the trigger", event.Event, event.Branch, event.Tag),
+		}, nil
+	}
+	if webhookTrigger.PropertiesTemplate != "" {
+		patch, err := renderGitProperties(webhookTrigger.PropertiesTemplate, event)
+		if err != nil {
+			return nil, err
+		}
+		component, err := getComponent(ctx, g.w.Store, webhookTrigger)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.w.patchComponentProperties(ctx, component, patch); err != nil {
+			return nil, err
+		}
+	}
+	return g.w.ApplicationService.Deploy(ctx, app, apisv1.ApplicationDeployRequest{
+		WorkflowName: webhookTrigger.WorkflowName,
+		Note:         "triggered by webhook " + g.provider,
+		TriggerType:  apisv1.TriggerTypeWebhook,
+		Force:        true,
+		CodeInfo: &model.CodeInfo{
+			Commit:     event.Commit,
+			Branch:     event.Branch,
+			Tag:        event.Tag,
+			User:       event.User,
+			CommitTime: event.CommitTime,
+		},
+	})
+}
+
+func (g *gitHandlerImpl) install() {
+	WebhookHandlers = append(WebhookHandlers, g.provider)
+}
+
+func parseGitHubEvent(eventType string, req *restful.Request) (*gitEvent, string, error) {
+	var githubReq apisv1.HandleApplicationTriggerGitHubRequest
+	if err := req.ReadEntity(&githubReq); err != nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	switch eventType {
+	case "push":
+		if githubReq.Deleted || githubReq.After == gitZeroCommit {
+			return nil, "the reference is deleted", nil
+		}
+		event := newGitRefEvent(githubReq.Ref)
+		if event == nil {
+			return nil, "", bcode.ErrInvalidWebhookPayloadBody
+		}
+		event.Repository = githubReq.Repository.FullName
+		event.Commit = githubReq.After
+		event.User = firstNonEmpty(githubReq.Pusher.Name, githubReq.Pusher.Login, githubReq.Pusher.Username, githubReq.Sender.Login)
+		if githubReq.HeadCommit != nil {
+			event.CommitTime = parseGitTime(githubReq.HeadCommit.Timestamp)
+		} else if len(githubReq.Commits) > 0 {
+			event.CommitTime = parseGitTime(githubReq.Commits[len(githubReq.Commits)-1].Timestamp)
+		}
+		return event, "", nil
+	case "pull_request":
+		pr := githubReq.PullRequest
+		if pr == nil {
+			return nil, "", bcode.ErrInvalidWebhookPayloadBody
+		}
+		// Gitea uses synchronized instead of synchronize
+		if !slices.Contains([]string{"opened", "reopened", "synchronize", "synchronized"}, githubReq.Action) {
+			return nil, fmt.Sprintf("the pull request action %s is ignored", githubReq.Action), nil
+		}
+		return &gitEvent{
+			Event:      model.GitEventMergeRequest,
+			Repository: githubReq.Repository.FullName,
+			Branch:     pr.Head.Ref,
+			Commit:     pr.Head.SHA,
+			User:       firstNonEmpty(pr.User.Login, pr.User.Username, githubReq.Sender.Login),
+			Number:     pr.Number,
+		}, "", nil
+	default:
+		return nil, fmt.Sprintf("the event %s is not supported", eventType), nil
+	}
+}
+
+func parseGitLabEvent(req *restful.Request) (*gitEvent, string, error) {
+	var gitlabReq apisv1.HandleApplicationTriggerGitLabRequest
+	if err := req.ReadEntity(&gitlabReq); err != nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	switch gitlabReq.ObjectKind {
+	case "push", "tag_push":
+		if gitlabReq.After == gitZeroCommit {
+			return nil, "the reference is deleted", nil
+		}
+		event := newGitRefEvent(gitlabReq.Ref)
+		if event == nil {
+			return nil, "", bcode.ErrInvalidWebhookPayloadBody
+		}
+		event.Repository = gitlabReq.Project.PathWithNamespace
+		event.Commit = firstNonEmpty(gitlabReq.CheckoutSHA, gitlabReq.After)
+		event.User = firstNonEmpty(gitlabReq.UserUsername, gitlabReq.UserName)
+		for _, commit := range gitlabReq.Commits {
+			if commit.ID == event.Commit {
+				event.CommitTime = parseGitTime(commit.Timestamp)
+			}
+		}
+		return event, "", nil
+	case "merge_request":
+		mr := gitlabReq.ObjectAttributes
+		if mr == nil {
+			return nil, "", bcode.ErrInvalidWebhookPayloadBody
+		}
+		if !slices.Contains([]string{"open", "reopen", "update"}, mr.Action) {
+			return nil, fmt.Sprintf("the merge request action %s is ignored", mr.Action), nil
+		}
+		event := &gitEvent{
+			Event:      model.GitEventMergeRequest,
+			Repository: gitlabReq.Project.PathWithNamespace,
+			Branch:     mr.SourceBranch,
+			Commit:     mr.LastCommit.ID,
+			CommitTime: parseGitTime(mr.LastCommit.Timestamp),
+			Number:     mr.IID,
+		}
+		if gitlabReq.User != nil {
+			event.User = firstNonEmpty(gitlabReq.User.Username, gitlabReq.User.Name)
+		}
+		return event, "", nil
+	default:
+		return nil, fmt.Sprintf("the event %s is not supported", gitlabReq.ObjectKind), nil
+	}
+}
+
+func parseBitbucketEvent(eventType string, req *restful.Request) (*gitEvent, string, error) {
+	var bitbucketReq apisv1.HandleApplicationTriggerBitbucketRequest
+	if err := req.ReadEntity(&bitbucketReq); err != nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	user := firstNonEmpty(bitbucketReq.Actor.Nickname, bitbucketReq.Actor.DisplayName)
+	switch eventType {
+	case "repo:push":
+		if bitbucketReq.Push == nil {
+			return nil, "", bcode.ErrInvalidWebhookPayloadBody
+		}
+		// the last change that creates or updates the reference is handled
+		var ref *apisv1.BitbucketRef
+		for _, change := range bitbucketReq.Push.Changes {
+			if change.New != nil {
+				ref = change.New
+			}
+		}
+		if ref == nil {
+			return nil, "the reference is deleted", nil
+		}
+		event := &gitEvent{
+			Repository: bitbucketReq.Repository.FullName,
+			Commit:     ref.Target.Hash,
+			CommitTime: parseGitTime(ref.Target.Date),
+			User:       user,
+		}
+		switch ref.Type {
+		case "branch":
+			event.Event = model.GitEventPush
+			event.Branch = ref.Name
+		case "tag", "annotated_tag":
+			event.Event = model.GitEventTag
+			event.Tag = ref.Name
+		default:
+			return nil, fmt.Sprintf("the reference type %s is not supported", ref.Type), nil
+		}
+		return event, "", nil
+	case "pullrequest:created", "pullrequest:updated":
+		pr := bitbucketReq.PullRequest
+		if pr == nil {
+			return nil, "", bcode.ErrInvalidWebhookPayloadBody
+		}
+		return &gitEvent{
+			Event:      model.GitEventMergeRequest,
+			Repository: bitbucketReq.Repository.FullName,
+			Branch:     pr.Source.Branch.Name,
+			Commit:     pr.Source.Commit.Hash,
+			User:       firstNonEmpty(pr.Author.Nickname, pr.Author.DisplayName, user),
+			Number:     pr.ID,
+		}, "", nil
+	default:
+		return nil, fmt.Sprintf("the event %s is not supported", eventType), nil
+	}
+}
+
+// newGitRefEvent build the push or tag event by the full reference name
+func newGitRefEvent(ref string) *gitEvent {
+	switch {
+	case strings.HasPrefix(ref, gitBranchRef):
+		return &gitEvent{Event: model.GitEventPush, Branch: strings.TrimPrefix(ref, gitBranchRef)}
+	case strings.HasPrefix(ref, gitTagRef):
+		return &gitEvent{Event: model.GitEventTag, Tag: strings.TrimPrefix(ref, gitTagRef)}
+	default:
+		return nil
+	}
+}
+
+// matchGitTriggerRules the tag filters match the tag events, the branch filters match the branch of the push and merge request events
+func matchGitTriggerRules(trigger *model.ApplicationTrigger, event *gitEvent) bool {
+	events := trigger.GitEvents
+	if len(events) == 0 {
+		events = defaultGitEvents
+	}
+	if !slices.Contains(events, event.Event) {
+		return false
+	}
+	if event.Event == model.GitEventTag {
+		return matchGlobs(trigger.TagFilters, event.Tag)
+	}
+	return matchGlobs(trigger.BranchFilters, event.Branch)
+}
+
+func matchGlobs(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// renderGitProperties render the properties template to the patch of the component properties.
+// The values of the payload are rendered as the placeholders and set after the YAML is parsed,
+// so a branch or a user name containing `:` or a newline could not inject the keys.
+func renderGitProperties(properties string, event *gitEvent) (*runtime.RawExtension, error) {
+	tmpl, err := template.New("properties").Option("missingkey=error").Parse(properties)
+	if err != nil {
+		return nil, bcode.ErrApplicationTriggerTemplateInvalid
+	}
+	data := *event
+	var replacements []string
+	placeholder := func(value string) string {
+		if value == "" {
+			return ""
+		}
+		key := fmt.Sprintf("__git_value_%d__", len(replacements)/2)
+		replacements = append(replacements, key, value)
+		return key
+	}
+	data.Repository = placeholder(event.Repository)
+	data.Branch = placeholder(event.Branch)
+	data.Tag = placeholder(event.Tag)
+	data.Commit = placeholder(event.Commit)
+	data.ShortCommit = placeholder(event.ShortCommit)
+	data.User = placeholder(event.User)
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, &data); err != nil {
+		klog.Errorf("failed to render the properties template: %s", err.Error())
+		return nil, bcode.ErrApplicationTriggerTemplateInvalid
+	}
+	var rendered map[string]interface{}
+	if err := yaml.Unmarshal(buffer.Bytes(), &rendered); err != nil || rendered == nil {
+		return nil, bcode.ErrApplicationTriggerTemplateInvalid
+	}
+	patch, err := json.Marshal(replaceGitValues(rendered, strings.NewReplacer(replacements...)))
+	if err != nil {
+		return nil, bcode.ErrApplicationTriggerTemplateInvalid
+	}
+	return &runtime.RawExtension{Raw: patch}, nil
+}
+
+// replaceGitValues replace the placeholders in the keys and the string values with the values of the payload
+func replaceGitValues(value interface{}, replacer *strings.Replacer) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		replaced := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			replaced[replacer.Replace(key)] = replaceGitValues(item, replacer)
+		}
+		return replaced
+	case []interface{}:
+		for i, item := range v {
+			v[i] = replaceGitValues(item, replacer)
+		}
+		return v
+	case string:
+		return replacer.Replace(v)
+	default:
+		return v
+	}
+}
+
+// checkGitTriggerRules check the filters are the valid glob patterns and the template could be parsed
+func checkGitTriggerRules(branchFilters, tagFilters []string, properties string) error {
+	for _, pattern := range append(append([]string{}, branchFilters...), tagFilters...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return bcode.ErrApplicationTriggerFilterInvalid
+		}
+	}
+	if properties != "" {
+		if _, err := template.New("properties").Parse(properties); err != nil {
+			return bcode.ErrApplicationTriggerTemplateInvalid
+		}
+	}
+	return nil
+}
+
+func parseGitTime(t string) *time.Time {
+	if t == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, t)
+	if err != nil {
+		klog.Warningf("failed to parse the commit time %s: %s", t, err.Error())
+		return nil
+	}
+	return &parsed
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
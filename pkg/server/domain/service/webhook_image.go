@@ -0,0 +1,240 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/emicklei/go-restful/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// defaultImageProperties keep the behavior before the property mapping is supported
+var defaultImageProperties = []model.ImagePropertyMapping{{Path: "image", Template: "{{.Image}}"}}
+
+// imageEvent the pushed image, it is the data of the property templates
+type imageEvent struct {
+	// Image the full reference of the image, such as docker.io/library/nginx:1.25
+	Image string
+	// Repository the image without the tag, such as docker.io/library/nginx
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+func newImageEvent(image, tag, digest string) *imageEvent {
+	repository := image
+	if i := strings.LastIndex(repository, "@"); i > 0 {
+		repository = repository[:i]
+	}
+	if tag != "" {
+		repository = strings.TrimSuffix(repository, ":"+tag)
+	}
+	return &imageEvent{Image: image, Repository: repository, Tag: tag, Digest: digest}
+}
+
+// imageHandler the handler of the image registry, the pushed image is parsed from the payload
+type imageHandler interface {
+	// pushedImage return the pushed image, or the reason why the event is not handled
+	pushedImage(trigger *model.ApplicationTrigger) (*imageEvent, string, error)
+}
+
+// newImageHandler create the handler of the image registry by the payload type of the trigger
+func (c *webhookServiceImpl) newImageHandler(trigger *model.ApplicationTrigger, req *restful.Request) (imageHandler, error) {
+	var handler webhookHandler
+	var err error
+	switch trigger.PayloadType {
+	case model.PayloadTypeACR:
+		handler, err = c.newACRHandler(req)
+	case model.PayloadTypeHarbor:
+		handler, err = c.newHarborHandler(req)
+	case model.PayloadTypeDockerhub:
+		handler, err = c.newDockerHubHandler(req)
+	case model.PayloadTypeJFrog:
+		handler, err = c.newJFrogHandler(req)
+	default:
+		handler, err = c.newRegistryHandler(trigger, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return handler.(imageHandler), nil
+}
+
+// skipImageEvent return the response if the tag is filtered by the trigger or the image has been deployed
+func (c *webhookServiceImpl) skipImageEvent(ctx context.Context, trigger *model.ApplicationTrigger, event *imageEvent) (*apisv1.ApplicationWebhookSkippedResponse, error) {
+	if !matchImageTagFilter(trigger.ImageTagFilter, event.Tag) {
+		klog.Infof("skip the image tag %s that is filtered by the trigger %s", event.Tag, trigger.Name)
+		return &apisv1.ApplicationWebhookSkippedResponse{
+			State:       model.WebhookDeliverySkipped,
+			Description: fmt.Sprintf("the tag %s is filtered by the trigger", event.Tag),
+		}, nil
+	}
+	return c.checkDuplicatedImage(ctx, event)
+}
+
+// matchImageTagFilter the tag must match the regex and the semver constraint, and not be ignored
+func matchImageTagFilter(filter *model.ImageTagFilter, tag string) bool {
+	if filter == nil {
+		return true
+	}
+	for _, pattern := range filter.Ignore {
+		if matched, _ := path.Match(pattern, tag); matched {
+			return false
+		}
+	}
+	if filter.Regex != "" {
+		re, err := regexp.Compile(filter.Regex)
+		if err != nil || !re.MatchString(tag) {
+			return false
+		}
+	}
+	if filter.Semver != "" {
+		constraint, err := semver.NewConstraint(filter.Semver)
+		if err != nil {
+			return false
+		}
+		version, err := semver.NewVersion(tag)
+		if err != nil || !constraint.Check(version) {
+			return false
+		}
+	}
+	return true
+}
+
+// patchImageProperties render the property templates by the image and set them to the components
+func (c *webhookServiceImpl) patchImageProperties(ctx context.Context, trigger *model.ApplicationTrigger, event *imageEvent) error {
+	mappings := trigger.ImageProperties
+	if len(mappings) == 0 {
+		mappings = defaultImageProperties
+	}
+	components := map[string]*model.ApplicationComponent{}
+	var names []string
+	for _, mapping := range mappings {
+		component, err := c.getMappedComponent(ctx, trigger, mapping.Component, components)
+		if err != nil {
+			return err
+		}
+		if _, exist := components[component.Name]; !exist {
+			components[component.Name] = component
+			names = append(names, component.Name)
+		}
+		value, err := renderImageValue(mapping.Template, event)
+		if err != nil {
+			return err
+		}
+		if component.Properties == nil {
+			component.Properties = &model.JSONStruct{}
+		}
+		if err := setPropertyByPath(*component.Properties, mapping.Path, value); err != nil {
+			klog.Errorf("failed to set the property %s of the component %s: %s", mapping.Path, component.Name, err.Error())
+			return bcode.ErrApplicationTriggerPropertyPathInvalid
+		}
+	}
+	for _, name := range names {
+		if err := c.Store.Put(ctx, components[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *webhookServiceImpl) getMappedComponent(ctx context.Context, trigger *model.ApplicationTrigger, name string, cache map[string]*model.ApplicationComponent) (*model.ApplicationComponent, error) {
+	if name == "" {
+		name = trigger.ComponentName
+	}
+	if name == "" {
+		// the first component of the application
+		component, err := getComponent(ctx, c.Store, trigger)
+		if err != nil {
+			return nil, err
+		}
+		name = component.Name
+		if cached, exist := cache[name]; exist {
+			return cached, nil
+		}
+		return component, nil
+	}
+	if cached, exist := cache[name]; exist {
+		return cached, nil
+	}
+	component := &model.ApplicationComponent{AppPrimaryKey: trigger.AppPrimaryKey, Name: name}
+	if err := c.Store.Get(ctx, component); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrApplicationComponentNotExist
+		}
+		return nil, err
+	}
+	return component, nil
+}
+
+func renderImageValue(value string, event *imageEvent) (string, error) {
+	tmpl, err := template.New("value").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", bcode.ErrApplicationTriggerTemplateInvalid
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, event); err != nil {
+		klog.Errorf("failed to render the image template: %s", err.Error())
+		return "", bcode.ErrApplicationTriggerTemplateInvalid
+	}
+	return buffer.String(), nil
+}
+
+// checkImageTriggerRules check the property mappings and the tag filter of the trigger
+func checkImageTriggerRules(mappings []model.ImagePropertyMapping, filter *model.ImageTagFilter) error {
+	for _, mapping := range mappings {
+		if _, err := parsePropertyPath(mapping.Path); err != nil {
+			return bcode.ErrApplicationTriggerPropertyPathInvalid
+		}
+		if _, err := template.New("value").Parse(mapping.Template); err != nil || mapping.Template == "" {
+			return bcode.ErrApplicationTriggerTemplateInvalid
+		}
+	}
+	if filter == nil {
+		return nil
+	}
+	for _, pattern := range filter.Ignore {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return bcode.ErrApplicationTriggerFilterInvalid
+		}
+	}
+	if filter.Regex != "" {
+		if _, err := regexp.Compile(filter.Regex); err != nil {
+			return bcode.ErrApplicationTriggerFilterInvalid
+		}
+	}
+	if filter.Semver != "" {
+		if _, err := semver.NewConstraint(filter.Semver); err != nil {
+			return bcode.ErrApplicationTriggerFilterInvalid
+		}
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/kubevela/pkg/util/slices"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+var payloadMappingActions = []string{"", ActionExecute, ActionApprove, ActionTerminate, ActionRollback}
+
+type mappedHandlerImpl struct {
+	payload map[string]interface{}
+	w       *webhookServiceImpl
+}
+
+func (c *webhookServiceImpl) newMappedHandler(req *restful.Request) (webhookHandler, error) {
+	var payload map[string]interface{}
+	if err := req.ReadEntity(&payload); err != nil {
+		return nil, bcode.ErrInvalidWebhookPayloadBody
+	}
+	return &mappedHandlerImpl{payload: payload, w: c}, nil
+}
+
+func (m *mappedHandlerImpl) handle(ctx context.Context, webhookTrigger *model.ApplicationTrigger, app *model.Application) (interface{}, error) {
+	result, err := m.w.evaluatePayloadMapping(ctx, webhookTrigger, m.payload)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Matched {
+		return &apisv1.ApplicationWebhookSkippedResponse{State: "skipped", Description: result.Reason}, nil
+	}
+	// the mapped request is handled in the same way as the custom payload
+	custom := &customHandlerImpl{
+		req: apisv1.HandleApplicationTriggerWebhookRequest{
+			Action:   result.Action,
+			Step:     result.Step,
+			Upgrade:  result.Upgrade,
+			CodeInfo: result.CodeInfo,
+		},
+		imageInfo: result.ImageInfo,
+		w:         m.w,
+	}
+	return custom.handle(ctx, webhookTrigger, app)
+}
+
+func (m *mappedHandlerImpl) install() {
+	WebhookHandlers = append(WebhookHandlers, model.PayloadTypeMapped)
+}
+
+// TestApplicationTrigger evaluate the sample payload by the mapping of the trigger without deploying
+func (c *webhookServiceImpl) TestApplicationTrigger(ctx context.Context, app *model.Application, token string, payload map[string]interface{}) (*apisv1.TestApplicationTriggerResponse, error) {
+	trigger, err := c.getAppTrigger(ctx, app, token)
+	if err != nil {
+		return nil, err
+	}
+	if trigger.PayloadType != model.PayloadTypeMapped {
+		return nil, bcode.ErrInvalidWebhookPayloadType
+	}
+	return c.evaluatePayloadMapping(ctx, trigger, payload)
+}
+
+func (c *webhookServiceImpl) evaluatePayloadMapping(ctx context.Context, trigger *model.ApplicationTrigger, payload map[string]interface{}) (*apisv1.TestApplicationTriggerResponse, error) {
+	mapping := trigger.PayloadMapping
+	if mapping == nil {
+		return nil, bcode.ErrApplicationTriggerMappingInvalid
+	}
+	for _, condition := range mapping.Conditions {
+		if !matchPayloadCondition(payload, condition) {
+			return &apisv1.TestApplicationTriggerResponse{
+				Reason: fmt.Sprintf("the condition %s %s %s is not matched", condition.Path, condition.Operator, strings.Join(condition.Values, ",")),
+			}, nil
+		}
+	}
+	result := &apisv1.TestApplicationTriggerResponse{
+		Matched:      true,
+		WorkflowName: trigger.WorkflowName,
+		Action:       lookupPayloadString(payload, mapping.Action),
+		Step:         lookupPayloadString(payload, mapping.Step),
+	}
+	if !slices.Contains(payloadMappingActions, result.Action) {
+		return nil, bcode.ErrInvalidWebhookPayloadBody
+	}
+
+	properties := map[string]interface{}{}
+	paths := make([]string, 0, len(mapping.Properties))
+	for path := range mapping.Properties {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		value, exist := lookupPayloadValue(payload, mapping.Properties[path])
+		if !exist {
+			continue
+		}
+		if err := setPropertyByPath(properties, path, value); err != nil {
+			return nil, bcode.ErrApplicationTriggerPropertyPathInvalid
+		}
+	}
+	if len(properties) > 0 {
+		component := lookupPayloadString(payload, mapping.Component)
+		if component == "" {
+			target, err := getComponent(ctx, c.Store, trigger)
+			if err != nil {
+				return nil, err
+			}
+			component = target.Name
+		}
+		upgrade := model.JSONStruct(properties)
+		result.Upgrade = map[string]*model.JSONStruct{component: &upgrade}
+	}
+
+	if info := mapping.CodeInfo; info != nil {
+		result.CodeInfo = &model.CodeInfo{
+			Commit:     lookupPayloadString(payload, info.Commit),
+			Branch:     lookupPayloadString(payload, info.Branch),
+			Tag:        lookupPayloadString(payload, info.Tag),
+			User:       lookupPayloadString(payload, info.User),
+			CommitTime: parsePayloadTime(lookupPayloadString(payload, info.CommitTime)),
+		}
+	}
+	if info := mapping.ImageInfo; info != nil {
+		repository := lookupPayloadString(payload, info.Repository)
+		namespace := lookupPayloadString(payload, info.Namespace)
+		fullName := repository
+		if namespace != "" {
+			fullName = namespace + "/" + repository
+		}
+		result.ImageInfo = &model.ImageInfo{
+			Type: model.PayloadTypeMapped,
+			Resource: &model.ImageResource{
+				URL:    lookupPayloadString(payload, info.URL),
+				Tag:    lookupPayloadString(payload, info.Tag),
+				Digest: lookupPayloadString(payload, info.Digest),
+			},
+			Repository: &model.ImageRepository{
+				Name:      repository,
+				Namespace: namespace,
+				FullName:  fullName,
+			},
+		}
+	}
+	return result, nil
+}
+
+func matchPayloadCondition(payload map[string]interface{}, condition model.PayloadCondition) bool {
+	value, exist := lookupPayloadValue(payload, condition.Path)
+	switch condition.Operator {
+	case model.PayloadConditionExists:
+		return exist
+	case model.PayloadConditionNotExists:
+		return !exist
+	case model.PayloadConditionEquals:
+		return exist && slices.Contains(condition.Values, formatPayloadValue(value))
+	case model.PayloadConditionNotEquals:
+		return !exist || !slices.Contains(condition.Values, formatPayloadValue(value))
+	case model.PayloadConditionMatches:
+		if !exist || len(condition.Values) == 0 {
+			return false
+		}
+		re, err := regexp.Compile(condition.Values[0])
+		return err == nil && re.MatchString(formatPayloadValue(value))
+	default:
+		return false
+	}
+}
+
+// lookupPayloadValue evaluate the JSONPath expression, or return the literal if it does not start with `$`
+func lookupPayloadValue(payload map[string]interface{}, expression string) (interface{}, bool) {
+	if expression == "" {
+		return nil, false
+	}
+	if !strings.HasPrefix(expression, "$") {
+		return expression, true
+	}
+	value, err := utils.JsonPathLookup(payload, expression)
+	if err != nil || value == nil {
+		return nil, false
+	}
+	return value, true
+}
+
+func lookupPayloadString(payload map[string]interface{}, expression string) string {
+	value, exist := lookupPayloadValue(payload, expression)
+	if !exist {
+		return ""
+	}
+	return formatPayloadValue(value)
+}
+
+func formatPayloadValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		out, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(out)
+	}
+}
+
+// parsePayloadTime parse the RFC3339 time or the unix seconds
+func parsePayloadTime(t string) *time.Time {
+	if unix, err := strconv.ParseInt(t, 10, 64); err == nil {
+		parsed := time.Unix(unix, 0)
+		return &parsed
+	}
+	return parseGitTime(t)
+}
+
+// checkPayloadMapping the mapping is required by the mapped payload type, and the expressions must be valid
+func checkPayloadMapping(payloadType string, mapping *model.PayloadMapping) error {
+	if mapping == nil {
+		if payloadType == model.PayloadTypeMapped {
+			return bcode.ErrApplicationTriggerMappingInvalid
+		}
+		return nil
+	}
+	expressions := []string{mapping.Action, mapping.Step, mapping.Component}
+	for _, condition := range mapping.Conditions {
+		if !strings.HasPrefix(condition.Path, "$") {
+			return bcode.ErrApplicationTriggerMappingInvalid
+		}
+		expressions = append(expressions, condition.Path)
+		switch condition.Operator {
+		case model.PayloadConditionExists, model.PayloadConditionNotExists:
+		case model.PayloadConditionEquals, model.PayloadConditionNotEquals:
+			if len(condition.Values) == 0 {
+				return bcode.ErrApplicationTriggerMappingInvalid
+			}
+		case model.PayloadConditionMatches:
+			if len(condition.Values) != 1 {
+				return bcode.ErrApplicationTriggerMappingInvalid
+			}
+			if _, err := regexp.Compile(condition.Values[0]); err != nil {
+				return bcode.ErrApplicationTriggerMappingInvalid
+			}
+		default:
+			return bcode.ErrApplicationTriggerMappingInvalid
+		}
+	}
+	for path, expression := range mapping.Properties {
+		if _, err := parsePropertyPath(path); err != nil {
+			return bcode.ErrApplicationTriggerPropertyPathInvalid
+		}
+		expressions = append(expressions, expression)
+	}
+	if info := mapping.CodeInfo; info != nil {
+		expressions = append(expressions, info.Commit, info.Branch, info.Tag, info.User, info.CommitTime)
+	}
+	if info := mapping.ImageInfo; info != nil {
+		expressions = append(expressions, info.URL, info.Tag, info.Digest, info.Repository, info.Namespace)
+	}
+	for _, expression := range expressions {
+		if !strings.HasPrefix(expression, "$") {
+			continue
+		}
+		if _, err := utils.Compile(expression); err != nil {
+			return bcode.ErrApplicationTriggerMappingInvalid
+		}
+	}
+	if !strings.HasPrefix(mapping.Action, "$") && !slices.Contains(payloadMappingActions, mapping.Action) {
+		return bcode.ErrApplicationTriggerMappingInvalid
+	}
+	return nil
+}
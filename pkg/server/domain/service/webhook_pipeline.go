@@ -0,0 +1,135 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/emicklei/go-restful/v3"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// HandlePipelineWebhook verify the request by the settings of the pipeline trigger, and run the pipeline by the payload.
+// The payload contains the request body as `body`, and the git or image event parsed by the provider as `event`.
+func (c *webhookServiceImpl) HandlePipelineWebhook(ctx context.Context, token string, req *restful.Request) (interface{}, error) {
+	trigger := &model.PipelineTrigger{Token: token}
+	if err := c.Store.Get(ctx, trigger); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrInvalidWebhookToken
+		}
+		return nil, err
+	}
+	if trigger.Type != model.PipelineTriggerTypeWebhook {
+		return nil, bcode.ErrInvalidWebhookToken
+	}
+	webhookTrigger := pipelineTrigger2Webhook(trigger)
+	var body []byte
+	if req.Request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Request.Body); err != nil {
+			return nil, bcode.ErrInvalidWebhookPayloadBody
+		}
+		req.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if err := c.verifyWebhookRequest(ctx, webhookTrigger, req, body); err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{}
+	if len(bytes.TrimSpace(body)) > 0 {
+		var content interface{}
+		if err := json.Unmarshal(body, &content); err != nil {
+			return nil, bcode.ErrInvalidWebhookPayloadBody
+		}
+		payload["body"] = content
+	}
+	event, skipped, err := c.parseWebhookEvent(webhookTrigger, req)
+	if err != nil {
+		return nil, err
+	}
+	if skipped != "" {
+		return &apisv1.ApplicationWebhookSkippedResponse{State: model.WebhookDeliverySkipped, Description: skipped}, nil
+	}
+	if event != nil {
+		payload["event"] = event
+	}
+	return c.PipelineService.RunPipelineByTrigger(ctx, trigger, payload)
+}
+
+// parseWebhookEvent parse the git event or the image event by the provider handlers, the reason is returned if the event is skipped.
+// The tag filter of the trigger is applied to the image event.
+// The other payload types have no event, the values are mapped from the request body.
+func (c *webhookServiceImpl) parseWebhookEvent(trigger *model.ApplicationTrigger, req *restful.Request) (map[string]interface{}, string, error) {
+	switch trigger.PayloadType {
+	case model.PayloadTypeGitHub, model.PayloadTypeGitLab, model.PayloadTypeGitea, model.PayloadTypeBitbucket:
+		handler, err := c.newGitHandler(trigger.PayloadType, req)
+		if err != nil {
+			return nil, "", err
+		}
+		event := handler.(*gitHandlerImpl).event
+		if event == nil {
+			return nil, handler.(*gitHandlerImpl).skipped, nil
+		}
+		if !matchGitTriggerRules(trigger, event) {
+			return nil, fmt.Sprintf("the %s event of %s%s is filtered by the trigger", event.Event, event.Branch, event.Tag), nil
+		}
+		res := map[string]interface{}{
+			"event":       event.Event,
+			"repository":  event.Repository,
+			"branch":      event.Branch,
+			"tag":         event.Tag,
+			"commit":      event.Commit,
+			"shortCommit": event.ShortCommit,
+			"user":        event.User,
+			"number":      event.Number,
+		}
+		if event.CommitTime != nil {
+			res["commitTime"] = event.CommitTime
+		}
+		return res, "", nil
+	case model.PayloadTypeACR, model.PayloadTypeHarbor, model.PayloadTypeDockerhub, model.PayloadTypeJFrog,
+		model.PayloadTypeGHCR, model.PayloadTypeQuay, model.PayloadTypeECR, model.PayloadTypeArtifactRegistry, model.PayloadTypeGitLabRegistry:
+		handler, err := c.newImageHandler(trigger, req)
+		if err != nil {
+			return nil, "", err
+		}
+		event, skipped, err := handler.pushedImage(trigger)
+		if err != nil || event == nil {
+			return nil, skipped, err
+		}
+		if !matchImageTagFilter(trigger.ImageTagFilter, event.Tag) {
+			return nil, fmt.Sprintf("the tag %s is filtered by the trigger", event.Tag), nil
+		}
+		return map[string]interface{}{
+			"image":      event.Image,
+			"repository": event.Repository,
+			"tag":        event.Tag,
+			"digest":     event.Digest,
+		}, "", nil
+	default:
+		return nil, "", nil
+	}
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	apisv1 "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	ghcrEventPackage   = "package"
+	ecrEventSource     = "aws.ecr"
+	ecrEventDetailType = "ECR Image Action"
+	// artifactRegistryHostSuffix the host of the Artifact Registry is <region>-docker.pkg.dev
+	artifactRegistryHostSuffix = "-docker.pkg.dev"
+)
+
+// registryPayloadTypes the registries that share the same handler
+var registryPayloadTypes = []string{model.PayloadTypeGHCR, model.PayloadTypeQuay, model.PayloadTypeECR, model.PayloadTypeArtifactRegistry, model.PayloadTypeGitLabRegistry}
+
+type registryHandlerImpl struct {
+	provider string
+	info     *model.ImageInfo
+	// skipped the reason why the event is not handled
+	skipped string
+	w       *webhookServiceImpl
+}
+
+func (c *webhookServiceImpl) newRegistryHandler(trigger *model.ApplicationTrigger, req *restful.Request) (webhookHandler, error) {
+	provider := trigger.PayloadType
+	var info *model.ImageInfo
+	var skipped string
+	var err error
+	switch provider {
+	case model.PayloadTypeGHCR:
+		info, skipped, err = parseGHCREvent(req)
+	case model.PayloadTypeQuay:
+		info, skipped, err = parseQuayEvent(trigger, req)
+	case model.PayloadTypeECR:
+		info, skipped, err = parseECREvent(trigger, req)
+	case model.PayloadTypeArtifactRegistry:
+		info, skipped, err = parseArtifactRegistryEvent(req)
+	case model.PayloadTypeGitLabRegistry:
+		info, skipped, err = parseRegistryNotification(trigger, req)
+	default:
+		return nil, bcode.ErrInvalidWebhookPayloadType
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info != nil {
+		info.Type = provider
+	}
+	return &registryHandlerImpl{provider: provider, info: info, skipped: skipped, w: c}, nil
+}
+
+func (r *registryHandlerImpl) pushedImage(_ *model.ApplicationTrigger) (*imageEvent, string, error) {
+	if r.info == nil || r.info.Resource == nil {
+		return nil, r.skipped, nil
+	}
+	resource := r.info.Resource
+	return newImageEvent(resource.URL, resource.Tag, resource.Digest), "", nil
+}
+
+func (r *registryHandlerImpl) handle(ctx context.Context, webhookTrigger *model.ApplicationTrigger, app *model.Application) (interface{}, error) {
+	event, reason, _ := r.pushedImage(webhookTrigger)
+	if event == nil {
+		klog.Infof("skip the %s webhook of the application %s: %s", r.provider, app.Name, reason)
+		return &apisv1.ApplicationWebhookSkippedResponse{State: model.WebhookDeliverySkipped, Description: reason}, nil
+	}
+	skipped, err := r.w.skipImageEvent(ctx, webhookTrigger, event)
+	if err != nil {
+		return nil, err
+	}
+	if skipped != nil {
+		return skipped, nil
+	}
+	if err := r.w.patchImageProperties(ctx, webhookTrigger, event); err != nil {
+		return nil, err
+	}
+	return r.w.ApplicationService.Deploy(ctx, app, apisv1.ApplicationDeployRequest{
+		WorkflowName: webhookTrigger.WorkflowName,
+		Note:         "triggered by webhook " + r.provider,
+		TriggerType:  apisv1.TriggerTypeWebhook,
+		Force:        true,
+		ImageInfo:    r.info,
+	})
+}
+
+func (r *registryHandlerImpl) install() {
+	WebhookHandlers = append(WebhookHandlers, r.provider)
+}
+
+// parseGHCREvent handle the published container packages, the other packages and the untagged versions are skipped
+func parseGHCREvent(req *restful.Request) (*model.ImageInfo, string, error) {
+	if event := req.HeaderParameter(headerGitHubEvent); event != ghcrEventPackage {
+		return nil, fmt.Sprintf("the %s event is not handled", event), nil
+	}
+	var ghcrReq apisv1.HandleApplicationTriggerGHCRRequest
+	if err := req.ReadEntity(&ghcrReq); err != nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	pkg := ghcrReq.Package
+	if !strings.EqualFold(pkg.PackageType, model.GHCRPackageTypeContainer) {
+		return nil, fmt.Sprintf("the %s package is not handled", pkg.PackageType), nil
+	}
+	if ghcrReq.Action != "published" && ghcrReq.Action != "updated" {
+		return nil, fmt.Sprintf("the %s action is not handled", ghcrReq.Action), nil
+	}
+	version := pkg.PackageVersion
+	tag := version.ContainerMetadata.Tag.Name
+	if tag == "" {
+		return nil, "the untagged version is not handled", nil
+	}
+	owner := firstNonEmpty(pkg.Namespace, pkg.Owner.Login)
+	fullName := strings.ToLower(owner + "/" + pkg.Name)
+	image := version.PackageURL
+	if !strings.HasSuffix(image, ":"+tag) {
+		image = fmt.Sprintf("ghcr.io/%s:%s", fullName, tag)
+	}
+	repositoryType := "public"
+	if ghcrReq.Repository.Private {
+		repositoryType = "private"
+	}
+	return &model.ImageInfo{
+		Resource: &model.ImageResource{
+			Digest:     version.ContainerMetadata.Tag.Digest,
+			Tag:        tag,
+			URL:        image,
+			CreateTime: parseImageTime(version.CreatedAt),
+		},
+		Repository: &model.ImageRepository{
+			Name:       pkg.Name,
+			Namespace:  owner,
+			FullName:   fullName,
+			Type:       repositoryType,
+			CreateTime: parseImageTime(pkg.CreatedAt),
+		},
+	}, "", nil
+}
+
+// parseQuayEvent Quay sends all the updated tags of the push, the first tag that matches the filter of the trigger is deployed
+func parseQuayEvent(trigger *model.ApplicationTrigger, req *restful.Request) (*model.ImageInfo, string, error) {
+	var quayReq apisv1.HandleApplicationTriggerQuayRequest
+	if err := req.ReadEntity(&quayReq); err != nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	if quayReq.DockerURL == "" || len(quayReq.UpdatedTags) == 0 {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	tag := quayReq.UpdatedTags[0]
+	for _, updated := range quayReq.UpdatedTags {
+		if matchImageTagFilter(trigger.ImageTagFilter, updated) {
+			tag = updated
+			break
+		}
+	}
+	return &model.ImageInfo{
+		Resource: &model.ImageResource{
+			Tag: tag,
+			URL: fmt.Sprintf("%s:%s", quayReq.DockerURL, tag),
+		},
+		Repository: &model.ImageRepository{
+			Name:      quayReq.Name,
+			Namespace: quayReq.Namespace,
+			FullName:  quayReq.Repository,
+		},
+	}, "", nil
+}
+
+// parseECREvent handle the succeeded push actions, the registry of the account is used if the trigger does not specify it
+func parseECREvent(trigger *model.ApplicationTrigger, req *restful.Request) (*model.ImageInfo, string, error) {
+	var ecrReq apisv1.HandleApplicationTriggerECRRequest
+	if err := req.ReadEntity(&ecrReq); err != nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	if ecrReq.Source != ecrEventSource || ecrReq.DetailType != ecrEventDetailType {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	detail := ecrReq.Detail
+	if detail.ActionType != model.ECRActionTypePush || detail.Result != model.ECRResultSuccess {
+		return nil, fmt.Sprintf("the %s action with the result %s is not handled", detail.ActionType, detail.Result), nil
+	}
+	if detail.ImageTag == "" {
+		return nil, "the untagged image is not handled", nil
+	}
+	registry := trigger.Registry
+	if registry == "" {
+		registry = fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com", ecrReq.Account, ecrReq.Region)
+	}
+	namespace, name := splitRepository(detail.RepositoryName)
+	return &model.ImageInfo{
+		Resource: &model.ImageResource{
+			Digest:     detail.ImageDigest,
+			Tag:        detail.ImageTag,
+			URL:        fmt.Sprintf("%s/%s:%s", registry, detail.RepositoryName, detail.ImageTag),
+			CreateTime: parseImageTime(ecrReq.Time),
+		},
+		Repository: &model.ImageRepository{
+			Name:      name,
+			Namespace: namespace,
+			FullName:  detail.RepositoryName,
+			Region:    ecrReq.Region,
+		},
+	}, "", nil
+}
+
+// parseArtifactRegistryEvent decode the notification in the Pub/Sub message, the deleted and the untagged images are skipped
+func parseArtifactRegistryEvent(req *restful.Request) (*model.ImageInfo, string, error) {
+	var pushReq apisv1.HandleApplicationTriggerArtifactRegistryRequest
+	if err := req.ReadEntity(&pushReq); err != nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	data, err := base64.StdEncoding.DecodeString(pushReq.Message.Data)
+	if err != nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	var notification apisv1.ArtifactRegistryNotification
+	if err := json.Unmarshal(data, &notification); err != nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	if notification.Action != model.ArtifactRegistryActionInsert {
+		return nil, fmt.Sprintf("the %s action is not handled", notification.Action), nil
+	}
+	if notification.Tag == "" {
+		return nil, "the untagged image is not handled", nil
+	}
+	image := notification.Tag
+	i := strings.LastIndex(image, ":")
+	if i < strings.LastIndex(image, "/") {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	host, fullName, _ := strings.Cut(image[:i], "/")
+	var digest string
+	if j := strings.LastIndex(notification.Digest, "@"); j >= 0 {
+		digest = notification.Digest[j+1:]
+	}
+	// the path is <project>/<repository>/<image>
+	segments := strings.SplitN(fullName, "/", 3)
+	name, namespace := fullName, ""
+	if len(segments) == 3 {
+		name, namespace = segments[2], segments[0]+"/"+segments[1]
+	}
+	return &model.ImageInfo{
+		Resource: &model.ImageResource{
+			Digest:     digest,
+			Tag:        image[i+1:],
+			URL:        image,
+			CreateTime: parseImageTime(pushReq.Message.PublishTime),
+		},
+		Repository: &model.ImageRepository{
+			Name:      name,
+			Namespace: namespace,
+			FullName:  fullName,
+			Region:    strings.TrimSuffix(host, artifactRegistryHostSuffix),
+		},
+	}, "", nil
+}
+
+// parseRegistryNotification handle the latest tagged push event in the envelope of the distribution registry.
+// The content type is application/vnd.docker.distribution.events.v1+json, so the body is decoded directly.
+func parseRegistryNotification(trigger *model.ApplicationTrigger, req *restful.Request) (*model.ImageInfo, string, error) {
+	if req.Request.Body == nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	body, err := io.ReadAll(req.Request.Body)
+	if err != nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	var notificationReq apisv1.HandleApplicationTriggerRegistryNotificationRequest
+	if err := json.Unmarshal(body, &notificationReq); err != nil {
+		return nil, "", bcode.ErrInvalidWebhookPayloadBody
+	}
+	for i := len(notificationReq.Events) - 1; i >= 0; i-- {
+		event := notificationReq.Events[i]
+		// the layers are pushed before the manifest, only the manifest has the tag
+		if event.Action != model.RegistryNotificationActionPush || event.Target.Tag == "" {
+			continue
+		}
+		registry := firstNonEmpty(trigger.Registry, event.Request.Host)
+		namespace, name := splitRepository(event.Target.Repository)
+		return &model.ImageInfo{
+			Resource: &model.ImageResource{
+				Digest:     event.Target.Digest,
+				Tag:        event.Target.Tag,
+				URL:        fmt.Sprintf("%s/%s:%s", registry, event.Target.Repository, event.Target.Tag),
+				CreateTime: parseImageTime(event.Timestamp),
+			},
+			Repository: &model.ImageRepository{
+				Name:      name,
+				Namespace: namespace,
+				FullName:  event.Target.Repository,
+			},
+		}, "", nil
+	}
+	return nil, "no tagged image is pushed", nil
+}
+
+// splitRepository split the repository such as group/project/image to the namespace and the name
+func splitRepository(repository string) (string, string) {
+	i := strings.LastIndex(repository, "/")
+	if i < 0 {
+		return "", repository
+	}
+	return repository[:i], repository[i+1:]
+}
+
+func parseImageTime(t string) time.Time {
+	if parsed := parseGitTime(t); parsed != nil {
+		return *parsed
+	}
+	return time.Time{}
+}
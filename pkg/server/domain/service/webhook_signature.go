@@ -0,0 +1,278 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+const (
+	// HeaderWebhookSignature the HMAC-SHA256 of the timestamp and the body, in the format of sha256=<hex>
+	HeaderWebhookSignature = "X-VelaUX-Signature"
+	// HeaderWebhookTimestamp the unix seconds when the request is signed
+	HeaderWebhookTimestamp = "X-VelaUX-Timestamp"
+	// HeaderWebhookDelivery the unique ID of the request, the signature is used if it is empty
+	HeaderWebhookDelivery = "X-VelaUX-Delivery"
+
+	headerGitHubSignature = "X-Hub-Signature-256"
+	headerGitHubDelivery  = "X-GitHub-Delivery"
+	// Gitea sends the hex of the signature without the prefix
+	headerGiteaSignature = "X-Gitea-Signature"
+	headerGiteaDelivery  = "X-Gitea-Delivery"
+	// Bitbucket Cloud sends the sha256 signature in the legacy header
+	headerBitbucketSignature = "X-Hub-Signature"
+	headerBitbucketDelivery  = "X-Request-UUID"
+	headerGitLabToken        = "X-Gitlab-Token"
+	headerGitLabEventUUID    = "X-Gitlab-Event-UUID"
+	headerHarborAuth         = "Authorization"
+
+	// webhookSignatureWindow the timestamp of the signed request must be in the window
+	webhookSignatureWindow = 5 * time.Minute
+	// webhookDeliveryRetention the delivery IDs of the providers that don't sign the timestamp are kept longer
+	webhookDeliveryRetention = 24 * time.Hour
+	webhookSecretLen         = 32
+)
+
+// verifyWebhookRequest check the source address, the signature and the replay of the request
+func (c *webhookServiceImpl) verifyWebhookRequest(ctx context.Context, trigger *model.ApplicationTrigger, req *restful.Request, body []byte) error {
+	nonce, retention, err := c.verifyWebhookSignature(trigger, req, body)
+	if err != nil || nonce == "" {
+		return err
+	}
+	return c.checkWebhookNonce(ctx, trigger.Token, nonce, retention)
+}
+
+// verifyWebhookSignature check the source address and the signature of the request,
+// return the nonce of the request and how long it should be kept to reject the replay.
+func (c *webhookServiceImpl) verifyWebhookSignature(trigger *model.ApplicationTrigger, req *restful.Request, body []byte) (string, time.Duration, error) {
+	if len(trigger.AllowedCIDRs) > 0 && !matchAllowedCIDRs(trigger.AllowedCIDRs, webhookSourceIP(req.Request, c.TrustedProxies)) {
+		return "", 0, bcode.ErrWebhookSourceForbidden
+	}
+	if trigger.Secret == "" {
+		return "", 0, nil
+	}
+	secrets := []string{trigger.Secret}
+	if trigger.PreviousSecret != "" && trigger.PreviousSecretExpireTime != nil && time.Now().Before(*trigger.PreviousSecretExpireTime) {
+		secrets = append(secrets, trigger.PreviousSecret)
+	}
+
+	header := req.Request.Header
+	var nonce string
+	retention := webhookDeliveryRetention
+	switch getSignatureType(trigger) {
+	case model.SignatureTypeGitHub:
+		signature := firstNonEmpty(header.Get(headerGitHubSignature), header.Get(headerGiteaSignature), header.Get(headerBitbucketSignature))
+		if !matchSignature(secrets, signature, body) {
+			return "", 0, bcode.ErrInvalidWebhookSignature
+		}
+		nonce = firstNonEmpty(header.Get(headerGitHubDelivery), header.Get(headerGiteaDelivery), header.Get(headerBitbucketDelivery))
+	case model.SignatureTypeGitLab:
+		if !matchSecret(secrets, header.Get(headerGitLabToken)) {
+			return "", 0, bcode.ErrInvalidWebhookSignature
+		}
+		nonce = header.Get(headerGitLabEventUUID)
+	case model.SignatureTypeHarbor:
+		if !matchSecret(secrets, header.Get(headerHarborAuth)) {
+			return "", 0, bcode.ErrInvalidWebhookSignature
+		}
+	default:
+		timestamp := header.Get(HeaderWebhookTimestamp)
+		signature := header.Get(HeaderWebhookSignature)
+		if !matchSignature(secrets, signature, []byte(timestamp+"."+string(body))) {
+			return "", 0, bcode.ErrInvalidWebhookSignature
+		}
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return "", 0, bcode.ErrInvalidWebhookSignature
+		}
+		if diff := time.Since(time.Unix(unix, 0)); diff > webhookSignatureWindow || diff < -webhookSignatureWindow {
+			return "", 0, bcode.ErrWebhookReplayed
+		}
+		nonce = header.Get(HeaderWebhookDelivery)
+		if nonce == "" {
+			nonce = signature
+		}
+		retention = 2 * webhookSignatureWindow
+	}
+	return nonce, retention, nil
+}
+
+// checkWebhookNonce record the nonce of the request, the request is replayed if the nonce has been recorded
+func (c *webhookServiceImpl) checkWebhookNonce(ctx context.Context, token, nonce string, retention time.Duration) error {
+	hash := sha256.Sum256([]byte(token + ":" + nonce))
+	record := &model.WebhookNonce{ID: hex.EncodeToString(hash[:16]), Token: token, ExpireTime: time.Now().Add(retention)}
+	err := c.Store.Add(ctx, record)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, datastore.ErrRecordExist) {
+		return err
+	}
+	existing := &model.WebhookNonce{ID: record.ID}
+	if err := c.Store.Get(ctx, existing); err != nil {
+		return err
+	}
+	if time.Now().Before(existing.ExpireTime) {
+		return bcode.ErrWebhookReplayed
+	}
+	// the nonce is expired but not purged yet
+	return c.Store.Put(ctx, record)
+}
+
+// PurgeExpiredNonces remove the expired nonces of the webhook requests
+func (c *webhookServiceImpl) PurgeExpiredNonces(ctx context.Context) error {
+	entities, err := c.Store.List(ctx, &model.WebhookNonce{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entity := range entities {
+		nonce := entity.(*model.WebhookNonce)
+		if now.Before(nonce.ExpireTime) {
+			continue
+		}
+		if err := c.Store.Delete(ctx, nonce); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			klog.Errorf("failed to delete the webhook nonce %s: %s", nonce.ID, err.Error())
+		}
+	}
+	return nil
+}
+
+func getSignatureType(trigger *model.ApplicationTrigger) string {
+	if trigger.SignatureType != "" {
+		return trigger.SignatureType
+	}
+	switch trigger.PayloadType {
+	case model.PayloadTypeHarbor, model.PayloadTypeGitLabRegistry:
+		return model.SignatureTypeHarbor
+	case model.PayloadTypeGitHub, model.PayloadTypeGitea, model.PayloadTypeBitbucket, model.PayloadTypeGHCR:
+		return model.SignatureTypeGitHub
+	case model.PayloadTypeGitLab:
+		return model.SignatureTypeGitLab
+	}
+	return model.SignatureTypeHMAC
+}
+
+func matchSignature(secrets []string, signature string, content []byte) bool {
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(content)
+		if hmac.Equal(mac.Sum(nil), expected) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSecret(secrets []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, secret := range secrets {
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(value)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAllowedCIDRs(cidrs []string, address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range cidrs {
+		if allowed := net.ParseIP(cidr); allowed != nil {
+			if allowed.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookSourceIP return the address of the caller. The forwarded headers could be set by anyone, so they are only trusted
+// if the request comes from a trusted proxy, the caller is the first address from the right that is not a trusted proxy.
+func webhookSourceIP(req *http.Request, trustedProxies []string) string {
+	remote := strings.TrimSpace(req.RemoteAddr)
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if len(trustedProxies) == 0 || !matchAllowedCIDRs(trustedProxies, remote) {
+		return remote
+	}
+	forwarded := strings.Split(req.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if ip != "" && !matchAllowedCIDRs(trustedProxies, ip) {
+			return ip
+		}
+	}
+	if ip := strings.TrimSpace(req.Header.Get("X-Real-Ip")); ip != "" {
+		return ip
+	}
+	return remote
+}
+
+// checkAllowedCIDRs the allowed sources could be the IP addresses or the CIDRs
+func checkAllowedCIDRs(cidrs []string) error {
+	for _, cidr := range cidrs {
+		if net.ParseIP(cidr) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return bcode.ErrApplicationTriggerCIDRInvalid
+		}
+	}
+	return nil
+}
+
+func genWebhookSecret() string {
+	b := make([]byte, webhookSecretLen)
+	if _, err := crand.Read(b); err != nil {
+		// fallback to the token generator, it never happens on the supported platforms
+		klog.Errorf("failed to generate the webhook secret: %s", err.Error())
+		return genWebhookToken() + genWebhookToken()
+	}
+	return hex.EncodeToString(b)
+}
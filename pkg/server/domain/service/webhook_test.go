@@ -20,7 +20,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/emicklei/go-restful/v3"
 	"github.com/google/go-cmp/cmp"
@@ -588,6 +592,14 @@ var _ = Describe("Test application service function", func() {
 		comp, err = appService.GetApplicationComponent(context.TODO(), appModel, "component-name-webhook")
 		Expect(err).Should(BeNil())
 		Expect((*comp.Properties)["image"]).Should(Equal("docker.io/test-namespace/test-repo:test-tag"))
+		// the dockerhub payload has no digest, pushing the same tag again is not duplicated
+		httpreq, err = http.NewRequest("post", "/", bytes.NewBuffer(body))
+		Expect(err).Should(BeNil())
+		httpreq.Header.Add(restful.HEADER_ContentType, "application/json")
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), dockerhubTrigger.Token, restful.NewRequest(httpreq))
+		Expect(err).Should(BeNil())
+		_, duplicated := res.(*apisv1.ApplicationWebhookSkippedResponse)
+		Expect(duplicated).Should(BeFalse())
 
 		By("Test HandleApplicationWebhook function with jfrog payload without header of X-JFrogURL")
 		jfrogTrigger, err := appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
@@ -629,5 +641,471 @@ var _ = Describe("Test application service function", func() {
 		comp, err = appService.GetApplicationComponent(context.TODO(), appModel, "component-name-webhook")
 		Expect(err).Should(BeNil())
 		Expect((*comp.Properties)["image"]).Should(Equal("test-addr/test-repo/test-image:test-tag"))
+
+		By("Test HandleApplicationWebhook function with the secret and the allowed CIDRs")
+		_, err = appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:         "test-invalid-cidr",
+			PayloadType:  "dockerhub",
+			Type:         "webhook",
+			WorkflowName: repository.ConvertWorkflowName("webhook-dev"),
+			AllowedCIDRs: []string{"10.0.0.0/33"},
+		})
+		Expect(err).Should(Equal(bcode.ErrApplicationTriggerCIDRInvalid))
+		signedTrigger, err := appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:          "test-signed",
+			PayloadType:   "dockerhub",
+			Type:          "webhook",
+			ComponentName: "component-name-webhook",
+			WorkflowName:  repository.ConvertWorkflowName("webhook-dev"),
+			Secret:        "secret",
+			AllowedCIDRs:  []string{"10.0.0.0/8"},
+		})
+		Expect(err).Should(BeNil())
+		Expect(signedTrigger.HasSecret).Should(BeTrue())
+		body, err = json.Marshal(dockerhubBody)
+		Expect(err).Should(BeNil())
+		newSignedRequest := func(secret string, timestamp time.Time, remoteAddr string) *restful.Request {
+			httpreq, err := http.NewRequest("post", "/", bytes.NewBuffer(body))
+			Expect(err).Should(BeNil())
+			httpreq.RemoteAddr = remoteAddr
+			httpreq.Header.Add(restful.HEADER_ContentType, "application/json")
+			if secret != "" {
+				unix := strconv.FormatInt(timestamp.Unix(), 10)
+				httpreq.Header.Add(HeaderWebhookTimestamp, unix)
+				httpreq.Header.Add(HeaderWebhookSignature, "sha256="+signNotification(secret, []byte(unix+"."+string(body))))
+			}
+			return restful.NewRequest(httpreq)
+		}
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), signedTrigger.Token, newSignedRequest("secret", time.Now(), "192.168.1.1:8000"))
+		Expect(err).Should(Equal(bcode.ErrWebhookSourceForbidden))
+		// the rejected deliveries could not be replayed
+		rejected, err := webhookService.ListWebhookDeliveries(context.TODO(), appModel, signedTrigger.Token, model.WebhookDeliveryFailed, 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(len(rejected.Deliveries)).Should(Equal(1))
+		_, err = webhookService.ReplayWebhookDelivery(context.TODO(), appModel, signedTrigger.Token, rejected.Deliveries[0].ID)
+		Expect(err).Should(Equal(bcode.ErrWebhookDeliveryNotVerified))
+		// the forwarded header is not trusted if the request does not come from the trusted proxies
+		forwardedReq := newSignedRequest("secret", time.Now(), "192.168.1.1:8000")
+		forwardedReq.Request.Header.Set("X-Forwarded-For", "10.1.1.1")
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), signedTrigger.Token, forwardedReq)
+		Expect(err).Should(Equal(bcode.ErrWebhookSourceForbidden))
+		Expect(webhookSourceIP(forwardedReq.Request, []string{"192.168.0.0/16"})).Should(Equal("10.1.1.1"))
+		forwardedReq.Request.Header.Set("X-Forwarded-For", "10.1.1.1, 172.16.0.1")
+		Expect(webhookSourceIP(forwardedReq.Request, []string{"192.168.0.0/16"})).Should(Equal("172.16.0.1"))
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), signedTrigger.Token, newSignedRequest("", time.Now(), "10.1.1.1:8000"))
+		Expect(err).Should(Equal(bcode.ErrInvalidWebhookSignature))
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), signedTrigger.Token, newSignedRequest("invalid", time.Now(), "10.1.1.1:8000"))
+		Expect(err).Should(Equal(bcode.ErrInvalidWebhookSignature))
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), signedTrigger.Token, newSignedRequest("secret", time.Now().Add(-10*time.Minute), "10.1.1.1:8000"))
+		Expect(err).Should(Equal(bcode.ErrWebhookReplayed))
+		signedReq := newSignedRequest("secret", time.Now(), "10.1.1.1:8000")
+		replayedReq := newSignedRequest("secret", time.Now(), "10.1.1.1:8000")
+		replayedReq.Request.Header = signedReq.Request.Header.Clone()
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), signedTrigger.Token, signedReq)
+		Expect(err).Should(BeNil())
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), signedTrigger.Token, replayedReq)
+		Expect(err).Should(Equal(bcode.ErrWebhookReplayed))
+
+		By("Test rotating the token and the secret of the trigger")
+		_, err = appService.RotateApplicationTrigger(context.TODO(), &model.Application{Name: "another-app"}, signedTrigger.Token, apisv1.RotateApplicationTriggerRequest{Secret: true})
+		Expect(err).Should(Equal(bcode.ErrApplicationTriggerNotExist))
+		rotated, err := appService.RotateApplicationTrigger(context.TODO(), appModel, signedTrigger.Token, apisv1.RotateApplicationTriggerRequest{
+			Token: true, Secret: true, SecretGracePeriod: 60})
+		Expect(err).Should(BeNil())
+		Expect(rotated.Token).ShouldNot(Equal(signedTrigger.Token))
+		Expect(rotated.Secret).ShouldNot(BeEmpty())
+		Expect(rotated.Name).Should(Equal("test-signed"))
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), signedTrigger.Token, newSignedRequest("secret", time.Now(), "10.1.1.1:8000"))
+		Expect(err).Should(Equal(bcode.ErrInvalidWebhookToken))
+		// the previous secret is accepted in the grace period
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), rotated.Token, newSignedRequest("secret", time.Now().Add(time.Second), "10.1.1.1:8000"))
+		Expect(err).Should(BeNil())
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), rotated.Token, newSignedRequest(rotated.Secret, time.Now().Add(2*time.Second), "10.1.1.1:8000"))
+		Expect(err).Should(BeNil())
+
+		By("Test HandleApplicationWebhook function with the GitLab token")
+		_, err = appService.UpdateApplicationTrigger(context.TODO(), appModel, rotated.Token, apisv1.UpdateApplicationTriggerRequest{
+			PayloadType:   "dockerhub",
+			ComponentName: "component-name-webhook",
+			WorkflowName:  repository.ConvertWorkflowName("webhook-dev"),
+			SignatureType: model.SignatureTypeGitLab,
+		})
+		Expect(err).Should(BeNil())
+		httpreq, err = http.NewRequest("post", "/", bytes.NewBuffer(body))
+		Expect(err).Should(BeNil())
+		httpreq.Header.Add(restful.HEADER_ContentType, "application/json")
+		httpreq.Header.Add("X-Gitlab-Token", rotated.Secret)
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), rotated.Token, restful.NewRequest(httpreq))
+		Expect(err).Should(BeNil())
+
+		By("Test HandleApplicationWebhook function with the git payloads")
+		_, err = appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:               "test-invalid-template",
+			PayloadType:        "github",
+			Type:               "webhook",
+			WorkflowName:       repository.ConvertWorkflowName("webhook-dev"),
+			PropertiesTemplate: "image: {{.Tag",
+		})
+		Expect(err).Should(Equal(bcode.ErrApplicationTriggerTemplateInvalid))
+		githubTrigger, err := appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:               "test-github",
+			PayloadType:        "github",
+			Type:               "webhook",
+			ComponentName:      "component-name-webhook",
+			WorkflowName:       repository.ConvertWorkflowName("webhook-dev"),
+			BranchFilters:      []string{"main", "release/*"},
+			PropertiesTemplate: "image: nginx:{{.Branch}}-{{.ShortCommit}}",
+		})
+		Expect(err).Should(BeNil())
+		newGitRequest := func(payload string, headers map[string]string) *restful.Request {
+			httpreq, err := http.NewRequest("post", "/", bytes.NewBufferString(payload))
+			Expect(err).Should(BeNil())
+			httpreq.Header.Add(restful.HEADER_ContentType, "application/json")
+			for k, v := range headers {
+				httpreq.Header.Add(k, v)
+			}
+			return restful.NewRequest(httpreq)
+		}
+		githubPush := `{"ref":"refs/heads/%s","before":"9049f1265b7d61be4a8904a9a27120d2064dab3b","after":"0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c","deleted":false,
+"head_commit":{"id":"0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c","message":"Update README.md","timestamp":"2023-05-15T15:04:05-07:00","author":{"name":"Codertocat","username":"Codertocat"}},
+"pusher":{"name":"Codertocat","email":"21031067+Codertocat@users.noreply.github.com"},"sender":{"login":"Codertocat"},"repository":{"full_name":"Codertocat/Hello-World"}}`
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), githubTrigger.Token, newGitRequest(fmt.Sprintf(githubPush, "dev"), map[string]string{"X-GitHub-Event": "push"}))
+		Expect(err).Should(BeNil())
+		Expect(res.(*apisv1.ApplicationWebhookSkippedResponse).State).Should(Equal("skipped"))
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), githubTrigger.Token, newGitRequest(`{"zen":"Keep it logically awesome."}`, map[string]string{"X-GitHub-Event": "ping"}))
+		Expect(err).Should(BeNil())
+		Expect(res.(*apisv1.ApplicationWebhookSkippedResponse).State).Should(Equal("skipped"))
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), githubTrigger.Token, newGitRequest(fmt.Sprintf(githubPush, "release/1.0"), map[string]string{"X-GitHub-Event": "push"}))
+		Expect(err).Should(BeNil())
+		deployed := res.(*apisv1.ApplicationDeployResponse)
+		Expect(deployed.CodeInfo.Branch).Should(Equal("release/1.0"))
+		Expect(deployed.CodeInfo.Commit).Should(Equal("0d1a26e67d8f5eaf1f6ba5c57fc3c7d91ac0fd1c"))
+		Expect(deployed.CodeInfo.User).Should(Equal("Codertocat"))
+		Expect(deployed.CodeInfo.CommitTime).ShouldNot(BeNil())
+		comp, err = appService.GetApplicationComponent(context.TODO(), appModel, "component-name-webhook")
+		Expect(err).Should(BeNil())
+		Expect((*comp.Properties)["image"]).Should(Equal("nginx:release/1.0-0d1a26e"))
+		patch, err := renderGitProperties("image: nginx:{{.Branch}}\nuser: {{.User}}", &gitEvent{Branch: "main\nreplicas: 3", User: "admin: true"})
+		Expect(err).Should(BeNil())
+		Expect(string(patch.Raw)).Should(Equal(`{"image":"nginx:main\nreplicas: 3","user":"admin: true"}`))
+
+		gitlabTrigger, err := appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:               "test-gitlab",
+			PayloadType:        "gitlab",
+			Type:               "webhook",
+			ComponentName:      "component-name-webhook",
+			WorkflowName:       repository.ConvertWorkflowName("webhook-dev"),
+			GitEvents:          []string{model.GitEventTag},
+			TagFilters:         []string{"v*"},
+			PropertiesTemplate: "image: registry.gitlab.com/example/app:{{.Tag}}",
+		})
+		Expect(err).Should(BeNil())
+		gitlabTagPush := `{"object_kind":"tag_push","before":"0000000000000000000000000000000000000000","after":"82b3d5ae55f7080f1e6022629cdb57bfae7cccc7",
+"ref":"refs/tags/v1.0.0","checkout_sha":"82b3d5ae55f7080f1e6022629cdb57bfae7cccc7","user_name":"John Smith","user_username":"jsmith",
+"project":{"path_with_namespace":"jsmith/example"},"commits":[{"id":"82b3d5ae55f7080f1e6022629cdb57bfae7cccc7","message":"Release","timestamp":"2023-05-15T14:27:31+02:00"}]}`
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), gitlabTrigger.Token, newGitRequest(gitlabTagPush, nil))
+		Expect(err).Should(BeNil())
+		deployed = res.(*apisv1.ApplicationDeployResponse)
+		Expect(deployed.CodeInfo.Tag).Should(Equal("v1.0.0"))
+		Expect(deployed.CodeInfo.User).Should(Equal("jsmith"))
+		comp, err = appService.GetApplicationComponent(context.TODO(), appModel, "component-name-webhook")
+		Expect(err).Should(BeNil())
+		Expect((*comp.Properties)["image"]).Should(Equal("registry.gitlab.com/example/app:v1.0.0"))
+		// the push events are not handled by the trigger
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), gitlabTrigger.Token, newGitRequest(`{"object_kind":"push","after":"da1560886d4f094c3e6c9ef40349f7d38b5d27d7","ref":"refs/heads/main","user_username":"jsmith"}`, nil))
+		Expect(err).Should(BeNil())
+		Expect(res.(*apisv1.ApplicationWebhookSkippedResponse).State).Should(Equal("skipped"))
+
+		bitbucketTrigger, err := appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:         "test-bitbucket",
+			PayloadType:  "bitbucket",
+			Type:         "webhook",
+			WorkflowName: repository.ConvertWorkflowName("webhook-dev"),
+			GitEvents:    []string{model.GitEventMergeRequest},
+		})
+		Expect(err).Should(BeNil())
+		bitbucketPullRequest := `{"actor":{"display_name":"Emma","nickname":"emma"},"repository":{"full_name":"team/app"},
+"pullrequest":{"id":12,"state":"OPEN","source":{"branch":{"name":"feature/login"},"commit":{"hash":"d3adb33fc0de"}},"destination":{"branch":{"name":"main"},"commit":{"hash":"ce5965ddd289"}},"author":{"display_name":"Emma","nickname":"emma"}}}`
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), bitbucketTrigger.Token, newGitRequest(bitbucketPullRequest, map[string]string{"X-Event-Key": "pullrequest:created"}))
+		Expect(err).Should(BeNil())
+		deployed = res.(*apisv1.ApplicationDeployResponse)
+		Expect(deployed.CodeInfo.Branch).Should(Equal("feature/login"))
+		Expect(deployed.CodeInfo.Commit).Should(Equal("d3adb33fc0de"))
+
+		By("Test HandleApplicationWebhook function with the image property mapping and the tag filter")
+		_, err = appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:           "test-invalid-semver",
+			PayloadType:    "harbor",
+			Type:           "webhook",
+			WorkflowName:   repository.ConvertWorkflowName("webhook-dev"),
+			ImageTagFilter: &model.ImageTagFilter{Semver: "~> invalid"},
+		})
+		Expect(err).Should(Equal(bcode.ErrApplicationTriggerFilterInvalid))
+		_, err = appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:            "test-invalid-path",
+			PayloadType:     "harbor",
+			Type:            "webhook",
+			WorkflowName:    repository.ConvertWorkflowName("webhook-dev"),
+			ImageProperties: []model.ImagePropertyMapping{{Path: "containers[a].image", Template: "{{.Image}}"}},
+		})
+		Expect(err).Should(Equal(bcode.ErrApplicationTriggerPropertyPathInvalid))
+		mappedTrigger, err := appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:          "test-harbor-mapped",
+			PayloadType:   "harbor",
+			Type:          "webhook",
+			ComponentName: "component-name-webhook",
+			WorkflowName:  repository.ConvertWorkflowName("webhook-dev"),
+			ImageProperties: []model.ImagePropertyMapping{
+				{Path: "values.image.repository", Template: "{{.Repository}}"},
+				{Path: "values.image.tag", Template: "{{.Tag}}"},
+				{Path: "values.image.digest", Template: "{{.Digest}}"},
+			},
+			ImageTagFilter: &model.ImageTagFilter{Semver: ">= 1.0.0", Ignore: []string{"*-rc*"}},
+		})
+		Expect(err).Should(BeNil())
+		newHarborRequest := func(tag string) *restful.Request {
+			harborBody.EventData.Resources[0].Tag = tag
+			harborBody.EventData.Resources[0].ResourceURL = "harbor.server/test-pro/test-repo:" + tag
+			body, err := json.Marshal(harborBody)
+			Expect(err).Should(BeNil())
+			httpreq, err := http.NewRequest("post", "/", bytes.NewBuffer(body))
+			Expect(err).Should(BeNil())
+			httpreq.Header.Add(restful.HEADER_ContentType, "application/json")
+			return restful.NewRequest(httpreq)
+		}
+		for _, tag := range []string{"latest", "0.9.0", "1.1.0-rc1"} {
+			res, err = webhookService.HandleApplicationWebhook(context.TODO(), mappedTrigger.Token, newHarborRequest(tag))
+			Expect(err).Should(BeNil())
+			Expect(res.(*apisv1.ApplicationWebhookSkippedResponse).State).Should(Equal("skipped"))
+		}
+		// the pipeline triggers parse the registry payloads and filter the tags in the same way
+		pipelineWebhook := pipelineTrigger2Webhook(&model.PipelineTrigger{
+			Type: model.PipelineTriggerTypeWebhook, PayloadType: model.PayloadTypeHarbor, ImageTagFilter: &model.ImageTagFilter{Semver: ">=1.0.0"}})
+		_, skipped, err := webhookService.parseWebhookEvent(pipelineWebhook, newHarborRequest("latest"))
+		Expect(err).Should(BeNil())
+		Expect(skipped).Should(ContainSubstring("filtered"))
+		imageEvent, _, err := webhookService.parseWebhookEvent(pipelineWebhook, newHarborRequest("v1.2.0"))
+		Expect(err).Should(BeNil())
+		Expect(imageEvent["repository"]).Should(Equal("harbor.server/test-pro/test-repo"))
+		Expect(imageEvent["tag"]).Should(Equal("v1.2.0"))
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), mappedTrigger.Token, newHarborRequest("v1.2.0"))
+		Expect(err).Should(BeNil())
+		comp, err = appService.GetApplicationComponent(context.TODO(), appModel, "component-name-webhook")
+		Expect(err).Should(BeNil())
+		Expect((*comp.Properties)["values"]).Should(Equal(map[string]interface{}{"image": map[string]interface{}{
+			"repository": "harbor.server/test-pro/test-repo",
+			"tag":        "v1.2.0",
+			"digest":     "test-digest",
+		}}))
+
+		By("Test HandleApplicationWebhook function with the mapped payload")
+		_, err = appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:         "test-mapped-invalid",
+			PayloadType:  "mapped",
+			Type:         "webhook",
+			WorkflowName: repository.ConvertWorkflowName("webhook-dev"),
+		})
+		Expect(err).Should(Equal(bcode.ErrApplicationTriggerMappingInvalid))
+		mappedPayloadTrigger, err := appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:          "test-mapped",
+			PayloadType:   "mapped",
+			Type:          "webhook",
+			ComponentName: "component-name-webhook",
+			WorkflowName:  repository.ConvertWorkflowName("webhook-dev"),
+			PayloadMapping: &model.PayloadMapping{
+				Conditions: []model.PayloadCondition{
+					{Path: "$.event", Operator: model.PayloadConditionEquals, Values: []string{"build_succeeded"}},
+					{Path: "$.build.branch", Operator: model.PayloadConditionMatches, Values: []string{"^(main|release-.*)$"}},
+				},
+				Properties: map[string]string{
+					"image":    "$.artifacts[0].image",
+					"replicas": "$.build.replicas",
+				},
+				CodeInfo:  &model.CodeInfoMapping{Commit: "$.build.commit", Branch: "$.build.branch", User: "$.build.author", CommitTime: "$.build.finished_at"},
+				ImageInfo: &model.ImageInfoMapping{URL: "$.artifacts[0].image", Tag: "$.artifacts[0].tag", Repository: "app", Namespace: "team"},
+			},
+		})
+		Expect(err).Should(BeNil())
+		payload := `{"event":"build_succeeded","build":{"branch":"%s","commit":"a1b2c3d","author":"ci-bot","finished_at":1684152000,"replicas":3},
+"artifacts":[{"image":"registry.example.com/team/app:a1b2c3d","tag":"a1b2c3d"}]}`
+		var sample map[string]interface{}
+		Expect(json.Unmarshal([]byte(fmt.Sprintf(payload, "feature-x")), &sample)).Should(BeNil())
+		result, err := webhookService.TestApplicationTrigger(context.TODO(), appModel, mappedPayloadTrigger.Token, sample)
+		Expect(err).Should(BeNil())
+		Expect(result.Matched).Should(BeFalse())
+		Expect(json.Unmarshal([]byte(fmt.Sprintf(payload, "main")), &sample)).Should(BeNil())
+		result, err = webhookService.TestApplicationTrigger(context.TODO(), appModel, mappedPayloadTrigger.Token, sample)
+		Expect(err).Should(BeNil())
+		Expect(result.Matched).Should(BeTrue())
+		Expect((*result.Upgrade["component-name-webhook"])["image"]).Should(Equal("registry.example.com/team/app:a1b2c3d"))
+		Expect((*result.Upgrade["component-name-webhook"])["replicas"]).Should(BeEquivalentTo(3))
+		Expect(result.CodeInfo.Commit).Should(Equal("a1b2c3d"))
+		Expect(result.CodeInfo.CommitTime.Unix()).Should(BeEquivalentTo(1684152000))
+		Expect(result.ImageInfo.Repository.FullName).Should(Equal("team/app"))
+		// the test does not patch the component
+		comp, err = appService.GetApplicationComponent(context.TODO(), appModel, "component-name-webhook")
+		Expect(err).Should(BeNil())
+		Expect((*comp.Properties)["image"]).ShouldNot(Equal("registry.example.com/team/app:a1b2c3d"))
+
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), mappedPayloadTrigger.Token, newGitRequest(fmt.Sprintf(payload, "main"), nil))
+		Expect(err).Should(BeNil())
+		deployed = res.(*apisv1.ApplicationDeployResponse)
+		Expect(deployed.CodeInfo.Branch).Should(Equal("main"))
+		Expect(deployed.ImageInfo.Resource.Tag).Should(Equal("a1b2c3d"))
+		comp, err = appService.GetApplicationComponent(context.TODO(), appModel, "component-name-webhook")
+		Expect(err).Should(BeNil())
+		Expect((*comp.Properties)["image"]).Should(Equal("registry.example.com/team/app:a1b2c3d"))
+
+		By("Test the webhook deliveries and the idempotency")
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), mappedTrigger.Token, newHarborRequest("v1.2.0"))
+		Expect(err).Should(BeNil())
+		Expect(res.(*apisv1.ApplicationWebhookSkippedResponse).State).Should(Equal(model.WebhookDeliveryDuplicated))
+		deliveries, err := webhookService.ListWebhookDeliveries(context.TODO(), appModel, mappedTrigger.Token, "", 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(deliveries.Total).Should(BeEquivalentTo(5))
+		deliveries, err = webhookService.ListWebhookDeliveries(context.TODO(), appModel, mappedTrigger.Token, model.WebhookDeliverySucceeded, 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(len(deliveries.Deliveries)).Should(Equal(1))
+		Expect(deliveries.Deliveries[0].Revision).ShouldNot(BeEmpty())
+		Expect(deliveries.Deliveries[0].PayloadType).Should(Equal("harbor"))
+		detail, err := webhookService.GetWebhookDelivery(context.TODO(), appModel, mappedTrigger.Token, deliveries.Deliveries[0].ID)
+		Expect(err).Should(BeNil())
+		Expect(detail.Body).Should(ContainSubstring("v1.2.0"))
+		Expect(detail.Headers[restful.HEADER_ContentType]).Should(Equal("application/json"))
+		_, err = webhookService.GetWebhookDelivery(context.TODO(), appModel, githubTrigger.Token, deliveries.Deliveries[0].ID)
+		Expect(err).Should(Equal(bcode.ErrWebhookDeliveryNotExist))
+		replayed, err := webhookService.ReplayWebhookDelivery(context.TODO(), appModel, mappedTrigger.Token, deliveries.Deliveries[0].ID)
+		Expect(err).Should(BeNil())
+		Expect(replayed.Status).Should(Equal(model.WebhookDeliverySucceeded))
+		Expect(replayed.ReplayOf).Should(Equal(deliveries.Deliveries[0].ID))
+		Expect(replayed.Revision).ShouldNot(Equal(deliveries.Deliveries[0].Revision))
+
+		headers := map[string]string{"X-GitHub-Event": "push", "X-GitHub-Delivery": "72d3162e-cc78-11e3-81ab-4c9367dc0958", "Authorization": "Bearer token"}
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), githubTrigger.Token, newGitRequest(fmt.Sprintf(githubPush, "main"), headers))
+		Expect(err).Should(BeNil())
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), githubTrigger.Token, newGitRequest(fmt.Sprintf(githubPush, "main"), headers))
+		Expect(err).Should(BeNil())
+		Expect(res.(*apisv1.ApplicationWebhookSkippedResponse).State).Should(Equal(model.WebhookDeliveryDuplicated))
+		deliveries, err = webhookService.ListWebhookDeliveries(context.TODO(), appModel, githubTrigger.Token, model.WebhookDeliveryDuplicated, 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(len(deliveries.Deliveries)).Should(Equal(1))
+		detail, err = webhookService.GetWebhookDelivery(context.TODO(), appModel, githubTrigger.Token, deliveries.Deliveries[0].ID)
+		Expect(err).Should(BeNil())
+		Expect(detail.Headers["Authorization"]).Should(Equal("******"))
+		Expect(detail.Headers["X-Github-Delivery"]).Should(Equal("72d3162e-cc78-11e3-81ab-4c9367dc0958"))
+		// the repeated delivery of the signed trigger is duplicated instead of replayed
+		signedGithubTrigger, err := appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+			Name:               "test-github-signed",
+			PayloadType:        "github",
+			Type:               "webhook",
+			ComponentName:      "component-name-webhook",
+			WorkflowName:       repository.ConvertWorkflowName("webhook-dev"),
+			PropertiesTemplate: "image: nginx:{{.Branch}}-{{.ShortCommit}}",
+			Secret:             "secret",
+			SignatureType:      model.SignatureTypeGitHub,
+		})
+		Expect(err).Should(BeNil())
+		signedPush := fmt.Sprintf(githubPush, "main")
+		headers = map[string]string{"X-GitHub-Event": "push", "X-GitHub-Delivery": "8e2c6a3a-cc78-11e3-81ab-4c9367dc0958",
+			"X-Hub-Signature-256": "sha256=" + signNotification("secret", []byte(signedPush))}
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), signedGithubTrigger.Token, newGitRequest(signedPush, headers))
+		Expect(err).Should(BeNil())
+		Expect(res).Should(BeAssignableToTypeOf(&apisv1.ApplicationDeployResponse{}))
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), signedGithubTrigger.Token, newGitRequest(signedPush, headers))
+		Expect(err).Should(BeNil())
+		Expect(res.(*apisv1.ApplicationWebhookSkippedResponse).State).Should(Equal(model.WebhookDeliveryDuplicated))
+
+		By("Test recording the failed delivery")
+		httpreq, err = http.NewRequest("post", "/", bytes.NewBufferString("invalid"))
+		Expect(err).Should(BeNil())
+		httpreq.Header.Add(restful.HEADER_ContentType, "application/json")
+		_, err = webhookService.HandleApplicationWebhook(context.TODO(), mappedTrigger.Token, restful.NewRequest(httpreq))
+		Expect(err).Should(Equal(bcode.ErrInvalidWebhookPayloadBody))
+		deliveries, err = webhookService.ListWebhookDeliveries(context.TODO(), appModel, mappedTrigger.Token, model.WebhookDeliveryFailed, 0, 10)
+		Expect(err).Should(BeNil())
+		Expect(len(deliveries.Deliveries)).Should(Equal(1))
+		Expect(deliveries.Deliveries[0].Error).Should(Equal(bcode.ErrInvalidWebhookPayloadBody.Message))
+
+		By("Test HandleApplicationWebhook function with the recorded registry payloads")
+		newSampleRequest := func(file, contentType string, headers map[string]string) *restful.Request {
+			sample, err := os.ReadFile("./testdata/webhook/" + file)
+			Expect(err).Should(BeNil())
+			httpreq, err := http.NewRequest("post", "/", bytes.NewBuffer(sample))
+			Expect(err).Should(BeNil())
+			httpreq.Header.Add(restful.HEADER_ContentType, contentType)
+			for k, v := range headers {
+				httpreq.Header.Add(k, v)
+			}
+			return restful.NewRequest(httpreq)
+		}
+		newRegistryTrigger := func(payloadType string, filter *model.ImageTagFilter) *apisv1.ApplicationTriggerBase {
+			trigger, err := appService.CreateApplicationTrigger(context.TODO(), appModel, apisv1.CreateApplicationTriggerRequest{
+				Name:           "test-" + payloadType,
+				PayloadType:    payloadType,
+				Type:           "webhook",
+				ComponentName:  "component-name-webhook",
+				WorkflowName:   repository.ConvertWorkflowName("webhook-dev"),
+				ImageTagFilter: filter,
+			})
+			Expect(err).Should(BeNil())
+			return trigger
+		}
+		expectComponentImage := func(image string) {
+			comp, err := appService.GetApplicationComponent(context.TODO(), appModel, "component-name-webhook")
+			Expect(err).Should(BeNil())
+			Expect((*comp.Properties)["image"]).Should(Equal(image))
+		}
+
+		ghcrTrigger := newRegistryTrigger(model.PayloadTypeGHCR, nil)
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), ghcrTrigger.Token, newSampleRequest("ghcr-package.json", "application/json", map[string]string{"X-GitHub-Event": "ping"}))
+		Expect(err).Should(BeNil())
+		Expect(res.(*apisv1.ApplicationWebhookSkippedResponse).State).Should(Equal("skipped"))
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), ghcrTrigger.Token, newSampleRequest("ghcr-package.json", "application/json", map[string]string{"X-GitHub-Event": "package"}))
+		Expect(err).Should(BeNil())
+		deployed = res.(*apisv1.ApplicationDeployResponse)
+		Expect(deployed.ImageInfo.Type).Should(Equal(model.PayloadTypeGHCR))
+		Expect(deployed.ImageInfo.Resource.Tag).Should(Equal("v1.2.0"))
+		Expect(deployed.ImageInfo.Resource.Digest).Should(Equal("sha256:2a8a4f1e43e47b5b0b1a9c1ad5c8c0d6a8c1f8e7b6d5c4b3a2918f7e6d5c4b3a"))
+		Expect(deployed.ImageInfo.Repository.FullName).Should(Equal("octo-org/hello-world"))
+		Expect(deployed.ImageInfo.Repository.Type).Should(Equal("private"))
+		expectComponentImage("ghcr.io/octo-org/hello-world:v1.2.0")
+
+		quayTrigger := newRegistryTrigger(model.PayloadTypeQuay, &model.ImageTagFilter{Semver: ">= 2.0.0"})
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), quayTrigger.Token, newSampleRequest("quay-push.json", "application/json", nil))
+		Expect(err).Should(BeNil())
+		deployed = res.(*apisv1.ApplicationDeployResponse)
+		Expect(deployed.ImageInfo.Resource.Tag).Should(Equal("v2.0.1"))
+		Expect(deployed.ImageInfo.Repository.Namespace).Should(Equal("mynamespace"))
+		expectComponentImage("quay.io/mynamespace/repository:v2.0.1")
+
+		ecrTrigger := newRegistryTrigger(model.PayloadTypeECR, nil)
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), ecrTrigger.Token, newSampleRequest("ecr-image-action.json", "application/json", nil))
+		Expect(err).Should(BeNil())
+		deployed = res.(*apisv1.ApplicationDeployResponse)
+		Expect(deployed.ImageInfo.Resource.Digest).Should(Equal("sha256:7f5b2640fe6fb4f46592dfd3410c4a79dac4f89e4782432e0378abcd1234"))
+		Expect(deployed.ImageInfo.Repository.Region).Should(Equal("us-west-2"))
+		Expect(deployed.ImageInfo.Repository.Name).Should(Equal("my-repository-name"))
+		Expect(deployed.ImageInfo.Repository.Namespace).Should(Equal("team"))
+		expectComponentImage("123456789012.dkr.ecr.us-west-2.amazonaws.com/team/my-repository-name:v1.0.3")
+
+		garTrigger := newRegistryTrigger(model.PayloadTypeArtifactRegistry, nil)
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), garTrigger.Token, newSampleRequest("artifact-registry-push.json", "application/json", nil))
+		Expect(err).Should(BeNil())
+		deployed = res.(*apisv1.ApplicationDeployResponse)
+		Expect(deployed.ImageInfo.Resource.Tag).Should(Equal("1.1"))
+		Expect(deployed.ImageInfo.Resource.Digest).Should(Equal("sha256:6ec128e26cd5c2f7b5b7d5b8f1e2a3c4d5e6f708192a3b4c5d6e7f8091a2b3c4"))
+		Expect(deployed.ImageInfo.Repository.Region).Should(Equal("us-east1"))
+		Expect(deployed.ImageInfo.Repository.Namespace).Should(Equal("my-project/my-repo"))
+		Expect(deployed.ImageInfo.Repository.Name).Should(Equal("hello-world"))
+		expectComponentImage("us-east1-docker.pkg.dev/my-project/my-repo/hello-world:1.1")
+
+		gitlabRegistryTrigger := newRegistryTrigger(model.PayloadTypeGitLabRegistry, nil)
+		res, err = webhookService.HandleApplicationWebhook(context.TODO(), gitlabRegistryTrigger.Token, newSampleRequest("gitlab-registry-events.json", "application/vnd.docker.distribution.events.v1+json", nil))
+		Expect(err).Should(BeNil())
+		deployed = res.(*apisv1.ApplicationDeployResponse)
+		Expect(deployed.ImageInfo.Resource.Tag).Should(Equal("3.4.0"))
+		Expect(deployed.ImageInfo.Resource.Digest).Should(Equal("sha256:0b3c2a1d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f809"))
+		Expect(deployed.ImageInfo.Repository.Namespace).Should(Equal("group/project"))
+		expectComponentImage("registry.gitlab.example.com/group/project/api:3.4.0")
 	})
 })
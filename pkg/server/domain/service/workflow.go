@@ -103,6 +103,7 @@ type workflowServiceImpl struct {
 	Apply             apply.Applicator    `inject:"apply"`
 	EnvService        EnvService          `inject:""`
 	EnvBindingService EnvBindingService   `inject:""`
+	EventBus          EventBus            `inject:""`
 }
 
 // DeleteWorkflow delete application workflow
@@ -469,6 +470,33 @@ func (w *workflowServiceImpl) SyncWorkflowRecord(ctx context.Context, appPrimary
 	return nil
 }
 
+// publishStatusChanged publish the events of the changes of the workflow record and the revision status
+func (w *workflowServiceImpl) publishStatusChanged(ctx context.Context, record *model.WorkflowRecord, previousStatus string, revision *model.ApplicationRevision, previousRevisionStatus string) {
+	// the records are synced frequently, load the application only if there is an event to publish
+	if record.Status == previousStatus && revision.Status == previousRevisionStatus {
+		return
+	}
+	app := &model.Application{Name: record.AppPrimaryKey}
+	if err := w.Store.Get(ctx, app); err != nil {
+		klog.Warningf("failed to load the application %s for the event: %s", record.AppPrimaryKey, err.Error())
+		return
+	}
+	data := model.StatusChangedData{
+		AppEventData: newAppEventData(app, revision.EnvName, revision.Version),
+		WorkflowName: record.WorkflowName,
+		RecordName:   record.Name,
+	}
+	data.Message = record.Message
+	if record.Status != previousStatus {
+		data.From, data.To = previousStatus, record.Status
+		publishEvent(ctx, w.EventBus, newDomainEvent(model.EventWorkflowRecordStatusChanged, app.Project, "project/application", data))
+	}
+	if revision.Status != previousRevisionStatus {
+		data.From, data.To = previousRevisionStatus, revision.Status
+		publishEvent(ctx, w.EventBus, newDomainEvent(model.EventRevisionStatusChanged, app.Project, "project/application", data))
+	}
+}
+
 func (w *workflowServiceImpl) syncRecordFromApplicationStatus(ctx context.Context, app *v1beta1.Application, record *model.WorkflowRecord, revision *model.ApplicationRevision, workflowContext map[string]string) error {
 	if app == nil || app.Annotations == nil || app.Status.Workflow == nil {
 		return nil
@@ -479,6 +507,7 @@ func (w *workflowServiceImpl) syncRecordFromApplicationStatus(ctx context.Contex
 	}
 
 	status := app.Status.Workflow
+	previousStatus := record.Status
 	record.Status = string(status.Phase)
 	record.Message = status.Message
 	record.Mode = status.Mode
@@ -535,6 +564,7 @@ func (w *workflowServiceImpl) syncRecordFromApplicationStatus(ctx context.Contex
 		return err
 	}
 
+	previousRevisionStatus := revision.Status
 	revision.Status = generateRevisionStatus(status.Phase)
 	if app.Status.LatestRevision != nil && revision.RevisionCRName == "" {
 		revision.RevisionCRName = app.Status.LatestRevision.Name
@@ -544,6 +574,8 @@ func (w *workflowServiceImpl) syncRecordFromApplicationStatus(ctx context.Contex
 		return err
 	}
 
+	w.publishStatusChanged(ctx, record, previousStatus, revision, previousRevisionStatus)
+
 	if record.Finished == "true" {
 		klog.InfoS("successfully sync workflow status", "oam app name", app.Name, "workflow name", record.WorkflowName, "record name", record.Name, "status", record.Status, "sync source", app.Name)
 	}
@@ -755,6 +787,10 @@ func (w *workflowServiceImpl) TerminateWorkflow(ctx context.Context, appModel *m
 }
 
 func (w *workflowServiceImpl) RollbackRecord(ctx context.Context, appModel *model.Application, workflow *model.Workflow, recordName, revisionVersion string) (*apisv1.WorkflowRecordBase, error) {
+	userName, _ := ctx.Value(&apisv1.CtxKeyUser).(string)
+	if err := checkProtectedEnvRollback(ctx, w.Store, workflow.EnvName, appModel.Project, userName); err != nil {
+		return nil, err
+	}
 	if revisionVersion == "" {
 		// find the latest complete revision version
 		var revision = model.ApplicationRevision{
@@ -31,6 +31,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/multicluster"
 
 	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/service"
 	"github.com/kubevela/velaux/pkg/server/infrastructure/datastore"
 
 	"github.com/robfig/cron/v3"
@@ -45,6 +46,9 @@ var TopKFrequent = 5
 // CrontabSpec the cron spec of job running
 var CrontabSpec = "0 0 * * *"
 
+// DeliveryMetricCrontabSpec the cron spec of rolling up the delivery metrics, the metrics of today are served from the latest rollup
+var DeliveryMetricCrontabSpec = "*/10 * * * *"
+
 // maximum tires is 5, initial duration is 1 minute
 var waitBackOff = wait.Backoff{
 	Steps:    5,
@@ -55,22 +59,27 @@ var waitBackOff = wait.Backoff{
 
 // InfoCalculateCronJob is the cronJob to calculate the system info store in db
 type InfoCalculateCronJob struct {
-	KubeClient client.Client       `inject:"kubeClient"`
-	Store      datastore.DataStore `inject:"datastore"`
-	cron       *cron.Cron
+	KubeClient            client.Client                 `inject:"kubeClient"`
+	Store                 datastore.DataStore           `inject:"datastore"`
+	DeliveryMetricService service.DeliveryMetricService `inject:""`
+	cron                  *cron.Cron
 }
 
 // Start start the worker
 func (i *InfoCalculateCronJob) Start(ctx context.Context, _ chan error) {
-	i.start(CrontabSpec)
+	i.start(CrontabSpec, DeliveryMetricCrontabSpec)
 	defer i.cron.Stop()
+	// backfill the delivery metric rollups that are missed when the server is down
+	go i.rollupDeliveryMetrics(ctx)
 	<-ctx.Done()
 }
 
-func (i *InfoCalculateCronJob) start(cronSpec string) {
+func (i *InfoCalculateCronJob) start(cronSpec, metricCronSpec string) {
 	c := cron.New(cron.WithChain(
 		// don't let job panic crash whole api-server process
 		cron.Recover(cron.DefaultLogger),
+		// the rollups of many applications may take longer than the interval
+		cron.SkipIfStillRunning(cron.DefaultLogger),
 	))
 	// ignore the entityId and error, the cron spec is defined by hard code, mustn't generate error
 	_, _ = c.AddFunc(cronSpec, func() {
@@ -91,10 +100,25 @@ func (i *InfoCalculateCronJob) start(cronSpec string) {
 			klog.Errorf("After 5 tries the calculating cronJob failed: %v", err)
 		}
 	})
+	// the delivery metrics are rolled up no matter whether the collection is enabled
+	_, _ = c.AddFunc(metricCronSpec, func() {
+		i.rollupDeliveryMetrics(context.Background())
+	})
 	i.cron = c
 	c.Start()
 }
 
+func (i *InfoCalculateCronJob) rollupDeliveryMetrics(ctx context.Context) {
+	if i.DeliveryMetricService == nil {
+		return
+	}
+	if err := i.DeliveryMetricService.RollupDeliveryMetrics(ctx); err != nil {
+		klog.Errorf("Failed to rollup the delivery metrics %v", err)
+		return
+	}
+	klog.Info("Successfully to rollup the delivery metrics")
+}
+
 func (i InfoCalculateCronJob) run() error {
 	ctx := context.Background()
 	systemInfo := model.SystemInfo{}
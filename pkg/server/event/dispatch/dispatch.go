@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dispatch
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+// DispatchCrontabSpec the cron spec of publishing the pipeline run events and redelivering the outbox
+var DispatchCrontabSpec = "@every 15s"
+
+// DispatchCronJob is the cronJob to publish the events of the pipeline runs and redeliver the events in the outbox
+type DispatchCronJob struct {
+	PipelineRunService service.PipelineRunService `inject:""`
+	EventBus           service.EventBus           `inject:""`
+	cron               *cron.Cron
+}
+
+// Start start the worker
+func (d *DispatchCronJob) Start(ctx context.Context, _ chan error) {
+	d.start(ctx, DispatchCrontabSpec)
+	defer d.cron.Stop()
+	<-ctx.Done()
+}
+
+func (d *DispatchCronJob) start(ctx context.Context, cronSpec string) {
+	c := cron.New(cron.WithChain(
+		// don't let job panic crash whole api-server process
+		cron.Recover(cron.DefaultLogger),
+		cron.SkipIfStillRunning(cron.DefaultLogger),
+	))
+	// ignore the entityId and error, the cron spec is defined by hard code, mustn't generate error
+	_, _ = c.AddFunc(cronSpec, func() {
+		if err := d.PipelineRunService.SyncPipelineRunEvents(ctx); err != nil {
+			klog.Errorf("Failed to sync the events of the pipeline runs %v", err)
+		}
+		if err := d.EventBus.RedeliverOutbox(ctx); err != nil {
+			klog.Errorf("Failed to redeliver the events in the outbox %v", err)
+		}
+	})
+	d.cron = c
+	c.Start()
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+// DetectCrontabSpec the cron spec of detecting the drifts
+var DetectCrontabSpec = "@every 5m"
+
+// DetectCronJob is the cronJob to detect the drifts between the applications and the application CRs
+type DetectCronJob struct {
+	ApplicationService service.ApplicationService `inject:""`
+	cron               *cron.Cron
+}
+
+// Start start the worker
+func (d *DetectCronJob) Start(ctx context.Context, _ chan error) {
+	d.start(ctx, DetectCrontabSpec)
+	defer d.cron.Stop()
+	<-ctx.Done()
+}
+
+func (d *DetectCronJob) start(ctx context.Context, cronSpec string) {
+	c := cron.New(cron.WithChain(
+		// don't let job panic crash whole api-server process
+		cron.Recover(cron.DefaultLogger),
+		// comparing all applications may take a long time
+		cron.SkipIfStillRunning(cron.DefaultLogger),
+	))
+	// ignore the entityId and error, the cron spec is defined by hard code, mustn't generate error
+	_, _ = c.AddFunc(cronSpec, func() {
+		if err := d.ApplicationService.DetectDrifts(ctx); err != nil {
+			klog.Errorf("Failed to detect the drifts of the applications %v", err)
+		}
+	})
+	d.cron = c
+	c.Start()
+}
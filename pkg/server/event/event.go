@@ -22,6 +22,11 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/kubevela/velaux/pkg/server/event/collect"
+	"github.com/kubevela/velaux/pkg/server/event/dispatch"
+	"github.com/kubevela/velaux/pkg/server/event/drift"
+	"github.com/kubevela/velaux/pkg/server/event/notification"
+	"github.com/kubevela/velaux/pkg/server/event/recycle"
+	"github.com/kubevela/velaux/pkg/server/event/schedule"
 	"github.com/kubevela/velaux/pkg/server/event/sync"
 )
 
@@ -38,8 +43,14 @@ func InitEvent() []interface{} {
 		Queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
 	}
 	collect := &collect.InfoCalculateCronJob{}
-	workers = append(workers, application, collect)
-	return []interface{}{application, collect}
+	purge := &recycle.PurgeCronJob{}
+	deploy := &schedule.DeployCronJob{}
+	pipeline := &schedule.PipelineCronJob{}
+	detect := &drift.DetectCronJob{}
+	notify := &notification.DeliverCronJob{}
+	dispatch := &dispatch.DispatchCronJob{}
+	workers = append(workers, application, collect, purge, deploy, pipeline, detect, notify, dispatch)
+	return []interface{}{application, collect, purge, deploy, pipeline, detect, notify, dispatch}
 }
 
 // StartEventWorker start all event worker
@@ -24,5 +24,5 @@ import (
 
 func TestInitEvent(t *testing.T) {
 	InitEvent()
-	assert.Equal(t, len(workers), 2)
+	assert.Equal(t, len(workers), 8)
 }
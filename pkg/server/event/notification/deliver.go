@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notification
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+// DeliverCrontabSpec the cron spec of delivering the notifications
+var DeliverCrontabSpec = "@every 15s"
+
+// PurgeCrontabSpec the cron spec of purging the expired deliveries
+var PurgeCrontabSpec = "@hourly"
+
+// DeliverCronJob is the cronJob to deliver the pending notifications and purge the expired deliveries
+type DeliverCronJob struct {
+	NotificationService service.NotificationService `inject:""`
+	cron                *cron.Cron
+}
+
+// Start start the worker
+func (d *DeliverCronJob) Start(ctx context.Context, _ chan error) {
+	d.start(ctx, DeliverCrontabSpec, PurgeCrontabSpec)
+	defer d.cron.Stop()
+	<-ctx.Done()
+}
+
+func (d *DeliverCronJob) start(ctx context.Context, cronSpec, purgeCronSpec string) {
+	c := cron.New(cron.WithChain(
+		// don't let job panic crash whole api-server process
+		cron.Recover(cron.DefaultLogger),
+		// the slow channels may delay the delivery
+		cron.SkipIfStillRunning(cron.DefaultLogger),
+	))
+	// ignore the entityId and error, the cron spec is defined by hard code, mustn't generate error
+	_, _ = c.AddFunc(cronSpec, func() {
+		if err := d.NotificationService.DeliverNotifications(ctx); err != nil {
+			klog.Errorf("Failed to deliver the notifications %v", err)
+		}
+	})
+	_, _ = c.AddFunc(purgeCronSpec, func() {
+		// the failed deliveries will be purged in the next round
+		if err := d.NotificationService.PurgeExpiredDeliveries(ctx); err != nil {
+			klog.Errorf("Failed to purge the notification deliveries %v", err)
+		}
+	})
+	d.cron = c
+	c.Start()
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recycle
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+// CrontabSpec the cron spec of purging the recycle bin
+var CrontabSpec = "@hourly"
+
+// PurgeCronJob is the cronJob to purge the expired items in the recycle bin the expired webhook nonces and deliveries
+type PurgeCronJob struct {
+	RecycleBinService service.RecycleBinService `inject:""`
+	WebhookService    service.WebhookService    `inject:""`
+	cron              *cron.Cron
+}
+
+// Start start the worker
+func (p *PurgeCronJob) Start(ctx context.Context, _ chan error) {
+	p.start(ctx, CrontabSpec)
+	defer p.cron.Stop()
+	<-ctx.Done()
+}
+
+func (p *PurgeCronJob) start(ctx context.Context, cronSpec string) {
+	c := cron.New(cron.WithChain(
+		// don't let job panic crash whole api-server process
+		cron.Recover(cron.DefaultLogger),
+	))
+	// ignore the entityId and error, the cron spec is defined by hard code, mustn't generate error
+	_, _ = c.AddFunc(cronSpec, func() {
+		// the failed items will be purged in the next round
+		if err := p.RecycleBinService.PurgeExpiredItems(ctx); err != nil {
+			klog.Errorf("Failed to purge the recycle bin %v", err)
+		}
+		if err := p.WebhookService.PurgeExpiredNonces(ctx); err != nil {
+			klog.Errorf("Failed to purge the webhook nonces %v", err)
+		}
+		if err := p.WebhookService.PurgeExpiredDeliveries(ctx); err != nil {
+			klog.Errorf("Failed to purge the webhook deliveries %v", err)
+		}
+	})
+	p.cron = c
+	c.Start()
+}
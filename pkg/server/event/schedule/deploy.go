@@ -0,0 +1,59 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+// DeployCrontabSpec the cron spec of checking the scheduled deploys
+var DeployCrontabSpec = "@every 1m"
+
+// DeployCronJob is the cronJob to execute the scheduled deploys that reach the schedule time
+type DeployCronJob struct {
+	ApplicationService service.ApplicationService `inject:""`
+	cron               *cron.Cron
+}
+
+// Start start the worker
+func (d *DeployCronJob) Start(ctx context.Context, _ chan error) {
+	d.start(ctx, DeployCrontabSpec)
+	defer d.cron.Stop()
+	<-ctx.Done()
+}
+
+func (d *DeployCronJob) start(ctx context.Context, cronSpec string) {
+	c := cron.New(cron.WithChain(
+		// don't let job panic crash whole api-server process
+		cron.Recover(cron.DefaultLogger),
+		// the deploys of the last round may not be finished
+		cron.SkipIfStillRunning(cron.DefaultLogger),
+	))
+	// ignore the entityId and error, the cron spec is defined by hard code, mustn't generate error
+	_, _ = c.AddFunc(cronSpec, func() {
+		if err := d.ApplicationService.ExecuteScheduledDeploys(ctx); err != nil {
+			klog.Errorf("Failed to execute the scheduled deploys %v", err)
+		}
+	})
+	d.cron = c
+	c.Start()
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"context"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+// PipelineCrontabSpec the cron spec of checking the pipeline schedules
+var PipelineCrontabSpec = "@every 1m"
+
+// PipelineCronJob is the cronJob to run the pipelines whose schedules are due or whose source pipelines are completed
+type PipelineCronJob struct {
+	PipelineService service.PipelineService `inject:""`
+	cron            *cron.Cron
+}
+
+// Start start the worker
+func (p *PipelineCronJob) Start(ctx context.Context, _ chan error) {
+	p.start(ctx, PipelineCrontabSpec)
+	defer p.cron.Stop()
+	<-ctx.Done()
+}
+
+func (p *PipelineCronJob) start(ctx context.Context, cronSpec string) {
+	c := cron.New(cron.WithChain(
+		// don't let job panic crash whole api-server process
+		cron.Recover(cron.DefaultLogger),
+		// the pipeline runs of the last round may not be created
+		cron.SkipIfStillRunning(cron.DefaultLogger),
+	))
+	// ignore the entityId and error, the cron spec is defined by hard code, mustn't generate error
+	_, _ = c.AddFunc(cronSpec, func() {
+		if err := p.PipelineService.ExecutePipelineSchedules(ctx); err != nil {
+			klog.Errorf("Failed to execute the pipeline schedules %v", err)
+		}
+		if err := p.PipelineService.ExecutePipelineTriggers(ctx); err != nil {
+			klog.Errorf("Failed to execute the pipeline triggers %v", err)
+		}
+	})
+	p.cron = c
+	c.Start()
+}
@@ -196,5 +196,5 @@ func (c *CR2UX) DeleteApp(ctx context.Context, targetApp *v1beta1.Application) e
 	if c.applicationService == nil {
 		return c.ds.Delete(ctx, &model.Application{Name: appName})
 	}
-	return c.applicationService.DeleteApplication(ctx, app)
+	return c.applicationService.PurgeApplication(ctx, app)
 }
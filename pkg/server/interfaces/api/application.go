@@ -35,10 +35,12 @@ import (
 )
 
 type application struct {
-	WorkflowAPI        Workflow                   `inject:"inline"`
-	RbacService        service.RBACService        `inject:""`
-	ApplicationService service.ApplicationService `inject:""`
-	EnvBindingService  service.EnvBindingService  `inject:""`
+	WorkflowAPI           Workflow                      `inject:"inline"`
+	RbacService           service.RBACService           `inject:""`
+	ApplicationService    service.ApplicationService    `inject:""`
+	EnvBindingService     service.EnvBindingService     `inject:""`
+	DeliveryMetricService service.DeliveryMetricService `inject:""`
+	WebhookService        service.WebhookService        `inject:""`
 }
 
 // NewApplication new application manage
@@ -62,6 +64,7 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Param(ws.QueryParameter("project", "search base on project name").DataType("string")).
 		Param(ws.QueryParameter("env", "search base on env name").DataType("string")).
 		Param(ws.QueryParameter("targetName", "Name of the application delivery target").DataType("string")).
+		Param(ws.QueryParameter("driftStatus", "search base on the drift status, synced or drifted").DataType("string")).
 		// This api will filter the app by user's permissions
 		// Filter(c.RbacService.CheckPerm("application", "list")).
 		Returns(200, "OK", apis.ListApplicationResponse{}).
@@ -118,6 +121,18 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.ApplicationStatisticsResponse{}))
 
+	ws.Route(ws.GET("/{appName}/delivery_metrics").To(c.applicationDeliveryMetrics).
+		Doc("get the delivery metrics of the application, including the deployment frequency, the lead time, the change failure rate and the time to restore").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "detail")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.QueryParameter("envName", "query identifier of the env").DataType("string")).
+		Param(ws.QueryParameter("days", "the days of the window, default is 30").DataType("integer")).
+		Returns(200, "OK", apis.DeliveryMetrics{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.DeliveryMetrics{}))
+
 	ws.Route(ws.POST("/{appName}/triggers").To(c.createApplicationTrigger).
 		Doc("Create an application trigger").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -151,6 +166,68 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes([]*apis.ApplicationTriggerBase{}))
 
+	ws.Route(ws.POST("/{appName}/triggers/{token}/rotate").To(c.rotateApplicationTrigger).
+		Doc("Rotate the token or the secret of an application trigger").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("trigger", "update")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("token", "identifier of the trigger").DataType("string")).
+		Reads(apis.RotateApplicationTriggerRequest{}).
+		Returns(200, "OK", apis.RotateApplicationTriggerResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.RotateApplicationTriggerResponse{}))
+
+	ws.Route(ws.POST("/{appName}/triggers/{token}/test").To(c.testApplicationTrigger).
+		Doc("Evaluate a sample payload by the mapping of the trigger without deploying").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("trigger", "update")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("token", "identifier of the trigger").DataType("string")).
+		Reads(apis.TestApplicationTriggerRequest{}).
+		Returns(200, "OK", apis.TestApplicationTriggerResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.TestApplicationTriggerResponse{}))
+
+	ws.Route(ws.GET("/{appName}/triggers/{token}/deliveries").To(c.listWebhookDeliveries).
+		Doc("List the webhook deliveries of the trigger").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("trigger", "list")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("token", "identifier of the trigger").DataType("string")).
+		Param(ws.QueryParameter("status", "query the deliveries by the status").DataType("string")).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Returns(200, "OK", apis.ListWebhookDeliveriesResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListWebhookDeliveriesResponse{}))
+
+	ws.Route(ws.GET("/{appName}/triggers/{token}/deliveries/{deliveryID}").To(c.detailWebhookDelivery).
+		Doc("Detail the webhook delivery with the headers and the body").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("trigger", "list")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("token", "identifier of the trigger").DataType("string")).
+		Param(ws.PathParameter("deliveryID", "identifier of the delivery").DataType("string")).
+		Returns(200, "OK", apis.WebhookDeliveryDetail{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.WebhookDeliveryDetail{}))
+
+	ws.Route(ws.POST("/{appName}/triggers/{token}/deliveries/{deliveryID}/replay").To(c.replayWebhookDelivery).
+		Doc("Replay the webhook delivery, the result is recorded as a new delivery").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("trigger", "update")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("token", "identifier of the trigger").DataType("string")).
+		Param(ws.PathParameter("deliveryID", "identifier of the delivery").DataType("string")).
+		Returns(200, "OK", apis.WebhookDeliveryBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.WebhookDeliveryBase{}))
+
 	ws.Route(ws.GET("/{appName}/triggers").To(c.listApplicationTriggers).
 		Doc("List the application triggers").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -165,13 +242,37 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Doc("create one application template").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Filter(c.RbacService.CheckPerm("applicationTemplate", "create")).
+		Filter(c.templateScopeFilter(c.RbacService.CheckPerm("platformAppTemplate", "create"))).
 		Filter(c.appCheckFilter).
 		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.QueryParameter("scope", "publish the template to the platform if the scope is platform").DataType("string")).
 		Reads(apis.CreateApplicationTemplateRequest{}).
 		Returns(200, "OK", apis.ApplicationTemplateBase{}).
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.ApplicationTemplateBase{}))
 
+	ws.Route(ws.POST("/{appName}/clone").To(c.cloneApplication).
+		Doc("clone the application with a new name, optionally into another project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "create")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Reads(apis.CloneApplicationRequest{}).
+		Returns(200, "OK", apis.ApplicationBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ApplicationBase{}))
+
+	ws.Route(ws.GET("/{appName}/export").To(c.exportApplication).
+		Doc("export the application as a portable bundle").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "detail")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.QueryParameter("format", "the format of the bundle, support yaml and tar").DataType("string")).
+		Returns(200, "OK", apis.ExportApplicationBundleResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ExportApplicationBundleResponse{}))
+
 	ws.Route(ws.POST("/{appName}/deploy").To(c.deployApplication).
 		Doc("deploy or upgrade the application").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -183,6 +284,18 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.ApplicationDeployResponse{}))
 
+	ws.Route(ws.POST("/{appName}/promote").To(c.promoteApplication).
+		Doc("promote the configuration of the application from the source env to the target env").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("envBinding", "update")).
+		Filter(c.RbacService.CheckPerm("application", "deploy")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Reads(apis.PromoteApplicationRequest{}).
+		Returns(200, "OK", apis.PromoteApplicationResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.PromoteApplicationResponse{}))
+
 	ws.Route(ws.GET("/{appName}/components").To(c.listApplicationComponents).
 		Doc("gets the list of application components").
 		Filter(c.RbacService.CheckPerm("component", "list")).
@@ -353,6 +466,121 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.ListRevisionsResponse{}))
 
+	ws.Route(ws.POST("/{appName}/scheduled_deploys/{scheduledDeploy}/cancel").To(c.cancelScheduledDeploy).
+		Doc("cancel the scheduled deploy before the schedule time").
+		Filter(c.RbacService.CheckPerm("application", "deploy")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("scheduledDeploy", "identifier of the scheduled deploy, it is the version in the revision list").DataType("string")).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.GET("/{appName}/change_requests").To(c.listChangeRequests).
+		Doc("list the change requests of the application").
+		Filter(c.RbacService.CheckPerm("changeRequest", "list")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.QueryParameter("envName", "query identifier of the env").DataType("string")).
+		Param(ws.QueryParameter("status", "query identifier of the status").DataType("string")).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.ListChangeRequestsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListChangeRequestsResponse{}))
+
+	ws.Route(ws.GET("/{appName}/change_requests/{changeRequest}").To(c.detailChangeRequest).
+		Doc("detail the change request with the diff").
+		Filter(c.RbacService.CheckPerm("changeRequest", "detail")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("changeRequest", "identifier of the change request").DataType("string")).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.DetailChangeRequestResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.DetailChangeRequestResponse{}))
+
+	ws.Route(ws.POST("/{appName}/change_requests/{changeRequest}/review").To(c.reviewChangeRequest).
+		Doc("approve or reject the change request").
+		Filter(c.RbacService.CheckPerm("changeRequest", "review")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("changeRequest", "identifier of the change request").DataType("string")).
+		Reads(apis.ReviewChangeRequestRequest{}).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.ChangeRequestBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ChangeRequestBase{}))
+
+	ws.Route(ws.POST("/{appName}/change_requests/{changeRequest}/deploy").To(c.deployChangeRequest).
+		Doc("deploy the approved change request").
+		Filter(c.RbacService.CheckPerm("application", "deploy")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("changeRequest", "identifier of the change request").DataType("string")).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.ApplicationDeployResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ApplicationDeployResponse{}))
+
+	ws.Route(ws.POST("/{appName}/change_requests/{changeRequest}/cancel").To(c.cancelChangeRequest).
+		Doc("cancel the change request").
+		Filter(c.RbacService.CheckPerm("changeRequest", "update")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("changeRequest", "identifier of the change request").DataType("string")).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.ChangeRequestBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ChangeRequestBase{}))
+
+	ws.Route(ws.GET("/{appName}/deploy_locks").To(c.listDeployLocks).
+		Doc("list the deploy locks of the application, including the released locks").
+		Filter(c.RbacService.CheckPerm("deployLock", "list")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.QueryParameter("envName", "query identifier of the env").DataType("string")).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.ListDeployLocksResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListDeployLocksResponse{}))
+
+	ws.Route(ws.POST("/{appName}/deploy_locks").To(c.createDeployLock).
+		Doc("lock the application in the env, the deploys of other users are blocked").
+		Filter(c.RbacService.CheckPerm("deployLock", "create")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Reads(apis.CreateDeployLockRequest{}).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.DeployLockBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.DeployLockBase{}))
+
+	ws.Route(ws.POST("/{appName}/deploy_locks/{deployLock}/release").To(c.releaseDeployLock).
+		Doc("release the deploy lock, the lock of another user could be broken with the force option").
+		Filter(c.RbacService.CheckPerm("deployLock", "update")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("deployLock", "identifier of the deploy lock").DataType("string")).
+		Reads(apis.ReleaseDeployLockRequest{}).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.DeployLockBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.DeployLockBase{}))
+
+	ws.Route(ws.POST("/{appName}/revisions/compare").To(c.compareApplicationRevisions).
+		Doc("compare two revisions of the application").
+		Filter(c.RbacService.CheckPerm("revision", "detail")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application").DataType("string")).
+		Reads(apis.CompareRevisionsRequest{}).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "OK", apis.CompareRevisionsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.CompareRevisionsResponse{}))
+
 	ws.Route(ws.GET("/{appName}/revisions/{revision}").To(c.detailApplicationRevision).
 		Doc("detail revision for application").
 		Filter(c.RbacService.CheckPerm("revision", "detail")).
@@ -664,6 +892,28 @@ func (c *application) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.AppResetResponse{}))
 
+	ws.Route(ws.GET("/{appName}/drift").To(c.getApplicationDrift).
+		Doc("get the drift between the application and the application CRs in the envs").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "detail")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Param(ws.QueryParameter("refresh", "detect the drift now instead of returning the last result").DataType("boolean")).
+		Returns(200, "OK", apis.ApplicationDriftResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ApplicationDriftResponse{}))
+
+	ws.Route(ws.PUT("/{appName}/drift").To(c.updateApplicationDrift).
+		Doc("update the drift setting of the application").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.RbacService.CheckPerm("application", "update")).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("appName", "identifier of the application ").DataType("string")).
+		Reads(apis.UpdateApplicationDriftRequest{}).
+		Returns(200, "OK", apis.ApplicationDriftResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ApplicationDriftResponse{}))
+
 	ws.Route(ws.POST("/{appName}/dry-run").To(c.dryRunAppOrRevision).
 		Doc("dry-run application to latest revision").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -724,11 +974,12 @@ func (c *application) listApplications(req *restful.Request, res *restful.Respon
 		}
 	}
 	apps, err := c.ApplicationService.ListApplications(req.Request.Context(), apis.ListApplicationOptions{
-		Projects:   projetNames,
-		Env:        req.QueryParameter("env"),
-		TargetName: req.QueryParameter("targetName"),
-		Query:      req.QueryParameter("query"),
-		Labels:     labels,
+		Projects:    projetNames,
+		Env:         req.QueryParameter("env"),
+		TargetName:  req.QueryParameter("targetName"),
+		Query:       req.QueryParameter("query"),
+		Labels:      labels,
+		DriftStatus: req.QueryParameter("driftStatus"),
 	})
 	if err != nil {
 		bcode.ReturnError(req, res, err)
@@ -759,6 +1010,10 @@ func (c *application) createApplicationTrigger(req *restful.Request, res *restfu
 		bcode.ReturnError(req, res, err)
 		return
 	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	base, err := c.ApplicationService.CreateApplicationTrigger(req.Request.Context(), app, createReq)
 	if err != nil {
@@ -802,6 +1057,10 @@ func (c *application) updateApplicationTrigger(req *restful.Request, res *restfu
 		bcode.ReturnError(req, res, err)
 		return
 	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	trigger, err := c.ApplicationService.UpdateApplicationTrigger(req.Request.Context(), app, req.PathParameter("token"), updateReq)
 	if err != nil {
@@ -814,10 +1073,147 @@ func (c *application) updateApplicationTrigger(req *restful.Request, res *restfu
 	}
 }
 
+func (c *application) rotateApplicationTrigger(req *restful.Request, res *restful.Response) {
+	var rotateReq apis.RotateApplicationTriggerRequest
+	if err := req.ReadEntity(&rotateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&rotateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	trigger, err := c.ApplicationService.RotateApplicationTrigger(req.Request.Context(), app, req.PathParameter("token"), rotateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(trigger); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) testApplicationTrigger(req *restful.Request, res *restful.Response) {
+	var testReq apis.TestApplicationTriggerRequest
+	if err := req.ReadEntity(&testReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	result, err := c.WebhookService.TestApplicationTrigger(req.Request.Context(), app, req.PathParameter("token"), testReq.Payload)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(result); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) listWebhookDeliveries(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	deliveries, err := c.WebhookService.ListWebhookDeliveries(req.Request.Context(), app, req.PathParameter("token"), req.QueryParameter("status"), page, pageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(deliveries); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) detailWebhookDelivery(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	delivery, err := c.WebhookService.GetWebhookDelivery(req.Request.Context(), app, req.PathParameter("token"), req.PathParameter("deliveryID"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(delivery); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) replayWebhookDelivery(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	delivery, err := c.WebhookService.ReplayWebhookDelivery(req.Request.Context(), app, req.PathParameter("token"), req.PathParameter("deliveryID"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(delivery); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+// templateScopeFilter the platform template could be used by all projects, so it requires the platform permission
+func (c *application) templateScopeFilter(platformFilter restful.FilterFunction) restful.FilterFunction {
+	return func(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+		if req.QueryParameter("scope") == apis.ApplicationTemplateScopePlatform {
+			platformFilter(req, res, chain)
+			return
+		}
+		chain.ProcessFilter(req, res)
+	}
+}
+
 func (c *application) publishApplicationTemplate(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateApplicationTemplateRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	platform := req.QueryParameter("scope") == apis.ApplicationTemplateScopePlatform
+	base, err := c.ApplicationService.PublishApplicationTemplate(req.Request.Context(), app, createReq, platform)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(base); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) cloneApplication(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var cloneReq apis.CloneApplicationRequest
+	if err := req.ReadEntity(&cloneReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&cloneReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
-	base, err := c.ApplicationService.PublishApplicationTemplate(req.Request.Context(), app)
+	// the permission of the source project is checked by the filter
+	if cloneReq.Project != "" && cloneReq.Project != app.Project {
+		if err := c.RbacService.CheckProjectPerm(req.Request.Context(), cloneReq.Project, "application", "create"); err != nil {
+			bcode.ReturnError(req, res, err)
+			return
+		}
+	}
+	base, err := c.ApplicationService.CloneApplication(req.Request.Context(), app, cloneReq)
 	if err != nil {
+		klog.Errorf("clone the application %s failure %s", app.Name, err.Error())
 		bcode.ReturnError(req, res, err)
 		return
 	}
@@ -827,6 +1223,65 @@ func (c *application) publishApplicationTemplate(req *restful.Request, res *rest
 	}
 }
 
+func (c *application) exportApplication(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	bundle, err := c.ApplicationService.ExportApplications(req.Request.Context(), app.Project, []string{app.Name}, req.QueryParameter("format"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(bundle); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) compareApplicationRevisions(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var compareReq apis.CompareRevisionsRequest
+	if err := req.ReadEntity(&compareReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&compareReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	diff, err := c.ApplicationService.CompareRevisions(req.Request.Context(), app, compareReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(diff); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) promoteApplication(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var promoteReq apis.PromoteApplicationRequest
+	if err := req.ReadEntity(&promoteReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&promoteReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	promotion, err := c.ApplicationService.PromoteApplication(req.Request.Context(), app, promoteReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(promotion); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 // deployApplication TODO: return event model
 func (c *application) deployApplication(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
@@ -1169,6 +1624,153 @@ func (c *application) listApplicationRevisions(req *restful.Request, res *restfu
 	}
 }
 
+func (c *application) cancelScheduledDeploy(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	if err := c.ApplicationService.CancelScheduledDeploy(req.Request.Context(), app, req.PathParameter("scheduledDeploy")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) listChangeRequests(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	changeRequests, err := c.ApplicationService.ListChangeRequests(req.Request.Context(), app, req.QueryParameter("envName"), req.QueryParameter("status"), page, pageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(changeRequests); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) detailChangeRequest(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	detail, err := c.ApplicationService.DetailChangeRequest(req.Request.Context(), app, req.PathParameter("changeRequest"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(detail); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) reviewChangeRequest(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var reviewReq apis.ReviewChangeRequestRequest
+	if err := req.ReadEntity(&reviewReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&reviewReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	changeRequest, err := c.ApplicationService.ReviewChangeRequest(req.Request.Context(), app, req.PathParameter("changeRequest"), reviewReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(changeRequest); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) deployChangeRequest(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	deployRes, err := c.ApplicationService.DeployChangeRequest(req.Request.Context(), app, req.PathParameter("changeRequest"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(deployRes); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) cancelChangeRequest(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	changeRequest, err := c.ApplicationService.CancelChangeRequest(req.Request.Context(), app, req.PathParameter("changeRequest"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(changeRequest); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) listDeployLocks(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	locks, err := c.ApplicationService.ListDeployLocks(req.Request.Context(), app, req.QueryParameter("envName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(locks); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) createDeployLock(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateDeployLockRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	lock, err := c.ApplicationService.CreateDeployLock(req.Request.Context(), app, createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(lock); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) releaseDeployLock(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var releaseReq apis.ReleaseDeployLockRequest
+	if err := req.ReadEntity(&releaseReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	lock, err := c.ApplicationService.ReleaseDeployLock(req.Request.Context(), app, req.PathParameter("deployLock"), releaseReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(lock); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (c *application) detailApplicationRevision(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	detail, err := c.ApplicationService.DetailRevision(req.Request.Context(), app.Name, req.PathParameter("revision"))
@@ -1364,6 +1966,44 @@ func (c *application) resetAppToLatestRevision(req *restful.Request, res *restfu
 	}
 }
 
+func (c *application) getApplicationDrift(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	refresh, _ := strconv.ParseBool(req.QueryParameter("refresh"))
+	drift, err := c.ApplicationService.GetApplicationDrift(req.Request.Context(), app, refresh)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(drift); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) updateApplicationDrift(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	// Verify the validity of parameters
+	var updateReq apis.UpdateApplicationDriftRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	drift, err := c.ApplicationService.UpdateApplicationDrift(req.Request.Context(), app, updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(drift); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *application) applicationDeliveryMetrics(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	getDeliveryMetrics(c.DeliveryMetricService, apis.DeliveryMetricsOptions{AppPrimaryKey: app.PrimaryKey()}, req, res)
+}
+
 func (c *application) dryRunAppOrRevision(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	// Verify the validity of parameters
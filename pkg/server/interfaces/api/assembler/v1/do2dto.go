@@ -80,6 +80,9 @@ func ConvertAppModelToBase(app *model.Application, projects []*apisv1.ProjectBas
 		Project:     &apisv1.ProjectBase{Name: app.Project},
 		ReadOnly:    app.IsReadOnly(),
 	}
+	if app.Drift != nil {
+		appBase.DriftStatus = app.Drift.Status
+	}
 
 	for _, project := range projects {
 		if project.Name == app.Project {
@@ -281,17 +284,46 @@ func ConvertPermission2DTO(permission *model.Permission) *apisv1.PermissionBase
 // ConvertTrigger2DTO convert trigger model to the DTO
 func ConvertTrigger2DTO(trigger model.ApplicationTrigger) *apisv1.ApplicationTriggerBase {
 	return &apisv1.ApplicationTriggerBase{
-		WorkflowName:  trigger.WorkflowName,
-		Name:          trigger.Name,
-		Alias:         trigger.Alias,
-		Description:   trigger.Description,
-		Type:          trigger.Type,
-		PayloadType:   trigger.PayloadType,
-		Token:         trigger.Token,
-		Registry:      trigger.Registry,
-		ComponentName: trigger.ComponentName,
-		CreateTime:    trigger.CreateTime,
-		UpdateTime:    trigger.UpdateTime,
+		WorkflowName:       trigger.WorkflowName,
+		Name:               trigger.Name,
+		Alias:              trigger.Alias,
+		Description:        trigger.Description,
+		Type:               trigger.Type,
+		PayloadType:        trigger.PayloadType,
+		Token:              trigger.Token,
+		Registry:           trigger.Registry,
+		ComponentName:      trigger.ComponentName,
+		HasSecret:          trigger.Secret != "",
+		SignatureType:      trigger.SignatureType,
+		AllowedCIDRs:       trigger.AllowedCIDRs,
+		GitEvents:          trigger.GitEvents,
+		BranchFilters:      trigger.BranchFilters,
+		TagFilters:         trigger.TagFilters,
+		PropertiesTemplate: trigger.PropertiesTemplate,
+		ImageProperties:    trigger.ImageProperties,
+		ImageTagFilter:     trigger.ImageTagFilter,
+		PayloadMapping:     trigger.PayloadMapping,
+		CreateTime:         trigger.CreateTime,
+		UpdateTime:         trigger.UpdateTime,
+	}
+}
+
+// ConvertWebhookDelivery2DTO convert the webhook delivery model to the DTO
+func ConvertWebhookDelivery2DTO(delivery model.WebhookDelivery) *apisv1.WebhookDeliveryBase {
+	return &apisv1.WebhookDeliveryBase{
+		ID:          delivery.ID,
+		TriggerName: delivery.TriggerName,
+		PayloadType: delivery.PayloadType,
+		SourceIP:    delivery.SourceIP,
+		Status:      delivery.Status,
+		Revision:    delivery.Revision,
+		RecordName:  delivery.RecordName,
+		Message:     delivery.Message,
+		Error:       delivery.Error,
+		Latency:     delivery.Latency,
+		ReplayOf:    delivery.ReplayOf,
+		User:        delivery.User,
+		Time:        delivery.Time,
 	}
 }
 
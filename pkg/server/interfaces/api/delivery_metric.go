@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"strconv"
+
+	"github.com/emicklei/go-restful/v3"
+	"github.com/pkg/errors"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// getDeliveryMetrics read the env and the window from the query parameters and write the delivery metrics
+func getDeliveryMetrics(deliveryMetricService service.DeliveryMetricService, options apis.DeliveryMetricsOptions, req *restful.Request, res *restful.Response) {
+	options.EnvName = req.QueryParameter("envName")
+	if days := req.QueryParameter("days"); days != "" {
+		var err error
+		if options.Days, err = strconv.Atoi(days); err != nil {
+			bcode.ReturnError(req, res, errors.Errorf("invalid days %s: %v", days, err))
+			return
+		}
+	}
+	metrics, err := deliveryMetricService.GetDeliveryMetrics(req.Request.Context(), options)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(metrics); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -398,6 +398,8 @@ type ListApplicationOptions struct {
 	TargetName string            `json:"targetName"`
 	Query      string            `json:"query"`
 	Labels     map[string]string `json:"labels"`
+	// DriftStatus filter the applications by the drift status, synced or drifted
+	DriftStatus string `json:"driftStatus"`
 }
 
 // ListApplicationResponse list applications by query params
@@ -420,6 +422,7 @@ type ApplicationBase struct {
 	Labels      map[string]string `json:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty"`
 	ReadOnly    bool              `json:"readOnly,omitempty"`
+	DriftStatus string            `json:"driftStatus,omitempty"`
 }
 
 // AppCompareResponse application compare result
@@ -435,6 +438,20 @@ type AppResetResponse struct {
 	IsReset bool `json:"isReset"`
 }
 
+// ApplicationDriftResponse the drift between the rendered application and the application CRs
+type ApplicationDriftResponse struct {
+	Status            string           `json:"status"`
+	DetectTime        *time.Time       `json:"detectTime,omitempty"`
+	Envs              []model.EnvDrift `json:"envs"`
+	AutoRemediate     bool             `json:"autoRemediate"`
+	LastRemediateTime *time.Time       `json:"lastRemediateTime,omitempty"`
+}
+
+// UpdateApplicationDriftRequest the drift setting of the application
+type UpdateApplicationDriftRequest struct {
+	AutoRemediate bool `json:"autoRemediate"`
+}
+
 // AppCompareReq  application compare req
 type AppCompareReq struct {
 	CompareRevisionWithRunning *CompareRevisionWithRunningOption `json:"compareRevisionWithRunning,omitempty"`
@@ -467,6 +484,50 @@ type AppDryRunReq struct {
 	Version    string `json:"version"`
 }
 
+const (
+	// RevisionDiffAdded the item is added in the target revision
+	RevisionDiffAdded = "added"
+	// RevisionDiffRemoved the item is removed in the target revision
+	RevisionDiffRemoved = "removed"
+	// RevisionDiffModified the item is modified in the target revision
+	RevisionDiffModified = "modified"
+)
+
+// CompareRevisionsRequest compare two revisions of the application
+type CompareRevisionsRequest struct {
+	BaseRevision   string `json:"baseRevision" validate:"required"`
+	TargetRevision string `json:"targetRevision" validate:"required"`
+	// WithResources diff the kubernetes resources rendered by dry-running the revisions
+	WithResources bool `json:"withResources,omitempty" optional:"true"`
+}
+
+// CompareRevisionsResponse the diff between two revisions
+type CompareRevisionsResponse struct {
+	BaseRevision   string              `json:"baseRevision"`
+	TargetRevision string              `json:"targetRevision"`
+	IsDiff         bool                `json:"isDiff"`
+	Components     []RevisionDiffEntry `json:"components"`
+	Policies       []RevisionDiffEntry `json:"policies"`
+	WorkflowSteps  []RevisionDiffEntry `json:"workflowSteps"`
+	// UnifiedDiff the unified diff of the application spec
+	UnifiedDiff string `json:"unifiedDiff"`
+	// ResourcesDiff the unified diff of the kubernetes resources, only set if the resources are requested
+	ResourcesDiff string `json:"resourcesDiff,omitempty"`
+	// ResourcesMessage the failure message of dry-running the revisions
+	ResourcesMessage string `json:"resourcesMessage,omitempty"`
+}
+
+// RevisionDiffEntry the changed component, trait, policy or workflow step
+type RevisionDiffEntry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Change is one of added, removed and modified
+	Change string `json:"change"`
+	// Diff the unified diff of the item, the traits of the component are not included
+	Diff   string              `json:"diff,omitempty"`
+	Traits []RevisionDiffEntry `json:"traits,omitempty"`
+}
+
 // AppDryRunResponse application dry-run result
 type AppDryRunResponse struct {
 	YAML    string `json:"yaml"`
@@ -491,6 +552,43 @@ type ApplicationStatisticsResponse struct {
 	TargetCount   int64 `json:"targetCount"`
 	RevisionCount int64 `json:"revisionCount"`
 	WorkflowCount int64 `json:"workflowCount"`
+	// Delivery the delivery metrics of the last 30 days
+	Delivery *DeliveryMetrics `json:"delivery,omitempty"`
+}
+
+// DeliveryMetricsOptions the scope and the window of the delivery metrics
+type DeliveryMetricsOptions struct {
+	Project       string `json:"project"`
+	AppPrimaryKey string `json:"appPrimaryKey"`
+	EnvName       string `json:"envName"`
+	// Days the window size, the window ends at today
+	Days int `json:"days"`
+}
+
+// DeliveryMetrics the DORA metrics in the window
+type DeliveryMetrics struct {
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+	// Deployments the number of the finished deploys in the window
+	Deployments int64 `json:"deployments"`
+	// DeploymentFrequency the average deploys per day
+	DeploymentFrequency float64 `json:"deploymentFrequency"`
+	// LeadTimeSeconds the median seconds from the commit to the completion of the deploys
+	LeadTimeSeconds int64 `json:"leadTimeSeconds"`
+	// ChangeFailureRate the ratio of the deploys that failed or were rolled back
+	ChangeFailureRate float64 `json:"changeFailureRate"`
+	// TimeToRestoreSeconds the median seconds from the failed deploys to the next successful deploys
+	TimeToRestoreSeconds int64                  `json:"timeToRestoreSeconds"`
+	Daily                []DeliveryMetricsDaily `json:"daily"`
+}
+
+// DeliveryMetricsDaily the delivery metrics of a day
+type DeliveryMetricsDaily struct {
+	Date                 string `json:"date"`
+	Deployments          int64  `json:"deployments"`
+	Failures             int64  `json:"failures"`
+	LeadTimeSeconds      int64  `json:"leadTimeSeconds"`
+	TimeToRestoreSeconds int64  `json:"timeToRestoreSeconds"`
 }
 
 // CreateApplicationRequest create application request body
@@ -503,6 +601,8 @@ type CreateApplicationRequest struct {
 	Labels      map[string]string       `json:"labels,omitempty"`
 	EnvBinding  []*EnvBinding           `json:"envBinding,omitempty"`
 	Component   *CreateComponentRequest `json:"component"`
+	// Template creates the application from the template, the env bindings of the template are used if EnvBinding is empty
+	Template *ApplicationTemplateReference `json:"template,omitempty" optional:"true"`
 }
 
 // UpdateApplicationRequest update application base config
@@ -524,6 +624,17 @@ type CreateApplicationTriggerRequest struct {
 	PayloadType   string `json:"payloadType" validate:"checkpayloadtype"`
 	ComponentName string `json:"componentName,omitempty" optional:"true"`
 	Registry      string `json:"registry,omitempty" optional:"true"`
+	// Secret the shared secret to verify the requests
+	Secret             string                       `json:"secret,omitempty" optional:"true"`
+	SignatureType      string                       `json:"signatureType,omitempty" validate:"omitempty,oneof=github gitlab harbor hmac" optional:"true"`
+	AllowedCIDRs       []string                     `json:"allowedCIDRs,omitempty" optional:"true"`
+	GitEvents          []string                     `json:"gitEvents,omitempty" validate:"dive,oneof=push tag mergeRequest" optional:"true"`
+	BranchFilters      []string                     `json:"branchFilters,omitempty" optional:"true"`
+	TagFilters         []string                     `json:"tagFilters,omitempty" optional:"true"`
+	PropertiesTemplate string                       `json:"propertiesTemplate,omitempty" optional:"true"`
+	ImageProperties    []model.ImagePropertyMapping `json:"imageProperties,omitempty" optional:"true"`
+	ImageTagFilter     *model.ImageTagFilter        `json:"imageTagFilter,omitempty" optional:"true"`
+	PayloadMapping     *model.PayloadMapping        `json:"payloadMapping,omitempty" optional:"true"`
 }
 
 // UpdateApplicationTriggerRequest update application trigger
@@ -534,21 +645,107 @@ type UpdateApplicationTriggerRequest struct {
 	PayloadType   string `json:"payloadType" validate:"checkpayloadtype"`
 	ComponentName string `json:"componentName,omitempty" optional:"true"`
 	Registry      string `json:"registry,omitempty" optional:"true"`
+	// Secret the existing secret is kept if it is empty
+	Secret             string                       `json:"secret,omitempty" optional:"true"`
+	SignatureType      string                       `json:"signatureType,omitempty" validate:"omitempty,oneof=github gitlab harbor hmac" optional:"true"`
+	AllowedCIDRs       []string                     `json:"allowedCIDRs,omitempty" optional:"true"`
+	GitEvents          []string                     `json:"gitEvents,omitempty" validate:"dive,oneof=push tag mergeRequest" optional:"true"`
+	BranchFilters      []string                     `json:"branchFilters,omitempty" optional:"true"`
+	TagFilters         []string                     `json:"tagFilters,omitempty" optional:"true"`
+	PropertiesTemplate string                       `json:"propertiesTemplate,omitempty" optional:"true"`
+	ImageProperties    []model.ImagePropertyMapping `json:"imageProperties,omitempty" optional:"true"`
+	ImageTagFilter     *model.ImageTagFilter        `json:"imageTagFilter,omitempty" optional:"true"`
+	PayloadMapping     *model.PayloadMapping        `json:"payloadMapping,omitempty" optional:"true"`
 }
 
 // ApplicationTriggerBase application trigger base model
 type ApplicationTriggerBase struct {
-	Name          string    `json:"name"`
-	Alias         string    `json:"alias,omitempty"`
-	Description   string    `json:"description,omitempty"`
-	WorkflowName  string    `json:"workflowName"`
-	Type          string    `json:"type"`
-	PayloadType   string    `json:"payloadType"`
-	Token         string    `json:"token"`
-	ComponentName string    `json:"componentName,omitempty"`
-	Registry      string    `json:"registry"`
-	CreateTime    time.Time `json:"createTime"`
-	UpdateTime    time.Time `json:"updateTime"`
+	Name               string                       `json:"name"`
+	Alias              string                       `json:"alias,omitempty"`
+	Description        string                       `json:"description,omitempty"`
+	WorkflowName       string                       `json:"workflowName"`
+	Type               string                       `json:"type"`
+	PayloadType        string                       `json:"payloadType"`
+	Token              string                       `json:"token"`
+	ComponentName      string                       `json:"componentName,omitempty"`
+	Registry           string                       `json:"registry"`
+	HasSecret          bool                         `json:"hasSecret"`
+	SignatureType      string                       `json:"signatureType,omitempty"`
+	AllowedCIDRs       []string                     `json:"allowedCIDRs,omitempty"`
+	GitEvents          []string                     `json:"gitEvents,omitempty"`
+	BranchFilters      []string                     `json:"branchFilters,omitempty"`
+	TagFilters         []string                     `json:"tagFilters,omitempty"`
+	PropertiesTemplate string                       `json:"propertiesTemplate,omitempty"`
+	ImageProperties    []model.ImagePropertyMapping `json:"imageProperties,omitempty"`
+	ImageTagFilter     *model.ImageTagFilter        `json:"imageTagFilter,omitempty"`
+	PayloadMapping     *model.PayloadMapping        `json:"payloadMapping,omitempty"`
+	CreateTime         time.Time                    `json:"createTime"`
+	UpdateTime         time.Time                    `json:"updateTime"`
+}
+
+// RotateApplicationTriggerRequest rotate the token or the secret of the trigger
+type RotateApplicationTriggerRequest struct {
+	Token  bool `json:"token,omitempty" optional:"true"`
+	Secret bool `json:"secret,omitempty" optional:"true"`
+	// SecretGracePeriod the seconds that the previous secret is still accepted after rotating
+	SecretGracePeriod int64 `json:"secretGracePeriod,omitempty" validate:"min=0" optional:"true"`
+}
+
+// RotateApplicationTriggerResponse the secret is only returned once after rotating
+type RotateApplicationTriggerResponse struct {
+	ApplicationTriggerBase
+	Secret string `json:"secret,omitempty"`
+}
+
+// TestApplicationTriggerRequest evaluate the sample payload by the trigger
+type TestApplicationTriggerRequest struct {
+	Payload map[string]interface{} `json:"payload"`
+}
+
+// TestApplicationTriggerResponse the result of evaluating the sample payload, the deploy is not executed
+type TestApplicationTriggerResponse struct {
+	// Matched whether the conditions are matched
+	Matched bool `json:"matched"`
+	// Reason why the conditions are not matched
+	Reason       string                       `json:"reason,omitempty"`
+	WorkflowName string                       `json:"workflowName,omitempty"`
+	Action       string                       `json:"action,omitempty"`
+	Step         string                       `json:"step,omitempty"`
+	Upgrade      map[string]*model.JSONStruct `json:"upgrade,omitempty"`
+	CodeInfo     *model.CodeInfo              `json:"codeInfo,omitempty"`
+	ImageInfo    *model.ImageInfo             `json:"imageInfo,omitempty"`
+}
+
+// WebhookDeliveryBase the summary of the inbound webhook request
+type WebhookDeliveryBase struct {
+	ID          string `json:"id"`
+	TriggerName string `json:"triggerName"`
+	PayloadType string `json:"payloadType"`
+	SourceIP    string `json:"sourceIP,omitempty"`
+	// Status succeeded, failed, skipped or duplicated
+	Status     string    `json:"status"`
+	Revision   string    `json:"revision,omitempty"`
+	RecordName string    `json:"recordName,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Latency    int64     `json:"latency"`
+	ReplayOf   string    `json:"replayOf,omitempty"`
+	User       string    `json:"user,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// WebhookDeliveryDetail the inbound webhook request with the headers and the body
+type WebhookDeliveryDetail struct {
+	WebhookDeliveryBase
+	Headers       map[string]string `json:"headers,omitempty"`
+	Body          string            `json:"body,omitempty"`
+	BodyTruncated bool              `json:"bodyTruncated,omitempty"`
+}
+
+// ListWebhookDeliveriesResponse the deliveries of the trigger, the latest one is the first
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []*WebhookDeliveryBase `json:"deliveries"`
+	Total      int64                  `json:"total"`
 }
 
 // ListApplicationTriggerResponse list application triggers response body
@@ -671,6 +868,280 @@ type JFrogWebhookData struct {
 	Tag       string `json:"tag"`
 }
 
+// HandleApplicationTriggerGitHubRequest the push and pull_request events of GitHub, Gitea sends the compatible payload
+type HandleApplicationTriggerGitHubRequest struct {
+	Ref         string             `json:"ref"`
+	After       string             `json:"after"`
+	Deleted     bool               `json:"deleted"`
+	HeadCommit  *GitHubCommit      `json:"head_commit,omitempty"`
+	Commits     []GitHubCommit     `json:"commits,omitempty"`
+	Pusher      GitHubUser         `json:"pusher"`
+	Sender      GitHubUser         `json:"sender"`
+	Action      string             `json:"action,omitempty"`
+	PullRequest *GitHubPullRequest `json:"pull_request,omitempty"`
+	Repository  GitHubRepository   `json:"repository"`
+}
+
+// GitHubCommit the commit of GitHub
+type GitHubCommit struct {
+	ID        string     `json:"id"`
+	Message   string     `json:"message"`
+	Timestamp string     `json:"timestamp"`
+	Author    GitHubUser `json:"author"`
+}
+
+// GitHubUser the user of GitHub, the pusher only has the name
+type GitHubUser struct {
+	Login    string `json:"login,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// GitHubPullRequest the pull request of GitHub
+type GitHubPullRequest struct {
+	Number    int64           `json:"number"`
+	Merged    bool            `json:"merged"`
+	UpdatedAt string          `json:"updated_at"`
+	Head      GitHubBranchRef `json:"head"`
+	Base      GitHubBranchRef `json:"base"`
+	User      GitHubUser      `json:"user"`
+}
+
+// GitHubBranchRef the branch and the commit of the pull request
+type GitHubBranchRef struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// GitHubRepository the repository of GitHub
+type GitHubRepository struct {
+	FullName string `json:"full_name"`
+}
+
+// HandleApplicationTriggerGitLabRequest the push, tag_push and merge_request events of GitLab
+type HandleApplicationTriggerGitLabRequest struct {
+	ObjectKind       string              `json:"object_kind"`
+	Ref              string              `json:"ref,omitempty"`
+	After            string              `json:"after,omitempty"`
+	CheckoutSHA      string              `json:"checkout_sha,omitempty"`
+	UserName         string              `json:"user_name,omitempty"`
+	UserUsername     string              `json:"user_username,omitempty"`
+	Commits          []GitLabCommit      `json:"commits,omitempty"`
+	User             *GitLabUser         `json:"user,omitempty"`
+	ObjectAttributes *GitLabMergeRequest `json:"object_attributes,omitempty"`
+	Project          GitLabProject       `json:"project"`
+}
+
+// GitLabCommit the commit of GitLab
+type GitLabCommit struct {
+	ID        string `json:"id"`
+	Message   string `json:"message"`
+	Timestamp string `json:"timestamp"`
+}
+
+// GitLabUser the user of GitLab
+type GitLabUser struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+// GitLabMergeRequest the attributes of the GitLab merge request
+type GitLabMergeRequest struct {
+	IID          int64        `json:"iid"`
+	Action       string       `json:"action"`
+	State        string       `json:"state"`
+	SourceBranch string       `json:"source_branch"`
+	TargetBranch string       `json:"target_branch"`
+	LastCommit   GitLabCommit `json:"last_commit"`
+}
+
+// GitLabProject the project of GitLab
+type GitLabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+}
+
+// HandleApplicationTriggerBitbucketRequest the repo:push and pullrequest events of Bitbucket Cloud
+type HandleApplicationTriggerBitbucketRequest struct {
+	Push        *BitbucketPush        `json:"push,omitempty"`
+	PullRequest *BitbucketPullRequest `json:"pullrequest,omitempty"`
+	Actor       BitbucketUser         `json:"actor"`
+	Repository  BitbucketRepository   `json:"repository"`
+}
+
+// BitbucketPush the changes of the push event
+type BitbucketPush struct {
+	Changes []BitbucketChange `json:"changes"`
+}
+
+// BitbucketChange the reference change, the new reference is empty if the branch or the tag is deleted
+type BitbucketChange struct {
+	New *BitbucketRef `json:"new,omitempty"`
+}
+
+// BitbucketRef the branch or the tag of Bitbucket
+type BitbucketRef struct {
+	Type   string          `json:"type"`
+	Name   string          `json:"name"`
+	Target BitbucketCommit `json:"target"`
+}
+
+// BitbucketCommit the commit of Bitbucket
+type BitbucketCommit struct {
+	Hash    string `json:"hash"`
+	Date    string `json:"date,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// BitbucketUser the user of Bitbucket
+type BitbucketUser struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname,omitempty"`
+}
+
+// BitbucketPullRequest the pull request of Bitbucket
+type BitbucketPullRequest struct {
+	ID          int64             `json:"id"`
+	State       string            `json:"state"`
+	UpdatedOn   string            `json:"updated_on"`
+	Source      BitbucketEndpoint `json:"source"`
+	Destination BitbucketEndpoint `json:"destination"`
+	Author      BitbucketUser     `json:"author"`
+}
+
+// BitbucketEndpoint the branch and the commit of the pull request
+type BitbucketEndpoint struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit BitbucketCommit `json:"commit"`
+}
+
+// BitbucketRepository the repository of Bitbucket
+type BitbucketRepository struct {
+	FullName string `json:"full_name"`
+}
+
+// HandleApplicationTriggerGHCRRequest the package event of GitHub, the container packages are pushed to GHCR
+type HandleApplicationTriggerGHCRRequest struct {
+	Action     string         `json:"action"`
+	Package    GHCRPackage    `json:"package"`
+	Repository GHCRRepository `json:"repository"`
+	Sender     GitHubUser     `json:"sender"`
+}
+
+// GHCRPackage the package of GitHub
+type GHCRPackage struct {
+	Name           string               `json:"name"`
+	Namespace      string               `json:"namespace"`
+	PackageType    string               `json:"package_type"`
+	CreatedAt      string               `json:"created_at"`
+	Owner          GitHubUser           `json:"owner"`
+	PackageVersion GHCRPackageVersion   `json:"package_version"`
+	Registry       *GHCRPackageRegistry `json:"registry,omitempty"`
+}
+
+// GHCRPackageVersion the pushed version of the container package
+type GHCRPackageVersion struct {
+	Name              string                `json:"name"`
+	PackageURL        string                `json:"package_url"`
+	CreatedAt         string                `json:"created_at"`
+	ContainerMetadata GHCRContainerMetadata `json:"container_metadata"`
+}
+
+// GHCRContainerMetadata the tag of the container package, the name is empty if the version is untagged
+type GHCRContainerMetadata struct {
+	Tag struct {
+		Name   string `json:"name"`
+		Digest string `json:"digest"`
+	} `json:"tag"`
+}
+
+// GHCRPackageRegistry the registry of the package
+type GHCRPackageRegistry struct {
+	URL string `json:"url"`
+}
+
+// GHCRRepository the repository that the package belongs to
+type GHCRRepository struct {
+	FullName string `json:"full_name"`
+	Private  bool   `json:"private"`
+}
+
+// HandleApplicationTriggerQuayRequest the repository push notification of Quay
+type HandleApplicationTriggerQuayRequest struct {
+	Name        string   `json:"name"`
+	Repository  string   `json:"repository"`
+	Namespace   string   `json:"namespace"`
+	DockerURL   string   `json:"docker_url"`
+	Homepage    string   `json:"homepage"`
+	UpdatedTags []string `json:"updated_tags"`
+}
+
+// HandleApplicationTriggerECRRequest the ECR Image Action event forwarded by the EventBridge
+type HandleApplicationTriggerECRRequest struct {
+	ID         string         `json:"id"`
+	DetailType string         `json:"detail-type"`
+	Source     string         `json:"source"`
+	Account    string         `json:"account"`
+	Time       string         `json:"time"`
+	Region     string         `json:"region"`
+	Detail     ECRImageAction `json:"detail"`
+}
+
+// ECRImageAction the detail of the ECR Image Action event
+type ECRImageAction struct {
+	Result         string `json:"result"`
+	RepositoryName string `json:"repository-name"`
+	ImageDigest    string `json:"image-digest"`
+	ActionType     string `json:"action-type"`
+	ImageTag       string `json:"image-tag"`
+}
+
+// HandleApplicationTriggerArtifactRegistryRequest the Pub/Sub push message of the Artifact Registry notification
+type HandleApplicationTriggerArtifactRegistryRequest struct {
+	Message struct {
+		// Data the base64 encoded ArtifactRegistryNotification
+		Data        string `json:"data"`
+		MessageID   string `json:"messageId"`
+		PublishTime string `json:"publishTime"`
+	} `json:"message"`
+	Subscription string `json:"subscription"`
+}
+
+// ArtifactRegistryNotification the image change of the Artifact Registry
+type ArtifactRegistryNotification struct {
+	Action string `json:"action"`
+	// Digest the image reference with the digest, such as us-east1-docker.pkg.dev/project/repo/image@sha256:...
+	Digest string `json:"digest"`
+	// Tag the image reference with the tag, it is empty if the image is untagged
+	Tag string `json:"tag,omitempty"`
+}
+
+// HandleApplicationTriggerRegistryNotificationRequest the notification envelope of the distribution registry, such as the GitLab Container Registry
+type HandleApplicationTriggerRegistryNotificationRequest struct {
+	Events []RegistryNotificationEvent `json:"events"`
+}
+
+// RegistryNotificationEvent the event of the distribution registry
+type RegistryNotificationEvent struct {
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp"`
+	Action    string `json:"action"`
+	Target    struct {
+		MediaType  string `json:"mediaType"`
+		Digest     string `json:"digest"`
+		Repository string `json:"repository"`
+		URL        string `json:"url"`
+		Tag        string `json:"tag"`
+	} `json:"target"`
+	Request struct {
+		Host string `json:"host"`
+	} `json:"request"`
+	Actor struct {
+		Name string `json:"name"`
+	} `json:"actor"`
+}
+
 // EnvBinding application env binding
 type EnvBinding struct {
 	Name string `json:"name" validate:"checkname"`
@@ -789,28 +1260,183 @@ type ListApplicationComponentOptions struct {
 	EnvName string `json:"envName"`
 }
 
+// ApplicationTemplateScopePlatform the template is shared by all projects
+const ApplicationTemplateScopePlatform = "platform"
+
 // CreateApplicationTemplateRequest create app template request model
 type CreateApplicationTemplateRequest struct {
-	TemplateName string `json:"templateName" validate:"checkname"`
-	Version      string `json:"version" validate:"required"`
-	Description  string `json:"description"`
+	TemplateName string                               `json:"templateName" validate:"checkname"`
+	Version      string                               `json:"version" validate:"required"`
+	Description  string                               `json:"description"`
+	Parameters   []model.ApplicationTemplateParameter `json:"parameters,omitempty"`
 }
 
 // ApplicationTemplateBase app template model
 type ApplicationTemplateBase struct {
-	TemplateName string                        `json:"templateName"`
-	Versions     []*ApplicationTemplateVersion `json:"versions,omitempty"`
-	CreateTime   time.Time                     `json:"createTime"`
-	UpdateTime   time.Time                     `json:"updateTime"`
+	TemplateName string `json:"templateName"`
+	// Project is empty if the template is shared by all projects
+	Project    *NameAlias                    `json:"project,omitempty"`
+	Versions   []*ApplicationTemplateVersion `json:"versions,omitempty"`
+	CreateTime time.Time                     `json:"createTime"`
+	UpdateTime time.Time                     `json:"updateTime"`
 }
 
 // ApplicationTemplateVersion template version model
 type ApplicationTemplateVersion struct {
-	Version     string    `json:"version"`
-	Description string    `json:"description"`
-	CreateUser  string    `json:"createUser"`
-	CreateTime  time.Time `json:"createTime"`
-	UpdateTime  time.Time `json:"updateTime"`
+	Version     string                               `json:"version"`
+	Description string                               `json:"description"`
+	CreateUser  string                               `json:"createUser"`
+	SourceApp   string                               `json:"sourceApp"`
+	Parameters  []model.ApplicationTemplateParameter `json:"parameters,omitempty"`
+	CreateTime  time.Time                            `json:"createTime"`
+	UpdateTime  time.Time                            `json:"updateTime"`
+}
+
+// ListApplicationTemplateResponse list the application templates
+type ListApplicationTemplateResponse struct {
+	Templates []*ApplicationTemplateBase `json:"templates"`
+}
+
+// ApplicationTemplateReference the template and the parameter values to create the application
+type ApplicationTemplateReference struct {
+	Name string `json:"name" validate:"checkname"`
+	// Version uses the latest version if it is empty
+	Version    string                 `json:"version,omitempty" optional:"true"`
+	Parameters map[string]interface{} `json:"parameters,omitempty" optional:"true"`
+}
+
+// PreviewApplicationTemplateRequest the request body to render the template with the parameter values
+type PreviewApplicationTemplateRequest struct {
+	Version    string                 `json:"version,omitempty" optional:"true"`
+	Parameters map[string]interface{} `json:"parameters,omitempty" optional:"true"`
+}
+
+// PreviewApplicationTemplateResponse the rendered template
+type PreviewApplicationTemplateResponse struct {
+	TemplateName string                       `json:"templateName"`
+	Version      string                       `json:"version"`
+	Components   []model.ApplicationComponent `json:"components"`
+	Policies     []model.ApplicationPolicy    `json:"policies"`
+	Workflows    []model.Workflow             `json:"workflows"`
+	EnvBindings  []model.EnvBinding           `json:"envBindings"`
+}
+
+// CloneApplicationRequest clone the application with a new name
+type CloneApplicationRequest struct {
+	Name        string `json:"name" validate:"checkname"`
+	Alias       string `json:"alias,omitempty" optional:"true" validate:"checkalias"`
+	Description string `json:"description,omitempty" optional:"true"`
+	// Project the application is cloned into, default is the project of the source application
+	Project string `json:"project,omitempty" optional:"true"`
+	// EnvMapping maps the envs of the source application to the envs of the target project.
+	// The envs which are not mapped are kept in the same project and skipped in another project.
+	EnvMapping map[string]string `json:"envMapping,omitempty" optional:"true"`
+}
+
+const (
+	// ApplicationBundleFormatYAML the bundle is one YAML document
+	ApplicationBundleFormatYAML = "yaml"
+	// ApplicationBundleFormatTar the bundle is a gzipped tar archive, one YAML file per application
+	ApplicationBundleFormatTar = "tar"
+)
+
+const (
+	// ImportConflictSkip skip the application if the name exists
+	ImportConflictSkip = "skip"
+	// ImportConflictOverwrite overwrite the existing application in the same project
+	ImportConflictOverwrite = "overwrite"
+	// ImportConflictRename import the application with a new name
+	ImportConflictRename = "rename"
+)
+
+// ApplicationBundle the portable bundle of the applications, the secrets such as the trigger tokens are not included
+type ApplicationBundle struct {
+	APIVersion   string                  `json:"apiVersion"`
+	Kind         string                  `json:"kind"`
+	Project      string                  `json:"project"`
+	ExportTime   time.Time               `json:"exportTime"`
+	Applications []ApplicationBundleItem `json:"applications,omitempty"`
+}
+
+// ApplicationBundleItem all records of one application in the bundle
+type ApplicationBundleItem struct {
+	Application ApplicationBundleMeta        `json:"application"`
+	Components  []model.ApplicationComponent `json:"components,omitempty"`
+	Policies    []model.ApplicationPolicy    `json:"policies,omitempty"`
+	Workflows   []model.Workflow             `json:"workflows,omitempty"`
+	EnvBindings []model.EnvBinding           `json:"envBindings,omitempty"`
+	Triggers    []ApplicationBundleTrigger   `json:"triggers,omitempty"`
+	// Manifests the rendered applications of the envs, they are only used for reviewing and ignored when importing
+	Manifests map[string]*v1beta1.Application `json:"manifests,omitempty"`
+}
+
+// ApplicationBundleMeta the base info of the application in the bundle
+type ApplicationBundleMeta struct {
+	Name        string            `json:"name"`
+	Alias       string            `json:"alias,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Icon        string            `json:"icon,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ApplicationBundleTrigger the settings of the trigger in the bundle, the token and the secrets are not exported
+type ApplicationBundleTrigger struct {
+	Name               string                       `json:"name"`
+	Alias              string                       `json:"alias,omitempty"`
+	Description        string                       `json:"description,omitempty"`
+	WorkflowName       string                       `json:"workflowName"`
+	Type               string                       `json:"type"`
+	PayloadType        string                       `json:"payloadType"`
+	ComponentName      string                       `json:"componentName,omitempty"`
+	Registry           string                       `json:"registry,omitempty"`
+	SignatureType      string                       `json:"signatureType,omitempty"`
+	AllowedCIDRs       []string                     `json:"allowedCIDRs,omitempty"`
+	GitEvents          []string                     `json:"gitEvents,omitempty"`
+	BranchFilters      []string                     `json:"branchFilters,omitempty"`
+	TagFilters         []string                     `json:"tagFilters,omitempty"`
+	PropertiesTemplate string                       `json:"propertiesTemplate,omitempty"`
+	ImageProperties    []model.ImagePropertyMapping `json:"imageProperties,omitempty"`
+	ImageTagFilter     *model.ImageTagFilter        `json:"imageTagFilter,omitempty"`
+	PayloadMapping     *model.PayloadMapping        `json:"payloadMapping,omitempty"`
+}
+
+// ExportApplicationBundleResponse the encoded bundle
+type ExportApplicationBundleResponse struct {
+	FileName string `json:"fileName"`
+	Format   string `json:"format"`
+	// Content is the YAML document, or the base64 encoded tar archive
+	Content string `json:"content"`
+}
+
+// ImportApplicationBundleRequest import the applications from the bundle to the project
+type ImportApplicationBundleRequest struct {
+	Format string `json:"format" optional:"true" validate:"omitempty,oneof=yaml tar"`
+	// Content is the YAML document, or the base64 encoded tar archive
+	Content          string `json:"content" validate:"required"`
+	DryRun           bool   `json:"dryRun,omitempty" optional:"true"`
+	ConflictStrategy string `json:"conflictStrategy,omitempty" optional:"true" validate:"omitempty,oneof=skip overwrite rename"`
+	// EnvMapping maps the envs in the bundle to the envs of the project, the env which is not mapped keeps the name
+	EnvMapping map[string]string `json:"envMapping,omitempty" optional:"true"`
+	// TargetMapping maps the targets in the bundle to the targets of the project, the target which is not mapped keeps the name
+	TargetMapping map[string]string `json:"targetMapping,omitempty" optional:"true"`
+}
+
+// ImportApplicationBundleResponse the result of importing the bundle
+type ImportApplicationBundleResponse struct {
+	DryRun       bool                      `json:"dryRun"`
+	Applications []ImportApplicationResult `json:"applications"`
+}
+
+// ImportApplicationResult the result of importing one application
+type ImportApplicationResult struct {
+	Name string `json:"name"`
+	// ImportName is different from the name if the application is renamed
+	ImportName string `json:"importName"`
+	// Action is one of create, overwrite and skip
+	Action string `json:"action"`
+	// Diff is the unified diff between the existing application and the bundle, only for overwriting
+	Diff string `json:"diff,omitempty"`
 }
 
 // ListProjectResponse list project response body
@@ -828,6 +1454,8 @@ type ProjectBase struct {
 	UpdateTime  time.Time `json:"updateTime"`
 	Owner       NameAlias `json:"owner,omitempty"`
 	Namespace   string    `json:"namespace"`
+	// PromotionPath the ordered envs that the applications are promoted through
+	PromotionPath *model.PromotionPath `json:"promotionPath,omitempty"`
 }
 
 // CreateProjectRequest create project request body
@@ -847,6 +1475,12 @@ type UpdateProjectRequest struct {
 	Owner       string `json:"owner" optional:"true"`
 }
 
+// UpdatePromotionPathRequest set the ordered envs of the project, the path is removed if the envs are empty
+type UpdatePromotionPathRequest struct {
+	Envs       []string `json:"envs" optional:"true"`
+	ForbidSkip bool     `json:"forbidSkip" optional:"true"`
+}
+
 // Env models the data of env in API
 type Env struct {
 	Name        string `json:"name"`
@@ -862,6 +1496,9 @@ type Env struct {
 	// In one project, a delivery target can only belong to one env.
 	Targets []NameAlias `json:"targets,omitempty"  optional:"true"`
 
+	// Protection the deploys of the env must be approved if it is set
+	Protection *model.EnvProtection `json:"protection,omitempty" optional:"true"`
+
 	CreateTime time.Time `json:"createTime"`
 	UpdateTime time.Time `json:"updateTime"`
 }
@@ -905,6 +1542,159 @@ type UpdateEnvRequest struct {
 	Targets []string `json:"targets,omitempty"  optional:"true"`
 }
 
+// UpdateEnvProtectionRequest set the approval rules of the env, the protection is removed if there is no approver
+type UpdateEnvProtectionRequest struct {
+	Approvers     []string `json:"approvers,omitempty" optional:"true"`
+	ApproverRoles []string `json:"approverRoles,omitempty" optional:"true"`
+	MinApprovals  int      `json:"minApprovals,omitempty" optional:"true"`
+}
+
+// FreezeWindowBase the base info of the freeze window
+type FreezeWindowBase struct {
+	Name        string     `json:"name"`
+	Alias       string     `json:"alias,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Project     string     `json:"project,omitempty"`
+	EnvName     string     `json:"envName,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	Duration    string     `json:"duration,omitempty"`
+	Timezone    string     `json:"timezone,omitempty"`
+	StartTime   *time.Time `json:"startTime,omitempty"`
+	EndTime     *time.Time `json:"endTime,omitempty"`
+	Disabled    bool       `json:"disabled"`
+	Creator     string     `json:"creator"`
+	// Active means the deploys are blocked by the window now
+	Active     bool      `json:"active"`
+	CreateTime time.Time `json:"createTime"`
+	UpdateTime time.Time `json:"updateTime"`
+}
+
+// CreateFreezeWindowRequest create a recurring or one-off freeze window
+type CreateFreezeWindowRequest struct {
+	Name                      string `json:"name" validate:"checkname"`
+	UpdateFreezeWindowRequest `json:",inline"`
+}
+
+// UpdateFreezeWindowRequest update the freeze window
+type UpdateFreezeWindowRequest struct {
+	Alias       string `json:"alias,omitempty" validate:"checkalias" optional:"true"`
+	Description string `json:"description,omitempty" optional:"true"`
+	// EnvName the window only blocks the deploys of the env, it is only available for the project windows
+	EnvName string `json:"envName,omitempty" optional:"true"`
+	// Recurrence the cron spec or the RRULE like "RRULE:FREQ=WEEKLY;BYDAY=FR;BYHOUR=18"
+	Recurrence string     `json:"recurrence,omitempty" optional:"true"`
+	Duration   string     `json:"duration,omitempty" optional:"true"`
+	Timezone   string     `json:"timezone,omitempty" optional:"true"`
+	StartTime  *time.Time `json:"startTime,omitempty" optional:"true"`
+	EndTime    *time.Time `json:"endTime,omitempty" optional:"true"`
+	Disabled   bool       `json:"disabled,omitempty" optional:"true"`
+}
+
+// ListFreezeWindowsResponse the freeze windows of the platform or the project
+type ListFreezeWindowsResponse struct {
+	FreezeWindows []*FreezeWindowBase `json:"freezeWindows"`
+}
+
+// NotificationChannelBase the notification channel, the secret and the password are not returned
+type NotificationChannelBase struct {
+	Name        string              `json:"name"`
+	Alias       string              `json:"alias,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Project     string              `json:"project"`
+	Type        string              `json:"type"`
+	URL         string              `json:"url,omitempty"`
+	HasSecret   bool                `json:"hasSecret"`
+	Headers     map[string]string   `json:"headers,omitempty"`
+	Email       *model.EmailChannel `json:"email,omitempty"`
+	Creator     string              `json:"creator"`
+	CreateTime  time.Time           `json:"createTime"`
+	UpdateTime  time.Time           `json:"updateTime"`
+}
+
+// CreateNotificationChannelRequest create a notification channel
+type CreateNotificationChannelRequest struct {
+	Name                             string `json:"name" validate:"checkname"`
+	UpdateNotificationChannelRequest `json:",inline"`
+}
+
+// UpdateNotificationChannelRequest update the notification channel, the existing secret and password are kept if they are empty
+type UpdateNotificationChannelRequest struct {
+	Alias       string              `json:"alias,omitempty" validate:"checkalias" optional:"true"`
+	Description string              `json:"description,omitempty" optional:"true"`
+	Type        string              `json:"type" validate:"oneof=webhook slack dingtalk lark wecom email"`
+	URL         string              `json:"url,omitempty" optional:"true"`
+	Secret      string              `json:"secret,omitempty" optional:"true"`
+	Headers     map[string]string   `json:"headers,omitempty" optional:"true"`
+	Email       *model.EmailChannel `json:"email,omitempty" optional:"true"`
+}
+
+// ListNotificationChannelsResponse the notification channels of the project
+type ListNotificationChannelsResponse struct {
+	Channels []*NotificationChannelBase `json:"channels"`
+}
+
+// NotificationSubscriptionBase the notification subscription
+type NotificationSubscriptionBase struct {
+	Name        string            `json:"name"`
+	Alias       string            `json:"alias,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Project     string            `json:"project"`
+	Channel     string            `json:"channel"`
+	EventTypes  []string          `json:"eventTypes,omitempty"`
+	EnvNames    []string          `json:"envNames,omitempty"`
+	AppSelector map[string]string `json:"appSelector,omitempty"`
+	Template    string            `json:"template,omitempty"`
+	Disabled    bool              `json:"disabled"`
+	Creator     string            `json:"creator"`
+	CreateTime  time.Time         `json:"createTime"`
+	UpdateTime  time.Time         `json:"updateTime"`
+}
+
+// CreateNotificationSubscriptionRequest create a notification subscription
+type CreateNotificationSubscriptionRequest struct {
+	Name                                  string `json:"name" validate:"checkname"`
+	UpdateNotificationSubscriptionRequest `json:",inline"`
+}
+
+// UpdateNotificationSubscriptionRequest update the notification subscription
+type UpdateNotificationSubscriptionRequest struct {
+	Alias       string            `json:"alias,omitempty" validate:"checkalias" optional:"true"`
+	Description string            `json:"description,omitempty" optional:"true"`
+	Channel     string            `json:"channel" validate:"checkname"`
+	EventTypes  []string          `json:"eventTypes,omitempty" optional:"true"`
+	EnvNames    []string          `json:"envNames,omitempty" optional:"true"`
+	AppSelector map[string]string `json:"appSelector,omitempty" optional:"true"`
+	// Template the go template of the message, the fields of the event could be used, such as {{.AppName}}
+	Template string `json:"template,omitempty" optional:"true"`
+	Disabled bool   `json:"disabled,omitempty" optional:"true"`
+}
+
+// ListNotificationSubscriptionsResponse the notification subscriptions of the project
+type ListNotificationSubscriptionsResponse struct {
+	Subscriptions []*NotificationSubscriptionBase `json:"subscriptions"`
+}
+
+// NotificationDeliveryBase the log of sending a notification
+type NotificationDeliveryBase struct {
+	Name         string     `json:"name"`
+	Subscription string     `json:"subscription"`
+	Channel      string     `json:"channel"`
+	EventType    string     `json:"eventType"`
+	Message      string     `json:"message"`
+	Status       string     `json:"status"`
+	Attempts     int        `json:"attempts"`
+	NextTime     time.Time  `json:"nextTime"`
+	Error        string     `json:"error,omitempty"`
+	DeliverTime  *time.Time `json:"deliverTime,omitempty"`
+	CreateTime   time.Time  `json:"createTime"`
+}
+
+// ListNotificationDeliveriesResponse the notification deliveries of the project
+type ListNotificationDeliveriesResponse struct {
+	Deliveries []*NotificationDeliveryBase `json:"deliveries"`
+	Total      int64                       `json:"total"`
+}
+
 // ListDefinitionResponse list definition response model
 type ListDefinitionResponse struct {
 	Definitions []*DefinitionBase `json:"definitions"`
@@ -1173,12 +1963,114 @@ type ApplicationDeployRequest struct {
 	CodeInfo *model.CodeInfo `json:"codeInfo,omitempty"`
 	// ImageInfo is the image code info of this deploy
 	ImageInfo *model.ImageInfo `json:"imageInfo,omitempty"`
+	// ScheduleTime the deploy is executed at the time if it is in the future
+	ScheduleTime *time.Time `json:"scheduleTime,omitempty" optional:"true"`
+	// OverrideFreeze deploy in the active freeze window, it requires the override permission
+	OverrideFreeze bool `json:"overrideFreeze,omitempty" optional:"true"`
+}
+
+// PromoteApplicationRequest promote the configuration of the source env to the target env
+type PromoteApplicationRequest struct {
+	SourceEnv string `json:"sourceEnv" validate:"checkname"`
+	TargetEnv string `json:"targetEnv" validate:"checkname"`
+	// DryRun only return the diff without updating the target env
+	DryRun bool `json:"dryRun,omitempty" optional:"true"`
+	// Deploy the target env after the promotion
+	Deploy bool   `json:"deploy,omitempty" optional:"true"`
+	Note   string `json:"note,omitempty" optional:"true"`
+}
+
+// PromoteApplicationResponse the result of the promotion
+type PromoteApplicationResponse struct {
+	SourceEnv string `json:"sourceEnv"`
+	TargetEnv string `json:"targetEnv"`
+	IsDiff    bool   `json:"isDiff"`
+	// Components the difference from the target env to the source env
+	Components []RevisionDiffEntry        `json:"components"`
+	Deploy     *ApplicationDeployResponse `json:"deploy,omitempty"`
 }
 
 // ApplicationDeployResponse application deploy response body
 type ApplicationDeployResponse struct {
 	ApplicationRevisionBase `json:",inline"`
 	WorkflowRecord          WorkflowRecordBase `json:"record"`
+	// ChangeRequest is set if the env is protected, the deploy is executed after the change request is approved
+	ChangeRequest *ChangeRequestBase `json:"changeRequest,omitempty"`
+}
+
+// ChangeRequestBase the base info of the change request
+type ChangeRequestBase struct {
+	Name         string                      `json:"name"`
+	Project      string                      `json:"project"`
+	EnvName      string                      `json:"envName"`
+	WorkflowName string                      `json:"workflowName"`
+	Requester    string                      `json:"requester"`
+	Note         string                      `json:"note,omitempty"`
+	TriggerType  string                      `json:"triggerType"`
+	Force        bool                        `json:"force,omitempty"`
+	Status       string                      `json:"status"`
+	Reason       string                      `json:"reason,omitempty"`
+	MinApprovals int                         `json:"minApprovals"`
+	Approvals    int                         `json:"approvals"`
+	Reviews      []model.ChangeRequestReview `json:"reviews,omitempty"`
+	Revision     string                      `json:"revision,omitempty"`
+	CodeInfo     *model.CodeInfo             `json:"codeInfo,omitempty"`
+	ImageInfo    *model.ImageInfo            `json:"imageInfo,omitempty"`
+	CreateTime   time.Time                   `json:"createTime"`
+	UpdateTime   time.Time                   `json:"updateTime"`
+}
+
+// DetailChangeRequestResponse the detail of the change request
+type DetailChangeRequestResponse struct {
+	ChangeRequestBase `json:",inline"`
+	ApplyAppConfig    string `json:"applyAppConfig"`
+	// Diff the unified diff from the latest revision of the env
+	Diff string `json:"diff"`
+}
+
+// ListChangeRequestsResponse the change requests of the application
+type ListChangeRequestsResponse struct {
+	ChangeRequests []*ChangeRequestBase `json:"changeRequests"`
+	Total          int64                `json:"total"`
+}
+
+// ReviewChangeRequestRequest approve or reject the change request
+type ReviewChangeRequestRequest struct {
+	Approved bool   `json:"approved"`
+	Comment  string `json:"comment,omitempty" optional:"true"`
+}
+
+// DeployLockBase the lock that blocks the deploys of the application in the env
+type DeployLockBase struct {
+	Name          string     `json:"name"`
+	EnvName       string     `json:"envName"`
+	Owner         *NameAlias `json:"owner"`
+	Reason        string     `json:"reason"`
+	ExpireTime    *time.Time `json:"expireTime,omitempty"`
+	Status        string     `json:"status"`
+	ReleaseUser   *NameAlias `json:"releaseUser,omitempty"`
+	ReleaseReason string     `json:"releaseReason,omitempty"`
+	ReleaseTime   *time.Time `json:"releaseTime,omitempty"`
+	CreateTime    time.Time  `json:"createTime"`
+	UpdateTime    time.Time  `json:"updateTime"`
+}
+
+// CreateDeployLockRequest lock the application in the env
+type CreateDeployLockRequest struct {
+	EnvName    string     `json:"envName" validate:"checkname"`
+	Reason     string     `json:"reason" validate:"required"`
+	ExpireTime *time.Time `json:"expireTime,omitempty" optional:"true"`
+}
+
+// ReleaseDeployLockRequest release the lock, the force option is required to break the lock of another user
+type ReleaseDeployLockRequest struct {
+	Force  bool   `json:"force,omitempty" optional:"true"`
+	Reason string `json:"reason,omitempty" optional:"true"`
+}
+
+// ListDeployLocksResponse the deploy locks of the application
+type ListDeployLocksResponse struct {
+	DeployLocks []*DeployLockBase `json:"deployLocks"`
 }
 
 // ApplicationRollbackResponse the response body that rollback with the revision
@@ -1194,6 +2086,12 @@ type ApplicationDockerhubWebhookResponse struct {
 	TargetURL   string `json:"target_url,omitempty"`
 }
 
+// ApplicationWebhookSkippedResponse the response of the webhook events that are not deployed, such as the filtered events
+type ApplicationWebhookSkippedResponse struct {
+	State       string `json:"state"`
+	Description string `json:"description,omitempty"`
+}
+
 // VelaQLViewResponse query response
 type VelaQLViewResponse map[string]interface{}
 
@@ -1302,6 +2200,8 @@ type ApplicationRevisionBase struct {
 	CodeInfo *model.CodeInfo `json:"codeInfo,omitempty"`
 	// ImageInfo is the image info of this application revision
 	ImageInfo *model.ImageInfo `json:"imageInfo,omitempty"`
+	// ScheduleTime is set if the revision is a scheduled deploy
+	ScheduleTime *time.Time `json:"scheduleTime,omitempty"`
 }
 
 // ListRevisionsResponse list application revisions
@@ -1673,7 +2573,36 @@ type PipelineMeta struct {
 // PipelineBase is the base info of pipeline
 type PipelineBase struct {
 	PipelineMeta `json:",inline"`
-	Spec         model.WorkflowSpec `json:"spec"`
+	Spec         model.WorkflowSpec     `json:"spec"`
+	Schedules    []PipelineScheduleBase `json:"schedules,omitempty"`
+	// Version the current version of the spec
+	Version int64 `json:"version,omitempty"`
+	// Parameters the typed parameters of the runs
+	Parameters []model.PipelineParameter `json:"parameters,omitempty"`
+}
+
+// PipelineSchedule is the cron schedule to run the pipeline
+type PipelineSchedule struct {
+	Name string `json:"name" validate:"checkname"`
+	// Cron the standard cron expression, such as `0 2 * * *`
+	Cron string `json:"cron"`
+	// TimeZone the IANA time zone, such as Asia/Shanghai, UTC by default
+	TimeZone    string `json:"timeZone,omitempty" optional:"true"`
+	ContextName string `json:"contextName,omitempty" optional:"true"`
+	// ConcurrencyPolicy allow, forbid or replace the unfinished runs of the schedule, allow by default
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty" validate:"omitempty,oneof=allow forbid replace" optional:"true"`
+	Suspend           bool   `json:"suspend,omitempty" optional:"true"`
+}
+
+// PipelineScheduleBase is the schedule with the fire times
+type PipelineScheduleBase struct {
+	PipelineSchedule `json:",inline"`
+	// NextFireTime is empty if the schedule is suspended
+	NextFireTime *time.Time `json:"nextFireTime,omitempty"`
+	LastFireTime *time.Time `json:"lastFireTime,omitempty"`
+	LastRunName  string     `json:"lastRunName,omitempty"`
+	// Message why the last fire is skipped or failed
+	Message string `json:"message,omitempty"`
 }
 
 // RunStatInfo is the pipeline run statistics info
@@ -1696,6 +2625,11 @@ type CreatePipelineRequest struct {
 	Alias       string             `json:"alias" validate:"checkalias" optional:"true"`
 	Description string             `json:"description" optional:"true"`
 	Spec        model.WorkflowSpec `json:"spec"`
+	Schedules   []PipelineSchedule `json:"schedules,omitempty" validate:"dive" optional:"true"`
+	// Message describes the initial version of the pipeline
+	Message string `json:"message,omitempty" optional:"true"`
+	// Parameters the typed parameters of the runs
+	Parameters []model.PipelineParameter `json:"parameters,omitempty" optional:"true"`
 }
 
 // PipelineMetaResponse is the response body contains PipelineMeta
@@ -1727,6 +2661,153 @@ type UpdatePipelineRequest struct {
 	Alias       string             `json:"alias" validate:"checkalias" optional:"true"`
 	Description string             `json:"description" optional:"true"`
 	Spec        model.WorkflowSpec `json:"spec" optional:"true"`
+	// Schedules replace the schedules of the pipeline, they are not changed if it is null
+	Schedules []PipelineSchedule `json:"schedules" validate:"dive" optional:"true"`
+	// Message describes the change, it is recorded in the new version if the spec is changed
+	Message string `json:"message,omitempty" optional:"true"`
+	// Parameters replace the parameters of the pipeline, they are not changed if it is null
+	Parameters []model.PipelineParameter `json:"parameters" optional:"true"`
+}
+
+// ValidatePipelineRequest is the request body of validating the pipeline spec
+type ValidatePipelineRequest struct {
+	Spec model.WorkflowSpec `json:"spec"`
+}
+
+// ValidatePipelineResponse is the result of validating the pipeline spec
+type ValidatePipelineResponse struct {
+	Valid  bool                `json:"valid"`
+	Errors []PipelineSpecError `json:"errors"`
+}
+
+// PipelineSpecError is the validation error of a pipeline step
+type PipelineSpecError struct {
+	Step string `json:"step"`
+	// SubStep is set if the error belongs to a sub-step of the step group
+	SubStep string `json:"subStep,omitempty"`
+	// Field is the invalid field, such as type, properties.url, dependsOn, inputs, if and timeout
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// PipelineVersionBase is the base info of the pipeline version
+type PipelineVersionBase struct {
+	Version    int64     `json:"version"`
+	Author     string    `json:"author"`
+	Message    string    `json:"message"`
+	CreateTime time.Time `json:"createTime"`
+	// RestoredFrom the version restored by this version
+	RestoredFrom int64 `json:"restoredFrom,omitempty"`
+}
+
+// PipelineVersionDetail is the pipeline version with the spec
+type PipelineVersionDetail struct {
+	PipelineVersionBase `json:",inline"`
+	Spec                model.WorkflowSpec        `json:"spec"`
+	Parameters          []model.PipelineParameter `json:"parameters,omitempty"`
+}
+
+// ListPipelineVersionsResponse is the response body of listing pipeline versions, the latest version is the first
+type ListPipelineVersionsResponse struct {
+	Versions []PipelineVersionBase `json:"versions"`
+	Total    int64                 `json:"total"`
+}
+
+// ComparePipelineVersionsRequest compare two versions of the pipeline
+type ComparePipelineVersionsRequest struct {
+	BaseVersion   int64 `json:"baseVersion" validate:"required"`
+	TargetVersion int64 `json:"targetVersion" validate:"required"`
+}
+
+// ComparePipelineVersionsResponse the diff between two versions of the pipeline
+type ComparePipelineVersionsResponse struct {
+	BaseVersion   int64               `json:"baseVersion"`
+	TargetVersion int64               `json:"targetVersion"`
+	IsDiff        bool                `json:"isDiff"`
+	Steps         []RevisionDiffEntry `json:"steps"`
+	// UnifiedDiff the unified diff of the pipeline spec
+	UnifiedDiff string `json:"unifiedDiff"`
+}
+
+// RestorePipelineVersionRequest is the request body of restoring the pipeline spec from a version
+type RestorePipelineVersionRequest struct {
+	Message string `json:"message,omitempty" optional:"true"`
+}
+
+// CreatePipelineTriggerRequest is the request body of creating the pipeline trigger
+type CreatePipelineTriggerRequest struct {
+	Name        string `json:"name" validate:"checkname"`
+	Alias       string `json:"alias" validate:"checkalias" optional:"true"`
+	Description string `json:"description" optional:"true"`
+	// Type webhook or pipeline
+	Type string `json:"type" validate:"oneof=webhook pipeline"`
+	PipelineTriggerSpec
+}
+
+// UpdatePipelineTriggerRequest is the request body of updating the pipeline trigger
+type UpdatePipelineTriggerRequest struct {
+	Alias       string `json:"alias" validate:"checkalias" optional:"true"`
+	Description string `json:"description" optional:"true"`
+	PipelineTriggerSpec
+}
+
+// PipelineTriggerSpec is the settings of the pipeline trigger.
+// The values of the parameters and the context are the JSONPath expressions evaluated on the payload, or the literals.
+// The payload contains the raw request body as `body` and the event parsed by the provider as `event`,
+// the event of the pipeline trigger is the completed source run.
+type PipelineTriggerSpec struct {
+	// PayloadType the provider of the webhook requests, it is required by the webhook trigger
+	PayloadType string `json:"payloadType,omitempty" validate:"omitempty,checkpayloadtype" optional:"true"`
+	Registry    string `json:"registry,omitempty" optional:"true"`
+	// Secret the shared secret to verify the requests, the existing secret is kept if it is empty when updating
+	Secret        string   `json:"secret,omitempty" optional:"true"`
+	SignatureType string   `json:"signatureType,omitempty" validate:"omitempty,oneof=github gitlab harbor hmac" optional:"true"`
+	AllowedCIDRs  []string `json:"allowedCIDRs,omitempty" optional:"true"`
+	GitEvents     []string `json:"gitEvents,omitempty" validate:"dive,oneof=push tag mergeRequest" optional:"true"`
+	BranchFilters []string `json:"branchFilters,omitempty" optional:"true"`
+	TagFilters    []string `json:"tagFilters,omitempty" optional:"true"`
+	// ImageTagFilter the pushed image tags to handle, it is used by the image registry payload types
+	ImageTagFilter *model.ImageTagFilter `json:"imageTagFilter,omitempty" optional:"true"`
+	// SourcePipeline the pipeline in the same project, it is required by the pipeline trigger
+	SourcePipeline string `json:"sourcePipeline,omitempty" optional:"true"`
+	// SourcePhases the phases of the completed source runs, succeeded by default
+	SourcePhases  []string                 `json:"sourcePhases,omitempty" validate:"dive,oneof=succeeded failed terminated" optional:"true"`
+	ContextName   string                   `json:"contextName,omitempty" optional:"true"`
+	Conditions    []model.PayloadCondition `json:"conditions,omitempty" optional:"true"`
+	Parameters    map[string]string        `json:"parameters,omitempty" optional:"true"`
+	ContextValues map[string]string        `json:"contextValues,omitempty" optional:"true"`
+}
+
+// PipelineTriggerBase is the pipeline trigger, the secret is not returned
+type PipelineTriggerBase struct {
+	Name           string                   `json:"name"`
+	Alias          string                   `json:"alias,omitempty"`
+	Description    string                   `json:"description,omitempty"`
+	Type           string                   `json:"type"`
+	Token          string                   `json:"token"`
+	PayloadType    string                   `json:"payloadType,omitempty"`
+	Registry       string                   `json:"registry,omitempty"`
+	HasSecret      bool                     `json:"hasSecret"`
+	SignatureType  string                   `json:"signatureType,omitempty"`
+	AllowedCIDRs   []string                 `json:"allowedCIDRs,omitempty"`
+	GitEvents      []string                 `json:"gitEvents,omitempty"`
+	BranchFilters  []string                 `json:"branchFilters,omitempty"`
+	TagFilters     []string                 `json:"tagFilters,omitempty"`
+	ImageTagFilter *model.ImageTagFilter    `json:"imageTagFilter,omitempty"`
+	SourcePipeline string                   `json:"sourcePipeline,omitempty"`
+	SourcePhases   []string                 `json:"sourcePhases,omitempty"`
+	ContextName    string                   `json:"contextName,omitempty"`
+	Conditions     []model.PayloadCondition `json:"conditions,omitempty"`
+	Parameters     map[string]string        `json:"parameters,omitempty"`
+	ContextValues  map[string]string        `json:"contextValues,omitempty"`
+	Creator        string                   `json:"creator,omitempty"`
+	CreateTime     time.Time                `json:"createTime"`
+	UpdateTime     time.Time                `json:"updateTime"`
+}
+
+// ListPipelineTriggersResponse is the response body of listing the pipeline triggers
+type ListPipelineTriggersResponse struct {
+	Triggers []PipelineTriggerBase `json:"triggers"`
 }
 
 // GetPipelineResponse is the response body of getting pipeline
@@ -1755,6 +2836,14 @@ type PipelineRunBriefing struct {
 	EndTime         metav1.Time                       `json:"endTime"`
 	ContextName     string                            `json:"contextName"`
 	ContextValues   []model.Value                     `json:"contextValues"`
+	// Schedule the name of the schedule that starts the run
+	Schedule string `json:"schedule,omitempty"`
+	// Trigger the name of the trigger that starts the run
+	Trigger string `json:"trigger,omitempty"`
+	// PipelineVersion the version of the pipeline spec used by the run
+	PipelineVersion int64 `json:"pipelineVersion,omitempty"`
+	// Parameters the effective parameters of the run
+	Parameters []PipelineRunParameter `json:"parameters,omitempty"`
 }
 
 // PipelineRunMeta is the metadata of pipeline run
@@ -1774,10 +2863,37 @@ type PipelineRun struct {
 type PipelineRunBase struct {
 	PipelineRunMeta `json:",inline"`
 	// Record marks the run of the pipeline
-	Record        int64                            `json:"record"`
-	ContextName   string                           `json:"contextName"`
-	ContextValues []model.Value                    `json:"contextValues"`
-	Spec          workflowv1alpha1.WorkflowRunSpec `json:"spec"`
+	Record        int64         `json:"record"`
+	ContextName   string        `json:"contextName"`
+	ContextValues []model.Value `json:"contextValues"`
+	// Schedule the name of the schedule that starts the run
+	Schedule string `json:"schedule,omitempty"`
+	// Trigger the name of the trigger that starts the run
+	Trigger string `json:"trigger,omitempty"`
+	// PipelineVersion the version of the pipeline spec used by the run
+	PipelineVersion int64 `json:"pipelineVersion,omitempty"`
+	// Parameters the effective parameters of the run
+	Parameters []PipelineRunParameter           `json:"parameters,omitempty"`
+	Spec       workflowv1alpha1.WorkflowRunSpec `json:"spec"`
+}
+
+const (
+	// ParameterSourceValue the parameter is set by the run request
+	ParameterSourceValue = "value"
+	// ParameterSourceContext the parameter is set by the context of the run
+	ParameterSourceContext = "context"
+	// ParameterSourceDefault the parameter is set by the default value
+	ParameterSourceDefault = "default"
+)
+
+// PipelineRunParameter is the effective parameter of the pipeline run
+type PipelineRunParameter struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Value the value of the secret-ref parameter is the reference, the secret value is never returned
+	Value interface{} `json:"value"`
+	// Source where the value comes from, value, context or default
+	Source string `json:"source"`
 }
 
 // RunPipelineRequest is the request body of running pipeline
@@ -1786,6 +2902,10 @@ type RunPipelineRequest struct {
 	// default: "StepByStep" for `step`, "DAG" for `subStep`
 	Mode        workflowv1alpha1.WorkflowExecuteMode `json:"mode" optional:"true"`
 	ContextName string                               `json:"contextName"`
+	// OverrideFreeze run the pipeline in the active freeze window, it requires the override permission
+	OverrideFreeze bool `json:"overrideFreeze,omitempty" optional:"true"`
+	// Parameters the values of the pipeline parameters, they are merged over the context values
+	Parameters map[string]interface{} `json:"parameters,omitempty" optional:"true"`
 }
 
 // ListPipelineRunResponse is the response body of listing pipeline run
@@ -1809,6 +2929,8 @@ type GetPipelineRunOutputResponse struct {
 // GetPipelineRunInputResponse is the response body of getting pipeline run input
 type GetPipelineRunInputResponse struct {
 	StepInputs []StepInputBase `json:"inputs"`
+	// Parameters the parameters of the run, they are the inputs of all steps from the run context
+	Parameters []PipelineRunParameter `json:"parameters,omitempty"`
 }
 
 // StepBase is the base info of step
@@ -1939,3 +3061,30 @@ type InstallPluginRequest struct {
 	Disable bool                   `json:"disable,omitempty"`
 	Options *velacommon.HTTPOption `json:"options,omitempty"`
 }
+
+const (
+	// RecycleBinItemTypeApplication the application in the recycle bin
+	RecycleBinItemTypeApplication = "application"
+	// RecycleBinItemTypeProject the project in the recycle bin
+	RecycleBinItemTypeProject = "project"
+	// RecycleBinItemTypePipeline the pipeline in the recycle bin
+	RecycleBinItemTypePipeline = "pipeline"
+)
+
+// RecycleBinItem the deleted application, project or pipeline
+type RecycleBinItem struct {
+	Type        string    `json:"type"`
+	Name        string    `json:"name"`
+	Alias       string    `json:"alias,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Project     NameAlias `json:"project"`
+	Deleter     string    `json:"deleter"`
+	DeleteTime  time.Time `json:"deleteTime"`
+	ExpireTime  time.Time `json:"expireTime"`
+}
+
+// ListRecycleBinItemsResponse the response of listing the recycle bin
+type ListRecycleBinItemsResponse struct {
+	Items []RecycleBinItem `json:"items"`
+	Total int64            `json:"total"`
+}
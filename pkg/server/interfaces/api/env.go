@@ -75,6 +75,16 @@ func (n *env) GetWebServiceRoute() *restful.WebService {
 		Returns(200, "OK", apis.Env{}).
 		Writes(apis.Env{}))
 
+	ws.Route(ws.PUT("/{envName}/protection").To(n.updateProtection).
+		Doc("update the approval rules of deploying the applications to the env").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(n.RBACService.CheckPerm("environment", "update")).
+		Param(ws.PathParameter("envName", "identifier of the environment").DataType("string")).
+		Reads(apis.UpdateEnvProtectionRequest{}).
+		Returns(200, "OK", apis.Env{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.Env{}))
+
 	ws.Route(ws.DELETE("/{envName}").To(n.delete).
 		Operation("envdelete").
 		Doc("delete one env").
@@ -184,3 +194,25 @@ func (n *env) update(req *restful.Request, res *restful.Response) {
 		return
 	}
 }
+
+func (n *env) updateProtection(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var updateReq apis.UpdateEnvProtectionRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	env, err := n.EnvService.UpdateEnvProtection(req.Request.Context(), req.PathParameter("envName"), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(env); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+	"github.com/kubevela/pkg/util/slices"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/model"
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+)
+
+const (
+	// eventHeartbeatInterval the interval of the comments that keep the stream alive
+	eventHeartbeatInterval = 30 * time.Second
+	// eventPermissionCacheTime how long the permission check result of a resource is cached in the stream
+	eventPermissionCacheTime = time.Minute
+)
+
+// NewEvent new the domain event API
+func NewEvent() Interface {
+	return &event{}
+}
+
+type event struct {
+	EventBus    service.EventBus    `inject:""`
+	RbacService service.RBACService `inject:""`
+}
+
+// GetWebServiceRoute get web service
+func (e *event) GetWebServiceRoute() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/events").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for the domain events")
+
+	tags := []string{"event"}
+
+	ws.Route(ws.GET("/stream").To(e.streamEvents).
+		Doc("stream the domain events by SSE, only the events of the resources that the user could list are sent").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.QueryParameter("type", "filter by the event types, separated by comma").DataType("string")).
+		Param(ws.QueryParameter("project", "filter by the project").DataType("string")).
+		Produces("text/event-stream").
+		Returns(200, "OK", model.DomainEvent{}).
+		Writes(model.DomainEvent{}))
+
+	ws.Filter(authCheckFilter)
+	return ws
+}
+
+type eventPermission struct {
+	allowed   bool
+	checkTime time.Time
+}
+
+func (e *event) streamEvents(req *restful.Request, res *restful.Response) {
+	ctx := req.Request.Context()
+	var types []string
+	if req.QueryParameter("type") != "" {
+		types = strings.Split(req.QueryParameter("type"), ",")
+	}
+	project := req.QueryParameter("project")
+	events := e.EventBus.Watch(ctx)
+	permissions := map[string]eventPermission{}
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	sse := NewSSEWriter(res.ResponseWriter)
+	if err := sse.SendComment("connected"); err != nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if err := sse.SendComment("ping"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if len(types) > 0 && !slices.Contains(types, event.Type) {
+				continue
+			}
+			if project != "" && event.Project != project {
+				continue
+			}
+			if !e.canSeeEvent(ctx, event, permissions) {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				klog.Errorf("failed to marshal the event %s: %s", event.ID, err.Error())
+				continue
+			}
+			if err := sse.SendEvent(event.ID, event.Type, data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// canSeeEvent check whether the user could list the resource of the event, the result is cached for a while
+func (e *event) canSeeEvent(ctx context.Context, event *model.DomainEvent, permissions map[string]eventPermission) bool {
+	key := event.Project + "/" + event.Resource
+	if permission, exist := permissions[key]; exist && time.Since(permission.checkTime) < eventPermissionCacheTime {
+		return permission.allowed
+	}
+	allowed := e.RbacService.CheckProjectPerm(ctx, event.Project, event.Resource, "list") == nil
+	permissions[key] = eventPermission{allowed: allowed, checkTime: time.Now()}
+	return allowed
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// NewFreezeWindow new the platform freeze window API
+func NewFreezeWindow() Interface {
+	return &freezeWindow{}
+}
+
+type freezeWindow struct {
+	FreezeWindowService service.FreezeWindowService `inject:""`
+	RbacService         service.RBACService         `inject:""`
+}
+
+// GetWebServiceRoute get web service
+func (f *freezeWindow) GetWebServiceRoute() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/freeze_windows").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for the freeze windows of the platform")
+
+	tags := []string{"freezeWindow"}
+
+	ws.Route(ws.GET("/").To(f.listFreezeWindows).
+		Doc("list the freeze windows of the platform").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(f.RbacService.CheckPerm("platformFreezeWindow", "list")).
+		Returns(200, "OK", apis.ListFreezeWindowsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListFreezeWindowsResponse{}))
+
+	ws.Route(ws.POST("/").To(f.createFreezeWindow).
+		Doc("create a freeze window that blocks the deploys of all projects").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(f.RbacService.CheckPerm("platformFreezeWindow", "create")).
+		Reads(apis.CreateFreezeWindowRequest{}).
+		Returns(200, "OK", apis.FreezeWindowBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.FreezeWindowBase{}))
+
+	ws.Route(ws.PUT("/{freezeWindowName}").To(f.updateFreezeWindow).
+		Doc("update the freeze window of the platform").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(f.RbacService.CheckPerm("platformFreezeWindow", "update")).
+		Param(ws.PathParameter("freezeWindowName", "identifier of the freeze window").DataType("string")).
+		Reads(apis.UpdateFreezeWindowRequest{}).
+		Returns(200, "OK", apis.FreezeWindowBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.FreezeWindowBase{}))
+
+	ws.Route(ws.DELETE("/{freezeWindowName}").To(f.deleteFreezeWindow).
+		Doc("delete the freeze window of the platform").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(f.RbacService.CheckPerm("platformFreezeWindow", "delete")).
+		Param(ws.PathParameter("freezeWindowName", "identifier of the freeze window").DataType("string")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Filter(authCheckFilter)
+	return ws
+}
+
+func (f *freezeWindow) listFreezeWindows(req *restful.Request, res *restful.Response) {
+	listFreezeWindows(f.FreezeWindowService, "", req, res)
+}
+
+func (f *freezeWindow) createFreezeWindow(req *restful.Request, res *restful.Response) {
+	createFreezeWindow(f.FreezeWindowService, "", req, res)
+}
+
+func (f *freezeWindow) updateFreezeWindow(req *restful.Request, res *restful.Response) {
+	updateFreezeWindow(f.FreezeWindowService, "", req, res)
+}
+
+func (f *freezeWindow) deleteFreezeWindow(req *restful.Request, res *restful.Response) {
+	deleteFreezeWindow(f.FreezeWindowService, "", req, res)
+}
+
+// the handlers are shared by the platform and the project freeze windows, the project is empty for the platform windows
+
+func listFreezeWindows(freezeWindowService service.FreezeWindowService, projectName string, req *restful.Request, res *restful.Response) {
+	windows, err := freezeWindowService.ListFreezeWindows(req.Request.Context(), projectName)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(windows); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func createFreezeWindow(freezeWindowService service.FreezeWindowService, projectName string, req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateFreezeWindowRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	window, err := freezeWindowService.CreateFreezeWindow(req.Request.Context(), projectName, createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(window); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func updateFreezeWindow(freezeWindowService service.FreezeWindowService, projectName string, req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var updateReq apis.UpdateFreezeWindowRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	window, err := freezeWindowService.UpdateFreezeWindow(req.Request.Context(), projectName, req.PathParameter("freezeWindowName"), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(window); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func deleteFreezeWindow(freezeWindowService service.FreezeWindowService, projectName string, req *restful.Request, res *restful.Response) {
+	if err := freezeWindowService.DeleteFreezeWindow(req.Request.Context(), projectName, req.PathParameter("freezeWindowName")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
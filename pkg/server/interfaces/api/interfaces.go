@@ -68,6 +68,9 @@ func InitAPIBean() []interface{} {
 	RegisterAPI(NewProject())
 	RegisterAPI(NewEnv())
 	RegisterAPI(NewPipeline())
+	RegisterAPI(NewRecycleBin())
+	RegisterAPI(NewFreezeWindow())
+	RegisterAPI(NewEvent())
 
 	// Extension
 	RegisterAPI(NewDefinition())
@@ -41,6 +41,10 @@ const (
 	PipelineRun string = "runName"
 	// ContextName is the context name of query param
 	ContextName string = "contextName"
+	// PipelineVersion is the pipeline version of query param
+	PipelineVersion string = "version"
+	// PipelineTrigger is the pipeline trigger name of query param
+	PipelineTrigger string = "triggerName"
 )
 
 func initPipelineRoutes(ws *restful.WebService, n *project) {
@@ -73,6 +77,14 @@ func initPipelineRoutes(ws *restful.WebService, n *project) {
 		Filter(n.RBACService.CheckPerm("project/pipeline", "create")).
 		Writes(apis.PipelineBase{}).Do(meta, projParam))
 
+	ws.Route(ws.POST("/{projectName}/pipelines/validate").To(n.validatePipeline).
+		Doc("validate the pipeline spec").
+		Reads(apis.ValidatePipelineRequest{}).
+		Returns(200, "OK", apis.ValidatePipelineResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "detail")).
+		Writes(apis.ValidatePipelineResponse{}).Do(meta, projParam))
+
 	ws.Route(ws.GET("/{projectName}/pipelines/{pipelineName}").To(n.getPipeline).
 		Doc("get pipeline").
 		Returns(200, "OK", apis.GetPipelineResponse{}).
@@ -97,6 +109,70 @@ func initPipelineRoutes(ws *restful.WebService, n *project) {
 		Filter(n.RBACService.CheckPerm("project/pipeline", "delete")).
 		Writes(apis.PipelineMetaResponse{}).Do(meta, projParam, pipelineParam))
 
+	ws.Route(ws.GET("/{projectName}/pipelines/{pipelineName}/versions").To(n.listPipelineVersions).
+		Doc("list pipeline versions").
+		Returns(200, "OK", apis.ListPipelineVersionsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "detail")).
+		Writes(apis.ListPipelineVersionsResponse{}).Do(meta, projParam, pipelineParam))
+
+	ws.Route(ws.POST("/{projectName}/pipelines/{pipelineName}/versions/compare").To(n.comparePipelineVersions).
+		Doc("compare two pipeline versions").
+		Reads(apis.ComparePipelineVersionsRequest{}).
+		Returns(200, "OK", apis.ComparePipelineVersionsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "detail")).
+		Writes(apis.ComparePipelineVersionsResponse{}).Do(meta, projParam, pipelineParam))
+
+	ws.Route(ws.GET("/{projectName}/pipelines/{pipelineName}/versions/{version}").To(n.getPipelineVersion).
+		Doc("get pipeline version").
+		Param(ws.PathParameter(PipelineVersion, "pipeline version").DataType("integer").Required(true)).
+		Returns(200, "OK", apis.PipelineVersionDetail{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "detail")).
+		Writes(apis.PipelineVersionDetail{}).Do(meta, projParam, pipelineParam))
+
+	ws.Route(ws.POST("/{projectName}/pipelines/{pipelineName}/versions/{version}/restore").To(n.restorePipelineVersion).
+		Doc("restore the pipeline spec from the version").
+		Param(ws.PathParameter(PipelineVersion, "pipeline version").DataType("integer").Required(true)).
+		Reads(apis.RestorePipelineVersionRequest{}).
+		Returns(200, "OK", apis.PipelineBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "update")).
+		Writes(apis.PipelineBase{}).Do(meta, projParam, pipelineParam))
+
+	ws.Route(ws.GET("/{projectName}/pipelines/{pipelineName}/triggers").To(n.listPipelineTriggers).
+		Doc("list pipeline triggers").
+		Returns(200, "OK", apis.ListPipelineTriggersResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "detail")).
+		Writes(apis.ListPipelineTriggersResponse{}).Do(meta, projParam, pipelineParam))
+
+	ws.Route(ws.POST("/{projectName}/pipelines/{pipelineName}/triggers").To(n.createPipelineTrigger).
+		Doc("create pipeline trigger").
+		Reads(apis.CreatePipelineTriggerRequest{}).
+		Returns(200, "OK", apis.PipelineTriggerBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "update")).
+		Writes(apis.PipelineTriggerBase{}).Do(meta, projParam, pipelineParam))
+
+	ws.Route(ws.PUT("/{projectName}/pipelines/{pipelineName}/triggers/{triggerName}").To(n.updatePipelineTrigger).
+		Doc("update pipeline trigger").
+		Param(ws.PathParameter(PipelineTrigger, "pipeline trigger name").Required(true)).
+		Reads(apis.UpdatePipelineTriggerRequest{}).
+		Returns(200, "OK", apis.PipelineTriggerBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "update")).
+		Writes(apis.PipelineTriggerBase{}).Do(meta, projParam, pipelineParam))
+
+	ws.Route(ws.DELETE("/{projectName}/pipelines/{pipelineName}/triggers/{triggerName}").To(n.deletePipelineTrigger).
+		Doc("delete pipeline trigger").
+		Param(ws.PathParameter(PipelineTrigger, "pipeline trigger name").Required(true)).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Filter(n.RBACService.CheckPerm("project/pipeline", "update")).
+		Writes(apis.EmptyResponse{}).Do(meta, projParam, pipelineParam))
+
 	ws.Route(ws.POST("/{projectName}/pipelines/{pipelineName}/contexts").To(n.createContextValue).
 		Doc("create pipeline context values").
 		Reads(apis.CreateContextValuesRequest{}).
@@ -321,6 +397,23 @@ func (n *project) createPipeline(req *restful.Request, res *restful.Response) {
 	}
 }
 
+func (n *project) validatePipeline(req *restful.Request, res *restful.Response) {
+	var validateReq apis.ValidatePipelineRequest
+	if err := req.ReadEntity(&validateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	result, err := n.PipelineService.ValidatePipelineSpec(req.Request.Context(), validateReq.Spec)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(result); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (n *project) updatePipeline(req *restful.Request, res *restful.Response) {
 	var updateReq apis.UpdatePipelineRequest
 	if err := req.ReadEntity(&updateReq); err != nil {
@@ -358,6 +451,157 @@ func (n *project) deletePipeline(req *restful.Request, res *restful.Response) {
 	}
 }
 
+func (n *project) listPipelineVersions(req *restful.Request, res *restful.Response) {
+	pipeline := req.Request.Context().Value(&apis.CtxKeyPipeline).(apis.PipelineBase)
+	versions, err := n.PipelineService.ListPipelineVersions(req.Request.Context(), pipeline)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(versions); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) comparePipelineVersions(req *restful.Request, res *restful.Response) {
+	var compareReq apis.ComparePipelineVersionsRequest
+	if err := req.ReadEntity(&compareReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&compareReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	pipeline := req.Request.Context().Value(&apis.CtxKeyPipeline).(apis.PipelineBase)
+	diff, err := n.PipelineService.ComparePipelineVersions(req.Request.Context(), pipeline, compareReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(diff); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) getPipelineVersion(req *restful.Request, res *restful.Response) {
+	version, err := strconv.ParseInt(req.PathParameter(PipelineVersion), 10, 64)
+	if err != nil {
+		bcode.ReturnError(req, res, bcode.ErrPipelineVersionNotExist)
+		return
+	}
+	pipeline := req.Request.Context().Value(&apis.CtxKeyPipeline).(apis.PipelineBase)
+	detail, err := n.PipelineService.GetPipelineVersion(req.Request.Context(), pipeline, version)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(detail); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) restorePipelineVersion(req *restful.Request, res *restful.Response) {
+	version, err := strconv.ParseInt(req.PathParameter(PipelineVersion), 10, 64)
+	if err != nil {
+		bcode.ReturnError(req, res, bcode.ErrPipelineVersionNotExist)
+		return
+	}
+	var restoreReq apis.RestorePipelineVersionRequest
+	if req.Request.ContentLength > 0 {
+		if err := req.ReadEntity(&restoreReq); err != nil {
+			bcode.ReturnError(req, res, err)
+			return
+		}
+	}
+	pipeline := req.Request.Context().Value(&apis.CtxKeyPipeline).(apis.PipelineBase)
+	pipelineBase, err := n.PipelineService.RestorePipelineVersion(req.Request.Context(), pipeline, version, restoreReq)
+	if err != nil {
+		klog.Errorf("restore pipeline version failure %s", err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(pipelineBase); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) listPipelineTriggers(req *restful.Request, res *restful.Response) {
+	pipeline := req.Request.Context().Value(&apis.CtxKeyPipeline).(apis.PipelineBase)
+	triggers, err := n.PipelineService.ListPipelineTriggers(req.Request.Context(), pipeline)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(triggers); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) createPipelineTrigger(req *restful.Request, res *restful.Response) {
+	var createReq apis.CreatePipelineTriggerRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	pipeline := req.Request.Context().Value(&apis.CtxKeyPipeline).(apis.PipelineBase)
+	trigger, err := n.PipelineService.CreatePipelineTrigger(req.Request.Context(), pipeline, createReq)
+	if err != nil {
+		klog.Errorf("create pipeline trigger failure %s", err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(trigger); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) updatePipelineTrigger(req *restful.Request, res *restful.Response) {
+	var updateReq apis.UpdatePipelineTriggerRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	pipeline := req.Request.Context().Value(&apis.CtxKeyPipeline).(apis.PipelineBase)
+	trigger, err := n.PipelineService.UpdatePipelineTrigger(req.Request.Context(), pipeline, req.PathParameter(PipelineTrigger), updateReq)
+	if err != nil {
+		klog.Errorf("update pipeline trigger failure %s", err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(trigger); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) deletePipelineTrigger(req *restful.Request, res *restful.Response) {
+	pipeline := req.Request.Context().Value(&apis.CtxKeyPipeline).(apis.PipelineBase)
+	if err := n.PipelineService.DeletePipelineTrigger(req.Request.Context(), pipeline, req.PathParameter(PipelineTrigger)); err != nil {
+		klog.Errorf("delete pipeline trigger failure %s", err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (n *project) runPipeline(req *restful.Request, res *restful.Response) {
 	var runReq apis.RunPipelineRequest
 	pipeline := req.Request.Context().Value(&apis.CtxKeyPipeline).(apis.PipelineBase)
@@ -551,7 +795,13 @@ func (n *project) createContextValue(req *restful.Request, res *restful.Response
 		bcode.ReturnError(req, res, err)
 		return
 	}
-	if err := res.WriteEntity(pipelineCtx); err != nil {
+	// the secret values are masked in the response
+	savedCtx, err := n.ContextService.GetContext(req.Request.Context(), pipeline.Project.Name, pipeline.Name, pipelineCtx.Name)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(savedCtx); err != nil {
 		bcode.ReturnError(req, res, err)
 		return
 	}
@@ -576,7 +826,13 @@ func (n *project) updateContextValue(req *restful.Request, res *restful.Response
 		bcode.ReturnError(req, res, err)
 		return
 	}
-	if err := res.WriteEntity(pipelineCtx); err != nil {
+	// the secret values are masked in the response
+	savedCtx, err := n.ContextService.GetContext(req.Request.Context(), pipeline.Project.Name, pipeline.Name, pipelineCtx.Name)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(savedCtx); err != nil {
 		bcode.ReturnError(req, res, err)
 		return
 	}
@@ -30,14 +30,19 @@ import (
 )
 
 type project struct {
-	RbacService        service.RBACService        `inject:""`
-	ProjectService     service.ProjectService     `inject:""`
-	TargetService      service.TargetService      `inject:""`
-	ConfigService      service.ConfigService      `inject:""`
-	PipelineService    service.PipelineService    `inject:""`
-	PipelineRunService service.PipelineRunService `inject:""`
-	ContextService     service.ContextService     `inject:""`
-	RBACService        service.RBACService        `inject:""`
+	RbacService           service.RBACService           `inject:""`
+	ProjectService        service.ProjectService        `inject:""`
+	TargetService         service.TargetService         `inject:""`
+	ConfigService         service.ConfigService         `inject:""`
+	PipelineService       service.PipelineService       `inject:""`
+	PipelineRunService    service.PipelineRunService    `inject:""`
+	ContextService        service.ContextService        `inject:""`
+	RBACService           service.RBACService           `inject:""`
+	RecycleBinService     service.RecycleBinService     `inject:""`
+	ApplicationService    service.ApplicationService    `inject:""`
+	FreezeWindowService   service.FreezeWindowService   `inject:""`
+	NotificationService   service.NotificationService   `inject:""`
+	DeliveryMetricService service.DeliveryMetricService `inject:""`
 }
 
 // NewProject new project
@@ -86,6 +91,16 @@ func (n *project) GetWebServiceRoute() *restful.WebService {
 		Returns(200, "OK", apis.ProjectBase{}).
 		Writes(apis.ProjectBase{}))
 
+	ws.Route(ws.PUT("/{projectName}/promotion_path").To(n.updatePromotionPath).
+		Doc("update the ordered envs that the applications are promoted through").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project", "update")).
+		Reads(apis.UpdatePromotionPathRequest{}).
+		Returns(200, "OK", apis.ProjectBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ProjectBase{}))
+
 	ws.Route(ws.DELETE("/{projectName}").To(n.deleteProject).
 		Doc("delete a project").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -316,6 +331,222 @@ func (n *project) GetWebServiceRoute() *restful.WebService {
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.ListTerraformProviderResponse{}))
 
+	ws.Route(ws.GET("/{projectName}/recycle_bin").To(n.listRecycleBinItems).
+		Doc("list the deleted applications and pipelines of a project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.QueryParameter("type", "list the items by type, support application and pipeline").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/recycleBin", "list")).
+		Returns(200, "OK", apis.ListRecycleBinItemsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListRecycleBinItemsResponse{}))
+
+	ws.Route(ws.POST("/{projectName}/recycle_bin/{itemType}/{itemName}/restore").To(n.restoreRecycleBinItem).
+		Doc("restore a deleted application or pipeline before it expires").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("itemType", "the type of the item, support application and pipeline").DataType("string")).
+		Param(ws.PathParameter("itemName", "the name of the item").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/recycleBin", "restore")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.DELETE("/{projectName}/recycle_bin/{itemType}/{itemName}").To(n.purgeRecycleBinItem).
+		Doc("delete a deleted application or pipeline permanently").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("itemType", "the type of the item, support application and pipeline").DataType("string")).
+		Param(ws.PathParameter("itemName", "the name of the item").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/recycleBin", "delete")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.GET("/{projectName}/delivery_metrics").To(n.projectDeliveryMetrics).
+		Doc("get the delivery metrics of all applications in the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.QueryParameter("envName", "query identifier of the env").DataType("string")).
+		Param(ws.QueryParameter("days", "the days of the window, default is 30").DataType("integer")).
+		Filter(n.RbacService.CheckPerm("project", "detail")).
+		Returns(200, "OK", apis.DeliveryMetrics{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.DeliveryMetrics{}))
+
+	ws.Route(ws.GET("/{projectName}/freeze_windows").To(n.listFreezeWindows).
+		Doc("list the freeze windows of the project and its envs").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/freezeWindow", "list")).
+		Returns(200, "OK", apis.ListFreezeWindowsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListFreezeWindowsResponse{}))
+
+	ws.Route(ws.POST("/{projectName}/freeze_windows").To(n.createFreezeWindow).
+		Doc("create a freeze window of the project or an env").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/freezeWindow", "create")).
+		Reads(apis.CreateFreezeWindowRequest{}).
+		Returns(200, "OK", apis.FreezeWindowBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.FreezeWindowBase{}))
+
+	ws.Route(ws.PUT("/{projectName}/freeze_windows/{freezeWindowName}").To(n.updateFreezeWindow).
+		Doc("update the freeze window of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("freezeWindowName", "identifier of the freeze window").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/freezeWindow", "update")).
+		Reads(apis.UpdateFreezeWindowRequest{}).
+		Returns(200, "OK", apis.FreezeWindowBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.FreezeWindowBase{}))
+
+	ws.Route(ws.DELETE("/{projectName}/freeze_windows/{freezeWindowName}").To(n.deleteFreezeWindow).
+		Doc("delete the freeze window of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("freezeWindowName", "identifier of the freeze window").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/freezeWindow", "delete")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.GET("/{projectName}/notification_channels").To(n.listNotificationChannels).
+		Doc("list the notification channels of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/notificationChannel", "list")).
+		Returns(200, "OK", apis.ListNotificationChannelsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListNotificationChannelsResponse{}))
+
+	ws.Route(ws.POST("/{projectName}/notification_channels").To(n.createNotificationChannel).
+		Doc("create a notification channel of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/notificationChannel", "create")).
+		Reads(apis.CreateNotificationChannelRequest{}).
+		Returns(200, "OK", apis.NotificationChannelBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.NotificationChannelBase{}))
+
+	ws.Route(ws.PUT("/{projectName}/notification_channels/{channelName}").To(n.updateNotificationChannel).
+		Doc("update the notification channel of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("channelName", "identifier of the notification channel").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/notificationChannel", "update")).
+		Reads(apis.UpdateNotificationChannelRequest{}).
+		Returns(200, "OK", apis.NotificationChannelBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.NotificationChannelBase{}))
+
+	ws.Route(ws.DELETE("/{projectName}/notification_channels/{channelName}").To(n.deleteNotificationChannel).
+		Doc("delete the notification channel of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("channelName", "identifier of the notification channel").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/notificationChannel", "delete")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.GET("/{projectName}/notification_subscriptions").To(n.listNotificationSubscriptions).
+		Doc("list the notification subscriptions of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/notificationSubscription", "list")).
+		Returns(200, "OK", apis.ListNotificationSubscriptionsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListNotificationSubscriptionsResponse{}))
+
+	ws.Route(ws.POST("/{projectName}/notification_subscriptions").To(n.createNotificationSubscription).
+		Doc("create a notification subscription of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/notificationSubscription", "create")).
+		Reads(apis.CreateNotificationSubscriptionRequest{}).
+		Returns(200, "OK", apis.NotificationSubscriptionBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.NotificationSubscriptionBase{}))
+
+	ws.Route(ws.PUT("/{projectName}/notification_subscriptions/{subscriptionName}").To(n.updateNotificationSubscription).
+		Doc("update the notification subscription of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("subscriptionName", "identifier of the notification subscription").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/notificationSubscription", "update")).
+		Reads(apis.UpdateNotificationSubscriptionRequest{}).
+		Returns(200, "OK", apis.NotificationSubscriptionBase{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.NotificationSubscriptionBase{}))
+
+	ws.Route(ws.DELETE("/{projectName}/notification_subscriptions/{subscriptionName}").To(n.deleteNotificationSubscription).
+		Doc("delete the notification subscription of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("subscriptionName", "identifier of the notification subscription").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/notificationSubscription", "delete")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.GET("/{projectName}/notification_deliveries").To(n.listNotificationDeliveries).
+		Doc("list the delivery logs of the notifications").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.QueryParameter("subscription", "filter by the subscription").DataType("string")).
+		Param(ws.QueryParameter("status", "filter by the status, pending, succeeded or failed").DataType("string")).
+		Param(ws.QueryParameter("page", "query the page number").DataType("integer")).
+		Param(ws.QueryParameter("pageSize", "query the page size number").DataType("integer")).
+		Filter(n.RbacService.CheckPerm("project/notificationDelivery", "list")).
+		Returns(200, "OK", apis.ListNotificationDeliveriesResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListNotificationDeliveriesResponse{}))
+
+	ws.Route(ws.GET("/{projectName}/application_templates").To(n.listApplicationTemplates).
+		Doc("list the application templates of a project and the platform").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/applicationTemplate", "list")).
+		Returns(200, "OK", apis.ListApplicationTemplateResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListApplicationTemplateResponse{}))
+
+	ws.Route(ws.POST("/{projectName}/application_templates/{templateName}/preview").To(n.previewApplicationTemplate).
+		Doc("render the application template with the parameters").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("templateName", "identifier of the application template").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/applicationTemplate", "detail")).
+		Reads(apis.PreviewApplicationTemplateRequest{}).
+		Returns(200, "OK", apis.PreviewApplicationTemplateResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.PreviewApplicationTemplateResponse{}))
+
+	ws.Route(ws.GET("/{projectName}/application_bundle").To(n.exportApplications).
+		Doc("export all applications of a project as a portable bundle").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.QueryParameter("format", "the format of the bundle, support yaml and tar").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/application", "detail")).
+		Returns(200, "OK", apis.ExportApplicationBundleResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ExportApplicationBundleResponse{}))
+
+	ws.Route(ws.POST("/{projectName}/application_bundle").To(n.importApplications).
+		Doc("import the applications from a bundle to a project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Filter(n.RbacService.CheckPerm("project/application", "create")).
+		Reads(apis.ImportApplicationBundleRequest{}).
+		Returns(200, "OK", apis.ImportApplicationBundleResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ImportApplicationBundleResponse{}))
+
 	initPipelineRoutes(ws, n)
 	ws.Filter(authCheckFilter)
 	return ws
@@ -390,6 +621,28 @@ func (n *project) updateProject(req *restful.Request, res *restful.Response) {
 	}
 }
 
+func (n *project) updatePromotionPath(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var updateReq apis.UpdatePromotionPathRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	projectBase, err := n.ProjectService.UpdatePromotionPath(req.Request.Context(), req.PathParameter("projectName"), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(projectBase); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (n *project) detailProject(req *restful.Request, res *restful.Response) {
 	project, err := n.ProjectService.DetailProject(req.Request.Context(), req.PathParameter("projectName"))
 	if err != nil {
@@ -862,3 +1115,314 @@ func (n *project) deleteDistribution(req *restful.Request, res *restful.Response
 		return
 	}
 }
+
+func (n *project) listRecycleBinItems(req *restful.Request, res *restful.Response) {
+	project, err := n.ProjectService.GetProject(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	items, err := n.RecycleBinService.ListRecycleBinItems(req.Request.Context(), project.Name, req.QueryParameter("type"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(items); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) restoreRecycleBinItem(req *restful.Request, res *restful.Response) {
+	project, err := n.ProjectService.GetProject(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := n.RecycleBinService.RestoreRecycleBinItem(req.Request.Context(), project.Name, req.PathParameter("itemType"), req.PathParameter("itemName")); err != nil {
+		klog.Errorf("restore the recycle bin item failure %s", err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) purgeRecycleBinItem(req *restful.Request, res *restful.Response) {
+	project, err := n.ProjectService.GetProject(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := n.RecycleBinService.PurgeRecycleBinItem(req.Request.Context(), project.Name, req.PathParameter("itemType"), req.PathParameter("itemName")); err != nil {
+		klog.Errorf("purge the recycle bin item failure %s", err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) projectDeliveryMetrics(req *restful.Request, res *restful.Response) {
+	if _, err := n.ProjectService.GetProject(req.Request.Context(), req.PathParameter("projectName")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	getDeliveryMetrics(n.DeliveryMetricService, apis.DeliveryMetricsOptions{Project: req.PathParameter("projectName")}, req, res)
+}
+
+func (n *project) listFreezeWindows(req *restful.Request, res *restful.Response) {
+	listFreezeWindows(n.FreezeWindowService, req.PathParameter("projectName"), req, res)
+}
+
+func (n *project) createFreezeWindow(req *restful.Request, res *restful.Response) {
+	createFreezeWindow(n.FreezeWindowService, req.PathParameter("projectName"), req, res)
+}
+
+func (n *project) updateFreezeWindow(req *restful.Request, res *restful.Response) {
+	updateFreezeWindow(n.FreezeWindowService, req.PathParameter("projectName"), req, res)
+}
+
+func (n *project) deleteFreezeWindow(req *restful.Request, res *restful.Response) {
+	deleteFreezeWindow(n.FreezeWindowService, req.PathParameter("projectName"), req, res)
+}
+
+func (n *project) listNotificationChannels(req *restful.Request, res *restful.Response) {
+	list, err := n.NotificationService.ListNotificationChannels(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(list); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) createNotificationChannel(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateNotificationChannelRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	base, err := n.NotificationService.CreateNotificationChannel(req.Request.Context(), req.PathParameter("projectName"), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(base); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) updateNotificationChannel(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var updateReq apis.UpdateNotificationChannelRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	base, err := n.NotificationService.UpdateNotificationChannel(req.Request.Context(), req.PathParameter("projectName"), req.PathParameter("channelName"), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(base); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) deleteNotificationChannel(req *restful.Request, res *restful.Response) {
+	if err := n.NotificationService.DeleteNotificationChannel(req.Request.Context(), req.PathParameter("projectName"), req.PathParameter("channelName")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) listNotificationSubscriptions(req *restful.Request, res *restful.Response) {
+	list, err := n.NotificationService.ListNotificationSubscriptions(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(list); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) createNotificationSubscription(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var createReq apis.CreateNotificationSubscriptionRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	base, err := n.NotificationService.CreateNotificationSubscription(req.Request.Context(), req.PathParameter("projectName"), createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(base); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) updateNotificationSubscription(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var updateReq apis.UpdateNotificationSubscriptionRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	base, err := n.NotificationService.UpdateNotificationSubscription(req.Request.Context(), req.PathParameter("projectName"), req.PathParameter("subscriptionName"), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(base); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) deleteNotificationSubscription(req *restful.Request, res *restful.Response) {
+	if err := n.NotificationService.DeleteNotificationSubscription(req.Request.Context(), req.PathParameter("projectName"), req.PathParameter("subscriptionName")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) listNotificationDeliveries(req *restful.Request, res *restful.Response) {
+	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	deliveries, err := n.NotificationService.ListNotificationDeliveries(req.Request.Context(), req.PathParameter("projectName"),
+		req.QueryParameter("subscription"), req.QueryParameter("status"), page, pageSize)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(deliveries); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) listApplicationTemplates(req *restful.Request, res *restful.Response) {
+	project, err := n.ProjectService.GetProject(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	templates, err := n.ApplicationService.ListApplicationTemplates(req.Request.Context(), project.Name)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(templates); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) previewApplicationTemplate(req *restful.Request, res *restful.Response) {
+	var previewReq apis.PreviewApplicationTemplateRequest
+	if err := req.ReadEntity(&previewReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	project, err := n.ProjectService.GetProject(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	preview, err := n.ApplicationService.PreviewApplicationTemplate(req.Request.Context(), project.Name, req.PathParameter("templateName"), previewReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(preview); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) exportApplications(req *restful.Request, res *restful.Response) {
+	project, err := n.ProjectService.GetProject(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	bundle, err := n.ApplicationService.ExportApplications(req.Request.Context(), project.Name, nil, req.QueryParameter("format"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(bundle); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *project) importApplications(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var importReq apis.ImportApplicationBundleRequest
+	if err := req.ReadEntity(&importReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&importReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	project, err := n.ProjectService.GetProject(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	result, err := n.ApplicationService.ImportApplications(req.Request.Context(), project.Name, importReq)
+	if err != nil {
+		klog.Errorf("import the applications to the project %s failure %s", project.Name, err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(result); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	"github.com/emicklei/go-restful/v3"
+	"k8s.io/klog/v2"
+
+	"github.com/kubevela/velaux/pkg/server/domain/service"
+	apis "github.com/kubevela/velaux/pkg/server/interfaces/api/dto/v1"
+	"github.com/kubevela/velaux/pkg/server/utils/bcode"
+)
+
+// NewRecycleBin new the platform recycle bin API
+func NewRecycleBin() Interface {
+	return &recycleBin{}
+}
+
+type recycleBin struct {
+	RecycleBinService service.RecycleBinService `inject:""`
+	RbacService       service.RBACService       `inject:""`
+}
+
+// GetWebServiceRoute get web service
+func (r *recycleBin) GetWebServiceRoute() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/recycle_bin").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for the recycle bin of the platform")
+
+	tags := []string{"recycleBin"}
+
+	ws.Route(ws.GET("/").To(r.listRecycleBinItems).
+		Doc("list the deleted applications, projects and pipelines").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(r.RbacService.CheckPerm("recycleBin", "list")).
+		Param(ws.QueryParameter("project", "list the items by project name").DataType("string")).
+		Param(ws.QueryParameter("type", "list the items by type, support application, project and pipeline").DataType("string")).
+		Returns(200, "OK", apis.ListRecycleBinItemsResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.ListRecycleBinItemsResponse{}))
+
+	ws.Route(ws.POST("/{itemType}/{itemName}/restore").To(r.restoreRecycleBinItem).
+		Doc("restore a deleted item before it expires").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(r.RbacService.CheckPerm("recycleBin", "restore")).
+		Param(ws.PathParameter("itemType", "the type of the item, support application, project and pipeline").DataType("string")).
+		Param(ws.PathParameter("itemName", "the name of the item").DataType("string")).
+		Param(ws.QueryParameter("project", "the project name, it is required for the pipeline").DataType("string")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.DELETE("/{itemType}/{itemName}").To(r.purgeRecycleBinItem).
+		Doc("delete a deleted item permanently").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(r.RbacService.CheckPerm("recycleBin", "delete")).
+		Param(ws.PathParameter("itemType", "the type of the item, support application, project and pipeline").DataType("string")).
+		Param(ws.PathParameter("itemName", "the name of the item").DataType("string")).
+		Param(ws.QueryParameter("project", "the project name, it is required for the pipeline").DataType("string")).
+		Returns(200, "OK", apis.EmptyResponse{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Filter(authCheckFilter)
+	return ws
+}
+
+func (r *recycleBin) listRecycleBinItems(req *restful.Request, res *restful.Response) {
+	items, err := r.RecycleBinService.ListRecycleBinItems(req.Request.Context(), req.QueryParameter("project"), req.QueryParameter("type"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(items); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *recycleBin) restoreRecycleBinItem(req *restful.Request, res *restful.Response) {
+	err := r.RecycleBinService.RestoreRecycleBinItem(req.Request.Context(), req.QueryParameter("project"), req.PathParameter("itemType"), req.PathParameter("itemName"))
+	if err != nil {
+		klog.Errorf("restore the recycle bin item failure %s", err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (r *recycleBin) purgeRecycleBinItem(req *restful.Request, res *restful.Response) {
+	err := r.RecycleBinService.PurgeRecycleBinItem(req.Request.Context(), req.QueryParameter("project"), req.PathParameter("itemType"), req.PathParameter("itemName"))
+	if err != nil {
+		klog.Errorf("purge the recycle bin item failure %s", err.Error())
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
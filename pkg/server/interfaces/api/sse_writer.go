@@ -30,3 +30,22 @@ func (sse *SSEWriter) SendMessage(data watch.EventType) error {
 	sse.writer.(http.Flusher).Flush()
 	return nil
 }
+
+// SendEvent send the SSE message with the id and the event name
+func (sse *SSEWriter) SendEvent(id, event string, data []byte) error {
+	message := fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", id, event, data)
+	if _, err := sse.writer.Write([]byte(message)); err != nil {
+		return err
+	}
+	sse.writer.(http.Flusher).Flush()
+	return nil
+}
+
+// SendComment send the SSE comment, it is used to keep the connection alive
+func (sse *SSEWriter) SendComment(comment string) error {
+	if _, err := fmt.Fprintf(sse.writer, ": %s\n\n", comment); err != nil {
+		return err
+	}
+	sse.writer.(http.Flusher).Flush()
+	return nil
+}
@@ -52,6 +52,14 @@ func (c *webhook) GetWebServiceRoute() *restful.WebService {
 		Returns(200, "OK", apis.ApplicationDeployResponse{}).
 		Returns(400, "Bad Request", bcode.Bcode{}).
 		Writes(apis.ApplicationDeployResponse{}))
+
+	ws.Route(ws.POST("/pipelines/{token}").To(c.handlePipelineWebhook).
+		Doc("handle pipeline webhook request").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.PathParameter("token", "webhook token").DataType("string")).
+		Returns(200, "OK", apis.PipelineRun{}).
+		Returns(400, "Bad Request", bcode.Bcode{}).
+		Writes(apis.PipelineRun{}))
 	return ws
 }
 
@@ -66,3 +74,15 @@ func (c *webhook) handleApplicationWebhook(req *restful.Request, res *restful.Re
 		return
 	}
 }
+
+func (c *webhook) handlePipelineWebhook(req *restful.Request, res *restful.Response) {
+	base, err := c.WebhookService.HandlePipelineWebhook(req.Request.Context(), req.PathParameter("token"), req)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(base); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -102,3 +102,90 @@ var ErrApplicationDryRunFailed = NewBcode(400, 10027, "The application dry run f
 
 // ErrApplicationRevisionConflict -
 var ErrApplicationRevisionConflict = NewBcode(400, 10028, "The current revision of the application is equal to the requested revision")
+
+// ErrApplicationTemplateNotExist means the application template or the version is not exist
+var ErrApplicationTemplateNotExist = NewBcode(404, 10029, "the application template is not exist")
+
+// ErrApplicationTemplateVersionExist means the version of the application template has been published
+var ErrApplicationTemplateVersionExist = NewBcode(400, 10030, "the application template version is already exist")
+
+// ErrApplicationTemplateScopeConflict means the template name is used by the template in the other scope
+var ErrApplicationTemplateScopeConflict = NewBcode(400, 10031, "the application template name is used by another project or the platform")
+
+// ErrApplicationTemplateParameterInvalid means the parameter definition or the parameter value is invalid
+var ErrApplicationTemplateParameterInvalid = NewBcode(400, 10032, "the application template parameter is invalid")
+
+// ErrApplicationTemplateComponentConflict means the template and the component can not be set at the same time
+var ErrApplicationTemplateComponentConflict = NewBcode(400, 10033, "the component can not be set when creating the application from the template")
+
+// ErrApplicationCloneEnvInvalid means the env of the cloned application is not exist in the target project
+var ErrApplicationCloneEnvInvalid = NewBcode(400, 10034, "the env of the cloned application does not belong to the target project")
+
+// ErrApplicationBundleInvalid means the content of the application bundle is invalid
+var ErrApplicationBundleInvalid = NewBcode(400, 10035, "the application bundle is invalid")
+
+// ErrApplicationBundleEnvInvalid means the env in the bundle is not exist in the project
+var ErrApplicationBundleEnvInvalid = NewBcode(400, 10036, "the env of the application bundle does not belong to the project, please map it to an env of the project")
+
+// ErrApplicationBundleTargetInvalid means the mapped target is not exist in the project
+var ErrApplicationBundleTargetInvalid = NewBcode(400, 10037, "the mapped target does not belong to the project")
+
+// ErrApplicationBundleConflict means the application exists in another project, it can not be overwritten
+var ErrApplicationBundleConflict = NewBcode(400, 10038, "the application exists in another project or the recycle bin, it can not be overwritten")
+
+// ErrScheduledDeployNotExist means the scheduled deploy is not found
+var ErrScheduledDeployNotExist = NewBcode(404, 10039, "the scheduled deploy is not exist")
+
+// ErrScheduledDeployNotPending means the scheduled deploy has been executed or canceled
+var ErrScheduledDeployNotPending = NewBcode(400, 10040, "the scheduled deploy has been executed or canceled")
+
+// ErrDeployLocked means the application is locked in the env
+var ErrDeployLocked = NewBcode(400, 10041, "the application is locked in the environment, the deploy is not allowed")
+
+// ErrDeployLockExist means there is an active lock in the env
+var ErrDeployLockExist = NewBcode(400, 10042, "the application has been locked in the environment")
+
+// ErrDeployLockNotExist means the deploy lock is not found
+var ErrDeployLockNotExist = NewBcode(404, 10043, "the deploy lock is not exist")
+
+// ErrDeployLockNotActive means the deploy lock has been released or expired
+var ErrDeployLockNotActive = NewBcode(400, 10044, "the deploy lock has been released or expired")
+
+// ErrDeployLockNotOwner means only the owner could release the lock without force
+var ErrDeployLockNotOwner = NewBcode(403, 10045, "the deploy lock is held by another user, breaking it requires the force option and the permission")
+
+// ErrDeployLockExpireTimeInvalid means the expire time of the deploy lock is in the past
+var ErrDeployLockExpireTimeInvalid = NewBcode(400, 10046, "the expire time of the deploy lock must be in the future")
+
+// ErrInvalidWebhookSignature means the webhook request is not signed by the secret of the trigger
+var ErrInvalidWebhookSignature = NewBcode(401, 10047, "the signature of the webhook request is invalid")
+
+// ErrWebhookReplayed means the signed webhook request has been handled or the timestamp is out of the window
+var ErrWebhookReplayed = NewBcode(400, 10048, "the webhook request is replayed or expired")
+
+// ErrWebhookSourceForbidden means the source address is not in the allowlist of the trigger
+var ErrWebhookSourceForbidden = NewBcode(403, 10049, "the source address is not allowed to call the webhook")
+
+// ErrApplicationTriggerCIDRInvalid means the allowed CIDR of the trigger is invalid
+var ErrApplicationTriggerCIDRInvalid = NewBcode(400, 10050, "the allowed CIDR of the trigger is invalid")
+
+// ErrApplicationTriggerFilterInvalid means the branch or tag filter of the trigger is not a valid glob pattern
+var ErrApplicationTriggerFilterInvalid = NewBcode(400, 10051, "the branch or tag filter of the trigger is invalid")
+
+// ErrApplicationTriggerTemplateInvalid means the properties template of the trigger could not be parsed
+var ErrApplicationTriggerTemplateInvalid = NewBcode(400, 10052, "the properties template of the trigger is invalid")
+
+// ErrApplicationTriggerPropertyPathInvalid means the property path of the image trigger is invalid
+var ErrApplicationTriggerPropertyPathInvalid = NewBcode(400, 10053, "the property path of the trigger is invalid")
+
+// ErrApplicationTriggerMappingInvalid means the payload mapping of the trigger is missing or invalid
+var ErrApplicationTriggerMappingInvalid = NewBcode(400, 10054, "the payload mapping of the trigger is invalid")
+
+// ErrWebhookDeliveryNotExist means the webhook delivery is not recorded or has been purged
+var ErrWebhookDeliveryNotExist = NewBcode(404, 10055, "the webhook delivery is not exist")
+
+// ErrWebhookDeliveryNotReplayable means the body of the webhook delivery is truncated and could not be replayed
+var ErrWebhookDeliveryNotReplayable = NewBcode(400, 10056, "the body of the webhook delivery is truncated, it could not be replayed")
+
+// ErrWebhookDeliveryNotVerified means the webhook delivery is rejected by the verification and could not be replayed
+var ErrWebhookDeliveryNotVerified = NewBcode(400, 10057, "the webhook delivery is not verified, it could not be replayed")
@@ -45,3 +45,6 @@ var ErrProjectUserExist = NewBcode(400, 30009, "the user is already exist in thi
 
 // ErrProjectOwnerInvalid means the project owner name is invalid
 var ErrProjectOwnerInvalid = NewBcode(400, 30010, "the project owner name is invalid")
+
+// ErrPromotionPathInvalid means the envs of the promotion path are invalid
+var ErrPromotionPathInvalid = NewBcode(400, 30011, "the envs of the promotion path must belong to the project and can not be repeated")
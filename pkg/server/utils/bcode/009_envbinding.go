@@ -33,3 +33,9 @@ var ErrEnvBindingExist = NewBcode(400, 90005, "application envbinding is exist")
 
 // ErrEnvBindingUpdateWorkflow application envbinding  update workflow error
 var ErrEnvBindingUpdateWorkflow = NewBcode(400, 90006, "application envbinding update workflow error")
+
+// ErrPromotionSameEnv means the source env and the target env of the promotion are the same
+var ErrPromotionSameEnv = NewBcode(400, 90007, "the source env and the target env of the promotion can not be the same")
+
+// ErrPromotionNotAllowed means the promotion does not follow the promotion path of the project
+var ErrPromotionNotAllowed = NewBcode(400, 90008, "the promotion does not follow the promotion path of the project")
@@ -36,3 +36,6 @@ var ErrEnvTargetConflict = NewBcode(400, 11006, "in one project, one target can
 
 // ErrEnvTargetNotAllowDelete means can not remove existing targets from this environment, because there are applications deployed.
 var ErrEnvTargetNotAllowDelete = NewBcode(400, 11007, "target can not be deleted, because there are applications deployed.")
+
+// ErrEnvProtectionInvalid means the approvers of the env protection are invalid
+var ErrEnvProtectionInvalid = NewBcode(400, 11008, "the env protection requires existing approvers and enough of them to satisfy the minimum approvals")
@@ -39,4 +39,24 @@ var (
 	ErrPipelineRunFinished = NewBcode(400, 17011, "pipeline run is finished")
 	// ErrWrongMode means the pipeline run mode is wrong
 	ErrWrongMode = NewBcode(400, 17012, "wrong pipeline run mode, only \"DAG\" and \"StepByStep\" are supported")
+	// ErrPipelineScheduleInvalid means the cron, the time zone or the concurrency policy of the schedule is invalid
+	ErrPipelineScheduleInvalid = NewBcode(400, 17013, "the pipeline schedule is invalid")
+	// ErrPipelineVersionNotExist means the version of the pipeline is not found
+	ErrPipelineVersionNotExist = NewBcode(404, 17014, "the pipeline version is not exist")
+	// ErrPipelineVersionConflict means the pipeline is changed by others at the same time
+	ErrPipelineVersionConflict = NewBcode(400, 17015, "the pipeline is changed by others, please refresh and retry")
+	// ErrPipelineSpecInvalid means the steps of the pipeline are invalid
+	ErrPipelineSpecInvalid = NewBcode(400, 17016, "the pipeline spec is invalid")
+	// ErrContextValueInvalid means the type or the key of the context value is invalid
+	ErrContextValueInvalid = NewBcode(400, 17017, "the context value is invalid")
+	// ErrPipelineParameterInvalid means the declaration or the value of the pipeline parameter is invalid
+	ErrPipelineParameterInvalid = NewBcode(400, 17018, "the pipeline parameter is invalid")
+	// ErrPipelineTriggerNotExist means the trigger of the pipeline is not found
+	ErrPipelineTriggerNotExist = NewBcode(404, 17019, "the pipeline trigger is not exist")
+	// ErrPipelineTriggerExist means the trigger name is used by another trigger of the pipeline
+	ErrPipelineTriggerExist = NewBcode(400, 17020, "the pipeline trigger is exist")
+	// ErrPipelineTriggerInvalid means the settings of the pipeline trigger are invalid
+	ErrPipelineTriggerInvalid = NewBcode(400, 17021, "the pipeline trigger is invalid")
+	// ErrPipelineTriggerLoop means the pipelines trigger each other
+	ErrPipelineTriggerLoop = NewBcode(400, 17022, "the pipeline triggers form a loop")
 )
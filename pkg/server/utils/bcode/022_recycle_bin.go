@@ -0,0 +1,29 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bcode
+
+// ErrRecycleBinItemNotExist means the deleted record is not found in the recycle bin
+var ErrRecycleBinItemNotExist = NewBcode(404, 22001, "the item is not exist in the recycle bin")
+
+// ErrRecycleBinItemExpired means the retention period of the deleted record is over
+var ErrRecycleBinItemExpired = NewBcode(400, 22002, "the item is expired and can not be restored")
+
+// ErrRecycleBinItemTypeNotSupport means the item type is not application, project or pipeline
+var ErrRecycleBinItemTypeNotSupport = NewBcode(400, 22003, "the item type is not supported by the recycle bin")
+
+// ErrRecycleBinProjectDeleted means the project of the item is in the recycle bin too
+var ErrRecycleBinProjectDeleted = NewBcode(400, 22004, "the project is in the recycle bin, please restore the project first")
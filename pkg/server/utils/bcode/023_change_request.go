@@ -0,0 +1,47 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bcode
+
+// ErrChangeRequestNotExist means the change request is not found
+var ErrChangeRequestNotExist = NewBcode(404, 23001, "the change request is not exist")
+
+// ErrChangeRequestNotPending means the change request has been approved, rejected or closed
+var ErrChangeRequestNotPending = NewBcode(400, 23002, "the change request is not pending")
+
+// ErrChangeRequestNotApprover means the user is not an approver of the protected env
+var ErrChangeRequestNotApprover = NewBcode(403, 23003, "you are not an approver of the env")
+
+// ErrChangeRequestSelfReview means the requester can not review the change request
+var ErrChangeRequestSelfReview = NewBcode(400, 23004, "the requester can not review the change request")
+
+// ErrChangeRequestReviewed means the user has reviewed the change request
+var ErrChangeRequestReviewed = NewBcode(400, 23005, "you have reviewed the change request")
+
+// ErrChangeRequestNotApproved means the change request could not be deployed before approved
+var ErrChangeRequestNotApproved = NewBcode(400, 23006, "the change request is not approved")
+
+// ErrChangeRequestOutdated means the application is changed after the change request is created
+var ErrChangeRequestOutdated = NewBcode(400, 23007, "the application is changed after the change request is created, please deploy it again")
+
+// ErrChangeRequestProtectionRemoved means the protection of the env is removed after the change request is created
+var ErrChangeRequestProtectionRemoved = NewBcode(400, 23008, "the protection of the env is removed, please deploy the application again")
+
+// ErrChangeRequestRollbackForbidden means the user is not an approver of the protected env and could not roll back the application
+var ErrChangeRequestRollbackForbidden = NewBcode(403, 23009, "the env is protected, only the approvers could roll back the application")
+
+// ErrChangeRequestPipelineForbidden means the pipeline applies the application to the namespace of a protected env
+var ErrChangeRequestPipelineForbidden = NewBcode(403, 23010, "the pipeline could not apply the application to the protected env, please deploy it by the change request")
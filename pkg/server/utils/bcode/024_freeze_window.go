@@ -0,0 +1,29 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bcode
+
+// ErrFreezeWindowNotExist means the freeze window is not found
+var ErrFreezeWindowNotExist = NewBcode(404, 24001, "the freeze window is not exist")
+
+// ErrFreezeWindowExist means the name of the freeze window is used
+var ErrFreezeWindowExist = NewBcode(400, 24002, "the freeze window is exist")
+
+// ErrFreezeWindowInvalid means the recurrence, the duration or the time range of the freeze window is invalid
+var ErrFreezeWindowInvalid = NewBcode(400, 24003, "the freeze window requires a valid recurrence with the duration, or a valid time range")
+
+// ErrDeployFrozen means the deploy is blocked by an active freeze window
+var ErrDeployFrozen = NewBcode(400, 24004, "the deploy is blocked by an active freeze window")
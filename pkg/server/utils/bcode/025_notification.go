@@ -0,0 +1,41 @@
+/*
+Copyright 2023 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bcode
+
+// ErrNotificationChannelNotExist means the notification channel is not found
+var ErrNotificationChannelNotExist = NewBcode(404, 25001, "the notification channel is not exist")
+
+// ErrNotificationChannelExist means the name of the notification channel is used
+var ErrNotificationChannelExist = NewBcode(400, 25002, "the notification channel is exist")
+
+// ErrNotificationChannelInvalid means the configuration of the channel does not match the type
+var ErrNotificationChannelInvalid = NewBcode(400, 25003, "the url is required for the webhook and the robot channels, the smtp host, the sender and the receivers are required for the email channel")
+
+// ErrNotificationChannelInUse means the channel is used by the subscriptions
+var ErrNotificationChannelInUse = NewBcode(400, 25004, "the notification channel is used by the subscriptions")
+
+// ErrNotificationSubscriptionNotExist means the notification subscription is not found
+var ErrNotificationSubscriptionNotExist = NewBcode(404, 25005, "the notification subscription is not exist")
+
+// ErrNotificationSubscriptionExist means the name of the notification subscription is used
+var ErrNotificationSubscriptionExist = NewBcode(400, 25006, "the notification subscription is exist")
+
+// ErrNotificationTemplateInvalid means the message template could not be parsed
+var ErrNotificationTemplateInvalid = NewBcode(400, 25007, "the message template is invalid")
+
+// ErrNotificationEventTypeInvalid means the event type is not supported
+var ErrNotificationEventTypeInvalid = NewBcode(400, 25008, "the event type is not supported")